@@ -46,9 +46,24 @@ var cmdDisk int
 var cmdOvr int
 var cmdPri int
 var cmdRet int
+var cmdRetryPolicy string
+var cmdTimeLimit string
+var cmdTimeLimitGrace string
+var cmdTimeLimitSignal string
+var cmdBeginAt string
+var cmdWindow string
+var cmdDeadline string
+var cmdArray string
+var cmdArrayList string
+var cmdArrayTSV string
+var cmdArrayName string
 var cmdFile string
 var cmdCwdMatters bool
 var cmdChangeHome bool
+var cmdCache bool
+var cmdLogDir string
+var cmdLogToManager bool
+var cmdHeld bool
 var cmdRepGroup string
 var cmdLimitGroups string
 var cmdDepGroups string
@@ -68,6 +83,8 @@ var cmdCloudConfigs string
 var cmdCloudSharedDisk bool
 var cmdFlavor string
 var cmdMonitorDocker string
+var cmdContainerImage string
+var cmdContainerRuntime string
 var rtimeoutint int
 
 // addCmd represents the add command
@@ -83,8 +100,11 @@ alternatively have only a JSON object in column 1 that also specifies the
 command as one of the name:value pairs. The possible options are:
 
 cmd cwd cwd_matters change_home on_failure on_success on_exit mounts req_grp
-memory time override cpus disk priority retries rep_grp dep_grps deps cmd_deps
-monitor_docker cloud_os cloud_username cloud_ram cloud_script cloud_config_files
+memory time time_limit time_limit_grace time_limit_signal begin_at window
+deadline override cpus disk priority retries retry_policy rep_grp array
+array_list array_tsv array_name dep_grps deps cmd_deps monitor_docker
+container_image container_runtime inputs outputs cache log_dir log_to_manager
+held cloud_os cloud_username cloud_ram cloud_script cloud_config_files
 cloud_flavor cloud_shared env bsub_mode
 
 If any of these will be the same for all your commands, you can instead specify
@@ -132,12 +152,16 @@ currently available behaviours are: "cleanup_all", which takes a boolean value
 and if true will completely delete the actual working directory created when
 cwd_matters is false (no effect when cwd_matters is true); "cleanup", which is
 like cleanup_all except that it doesn't delete files that have been specified as
-inputs or outputs [since you can't currently specify this, the current behaviour
-is identical to cleanup_all]; and "run", which takes a string command to run
-after the main cmd runs. For example [{"run":"cp error.log
+"outputs" (see below); "run", which takes a string command to run
+after the main cmd runs; and "copy_to_manager", which takes an array of paths
+(relative to the actual working directory) of files to copy back to the machine
+that wr manager is running on. For example [{"run":"cp error.log
 /shared/logs/this.log"},{"cleanup":true}] would copy a log file that your cmd
 generated to describe its problems to some shared location and then delete all
-files created by your cmd.
+files created by your cmd. [{"copy_to_manager":["error.log"]}] would instead
+copy the log file to a directory named after the cmd's internal identifier
+within the manager's configured managercopydir; the copied paths are listed by
+"wr status", and deleted if you "wr remove" the cmd.
 
 "on_success" is exactly like on_failure, except that the behaviours trigger when
 your cmd exits 0.
@@ -167,6 +191,13 @@ better job of spawning runners to handle these commands. "memory" values should
 specify a unit, eg "100M" for 100 megabytes, or "1G" for 1 gigabyte. "time"
 values should do the same, eg. "30m" for 30 minutes, or "1h" for 1 hour.
 
+On linux machines with cgroup v2 where the runner is able to create cgroups,
+each command runs in its own cgroup, which limits it to its cpus and (with 25%
+headroom) memory; if it uses more memory than that it is killed and retried
+with more. Its memory (excluding the file cache) and cpu time are then measured
+including any child processes it leaves running (which are killed when it
+exits).
+
 The manager learns how much memory and time commands in the same req_grp
 actually used in the past, and will use its own values unless you set an
 override. For this learning to work well, you should have reason to believe that
@@ -195,6 +226,39 @@ time will be used. If you want to override all 3 resources to disable learning
 completly, you must explicitly supply non-zero values for memory and time and 0
 or more for disk.)
 
+"time_limit" is different to "time": rather than being a hint, it is a hard
+limit on how long your command can run for, regardless of the job scheduler
+being used. Shortly before it is reached ("time_limit_grace" before, default
+1m), your command and its child processes are sent "time_limit_signal" (one of
+SIGTERM (the default), SIGINT, SIGHUP, SIGQUIT, SIGUSR1, SIGUSR2, SIGALRM or
+SIGXCPU), giving them a chance to checkpoint or clean up. If still running once
+the limit is reached, they are killed. Unless the command exited 0 before the
+limit was reached, it is then considered to have failed because it used too
+much time, and will be retried as per "retries" and "retry_policy" (use a
+"retry_policy" with a "time" reason of 0 if retrying would be pointless).
+
+"begin_at" and "window" restrict when your command can start running; until it
+is allowed to, it stays in the "delayed" state. "begin_at" is the earliest time
+it can start, given as a date and time like "2006-01-02 15:04", just a date, an
+RFC3339 time, or a duration from now like "2h". "window" is a comma separated
+list of recurring periods of the week, each being some days and/or a range of
+times, such as "Mon-Fri 19:00-07:00,Sat-Sun" for nights and weekends. Days are
+3 letter day names or a range of them and default to every day; times are 24hr
+and default to the whole day, with ranges that end earlier than they start
+continuing in to the next day. Times are in the manager's local time zone. Use
+the same "window" for all the commands in a report group to keep, for example,
+heavy I/O off a shared filesystem during working hours. Commands are not
+stopped if they are still running when their window closes.
+
+"deadline" is the time by which your command should have completed, in the same
+format as "begin_at". Using the run times learned for its req_grp (or "time"
+until there are some), commands at risk of missing their deadline (because the
+time left is less than double their expected run time) are moved to the front
+of the queue, and if any command in a report group is predicted to finish after
+its deadline, a warning about that report group is shown in the web interface.
+Give all the commands in a report group the same deadline to have the group
+meet a turnaround commitment.
+
 "cpus" tells wr manager exactly how many CPU cores your command needs.
 
 "disk" tells wr manager how much free disk space (in GB) your command needs.
@@ -220,10 +284,46 @@ more memory/time reserved). Once this number of retries is reached, the command
 will be 'buried' until you take manual action to fix the problem and press the
 retry button in the web interface.
 
+"retry_policy" gives finer control over retries. It is an object with the keys
+"backoff", "delay", "max_delay" and "reasons". Failed commands are normally
+delayed for 30s before being retried; "delay" changes this (specify units such
+as s for seconds or m for minutes). "backoff" can be "fixed" (the default),
+"linear" (the nth retry waits n times delay) or "exponential" (each retry waits
+twice as long as the previous one), capped at "max_delay" (default 24h).
+"reasons" lets you override "retries" depending on why the command failed, as
+an object of reason:retries pairs, where reason is one of env, cwd, start,
+cperm, cfound, cexit, exit, ram, disk, time, docker, container, abnormal, lost,
+signal, resource, mount, upload or output. Failures for those reasons don't use up the
+normal "retries". For example:
+{"backoff":"exponential","delay":"1m","max_delay":"1h",
+ "reasons":{"cfound":0,"lost":10,"exit":2}}
+never retries commands that weren't found, retries commands that were lost up to
+10 times and commands that exited non-zero twice, waiting 1m, 2m, 4m etc.
+between attempts.
+
 "rep_grp" is an arbitrary group you can give your commands so you can query
 their status later. This is only used for reporting and presentation purposes
 when viewing status.
 
+"array", "array_list" and "array_tsv" each make a command a template for a job
+array, which the manager expands in to many commands, avoiding the need to
+generate lots of nearly identical lines yourself. Specify only one of them.
+"array" gives the indices of the members, as a comma separated list of numbers
+and ranges of them with an optional step, eg. "1-100" or "1-10,20-100:10".
+"array_list" is a list of values, with a member for each value indexed from 1.
+"array_tsv" is the path to a tab separated file with a header line of parameter
+names and, beneath each, its possible values; there is a member for each
+combination of values (the cartesian product), indexed from 1. In the cmd, cwd
+and rep_grp of each member, {{index}} is replaced with its index, and
+{{param}} with its value of each parameter (for "array_list", the parameter is
+called "value"). Each member's environment also has WR_ARRAY_NAME,
+WR_ARRAY_INDEX and WR_ARRAY_<PARAM> (upper-cased) set. If the cmd doesn't
+change between members, a comment noting the member is appended to it.
+"array_name" names the array (it defaults to the rep_grp, before replacement),
+and that name can be used with --array_name in "wr status", "wr remove" and
+"wr mod" to address the whole array, optionally with --indices to address some
+of its members.
+
 "limit_grps" is an array of arbitrary names you can associate with a command,
 that can be used to limit the number of jobs that run at once in the same group.
 You can optionally suffix a group name with :n where n is a integer new limit
@@ -244,6 +344,14 @@ The value for "cmd_deps" is an array of JSON objects with "cmd" and "cwd"
 name:value pairs (if cwd doesn't matter for a cmd, provide it as an empty
 string). These are static dependencies; once resolved they do not get re-
 evaluated.
+By default a dependency is only satisfied when the commands it refers to
+complete successfully ("afterok"). You can instead prefix a dep_grp in "deps"
+(or a cmd in --cmd_deps) with "afternotok:" to have this command start only
+once those commands get buried (because they failed and ran out of retries),
+or with "afterany:" to have it start once they either complete or get buried,
+eg. "deps":["afternotok:step1"]. In "cmd_deps", supply a "type" name:value pair
+instead. If an afternotok dependency completes successfully, this command will
+be buried, since it can never run.
 
 "monitor_docker" turns on monitoring of a docker container identified by the
 given string, which could be the container's --name or path to its --cidfile. If
@@ -262,6 +370,56 @@ NB: does not handle monitoring of multiple docker containers run by a single
 command. A side effect of monitoring a container is that if you use wr to kill
 the job for this command, wr will also kill the container.
 
+"container_image" has your command run inside a container created from the
+given image, instead of directly on the machine, using the "container_runtime"
+docker (the default), singularity or apptainer (for HPC nodes without a docker
+daemon). Your command is run by the same shell as normal, which must exist in
+the image, as your user, with the actual working directory, its TMPDIR and the
+mount points of any mounts available at the same paths inside the container.
+With docker, the container gets the same environment variables as normal
+(other than PATH and HOSTNAME), is limited to the memory and cpus of the
+command, and its resource usage is monitored as for "monitor_docker" (which is
+ignored); if docker kills the container for using too much memory, the command
+is retried with more. With singularity or apptainer, the image can also be a
+URI like "docker://ubuntu:22.04", and resource limits are left to the job
+scheduler. The runtime must be installed on the machines where the commands
+will run.
+
+"inputs" and "outputs" are arrays of the paths to the files that your command
+reads and creates, respectively. Relative input paths are relative to cwd, while
+relative output paths are relative to the actual working directory (which is
+cwd itself if cwd_matters is true). If your command exits 0 but any of its
+outputs do not exist, it will be buried with a reason of "command did not
+create its declared output(s)". The "cleanup" behaviour will not delete your
+outputs. Like make, if when you add a command all of its outputs already exist
+and are newer than all of its inputs, the command will be marked as complete
+without being run (unless you use --rerun), and commands that depend on it will
+be able to start. Since the actual working directory only exists once a command
+runs, this only applies to relative outputs when cwd_matters is true.
+
+"cache" turns on the reuse of results between identical commands. If a command
+with the same cmd, environment variables and inputs (judged by the checksums of
+their contents) was previously added with cache turned on and completed, then
+when this command is picked up by a runner it will not actually be run: instead
+the outputs of the previous command (which must still exist) will be copied to
+this command's outputs, and it will be marked as complete. "wr status" shows
+how many completed commands reused cached results. This is useful if you
+repeatedly run the same expensive steps in different pipelines.
+
+Normally only the head and tail of your command's STDOUT and STDERR are kept.
+"log_dir" and "log_to_manager" turn on the capture of their complete output for
+every attempt at running your command. With "log_dir", files named like
+"2.stdout" and "2.stderr" (for the second attempt) are written to a sub-
+directory named after the command's internal identifier inside the given
+directory on the machine that runs your command. With "log_to_manager" set to
+true, those files are instead uploaded to a sub-directory of the manager's
+configured managerlogdir. Either way, you can view them with "wr logs".
+
+"held" set to true adds your command in the "held" state. Held commands keep
+their place in the queue, and their dependencies and limit groups, but will
+never be run until you "wr release" them. You can also hold commands after
+adding them with "wr hold".
+
 The "cloud_*" related options let you override the defaults of your cloud
 deployment. For example, if you do 'wr cloud deploy --os "Ubuntu 16" --os_ram
 2048 -u ubuntu -s ~/my_ubuntu_post_creation_script.sh', any commands you add
@@ -344,22 +502,39 @@ func init() {
 	// flags specific to this sub-command
 	addCmd.Flags().StringVarP(&cmdFile, "file", "f", "-", "file containing your commands; - means read from STDIN")
 	addCmd.Flags().StringVarP(&cmdRepGroup, "rep_grp", "i", "manually_added", "reporting group for your commands")
+	addCmd.Flags().StringVar(&cmdArray, "array", "", "make each command a job array with these indices [eg. \"1-100\" or \"1-10,20-100:10\"]")
+	addCmd.Flags().StringVar(&cmdArrayList, "array_list", "", "make each command a job array with a member for each of these comma separated values")
+	addCmd.Flags().StringVar(&cmdArrayTSV, "array_tsv", "", "make each command a job array of the combinations of parameter values in this TSV file")
+	addCmd.Flags().StringVar(&cmdArrayName, "array_name", "", "name for job arrays (default the --rep_grp)")
 	addCmd.Flags().StringVarP(&cmdLimitGroups, "limit_grps", "l", "", "comma-separated list of limit groups")
 	addCmd.Flags().StringVarP(&cmdDepGroups, "dep_grps", "e", "", "comma-separated list of dependency groups")
 	addCmd.Flags().StringVarP(&cmdCwd, "cwd", "c", "", "base for the command's working dir")
 	addCmd.Flags().BoolVar(&cmdCwdMatters, "cwd_matters", false, "--cwd should be used as the actual working directory")
 	addCmd.Flags().BoolVar(&cmdChangeHome, "change_home", false, "when not --cwd_matters, set $HOME to the actual working directory")
+	addCmd.Flags().BoolVar(&cmdCache, "cache", false, "reuse the outputs of identical previously completed commands")
+	addCmd.Flags().StringVar(&cmdLogDir, "log_dir", "", "directory on the runners to write complete STDOUT/ERR log files to")
+	addCmd.Flags().BoolVar(&cmdLogToManager, "log_to_manager", false, "upload complete STDOUT/ERR log files to the manager")
+	addCmd.Flags().BoolVar(&cmdHeld, "hold", false, "add the commands in the held state, so they won't run until you 'wr release' them")
 	addCmd.Flags().StringVarP(&reqGroup, "req_grp", "g", "", "group name for commands with similar reqs")
 	addCmd.Flags().StringVarP(&cmdMem, "memory", "m", "1G", "peak mem est. [specify units such as M for Megabytes or G for Gigabytes]")
 	addCmd.Flags().StringVarP(&cmdTime, "time", "t", "1h", "max time est. [specify units such as m for minutes or h for hours]")
+	addCmd.Flags().StringVar(&cmdTimeLimit, "time_limit", "", "hard time limit, after which commands are killed [specify units such as m for minutes or h for hours]")
+	addCmd.Flags().StringVar(&cmdTimeLimitGrace, "time_limit_grace", "", "how long before --time_limit to send --time_limit_signal (default 1m)")
+	addCmd.Flags().StringVar(&cmdTimeLimitSignal, "time_limit_signal", "", "signal to send when --time_limit is near (default SIGTERM)")
+	addCmd.Flags().StringVar(&cmdBeginAt, "begin_at", "", "don't start the commands before this time [eg. \"2006-01-02 15:04\" or a duration from now like 2h]")
+	addCmd.Flags().StringVar(&cmdWindow, "window", "", "only start the commands during these recurring periods [eg. \"Mon-Fri 19:00-07:00,Sat-Sun\"]")
+	addCmd.Flags().StringVar(&cmdDeadline, "deadline", "", "time by which the commands should complete [eg. \"2006-01-02 15:04\" or a duration from now like 24h]")
 	addCmd.Flags().Float64Var(&cmdCPUs, "cpus", 1, "cpu cores needed")
 	addCmd.Flags().IntVar(&cmdDisk, "disk", 0, "number of GB of disk space required (default 0)")
 	addCmd.Flags().IntVarP(&cmdOvr, "override", "o", 0, "[0|1|2] should your mem/time estimates override? (default 0)")
 	addCmd.Flags().IntVarP(&cmdPri, "priority", "p", 0, "[0-255] command priority (default 0)")
 	addCmd.Flags().IntVarP(&cmdRet, "retries", "r", 3, "[0-255] number of automatic retries for failed commands")
+	addCmd.Flags().StringVar(&cmdRetryPolicy, "retry_policy", "", "backoff and per-fail-reason retries for failed commands, in JSON format")
 	addCmd.Flags().StringVar(&cmdCmdDeps, "cmd_deps", "", "dependencies of your commands, in the form \"command1,cwd1,command2,cwd2...\"")
 	addCmd.Flags().StringVarP(&cmdGroupDeps, "deps", "d", "", "dependencies of your commands, in the form \"dep_grp1,dep_grp2...\"")
 	addCmd.Flags().StringVar(&cmdMonitorDocker, "monitor_docker", "", "monitor resource usage of docker container with given --name or --cidfile path")
+	addCmd.Flags().StringVar(&cmdContainerImage, "container_image", "", "run the commands inside a container created from this image")
+	addCmd.Flags().StringVar(&cmdContainerRuntime, "container_runtime", "", "[docker|singularity|apptainer] software that runs --container_image (default docker)")
 	addCmd.Flags().StringVar(&cmdOnFailure, "on_failure", "", "behaviours to carry out when cmds fails, in JSON format")
 	addCmd.Flags().StringVar(&cmdOnSuccess, "on_success", "", "behaviours to carry out when cmds succeed, in JSON format")
 	addCmd.Flags().StringVar(&cmdOnExit, "on_exit", `[{"cleanup":true}]`, "behaviours to carry out when cmds finish running, in JSON format")
@@ -385,10 +560,14 @@ func init() {
 	}
 }
 
-// convert cmd,cwd columns in to Dependency.
+// convert cmd,cwd columns in to Dependency. The cmd columns can be prefixed
+// with a dependency type, eg. "afternotok:command1".
 func colsToDeps(cols []string) (deps jobqueue.Dependencies) {
 	for i := 0; i < len(cols); i += 2 {
-		deps = append(deps, jobqueue.NewEssenceDependency(cols[i], cols[i+1]))
+		depType, cmd := jobqueue.SplitDependencyType(cols[i])
+		dep := jobqueue.NewEssenceDependency(cmd, cols[i+1])
+		dep.Type = depType
+		deps = append(deps, dep)
 	}
 	return
 }
@@ -401,6 +580,88 @@ func groupsToDeps(groups string) (deps jobqueue.Dependencies) {
 	return
 }
 
+// retryPolicyParse converts a --retry_policy JSON string in to a RetryPolicy,
+// dying if it is invalid.
+func retryPolicyParse(jsonString string) *jobqueue.RetryPolicy {
+	var rpj jobqueue.RetryPolicyViaJSON
+	err := json.Unmarshal([]byte(jsonString), &rpj)
+	if err != nil {
+		die("bad --retry_policy: %s", err)
+	}
+	rp, err := rpj.RetryPolicy()
+	if err != nil {
+		die("bad --retry_policy: %s", err)
+	}
+	return rp
+}
+
+// timeLimitParse converts --time_limit* values in to a TimeLimit, dying if they
+// are invalid.
+func timeLimitParse(limit, grace, signal string) *jobqueue.TimeLimit {
+	if limit == "" && (grace != "" || signal != "") {
+		die("--time_limit_grace and --time_limit_signal require --time_limit")
+	}
+	tl, err := jobqueue.ParseTimeLimit(limit, grace, signal)
+	if err != nil {
+		die("bad --time_limit: %s", err)
+	}
+	return tl
+}
+
+// containerParse converts --container_* values in to a Container, dying if
+// they are invalid.
+func containerParse(image, runtime string) *jobqueue.Container {
+	c, err := jobqueue.NewContainer(image, runtime)
+	if err != nil {
+		die("bad --container_image: %s", err)
+	}
+	return c
+}
+
+// beginAtParse converts a --begin_at value in to a time, dying if it is
+// invalid.
+func beginAtParse(when string) time.Time {
+	t, err := jobqueue.ParseBeginAt(when)
+	if err != nil {
+		die("bad --begin_at: %s", err)
+	}
+	return t
+}
+
+// deadlineParse converts a --deadline value in to a time, dying if it is
+// invalid.
+func deadlineParse(when string) time.Time {
+	t, err := jobqueue.ParseBeginAt(when)
+	if err != nil {
+		die("bad --deadline: %s", err)
+	}
+	return t
+}
+
+// arrayParse converts the --array* values in to a JobArray, dying if they are
+// invalid.
+func arrayParse(indices, list, tsvPath, name string) *jobqueue.JobArray {
+	var values []string
+	if list != "" {
+		values = strings.Split(list, ",")
+	}
+	a, err := jobqueue.NewJobArray(indices, values, tsvPath, name)
+	if err != nil {
+		die("bad --array: %s", err)
+	}
+	return a
+}
+
+// windowParse converts a --window value in to a Window, dying if it is
+// invalid.
+func windowParse(spec string) *jobqueue.Window {
+	w, err := jobqueue.ParseWindow(spec)
+	if err != nil {
+		die("bad --window: %s", err)
+	}
+	return w
+}
+
 // parseCmdFile reads the given cmd file to get desired jobs, modified by
 // defaults specified in other command line args. Returns job slice, bool for if
 // the manager is on the same host as us, and bool for if any job defaulted to
@@ -437,6 +698,10 @@ func parseCmdFile(jq *jobqueue.Client, diskSet bool) ([]*jobqueue.Job, bool, boo
 		Cwd:              cmdCwd,
 		CwdMatters:       cmdCwdMatters,
 		ChangeHome:       cmdChangeHome,
+		Cache:            cmdCache,
+		LogDir:           cmdLogDir,
+		LogToManager:     cmdLogToManager,
+		Held:             cmdHeld,
 		CPUs:             cmdCPUs,
 		Disk:             cmdDisk,
 		DiskSet:          diskSet,
@@ -523,6 +788,17 @@ func parseCmdFile(jq *jobqueue.Client, diskSet bool) ([]*jobqueue.Job, bool, boo
 		jd.OnExit = bjs.Behaviours(jobqueue.OnExit)
 	}
 
+	if cmdRetryPolicy != "" {
+		jd.RetryPolicy = retryPolicyParse(cmdRetryPolicy)
+	}
+
+	jd.TimeLimit = timeLimitParse(cmdTimeLimit, cmdTimeLimitGrace, cmdTimeLimitSignal)
+	jd.BeginAt = beginAtParse(cmdBeginAt)
+	jd.Window = windowParse(cmdWindow)
+	jd.Deadline = deadlineParse(cmdDeadline)
+	jd.Array = arrayParse(cmdArray, cmdArrayList, cmdArrayTSV, cmdArrayName)
+	jd.Container = containerParse(cmdContainerImage, cmdContainerRuntime)
+
 	if mountJSON != "" || mountSimple != "" {
 		jd.MountConfigs = mountParse(mountJSON, mountSimple)
 	}
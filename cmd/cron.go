@@ -0,0 +1,240 @@
+// Copyright © 2026 Genome Research Limited
+// Author: Sendu Bala <sb10@sanger.ac.uk>.
+//
+//  This file is part of wr.
+//
+//  wr is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Lesser General Public License as published by
+//  the Free Software Foundation, either version 3 of the License, or
+//  (at your option) any later version.
+//
+//  wr is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Lesser General Public License for more details.
+//
+//  You should have received a copy of the GNU Lesser General Public License
+//  along with wr. If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/VertebrateResequencing/wr/jobqueue"
+	"github.com/spf13/cobra"
+)
+
+// options for this cmd
+var cronName string
+var cronSchedule string
+var cronOverlap string
+var cronRepGroup string
+
+// cronCmd represents the cron command
+var cronCmd = &cobra.Command{
+	Use:   "cron",
+	Short: "Add commands on a recurring schedule",
+	Long: `Have the manager add a command to its queue on a recurring schedule.
+
+Instead of using system crontabs that call "wr add", which break whenever the
+manager is redeployed, you can store a command and a cron schedule in the
+manager itself with the 'add' sub-command. Each time the schedule fires, the
+manager adds a new copy of the command, which then runs like any other.
+
+Crons are stored in the manager's database, so they survive the manager being
+restarted. If a schedule fired while the manager was not running, the command
+is added once, soon after the manager starts again.
+
+Each command added by a cron has the environment variable WR_CRON_NAME set to
+the name of the cron, and WR_CRON_TIME set to the time the schedule fired, in
+RFC3339 format.`,
+}
+
+// add sub-command stores a new cron
+var cronAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Add a cron",
+	Long: `Add a command that the manager will add to its queue on a recurring schedule.
+
+Provide a unique --name for the cron, a --schedule and a file containing the
+single command, in the same format as taken by "wr add". The command options
+below, and any of the JSON options of "wr add", apply to the command, which
+will belong to you. Its report group (-i) defaults to the name of the cron.
+Adding a cron with the same name as an existing one of yours replaces it.
+
+The --schedule is a standard 5 field cron schedule, in the manager's local time
+zone: minute (0-59), hour (0-23), day of month (1-31), month (1-12 or Jan-Dec)
+and day of week (0-7 or Sun-Sat, where 0 and 7 are both Sunday). Each field can
+be *, a number, a range like 1-5, a list like 1,3,5, and can have a step like
+*/15. If both day of month and day of week are restricted, either matching
+will do. The shorthands @hourly, @daily (or @midnight), @weekly, @monthly and
+@yearly (or @annually) are also accepted. For example, "30 2 * * Mon-Fri" is
+2:30am on weekdays.
+
+--overlap says what to do if the schedule fires while the command added the
+previous time hasn't finished yet (it's still in the queue, which includes if it
+was buried; kick or remove a buried command to let the cron continue):
+  skip:  don't add it this time (the default)
+  queue: add it as soon as the previous one finishes; any further firings in
+         the mean time are skipped
+  allow: add it anyway, so it can run at the same time as the previous one;
+         to tell them apart, the command line of each one is suffixed with a
+         shell comment noting the time the schedule fired`,
+	Run: func(combraCmd *cobra.Command, args []string) {
+		if cronName == "" {
+			die("--name is required")
+		}
+		if cronSchedule == "" {
+			die("--schedule is required")
+		}
+		cmdRepGroup = cronRepGroup
+		if cmdRepGroup == "" {
+			cmdRepGroup = cronName
+		}
+
+		jq := connect(time.Duration(timeoutint) * time.Second)
+		defer func() {
+			err := jq.Disconnect()
+			if err != nil {
+				warn("Disconnecting from the server failed: %s", err)
+			}
+		}()
+
+		jobs, isLocal, _ := parseCmdFile(jq, combraCmd.Flags().Changed("disk"))
+		if len(jobs) != 1 {
+			die("the file must contain exactly 1 command, not %d", len(jobs))
+		}
+
+		var envVars []string
+		if isLocal {
+			envVars = os.Environ()
+		}
+
+		c := &jobqueue.Cron{Name: cronName, Schedule: cronSchedule, Overlap: cronOverlap, Job: jobs[0]}
+		err := jq.AddCron(c, envVars)
+		if err != nil {
+			die("failed to add cron %s: %s", cronName, err)
+		}
+		info("Added cron %s", cronName)
+	},
+}
+
+// remove sub-command removes a cron
+var cronRemoveCmd = &cobra.Command{
+	Use:   "remove",
+	Short: "Remove a cron",
+	Long: `Remove a cron, so that it adds no more commands to the queue.
+
+Commands it already added are unaffected. Unless you are an admin, you can only
+remove your own crons.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if cronName == "" {
+			die("--name is required")
+		}
+		jq := connect(time.Duration(timeoutint) * time.Second)
+		defer func() {
+			err := jq.Disconnect()
+			if err != nil {
+				warn("Disconnecting from the server failed: %s", err)
+			}
+		}()
+
+		removed, err := jq.RemoveCron(cronName)
+		if err != nil {
+			die("failed to remove cron %s: %s", cronName, err)
+		}
+		if !removed {
+			die("there is no cron named %s", cronName)
+		}
+		info("Removed cron %s", cronName)
+	},
+}
+
+// list sub-command shows all crons
+var cronListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List crons",
+	Long: `List all the crons, showing when each will next add its command, and when it
+last did so.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		jq := connect(time.Duration(timeoutint) * time.Second)
+		defer func() {
+			err := jq.Disconnect()
+			if err != nil {
+				warn("Disconnecting from the server failed: %s", err)
+			}
+		}()
+
+		crons, err := jq.GetCrons()
+		if err != nil {
+			die("failed to get crons: %s", err)
+		}
+		if len(crons) == 0 {
+			info("no crons have been added")
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 2, 2, 3, ' ', 0)
+		_, err = fmt.Fprintln(w, "NAME\tSCHEDULE\tOVERLAP\tOWNER\tNEXT\tLAST\tCMD")
+		if err != nil {
+			warn("failed to print header: %s", err)
+		}
+		for _, c := range crons {
+			last := cronTimeString(c.Last)
+			if c.Pending {
+				last += " (queued)"
+			}
+			_, err = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", c.Name, c.Schedule, c.Overlap, c.Owner,
+				cronTimeString(c.Next), last, c.Job.Cmd)
+			if err != nil {
+				warn("failed to print line: %s", err)
+			}
+		}
+		err = w.Flush()
+		if err != nil {
+			warn("failed to flush output: %s", err)
+		}
+	},
+}
+
+// cronTimeString formats a cron's time for display, with "-" for no time.
+func cronTimeString(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format(shortTimeFormat)
+}
+
+func init() {
+	RootCmd.AddCommand(cronCmd)
+	cronCmd.AddCommand(cronAddCmd)
+	cronCmd.AddCommand(cronRemoveCmd)
+	cronCmd.AddCommand(cronListCmd)
+
+	// flags specific to these sub-commands
+	cronAddCmd.Flags().StringVarP(&cronName, "name", "n", "", "unique name for the cron")
+	cronAddCmd.Flags().StringVarP(&cronSchedule, "schedule", "s", "", "cron schedule, eg. \"30 2 * * Mon-Fri\" or @daily")
+	cronAddCmd.Flags().StringVar(&cronOverlap, "overlap", jobqueue.CronOverlapSkip, "what to do if the previous command hasn't finished [skip|queue|allow]")
+	cronAddCmd.Flags().StringVarP(&cmdFile, "file", "f", "-", "file containing your command; - means read from STDIN")
+	cronAddCmd.Flags().StringVarP(&cronRepGroup, "rep_grp", "i", "", "reporting group for your command (default the cron's --name)")
+	cronAddCmd.Flags().StringVarP(&cmdLimitGroups, "limit_grps", "l", "", "comma-separated list of limit groups")
+	cronAddCmd.Flags().StringVarP(&cmdCwd, "cwd", "c", "", "base for the command's working dir")
+	cronAddCmd.Flags().BoolVar(&cmdCwdMatters, "cwd_matters", false, "--cwd should be used as the actual working directory")
+	cronAddCmd.Flags().StringVarP(&reqGroup, "req_grp", "g", "", "group name for commands with similar reqs")
+	cronAddCmd.Flags().StringVarP(&cmdMem, "memory", "m", "1G", "peak mem est. [specify units such as M for Megabytes or G for Gigabytes]")
+	cronAddCmd.Flags().StringVarP(&cmdTime, "time", "t", "1h", "max time est. [specify units such as m for minutes or h for hours]")
+	cronAddCmd.Flags().StringVar(&cmdTimeLimit, "time_limit", "", "hard time limit, after which the command is killed [specify units such as m for minutes or h for hours]")
+	cronAddCmd.Flags().StringVar(&cmdWindow, "window", "", "only start the command during these recurring periods [eg. \"Mon-Fri 19:00-07:00,Sat-Sun\"]")
+	cronAddCmd.Flags().Float64Var(&cmdCPUs, "cpus", 1, "cpu cores needed")
+	cronAddCmd.Flags().IntVar(&cmdDisk, "disk", 0, "number of GB of disk space required (default 0)")
+	cronAddCmd.Flags().IntVarP(&cmdPri, "priority", "p", 0, "[0-255] command priority (default 0)")
+	cronAddCmd.Flags().IntVarP(&cmdRet, "retries", "r", 3, "[0-255] number of automatic retries for a failed command")
+	cronAddCmd.Flags().StringVarP(&cmdGroupDeps, "deps", "d", "", "dependencies of your command, in the form \"dep_grp1,dep_grp2...\"")
+	cronAddCmd.Flags().StringVar(&cmdEnv, "env", "", "comma-separated list of key=value environment variables to set before running the command")
+	cronRemoveCmd.Flags().StringVarP(&cronName, "name", "n", "", "name of the cron to remove")
+	cronCmd.PersistentFlags().IntVar(&timeoutint, "timeout", 120, "how long (seconds) to wait to get a reply from 'wr manager'")
+}
@@ -0,0 +1,119 @@
+// Copyright © 2026 Genome Research Limited
+// Author: Sendu Bala <sb10@sanger.ac.uk>.
+//
+//  This file is part of wr.
+//
+//  wr is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Lesser General Public License as published by
+//  the Free Software Foundation, either version 3 of the License, or
+//  (at your option) any later version.
+//
+//  wr is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Lesser General Public License for more details.
+//
+//  You should have received a copy of the GNU Lesser General Public License
+//  along with wr. If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// explainCmd represents the explain command
+var explainCmd = &cobra.Command{
+	Use:   "explain",
+	Short: "Explain why commands are not running",
+	Long: `You can find out why commands you've previously added with "wr add" are
+not running (yet) using this command.
+
+For each command, its current state is shown along with everything the manager
+knows of that is holding it back, such as:
+
+  - the other commands it depends on that have not yet finished, and their
+    current state
+  - a full limit group (see "wr limit")
+  - its owner or report group being over quota (see "wr quota")
+  - the manager being paused or drained
+  - a begin time (--begin_at) or window (--window) that has not been reached
+  - no runners having been requested, or there not being enough free resources
+    to run them
+  - recent quota problems reported by the scheduler, or bad cloud servers
+  - its resource requirements being more than the scheduler can ever provide
+
+Specify one of the flags -f, -l or -i to choose which commands you want
+explained.
+
+-i is the report group (-i) you supplied to "wr add" when you added the job(s)
+you want explained. Combining with -z lets you explain jobs in multiple report
+groups, assuming you have arranged that related groups share some substring.
+Alternatively -y lets you specify -i as the internal job id reported during
+"wr status".
+
+The file to provide -f is in the format taken by "wr add".
+
+In -f and -l mode you must provide the cwd the commands were set to run in, if
+CwdMatters (and must NOT be provided otherwise). Likewise provide the mounts
+options that was used when the command was added, if any. You can do this by
+using the -c and --mounts/--mounts_json options in -l mode, or by providing the
+same file you gave to "wr add" in -f mode.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		set := countGetJobArgs()
+		if set > 1 {
+			die("-f, -i and -l are mutually exclusive; only specify one of them")
+		}
+		if set == 0 {
+			die("1 of -f, -i or -l is required")
+		}
+
+		timeout := time.Duration(timeoutint) * time.Second
+		jq := connect(timeout)
+		var err error
+		defer func() {
+			err = jq.Disconnect()
+			if err != nil {
+				warn("Disconnecting from the server failed: %s", err)
+			}
+		}()
+
+		jobs := getJobs(jq, "", false, 0, false, false)
+		if len(jobs) == 0 {
+			die("No matching jobs found")
+		}
+
+		exps, err := jq.Explain(jobsToJobEssenses(jobs))
+		if err != nil {
+			die("failed to explain the desired jobs: %s", err)
+		}
+
+		for i, exp := range exps {
+			if i > 0 {
+				fmt.Printf("\n")
+			}
+			fmt.Printf("# %s\nId: %s\nState: %s\n", exp.Cmd, exp.Key, exp.State)
+			for _, reason := range exp.Reasons {
+				fmt.Printf("  - %s\n", reason)
+			}
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(explainCmd)
+
+	// flags specific to this sub-command
+	explainCmd.Flags().StringVarP(&cmdFileStatus, "file", "f", "", "file containing commands you want explained; - means read from STDIN")
+	explainCmd.Flags().StringVarP(&cmdIDStatus, "identifier", "i", "", "identifier of the commands you want explained")
+	explainCmd.Flags().BoolVarP(&cmdIDIsSubStr, "search", "z", false, "treat -i as a substring to match against all report groups")
+	explainCmd.Flags().BoolVarP(&cmdIDIsInternal, "internal", "y", false, "treat -i as an internal job id")
+	explainCmd.Flags().StringVarP(&cmdLine, "cmdline", "l", "", "a command line you want explained")
+	explainCmd.Flags().StringVarP(&cmdCwd, "cwd", "c", "", "working dir that the command(s) specified by -l or -f were set to run in")
+	explainCmd.Flags().StringVarP(&mountJSON, "mount_json", "j", "", "mounts that the command(s) specified by -l or -f were set to use (JSON format)")
+	explainCmd.Flags().StringVar(&mountSimple, "mounts", "", "mounts that the command(s) specified by -l or -f were set to use (simple format)")
+	explainCmd.Flags().IntVar(&timeoutint, "timeout", 120, "how long (seconds) to wait to get a reply from 'wr manager'")
+}
@@ -0,0 +1,168 @@
+// Copyright © 2026 Genome Research Limited
+// Author: Sendu Bala <sb10@sanger.ac.uk>.
+//
+//  This file is part of wr.
+//
+//  wr is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Lesser General Public License as published by
+//  the Free Software Foundation, either version 3 of the License, or
+//  (at your option) any later version.
+//
+//  wr is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Lesser General Public License for more details.
+//
+//  You should have received a copy of the GNU Lesser General Public License
+//  along with wr. If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"time"
+
+	"github.com/VertebrateResequencing/wr/jobqueue"
+	"github.com/spf13/cobra"
+)
+
+// holdCmd represents the hold command
+var holdCmd = &cobra.Command{
+	Use:   "hold",
+	Short: "Stop commands from being run until released",
+	Long: `You can stop commands you've previously added with "wr add" from being
+run, without removing them, using this command.
+
+Held commands are put in the "held" state. They keep their place in the queue,
+along with their dependencies and limit groups, but will never be started and
+no runners will be requested for them until you release them with
+"wr release". Commands that depend on held commands will keep waiting. You can
+also add commands in the held state in the first place using "wr add --hold".
+
+Specify one of the flags -f, -l, -i or -a to choose which commands you want to
+hold. Amongst those, only delayed, dependent and ready jobs will be affected;
+running commands can be stopped with "wr suspend" or "wr kill" instead.
+
+-i is the report group (-i) you supplied to "wr add" when you added the job(s)
+you want to now hold. Combining with -z lets you hold jobs in multiple report
+groups, assuming you have arranged that related groups share some substring.
+Alternatively -y lets you specify -i as the internal job id reported during
+"wr status".
+
+The file to provide -f is in the format taken by "wr add".
+
+In -f and -l mode you must provide the cwd the commands were set to run in, if
+CwdMatters (and must NOT be provided otherwise). Likewise provide the mounts
+options that was used when the command was added, if any. You can do this by
+using the -c and --mounts/--mounts_json options in -l mode, or by providing the
+same file you gave to "wr add" in -f mode.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		holdOrRelease(true)
+	},
+}
+
+// releaseCmd represents the release command
+var releaseCmd = &cobra.Command{
+	Use:   "release",
+	Short: "Let held commands run",
+	Long: `You can let commands that you previously held with "wr hold" (or added
+with "wr add --hold") run again using this command.
+
+Released commands return to the state they would otherwise be in: "dependent"
+if they still have unfinished dependencies, "delayed" if they were waiting to
+be retried, or "ready" to run.
+
+Specify one of the flags -f, -l, -i or -a to choose which commands you want to
+release. Amongst those, only held jobs will be affected.
+
+-i is the report group (-i) you supplied to "wr add" when you added the job(s)
+you want to now release. Combining with -z lets you release jobs in multiple
+report groups, assuming you have arranged that related groups share some
+substring. Alternatively -y lets you specify -i as the internal job id reported
+during "wr status".
+
+The file to provide -f is in the format taken by "wr add".
+
+In -f and -l mode you must provide the cwd the commands were set to run in, if
+CwdMatters (and must NOT be provided otherwise). Likewise provide the mounts
+options that was used when the command was added, if any. You can do this by
+using the -c and --mounts/--mounts_json options in -l mode, or by providing the
+same file you gave to "wr add" in -f mode.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		holdOrRelease(false)
+	},
+}
+
+// holdOrRelease implements "wr hold" and "wr release".
+func holdOrRelease(hold bool) {
+	set := countGetJobArgs()
+	if set > 1 {
+		die("-f, -i, -l and -a are mutually exclusive; only specify one of them")
+	}
+	if set == 0 {
+		die("1 of -f, -i, -l or -a is required")
+	}
+
+	timeout := time.Duration(timeoutint) * time.Second
+	jq := connect(timeout)
+	var err error
+	defer func() {
+		err = jq.Disconnect()
+		if err != nil {
+			warn("Disconnecting from the server failed: %s", err)
+		}
+	}()
+
+	jstate := jobqueue.JobStateDeletable
+	if !hold {
+		jstate = jobqueue.JobStateHeld
+	}
+	jobs := getJobs(jq, jstate, cmdAll, 0, false, false)
+
+	if len(jobs) == 0 {
+		die("No matching jobs found")
+	}
+
+	jes := jobsToJobEssenses(jobs)
+	if hold {
+		held, errh := jq.HoldJobs(jes)
+		if errh != nil {
+			die("failed to hold desired jobs: %s", errh)
+		}
+		info("Held %d incomplete commands (out of %d eligible)", held, len(jobs))
+		return
+	}
+
+	released, err := jq.UnholdJobs(jes)
+	if err != nil {
+		die("failed to release desired jobs: %s", err)
+	}
+	info("Released %d held commands (out of %d eligible)", released, len(jobs))
+}
+
+func init() {
+	RootCmd.AddCommand(holdCmd)
+	RootCmd.AddCommand(releaseCmd)
+
+	// flags specific to these sub-commands
+	holdCmd.Flags().BoolVarP(&cmdAll, "all", "a", false, "hold all incomplete jobs")
+	holdCmd.Flags().StringVarP(&cmdFileStatus, "file", "f", "", "file containing commands you want to hold; - means read from STDIN")
+	holdCmd.Flags().StringVarP(&cmdIDStatus, "identifier", "i", "", "identifier of the commands you want to hold")
+	holdCmd.Flags().BoolVarP(&cmdIDIsSubStr, "search", "z", false, "treat -i as a substring to match against all report groups")
+	holdCmd.Flags().BoolVarP(&cmdIDIsInternal, "internal", "y", false, "treat -i as an internal job id")
+	holdCmd.Flags().StringVarP(&cmdLine, "cmdline", "l", "", "a command line you want to hold")
+	holdCmd.Flags().StringVarP(&cmdCwd, "cwd", "c", "", "working dir that the command(s) specified by -l or -f were set to run in")
+	holdCmd.Flags().StringVarP(&mountJSON, "mount_json", "j", "", "mounts that the command(s) specified by -l or -f were set to use (JSON format)")
+	holdCmd.Flags().StringVar(&mountSimple, "mounts", "", "mounts that the command(s) specified by -l or -f were set to use (simple format)")
+	holdCmd.Flags().IntVar(&timeoutint, "timeout", 120, "how long (seconds) to wait to get a reply from 'wr manager'")
+
+	releaseCmd.Flags().BoolVarP(&cmdAll, "all", "a", false, "release all held jobs")
+	releaseCmd.Flags().StringVarP(&cmdFileStatus, "file", "f", "", "file containing commands you want to release; - means read from STDIN")
+	releaseCmd.Flags().StringVarP(&cmdIDStatus, "identifier", "i", "", "identifier of the commands you want to release")
+	releaseCmd.Flags().BoolVarP(&cmdIDIsSubStr, "search", "z", false, "treat -i as a substring to match against all report groups")
+	releaseCmd.Flags().BoolVarP(&cmdIDIsInternal, "internal", "y", false, "treat -i as an internal job id")
+	releaseCmd.Flags().StringVarP(&cmdLine, "cmdline", "l", "", "a command line you want to release")
+	releaseCmd.Flags().StringVarP(&cmdCwd, "cwd", "c", "", "working dir that the command(s) specified by -l or -f were set to run in")
+	releaseCmd.Flags().StringVarP(&mountJSON, "mount_json", "j", "", "mounts that the command(s) specified by -l or -f were set to use (JSON format)")
+	releaseCmd.Flags().StringVar(&mountSimple, "mounts", "", "mounts that the command(s) specified by -l or -f were set to use (simple format)")
+	releaseCmd.Flags().IntVar(&timeoutint, "timeout", 120, "how long (seconds) to wait to get a reply from 'wr manager'")
+}
@@ -0,0 +1,232 @@
+// Copyright © 2026 Genome Research Limited
+// Author: Sendu Bala <sb10@sanger.ac.uk>.
+//
+//  This file is part of wr.
+//
+//  wr is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Lesser General Public License as published by
+//  the Free Software Foundation, either version 3 of the License, or
+//  (at your option) any later version.
+//
+//  wr is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Lesser General Public License for more details.
+//
+//  You should have received a copy of the GNU Lesser General Public License
+//  along with wr. If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/VertebrateResequencing/wr/jobqueue"
+	"github.com/spf13/cobra"
+)
+
+// options for this cmd
+var logsAttempt int
+var logsStdout bool
+var logsStderr bool
+var logsHead int
+var logsTail int
+var logsFollow bool
+
+// logsCmd represents the logs command
+var logsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "Show the complete output of commands",
+	Long: `You can see the complete STDOUT and STDERR of commands you've
+previously added with "wr add" using this command.
+
+This only works for commands that were added with the log_dir or
+log_to_manager options (see "wr add -h"); for other commands, "wr status" shows
+the head and tail of their output.
+
+Specify one of the flags -f, -l or -i to choose which commands you want to see
+the output of.
+
+-i is the report group (-i) you supplied to "wr add" when you added the job(s)
+you want the output of. Combining with -z lets you get the output of jobs in
+multiple report groups, assuming you have arranged that related groups share
+some substring. Alternatively -y lets you specify -i as the internal job id
+reported during "wr status".
+
+The file to provide -f is in the format taken by "wr add".
+
+In -f and -l mode you must provide the cwd the commands were set to run in, if
+CwdMatters (and must NOT be provided otherwise). Likewise provide the mounts
+options that was used when the command was added, if any. You can do this by
+using the -c and --mounts/--mounts_json options in -l mode, or by providing the
+same file you gave to "wr add" in -f mode.
+
+By default you see the output of the most recent attempt at running each
+command; use --attempt to choose an earlier one (1 is the first attempt).
+--stdout and --stderr restrict output to just that stream, and --head and
+--tail show only that many lines from the start or end of the output.
+
+With log_dir, the log files are read directly from the directory the commands
+wrote them to, so this only works if that directory is accessible from the
+machine you run this on (eg. it is on a shared disk).
+
+--follow instead streams the STDOUT and STDERR of a single running command as
+they are produced, until it stops running. This works for any command,
+regardless of log_dir and log_to_manager, but only the most recent 1MB of
+output produced before you start following is available.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		set := countGetJobArgs()
+		if set > 1 {
+			die("-f, -i and -l are mutually exclusive; only specify one of them")
+		}
+		if set == 0 {
+			die("1 of -f, -i or -l is required")
+		}
+		if logsAttempt < 0 {
+			die("--attempt can't be negative")
+		}
+		if logsHead > 0 && logsTail > 0 {
+			die("--head and --tail are mutually exclusive")
+		}
+		if logsFollow && (logsHead > 0 || logsTail > 0 || logsAttempt > 0) {
+			die("--follow can't be used with --head, --tail or --attempt")
+		}
+
+		showStdout, showStderr := logStreams(logsStdout, logsStderr)
+		var streams []string
+		if showStdout {
+			streams = append(streams, jobqueue.LogStdout)
+		}
+		if showStderr {
+			streams = append(streams, jobqueue.LogStderr)
+		}
+
+		timeout := time.Duration(timeoutint) * time.Second
+		jq := connect(timeout)
+		var err error
+		defer func() {
+			err = jq.Disconnect()
+			if err != nil {
+				warn("Disconnecting from the server failed: %s", err)
+			}
+		}()
+
+		jobs := getJobs(jq, "", false, 0, false, false)
+		if len(jobs) == 0 {
+			die("No matching jobs found")
+		}
+
+		if logsFollow {
+			if len(jobs) != 1 {
+				die("--follow needs your options to match a single command, but %d matched", len(jobs))
+			}
+			followOutput(jq, jobs[0], showStdout, showStderr)
+			return
+		}
+
+		failed := 0
+		for _, job := range jobs {
+			attempt := uint32(logsAttempt)
+			if attempt == 0 {
+				attempt = job.Attempts
+			}
+			for _, stream := range streams {
+				content, errg := jq.GetLog(job, attempt, stream)
+				if errg != nil {
+					warn("could not get the %s of [%s]: %s", stream, job.Cmd, errg)
+					failed++
+					continue
+				}
+				if len(jobs) > 1 || len(streams) > 1 {
+					fmt.Printf("==> %s (%s) attempt %d %s <==\n", job.Cmd, job.Key(), attempt, stream)
+				}
+				_, errw := os.Stdout.Write(logLines(content, logsHead, logsTail))
+				if errw != nil {
+					die("failed to write output: %s", errw)
+				}
+			}
+		}
+
+		if failed > 0 {
+			die("could not get %d of the requested logs", failed)
+		}
+	},
+}
+
+// logStreams returns which of STDOUT and STDERR to show given the --stdout and
+// --stderr options: just the ones that were asked for, or both if neither were.
+func logStreams(onlyStdout, onlyStderr bool) (showStdout, showStderr bool) {
+	return onlyStdout || !onlyStderr, onlyStderr || !onlyStdout
+}
+
+// followOutput prints the output of the given job as it is produced, until it
+// stops running.
+func followOutput(jq *jobqueue.Client, job *jobqueue.Job, showStdout, showStderr bool) {
+	var stdoutOffset, stderrOffset int64
+	for {
+		out, err := jq.GetOutput(job, stdoutOffset, stderrOffset)
+		if err != nil {
+			die("failed to get the output of [%s]: %s", job.Cmd, err)
+		}
+		stdoutOffset, stderrOffset = out.StdoutOffset, out.StderrOffset
+
+		if showStdout && len(out.Stdout) > 0 {
+			_, err = os.Stdout.Write(out.Stdout)
+			if err != nil {
+				die("failed to write output: %s", err)
+			}
+		}
+		if showStderr && len(out.Stderr) > 0 {
+			_, err = os.Stderr.Write(out.Stderr)
+			if err != nil {
+				die("failed to write output: %s", err)
+			}
+		}
+
+		if !out.Running {
+			return
+		}
+		<-time.After(jobqueue.ClientOutputInterval)
+	}
+}
+
+// logLines returns the first head lines of the given log, or the last tail
+// lines, or all of it if neither are greater than 0.
+func logLines(log []byte, head, tail int) []byte {
+	lines := bytes.SplitAfter(log, []byte("\n"))
+	if len(lines) > 0 && len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	switch {
+	case head > 0 && head < len(lines):
+		lines = lines[:head]
+	case tail > 0 && tail < len(lines):
+		lines = lines[len(lines)-tail:]
+	}
+	return bytes.Join(lines, nil)
+}
+
+func init() {
+	RootCmd.AddCommand(logsCmd)
+
+	// flags specific to this sub-command
+	logsCmd.Flags().StringVarP(&cmdFileStatus, "file", "f", "", "file containing commands you want the output of; - means read from STDIN")
+	logsCmd.Flags().StringVarP(&cmdIDStatus, "identifier", "i", "", "identifier of the commands you want the output of")
+	logsCmd.Flags().BoolVarP(&cmdIDIsSubStr, "search", "z", false, "treat -i as a substring to match against all report groups")
+	logsCmd.Flags().BoolVarP(&cmdIDIsInternal, "internal", "y", false, "treat -i as an internal job id")
+	logsCmd.Flags().StringVarP(&cmdLine, "cmdline", "l", "", "a command line you want the output of")
+	logsCmd.Flags().StringVarP(&cmdCwd, "cwd", "c", "", "working dir that the command(s) specified by -l or -f were set to run in")
+	logsCmd.Flags().StringVarP(&mountJSON, "mount_json", "j", "", "mounts that the command(s) specified by -l or -f were set to use (JSON format)")
+	logsCmd.Flags().StringVar(&mountSimple, "mounts", "", "mounts that the command(s) specified by -l or -f were set to use (simple format)")
+	logsCmd.Flags().IntVarP(&logsAttempt, "attempt", "n", 0, "show the output of this attempt at running the command [default: the most recent]")
+	logsCmd.Flags().BoolVarP(&logsStdout, "stdout", "o", false, "only show STDOUT")
+	logsCmd.Flags().BoolVarP(&logsStderr, "stderr", "e", false, "only show STDERR")
+	logsCmd.Flags().IntVar(&logsHead, "head", 0, "only show this many lines from the start of the output")
+	logsCmd.Flags().IntVar(&logsTail, "tail", 0, "only show this many lines from the end of the output")
+	logsCmd.Flags().BoolVar(&logsFollow, "follow", false, "stream the output of a running command as it is produced")
+
+	logsCmd.Flags().IntVar(&timeoutint, "timeout", 120, "how long (seconds) to wait to get a reply from 'wr manager'")
+}
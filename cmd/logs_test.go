@@ -0,0 +1,45 @@
+// Copyright © 2026 Genome Research Limited
+// Author: Sendu Bala <sb10@sanger.ac.uk>.
+//
+//  This file is part of wr.
+//
+//  wr is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Lesser General Public License as published by
+//  the Free Software Foundation, either version 3 of the License, or
+//  (at your option) any later version.
+//
+//  wr is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Lesser General Public License for more details.
+//
+//  You should have received a copy of the GNU Lesser General Public License
+//  along with wr. If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestLogStreams(t *testing.T) {
+	Convey("Logs, including followed output, show the streams asked for", t, func() {
+		showStdout, showStderr := logStreams(false, false)
+		So(showStdout, ShouldBeTrue)
+		So(showStderr, ShouldBeTrue)
+
+		showStdout, showStderr = logStreams(true, false)
+		So(showStdout, ShouldBeTrue)
+		So(showStderr, ShouldBeFalse)
+
+		showStdout, showStderr = logStreams(false, true)
+		So(showStdout, ShouldBeFalse)
+		So(showStderr, ShouldBeTrue)
+
+		showStdout, showStderr = logStreams(true, true)
+		So(showStdout, ShouldBeTrue)
+		So(showStderr, ShouldBeTrue)
+	})
+}
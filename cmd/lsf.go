@@ -43,9 +43,11 @@ var jobStateToLSFState = map[jobqueue.JobState]string{
 	jobqueue.JobStateNew:       "PEND",
 	jobqueue.JobStateDelayed:   "PEND",
 	jobqueue.JobStateDependent: "PEND",
+	jobqueue.JobStateHeld:      "PSUSP",
 	jobqueue.JobStateReady:     "PEND",
 	jobqueue.JobStateReserved:  "PEND",
 	jobqueue.JobStateRunning:   "RUN",
+	jobqueue.JobStateSuspended: "USUSP",
 	jobqueue.JobStateLost:      "UNKWN",
 	jobqueue.JobStateBuried:    "EXIT",
 	jobqueue.JobStateComplete:  "DONE",
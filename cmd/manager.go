@@ -56,6 +56,13 @@ var cloudNoSecurityGroups bool
 var cloudUseConfigDrive bool
 var useCertDomain bool
 var runnerDebug bool
+var fairShare string
+var fairShareHalfLife string
+var fairShareWeights string
+var priorityAging string
+var priorityAgingMax int
+var preemptPriority int
+var preemptMode string
 
 const kubernetes = "kubernetes"
 
@@ -174,6 +181,10 @@ fully.`,
 			die("--local_username must be %d characters or less", maxCloudResourceUsernameLength)
 		}
 
+		parseFairShareOptions()
+		parsePriorityAgingOptions()
+		parsePreemptOptions()
+
 		// later, we will wait for the daemonized manager to either create a new
 		// token file, or if we already have one, to touch it, so we store the
 		// time now to know when the touch happens
@@ -550,6 +561,13 @@ func init() {
 	managerStartCmd.Flags().StringVar(&cloudConfigFiles, "cloud_config_files", defaultConfig.CloudConfigFiles, "for cloud schedulers, comma separated paths of config files to copy to spawned servers")
 	managerStartCmd.Flags().BoolVar(&setDomainIP, "set_domain_ip", defaultConfig.ManagerSetDomainIP, "on success, use infoblox to set your domain's IP")
 	managerStartCmd.Flags().BoolVar(&useCertDomain, "use_cert_domain", false, "if cert domain is configured, provide it to spawned clients instead of our IP address")
+	managerStartCmd.Flags().StringVar(&fairShare, "fair_share", defaultConfig.FairShare, "['owner','repgroup'] turn on fair-share scheduling between the owners or report groups of commands")
+	managerStartCmd.Flags().StringVar(&fairShareHalfLife, "fair_share_half_life", defaultConfig.FairShareHalfLife, "with --fair_share, how long before past usage counts for half as much")
+	managerStartCmd.Flags().StringVar(&fairShareWeights, "fair_share_weights", defaultConfig.FairShareWeights, "with --fair_share, relative weights of parties in the form party1=2,party2=0.5 (default 1)")
+	managerStartCmd.Flags().StringVar(&priorityAging, "priority_aging", defaultConfig.PriorityAging, "raise the priority of commands by 1 for every period (eg. 1h) they wait to run")
+	managerStartCmd.Flags().IntVar(&priorityAgingMax, "priority_aging_max", defaultConfig.PriorityAgingMax, "with --priority_aging, the highest priority commands can be raised to")
+	managerStartCmd.Flags().IntVar(&preemptPriority, "preempt_priority", defaultConfig.PreemptPriority, "kill or suspend running commands of this priority or lower to make room for higher priority ones (0 means never)")
+	managerStartCmd.Flags().StringVar(&preemptMode, "preempt_mode", defaultConfig.PreemptMode, "['kill','suspend'] with --preempt_priority, how to make room for higher priority commands")
 	managerStartCmd.Flags().BoolVar(&managerDebug, "debug", false, "include extra debugging information in the logs")
 	managerStartCmd.Flags().BoolVar(&runnerDebug, "runner_debug", false, "have runners log to syslog on their machines")
 
@@ -705,24 +723,37 @@ func startJQ(postCreation []byte) {
 	}
 
 	// start the jobqueue server
+	fsHalfLife, fsWeights := parseFairShareOptions()
+	agingInterval := parsePriorityAgingOptions()
+	parsePreemptOptions()
+
 	server, msg, token, err := jobqueue.Serve(jobqueue.ServerConfig{
-		Port:            config.ManagerPort,
-		WebPort:         config.ManagerWeb,
-		SchedulerName:   scheduler,
-		SchedulerConfig: schedulerConfig,
-		RunnerCmd:       runnerCmd,
-		DBFile:          config.ManagerDbFile,
-		DBFileBackup:    config.ManagerDbBkFile,
-		TokenFile:       config.ManagerTokenFile,
-		UploadDir:       config.ManagerUploadDir,
-		CAFile:          config.ManagerCAFile,
-		CertFile:        config.ManagerCertFile,
-		KeyFile:         config.ManagerKeyFile,
-		CertDomain:      config.ManagerCertDomain,
-		DomainMatchesIP: useCertDomain,
-		Deployment:      config.Deployment,
-		CIDR:            serverCIDR,
-		Logger:          serverLogger,
+		Port:              config.ManagerPort,
+		WebPort:           config.ManagerWeb,
+		SchedulerName:     scheduler,
+		SchedulerConfig:   schedulerConfig,
+		RunnerCmd:         runnerCmd,
+		DBFile:            config.ManagerDbFile,
+		DBFileBackup:      config.ManagerDbBkFile,
+		TokenFile:         config.ManagerTokenFile,
+		UploadDir:         config.ManagerUploadDir,
+		CopyDir:           config.ManagerCopyDir,
+		LogDir:            config.ManagerLogDir,
+		FairShare:         fairShare,
+		FairShareHalfLife: fsHalfLife,
+		FairShareWeights:  fsWeights,
+		PriorityAging:     agingInterval,
+		PriorityAgingMax:  uint8(priorityAgingMax),
+		PreemptPriority:   uint8(preemptPriority),
+		PreemptMode:       preemptMode,
+		CAFile:            config.ManagerCAFile,
+		CertFile:          config.ManagerCertFile,
+		KeyFile:           config.ManagerKeyFile,
+		CertDomain:        config.ManagerCertDomain,
+		DomainMatchesIP:   useCertDomain,
+		Deployment:        config.Deployment,
+		CIDR:              serverCIDR,
+		Logger:            serverLogger,
 	})
 
 	if msg != "" {
@@ -758,6 +789,52 @@ func startJQ(postCreation []byte) {
 // so that the next time the manager is started it will create a new token.
 // For un-clean exits of the manager, we should keep the token so the manager
 // re-uses it, allowing any runners to reconnect.
+// parseFairShareOptions checks the --fair_share* options, returning the parsed
+// half-life and weights.
+func parseFairShareOptions() (time.Duration, map[string]float64) {
+	if fairShare == "" {
+		return 0, nil
+	}
+	if fairShare != jobqueue.FairShareOwner && fairShare != jobqueue.FairShareRepGroup {
+		die("--fair_share must be one of '%s' or '%s'", jobqueue.FairShareOwner, jobqueue.FairShareRepGroup)
+	}
+	halfLife, err := time.ParseDuration(fairShareHalfLife)
+	if err != nil || halfLife <= 0 {
+		die("--fair_share_half_life (%s) was not specified as a positive duration, eg. 24h", fairShareHalfLife)
+	}
+	weights, err := jobqueue.ParseFairShareWeights(fairShareWeights)
+	if err != nil {
+		die("--fair_share_weights was not specified correctly: %s", err)
+	}
+	return halfLife, weights
+}
+
+// parsePriorityAgingOptions checks the --priority_aging* options, returning the
+// parsed aging interval.
+func parsePriorityAgingOptions() time.Duration {
+	if priorityAging == "" {
+		return 0
+	}
+	interval, err := time.ParseDuration(priorityAging)
+	if err != nil || interval <= 0 {
+		die("--priority_aging (%s) was not specified as a positive duration, eg. 1h", priorityAging)
+	}
+	if priorityAgingMax < 1 || priorityAgingMax > 255 {
+		die("--priority_aging_max must be in the range 1..255")
+	}
+	return interval
+}
+
+// parsePreemptOptions checks the --preempt_* options.
+func parsePreemptOptions() {
+	if preemptPriority < 0 || preemptPriority > 254 {
+		die("--preempt_priority must be in the range 0..254")
+	}
+	if preemptPriority > 0 && preemptMode != jobqueue.PreemptKill && preemptMode != jobqueue.PreemptSuspend {
+		die("--preempt_mode must be one of '%s' or '%s'", jobqueue.PreemptKill, jobqueue.PreemptSuspend)
+	}
+}
+
 func deleteToken() {
 	err := os.Remove(config.ManagerTokenFile)
 	if err != nil && !os.IsNotExist(err) {
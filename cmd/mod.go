@@ -62,8 +62,8 @@ dependency groups and bsub mode.
 If you want to modify commands that are currently running you will need to
 "wr kill" them first.
 
-Specify one of the flags -i or -a to choose which commands you want to
-modify. Amongst those, only currently incomplete, non-running command will be
+Specify one of the flags -i, --array_name or -a to choose which commands you want
+to modify. Amongst those, only currently incomplete, non-running command will be
 affected.
 
 -i is the report group (-i) you supplied to "wr add" when you added the
@@ -72,6 +72,9 @@ multiple report groups, assuming you have arranged that related groups share
 some substring. Alternatively -y lets you specify -i as the internal job id
 reported during "wr status".
 
+--array_name is the name of a job array you added with "wr add". Combining with
+--indices lets you modify only some of its members, eg. "1-10,20".
+
 Having identified the command(s) to modify, provide any of "wr add"'s options
 (except for -f, -i, --rerun, --dep_grps and --bsub) to change that aspect of the
 command. If the boolean options --cwd_matters, --change_home or --cloud_shared
@@ -89,8 +92,8 @@ Because modifying a command may change its internal id, a mapping of old to
 new internal ids is printed.`,
 	Run: func(cobraCmd *cobra.Command, args []string) {
 		// check the command line options
-		if cmdAll && cmdIDStatus != "" {
-			die("-a and -i are mutually exclusive")
+		if (cmdAll && cmdIDStatus != "") || (cmdArrayName != "" && (cmdAll || cmdIDStatus != "")) {
+			die("-a, -i and --array_name are mutually exclusive")
 		}
 		if cmdAll && cmdLine != "" {
 			die("-a is not compatible with --cmdline")
@@ -98,8 +101,8 @@ new internal ids is printed.`,
 		if cmdIDStatus == "" && (cmdIDIsSubStr || cmdIDIsInternal) {
 			die("-z and -y require -i")
 		}
-		if !cmdAll && cmdIDStatus == "" {
-			die("one of -i or -a is required")
+		if !cmdAll && cmdIDStatus == "" && cmdArrayName == "" {
+			die("one of -i, --array_name or -a is required")
 		}
 
 		// we call getJobs() later, which finds jobs based on -f and -l, but
@@ -253,6 +256,26 @@ new internal ids is printed.`,
 		if cobraCmd.Flags().Changed("retries") {
 			jm.SetRetries(uint8(cmdRet))
 		}
+		if cobraCmd.Flags().Changed("retry_policy") {
+			if cmdRetryPolicy == "" {
+				jm.SetRetryPolicy(nil)
+			} else {
+				jm.SetRetryPolicy(retryPolicyParse(cmdRetryPolicy))
+			}
+		}
+
+		if cobraCmd.Flags().Changed("time_limit") || cobraCmd.Flags().Changed("time_limit_grace") || cobraCmd.Flags().Changed("time_limit_signal") {
+			jm.SetTimeLimit(timeLimitParse(cmdTimeLimit, cmdTimeLimitGrace, cmdTimeLimitSignal))
+		}
+		if cobraCmd.Flags().Changed("begin_at") {
+			jm.SetBeginAt(beginAtParse(cmdBeginAt))
+		}
+		if cobraCmd.Flags().Changed("window") {
+			jm.SetWindow(windowParse(cmdWindow))
+		}
+		if cobraCmd.Flags().Changed("deadline") {
+			jm.SetDeadline(deadlineParse(cmdDeadline))
+		}
 
 		var deps jobqueue.Dependencies
 		var depsSet bool
@@ -275,6 +298,9 @@ new internal ids is printed.`,
 		if cobraCmd.Flags().Changed("monitor_docker") {
 			jm.SetMonitorDocker(cmdMonitorDocker)
 		}
+		if cobraCmd.Flags().Changed("container_image") || cobraCmd.Flags().Changed("container_runtime") {
+			jm.SetContainer(containerParse(cmdContainerImage, cmdContainerRuntime))
+		}
 
 		var behaviours jobqueue.Behaviours
 		var behavioursSet bool
@@ -361,6 +387,8 @@ func init() {
 	modCmd.Flags().StringVarP(&cmdIDStatus, "identifier", "i", "", "identifier of the commands you want to modify")
 	modCmd.Flags().BoolVarP(&cmdIDIsSubStr, "search", "z", false, "treat -i as a substring to match against all report groups")
 	modCmd.Flags().BoolVarP(&cmdIDIsInternal, "internal", "y", false, "treat -i as an internal job id")
+	modCmd.Flags().StringVar(&cmdArrayName, "array_name", "", "name of the job array you want to modify")
+	modCmd.Flags().StringVar(&cmdArrayIndices, "indices", "", "with --array_name, only these members of the array [eg. \"1-10,20\"]")
 
 	modCmd.Flags().StringVar(&cmdLine, "cmdline", "", "new command line")
 	modCmd.Flags().StringVarP(&cmdLimitGroups, "limit_grps", "l", "", "comma-separated list of limit groups")
@@ -373,14 +401,23 @@ func init() {
 	modCmd.Flags().StringVarP(&reqGroup, "req_grp", "g", "", "group name for commands with similar reqs")
 	modCmd.Flags().StringVarP(&cmdMem, "memory", "m", "1G", "peak mem est. [specify units such as M for Megabytes or G for Gigabytes]")
 	modCmd.Flags().StringVarP(&cmdTime, "time", "t", "1h", "max time est. [specify units such as m for minutes or h for hours]")
+	modCmd.Flags().StringVar(&cmdTimeLimit, "time_limit", "", "hard time limit, after which commands are killed [specify units such as m for minutes or h for hours]")
+	modCmd.Flags().StringVar(&cmdTimeLimitGrace, "time_limit_grace", "", "how long before --time_limit to send --time_limit_signal (default 1m)")
+	modCmd.Flags().StringVar(&cmdTimeLimitSignal, "time_limit_signal", "", "signal to send when --time_limit is near (default SIGTERM)")
+	modCmd.Flags().StringVar(&cmdBeginAt, "begin_at", "", "don't start the commands before this time [eg. \"2006-01-02 15:04\" or a duration from now like 2h; blank to remove]")
+	modCmd.Flags().StringVar(&cmdWindow, "window", "", "only start the commands during these recurring periods [eg. \"Mon-Fri 19:00-07:00,Sat-Sun\"; blank to remove]")
+	modCmd.Flags().StringVar(&cmdDeadline, "deadline", "", "time by which the commands should complete [eg. \"2006-01-02 15:04\" or a duration from now like 24h; blank to remove]")
 	modCmd.Flags().Float64Var(&cmdCPUs, "cpus", 1, "cpu cores needed")
 	modCmd.Flags().IntVar(&cmdDisk, "disk", 0, "number of GB of disk space required (default 0)")
 	modCmd.Flags().IntVarP(&cmdOvr, "override", "o", 0, "[0|1|2] should your mem/time estimates override? (default 0)")
 	modCmd.Flags().IntVarP(&cmdPri, "priority", "p", 0, "[0-255] command priority (default 0)")
 	modCmd.Flags().IntVarP(&cmdRet, "retries", "r", 3, "[0-255] number of automatic retries for failed commands")
+	modCmd.Flags().StringVar(&cmdRetryPolicy, "retry_policy", "", "backoff and per-fail-reason retries for failed commands, in JSON format")
 	modCmd.Flags().StringVar(&cmdCmdDeps, "cmd_deps", "", "dependencies of your commands, in the form \"command1,cwd1,command2,cwd2...\"")
 	modCmd.Flags().StringVarP(&cmdGroupDeps, "deps", "d", "", "dependencies of your commands, in the form \"dep_grp1,dep_grp2...\"")
 	modCmd.Flags().StringVar(&cmdMonitorDocker, "monitor_docker", "", "monitor resource usage of docker container with given --name or --cidfile path")
+	modCmd.Flags().StringVar(&cmdContainerImage, "container_image", "", "run the commands inside a container created from this image")
+	modCmd.Flags().StringVar(&cmdContainerRuntime, "container_runtime", "", "[docker|singularity|apptainer] software that runs --container_image (default docker)")
 	modCmd.Flags().StringVar(&cmdOnFailure, "on_failure", "", "behaviours to carry out when cmds fails, in JSON format")
 	modCmd.Flags().StringVar(&cmdOnSuccess, "on_success", "", "behaviours to carry out when cmds succeed, in JSON format")
 	modCmd.Flags().StringVar(&cmdOnExit, "on_exit", `[{"cleanup":true}]`, "behaviours to carry out when cmds finish running, in JSON format")
@@ -0,0 +1,164 @@
+// Copyright © 2026 Genome Research Limited
+// Author: Sendu Bala <sb10@sanger.ac.uk>.
+//
+//  This file is part of wr.
+//
+//  wr is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Lesser General Public License as published by
+//  the Free Software Foundation, either version 3 of the License, or
+//  (at your option) any later version.
+//
+//  wr is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Lesser General Public License for more details.
+//
+//  You should have received a copy of the GNU Lesser General Public License
+//  along with wr. If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/VertebrateResequencing/wr/jobqueue"
+	"github.com/spf13/cobra"
+)
+
+// options for this cmd
+var quotaOwner string
+var quotaRepGroup string
+var quotaCores float64
+var quotaRAM float64
+var quotaCount int
+var quotaRemove bool
+
+// quotaCmd represents the quota command
+var quotaCmd = &cobra.Command{
+	Use:   "quota",
+	Short: "Limit the resources used by a user's or report group's commands",
+	Long: `Limit the total resources that the running commands of a particular user
+or report group can use at once.
+
+Whereas "wr limit" caps the number of running commands in a limit group, quotas
+cap the total number of cores, the total memory (in GB) and the number of
+running commands that belong to a user (see "wr user") or that are in a report
+group (the -i option to "wr add").
+
+Commands that would take their user or report group over quota stay in the
+ready state until enough of the others finish running; "wr status" shows why
+they are waiting. No runners are requested for them in the meantime.
+
+With no options, all quotas are listed along with their current usage.
+
+To set a quota, specify --owner or --report_grp along with any of --cores,
+--ram and --count. Resources you don't specify are not limited. Use --remove
+to remove the quota entirely.
+
+Only admins can set quotas.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if quotaOwner != "" && quotaRepGroup != "" {
+			die("--owner and --report_grp are mutually exclusive")
+		}
+		setting := cmd.Flags().Changed("cores") || cmd.Flags().Changed("ram") || cmd.Flags().Changed("count")
+		if (setting || quotaRemove) && quotaOwner == "" && quotaRepGroup == "" {
+			die("--owner or --report_grp is required to set a quota")
+		}
+		if setting && quotaRemove {
+			die("--remove can't be used with --cores, --ram or --count")
+		}
+
+		timeout := time.Duration(timeoutint) * time.Second
+		jq := connect(timeout)
+		var err error
+		defer func() {
+			err = jq.Disconnect()
+			if err != nil {
+				warn("Disconnecting from the server failed: %s", err)
+			}
+		}()
+
+		if !setting && !quotaRemove {
+			showQuotas(jq)
+			return
+		}
+
+		q := &jobqueue.Quota{Kind: jobqueue.QuotaOwner, Name: quotaOwner}
+		if quotaRepGroup != "" {
+			q = &jobqueue.Quota{Kind: jobqueue.QuotaRepGroup, Name: quotaRepGroup}
+		}
+		if setting {
+			q.Cores = quotaCores
+			q.RAM = quotaRAM
+			q.Count = quotaCount
+		}
+
+		err = jq.SetQuota(q)
+		if err != nil {
+			die("failed to set the quota of %s %s: %s", q.Kind, q.Name, err)
+		}
+		if quotaRemove || (q.Cores == 0 && q.RAM == 0 && q.Count == 0) {
+			info("removed the quota of %s %s", q.Kind, q.Name)
+		} else {
+			info("%s %s is now limited to %s", q.Kind, q.Name, q)
+		}
+	},
+}
+
+// showQuotas prints a table of all the quotas that have been set.
+func showQuotas(jq *jobqueue.Client) {
+	quotas, err := jq.GetQuotas()
+	if err != nil {
+		die("could not get quotas: %s", err)
+	}
+	if len(quotas) == 0 {
+		info("no quotas have been set")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 2, 2, 3, ' ', 0)
+	_, err = fmt.Fprintln(w, "KIND\tNAME\tCORES\tRAM_GB\tJOBS\tPENDING")
+	if err != nil {
+		warn("failed to print header: %s", err)
+	}
+	for _, q := range quotas {
+		_, err = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\n", q.Kind, q.Name,
+			quotaUsageString(q.UsedCores, q.Cores), quotaUsageString(q.UsedRAM, q.RAM),
+			quotaUsageString(float64(q.UsedCount), float64(q.Count)), q.Pending)
+		if err != nil {
+			warn("failed to print line: %s", err)
+		}
+	}
+	err = w.Flush()
+	if err != nil {
+		warn("failed to flush output: %s", err)
+	}
+}
+
+// quotaUsageString shows how much of a quota is used, eg. "2/10", or just the
+// usage if there's no quota.
+func quotaUsageString(used, limit float64) string {
+	usage := strconv.FormatFloat(used, 'f', -1, 64)
+	if limit > 0 {
+		return usage + "/" + strconv.FormatFloat(limit, 'f', -1, 64)
+	}
+	return usage
+}
+
+func init() {
+	RootCmd.AddCommand(quotaCmd)
+
+	// flags specific to this sub-command
+	quotaCmd.Flags().StringVarP(&quotaOwner, "owner", "o", "", "name of the user to set the quota of")
+	quotaCmd.Flags().StringVarP(&quotaRepGroup, "report_grp", "i", "", "name of the report group to set the quota of")
+	quotaCmd.Flags().Float64Var(&quotaCores, "cores", 0, "maximum total cores of running commands (0 for no limit)")
+	quotaCmd.Flags().Float64Var(&quotaRAM, "ram", 0, "maximum total memory (GB) of running commands (0 for no limit)")
+	quotaCmd.Flags().IntVar(&quotaCount, "count", 0, "maximum number of running commands (0 for no limit)")
+	quotaCmd.Flags().BoolVarP(&quotaRemove, "remove", "r", false, "remove the quota")
+
+	quotaCmd.Flags().IntVar(&timeoutint, "timeout", 120, "how long (seconds) to wait to get a reply from 'wr manager'")
+}
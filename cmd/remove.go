@@ -36,8 +36,8 @@ For use when you've made a mistake when specifying the command and it will never
 work. If you want to remove commands that are currently running you will need to
 "wr kill" them first.
 
-Specify one of the flags -f, -l, -i or -a to choose which commands you want to
-remove. Amongst those, only currently incomplete, non-running jobs will be
+Specify one of the flags -f, -l, -i, --array_name or -a to choose which commands
+you want to remove. Amongst those, only currently incomplete, non-running jobs will be
 affected.
 
 -i is the report group (-i) you supplied to "wr add" when you added the job(s)
@@ -46,6 +46,9 @@ report groups, assuming you have arranged that related groups share some
 substring. Alternatively -y lets you specify -i as the internal job id reported
 during "wr status".
 
+--array_name is the name of a job array you added with "wr add". Combining with
+--indices lets you remove only some of its members, eg. "1-10,20".
+
 The file to provide -f is in the format taken by "wr add".
 
 In -f and -l mode you must provide the cwd the commands were set to run in, if
@@ -66,10 +69,10 @@ command line of a job with dependants, you can either:
 	Run: func(cmd *cobra.Command, args []string) {
 		set := countGetJobArgs()
 		if set > 1 {
-			die("-f, -i, -l and -a are mutually exclusive; only specify one of them")
+			die("-f, -i, -l, --array_name and -a are mutually exclusive; only specify one of them")
 		}
 		if set == 0 {
-			die("1 of -f, -i, -l or -a is required")
+			die("1 of -f, -i, -l, --array_name or -a is required")
 		}
 
 		timeout := time.Duration(timeoutint) * time.Second
@@ -107,6 +110,8 @@ func init() {
 	removeCmd.Flags().BoolVarP(&cmdIDIsSubStr, "search", "z", false, "treat -i as a substring to match against all report groups")
 	removeCmd.Flags().BoolVarP(&cmdIDIsInternal, "internal", "y", false, "treat -i as an internal job id")
 	removeCmd.Flags().StringVarP(&cmdLine, "cmdline", "l", "", "a command line you want to remove")
+	removeCmd.Flags().StringVar(&cmdArrayName, "array_name", "", "name of the job array you want to remove")
+	removeCmd.Flags().StringVar(&cmdArrayIndices, "indices", "", "with --array_name, only these members of the array [eg. \"1-10,20\"]")
 	removeCmd.Flags().StringVarP(&cmdCwd, "cwd", "c", "", "working dir that the command(s) specified by -l or -f were set to run in")
 	removeCmd.Flags().StringVarP(&mountJSON, "mount_json", "j", "", "mounts that the command(s) specified by -l or -f were set to use (JSON format)")
 	removeCmd.Flags().StringVar(&mountSimple, "mounts", "", "mounts that the command(s) specified by -l or -f were set to use (simple format)")
@@ -25,8 +25,10 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"text/tabwriter"
 	"time"
 
+	"code.cloudfoundry.org/bytefmt"
 	"github.com/VertebrateResequencing/wr/jobqueue"
 	"github.com/carbocation/runningvariance"
 	"github.com/spf13/cobra"
@@ -41,6 +43,7 @@ var cmdIDStatus string
 var cmdIDIsSubStr bool
 var cmdIDIsInternal bool
 var cmdLine string
+var cmdArrayIndices string
 var showBuried bool
 var showStd bool
 var showEnv bool
@@ -54,9 +57,9 @@ var statusCmd = &cobra.Command{
 	Long: `You can find the status of commands you've previously added using
 "wr add" or "wr setup" by running this command.
 
-Specify one of the flags -f, -l  or -i to choose which commands you want the
-status of. If none are supplied, you will get the status of all your currently
-incomplete commands.
+Specify one of the flags -f, -l, -i or --array_name to choose which commands you
+want the status of. If none are supplied, you will get the status of all your
+currently incomplete commands.
 
 -i is the report group (-i) you supplied to "wr add" when you added the job(s)
 you want the status of now. Combining with -z lets you get the status of jobs
@@ -64,6 +67,9 @@ in multiple report groups, assuming you have arranged that related groups share
 some substring. Alternatively -y lets you specify -i as the internal job id
 reported when using this command.
 
+--array_name is the name of a job array you added with "wr add". Combining with
+--indices lets you get the status of only some of its members, eg. "1-10,20".
+
 The file to provide -f is in the format taken by "wr add".
 
 In -f and -l mode you must provide the cwd the commands were set to run in, if
@@ -72,13 +78,17 @@ options that was used when the command was added, if any. You can do this by
 using the -c and --mounts/--mounts_json options in -l mode, or by providing the
 same file you gave to "wr add" in -f mode.
 
-There are 4 output formats to choose from with -o (you can shorten the output
+There are 5 output formats to choose from with -o (you can shorten the output
 name to just the first letter, eg. -o c):
   "counts" just displays the count of jobs in each possible state.
   "summary" shows the counts broken down by report group, along with the mean
     (and standard deviation) resource usage of completed jobs in each report
     group, and the internal identifiers of any buried jobs, broken down by exit
-    code+failure reason.
+    code+failure reason. Resource usage includes CPU efficiency: the CPU time
+    used as a percentage of wall time multiplied by the requested cores, so a
+    low value means jobs are asking for more cores than they use.
+    Completed jobs that reused cached results (see "wr add --cache") instead of
+    running are counted separately, and excluded from the resource usage.
   "details" groups jobs with the same state, reason for failure and exitcode
     together and shows the complete details of --limit random jobs in each group
     (and you are told how many are not being displayed). A limit of 0 turns off
@@ -87,11 +97,17 @@ name to just the first letter, eg. -o c):
     commands.
   "json" simply dumps the complete details of every job out as an array of
     JSON objects. The properties of the JSON objects are described in the
-    documentation for wr's REST API.`,
+    documentation for wr's REST API.
+  "fairshare" ignores all other options and, if the manager was started with
+    fair-share scheduling enabled (see "wr manager start -h"), shows the
+    share of recent CPU-hour usage each user or report group has been getting,
+    compared to the share their weight entitles them to. Amongst commands of
+    the same priority, those belonging to whoever is furthest below their
+    target share are run first.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		set := countGetJobArgs()
 		if set > 1 {
-			die("-f, -i and -l are mutually exclusive; only specify one of them")
+			die("-f, -i, -l and --array_name are mutually exclusive; only specify one of them")
 		}
 		var cmdState jobqueue.JobState
 		if showBuried {
@@ -108,6 +124,11 @@ name to just the first letter, eg. -o c):
 			}
 		}()
 
+		if outputFormat == "fairshare" || outputFormat == "f" {
+			showFairShares(jq)
+			return
+		}
+
 		if outputFormat != "details" && outputFormat != "d" {
 			statusLimit = 0
 			showStd = false
@@ -118,7 +139,7 @@ name to just the first letter, eg. -o c):
 
 		switch outputFormat {
 		case "counts", "c":
-			var d, re, b, ru, l, c, dep int
+			var d, re, b, ru, su, l, c, ca, dep, h int
 			for _, job := range jobs {
 				switch job.State {
 				case jobqueue.JobStateDelayed:
@@ -129,22 +150,38 @@ name to just the first letter, eg. -o c):
 					b += 1 + job.Similar
 				case jobqueue.JobStateReserved, jobqueue.JobStateRunning:
 					ru += 1 + job.Similar
+				case jobqueue.JobStateSuspended:
+					su += 1 + job.Similar
 				case jobqueue.JobStateLost:
 					l += 1 + job.Similar
 				case jobqueue.JobStateComplete:
 					c += 1 + job.Similar
+					if job.CachedFrom != "" {
+						ca += 1 + job.Similar
+					}
 				case jobqueue.JobStateDependent:
 					dep += 1 + job.Similar
+				case jobqueue.JobStateHeld:
+					h += 1 + job.Similar
 				}
 			}
-			fmt.Printf("complete: %d\nrunning: %d\nready: %d\ndependent: %d\nlost contact: %d\ndelayed: %d\nburied: %d\n", c, ru, re, dep, l, d, b)
+			var cachedCount string
+			if ca > 0 {
+				cachedCount = fmt.Sprintf(" (%d cached)", ca)
+			}
+			fmt.Printf("complete: %d%s\nrunning: %d\nsuspended: %d\nready: %d\ndependent: %d\nheld: %d\nlost contact: %d\ndelayed: %d\nburied: %d\n", c, cachedCount, ru, su, re, dep, h, l, d, b)
 		case "summary", "s":
 			counts := make(map[string]map[jobqueue.JobState]int)
+			cached := make(map[string]int)
 			buried := make(map[string]map[string][]string)
 			memory := make(map[string]*runningvariance.RunningStat)
 			disk := make(map[string]*runningvariance.RunningStat)
 			walltime := make(map[string]*runningvariance.RunningStat)
 			cputime := make(map[string]*runningvariance.RunningStat)
+			read := make(map[string]*runningvariance.RunningStat)
+			written := make(map[string]*runningvariance.RunningStat)
+			ctxswitches := make(map[string]*runningvariance.RunningStat)
+			efficiency := make(map[string]*runningvariance.RunningStat)
 			startends := make(map[string][]time.Time)
 			counts[allRepGrps] = make(map[jobqueue.JobState]int)
 			for _, job := range jobs {
@@ -164,18 +201,31 @@ name to just the first letter, eg. -o c):
 					}
 					group := fmt.Sprintf("exitcode.%d,\"%s\"", job.Exitcode, job.FailReason)
 					buried[job.RepGroup][group] = append(buried[job.RepGroup][group], job.Key())
+				} else if state == jobqueue.JobStateComplete && job.CachedFrom != "" {
+					// these reused cached results instead of running, so have
+					// no resource usage to report
+					cached[job.RepGroup]++
+					cached[allRepGrps]++
 				} else if state == jobqueue.JobStateComplete {
 					if _, exists := memory[job.RepGroup]; !exists {
 						memory[job.RepGroup] = runningvariance.NewRunningStat()
 						disk[job.RepGroup] = runningvariance.NewRunningStat()
 						walltime[job.RepGroup] = runningvariance.NewRunningStat()
 						cputime[job.RepGroup] = runningvariance.NewRunningStat()
+						read[job.RepGroup] = runningvariance.NewRunningStat()
+						written[job.RepGroup] = runningvariance.NewRunningStat()
+						ctxswitches[job.RepGroup] = runningvariance.NewRunningStat()
+						efficiency[job.RepGroup] = runningvariance.NewRunningStat()
 						startends[job.RepGroup] = []time.Time{job.StartTime, job.EndTime}
 					}
 					memory[job.RepGroup].Push(float64(job.PeakRAM))
 					disk[job.RepGroup].Push(float64(job.PeakDisk))
 					walltime[job.RepGroup].Push(float64(job.WallTime()))
 					cputime[job.RepGroup].Push(float64(job.CPUtime))
+					read[job.RepGroup].Push(float64(job.BytesRead))
+					written[job.RepGroup].Push(float64(job.BytesWritten))
+					ctxswitches[job.RepGroup].Push(float64(job.CtxSwitches))
+					efficiency[job.RepGroup].Push(job.CPUEfficiency() * 100)
 					if job.StartTime.Before(startends[job.RepGroup][0]) {
 						startends[job.RepGroup][0] = job.StartTime
 					}
@@ -188,12 +238,20 @@ name to just the first letter, eg. -o c):
 						disk[allRepGrps] = runningvariance.NewRunningStat()
 						walltime[allRepGrps] = runningvariance.NewRunningStat()
 						cputime[allRepGrps] = runningvariance.NewRunningStat()
+						read[allRepGrps] = runningvariance.NewRunningStat()
+						written[allRepGrps] = runningvariance.NewRunningStat()
+						ctxswitches[allRepGrps] = runningvariance.NewRunningStat()
+						efficiency[allRepGrps] = runningvariance.NewRunningStat()
 						startends[allRepGrps] = []time.Time{job.StartTime, job.EndTime}
 					}
 					memory[allRepGrps].Push(float64(job.PeakRAM))
 					disk[allRepGrps].Push(float64(job.PeakDisk))
 					walltime[allRepGrps].Push(float64(job.WallTime()))
 					cputime[allRepGrps].Push(float64(job.CPUtime))
+					read[allRepGrps].Push(float64(job.BytesRead))
+					written[allRepGrps].Push(float64(job.BytesWritten))
+					ctxswitches[allRepGrps].Push(float64(job.CtxSwitches))
+					efficiency[allRepGrps].Push(job.CPUEfficiency() * 100)
 					if job.StartTime.Before(startends[allRepGrps][0]) {
 						startends[allRepGrps][0] = job.StartTime
 					}
@@ -220,8 +278,9 @@ name to just the first letter, eg. -o c):
 			// display summary for each RepGroup
 			for _, rg := range rgs {
 				var usage string
-				if counts[rg][jobqueue.JobStateComplete] > 0 {
+				if _, ran := memory[rg]; ran {
 					usage = fmt.Sprintf(" memory=%dMB(+/-%dMB) disk=%dMB(+/-%dMB) walltime=%s(+/-%s) cputime=%s(+/-%s)", int(memory[rg].Mean()), int(memory[rg].StandardDeviation()), int(disk[rg].Mean()), int(disk[rg].StandardDeviation()), time.Duration(walltime[rg].Mean()), time.Duration(walltime[rg].StandardDeviation()), time.Duration(cputime[rg].Mean()), time.Duration(cputime[rg].StandardDeviation()))
+					usage += fmt.Sprintf(" cpuefficiency=%.0f%%(+/-%.0f%%) read=%s(+/-%s) written=%s(+/-%s) ctxswitches=%d(+/-%d)", efficiency[rg].Mean(), efficiency[rg].StandardDeviation(), bytefmt.ByteSize(uint64(read[rg].Mean())), bytefmt.ByteSize(uint64(read[rg].StandardDeviation())), bytefmt.ByteSize(uint64(written[rg].Mean())), bytefmt.ByteSize(uint64(written[rg].StandardDeviation())), int64(ctxswitches[rg].Mean()), int64(ctxswitches[rg].StandardDeviation()))
 
 					if counts[rg][jobqueue.JobStateComplete] > 1 {
 						usage = usage + fmt.Sprintf(" started=%s ended=%s elapsed=%s", startends[rg][0].Format(shortTimeFormat), startends[rg][1].Format(shortTimeFormat), startends[rg][1].Sub(startends[rg][0]))
@@ -242,7 +301,12 @@ name to just the first letter, eg. -o c):
 					}
 				}
 
-				fmt.Printf("%s : complete=%d running=%d ready=%d dependent=%d lost=%d delayed=%d buried=%d%s%s\n", rg, counts[rg][jobqueue.JobStateComplete], counts[rg][jobqueue.JobStateRunning], counts[rg][jobqueue.JobStateReady], counts[rg][jobqueue.JobStateDependent], counts[rg][jobqueue.JobStateLost], counts[rg][jobqueue.JobStateDelayed], counts[rg][jobqueue.JobStateBuried], usage, dead)
+				var cachedCount string
+				if cached[rg] > 0 {
+					cachedCount = fmt.Sprintf("(%d cached)", cached[rg])
+				}
+
+				fmt.Printf("%s : complete=%d%s running=%d suspended=%d ready=%d dependent=%d held=%d lost=%d delayed=%d buried=%d%s%s\n", rg, counts[rg][jobqueue.JobStateComplete], cachedCount, counts[rg][jobqueue.JobStateRunning], counts[rg][jobqueue.JobStateSuspended], counts[rg][jobqueue.JobStateReady], counts[rg][jobqueue.JobStateDependent], counts[rg][jobqueue.JobStateHeld], counts[rg][jobqueue.JobStateLost], counts[rg][jobqueue.JobStateDelayed], counts[rg][jobqueue.JobStateBuried], usage, dead)
 			}
 		case "details", "d":
 			// print out status information for each job
@@ -271,10 +335,44 @@ name to just the first letter, eg. -o c):
 					}
 					dockerMonitored = fmt.Sprintf("Docker container monitoring turned on for: %s\n", dockerID)
 				}
+				if job.Container != nil {
+					dockerMonitored += fmt.Sprintf("Runs inside container: %s\n", job.Container)
+				}
 				var behaviours string
 				if len(job.Behaviours) > 0 {
 					behaviours = fmt.Sprintf("Behaviours: %s\n", job.Behaviours)
 				}
+				if job.RetryPolicy != nil {
+					behaviours += fmt.Sprintf("Retry policy: %s\n", job.RetryPolicy)
+				}
+				if job.TimeLimit != nil {
+					behaviours += fmt.Sprintf("Time limit: %s\n", job.TimeLimit)
+				}
+				if !job.BeginAt.IsZero() {
+					behaviours += fmt.Sprintf("Begin at: %s\n", job.BeginAt.Format(shortTimeFormat))
+				}
+				if job.Window != nil {
+					behaviours += fmt.Sprintf("Window: %s\n", job.Window)
+				}
+				if job.ArrayName != "" {
+					behaviours += fmt.Sprintf("Array: %s[%d]\n", job.ArrayName, job.ArrayIndex)
+				}
+				if !job.Deadline.IsZero() {
+					behaviours += fmt.Sprintf("Deadline: %s\n", job.Deadline.Format(shortTimeFormat))
+				}
+				var inouts string
+				if len(job.Inputs) > 0 {
+					inouts = fmt.Sprintf("Inputs: %s\n", strings.Join(job.Inputs, ", "))
+				}
+				if len(job.Outputs) > 0 {
+					inouts += fmt.Sprintf("Outputs: %s\n", strings.Join(job.Outputs, ", "))
+				}
+				if job.LogDir != "" {
+					inouts += fmt.Sprintf("Log files written to: %s\n", job.LogDir)
+				}
+				if job.LogToManager {
+					inouts += "Log files uploaded to the manager\n"
+				}
 				var other string
 				if len(job.Requirements.Other) > 0 {
 					var others []string
@@ -283,29 +381,61 @@ name to just the first letter, eg. -o c):
 					}
 					other = fmt.Sprintf("Resource requirements: %s\n", strings.Join(others, ", "))
 				}
-				fmt.Printf("\n# %s\nCwd: %s\n%s%s%s%s%sId: %s (%s); Requirements group: %s; %sPriority: %d; Attempts: %d\nExpected requirements: { memory: %dMB; time: %s; cpus: %s disk: %dGB }\n", job.Cmd, cwd, mounts, homeChanged, dockerMonitored, behaviours, other, job.RepGroup, job.Key(), job.ReqGroup, limitGroups, job.Priority, job.Attempts, job.Requirements.RAM, job.Requirements.Time, strconv.FormatFloat(job.Requirements.Cores, 'f', -1, 64), job.Requirements.Disk)
+				priority := strconv.Itoa(int(job.Priority))
+				if job.EffectivePriority > job.Priority {
+					priority += fmt.Sprintf(" (aged to %d)", job.EffectivePriority)
+				}
+				fmt.Printf("\n# %s\nCwd: %s\n%s%s%s%s%s%sId: %s (%s); Requirements group: %s; %sPriority: %s; Attempts: %d\nExpected requirements: { memory: %dMB; time: %s; cpus: %s disk: %dGB }\n", job.Cmd, cwd, mounts, homeChanged, dockerMonitored, behaviours, inouts, other, job.RepGroup, job.Key(), job.ReqGroup, limitGroups, priority, job.Attempts, job.Requirements.RAM, job.Requirements.Time, strconv.FormatFloat(job.Requirements.Cores, 'f', -1, 64), job.Requirements.Disk)
+				if job.Owner != "" {
+					fmt.Printf("Owner: %s\n", job.Owner)
+				}
 
 				switch job.State {
 				case jobqueue.JobStateDelayed:
-					fmt.Printf("Status: delayed following a temporary problem, will become ready soon (attempted at %s)\n", job.StartTime.Format(shortTimeFormat))
+					if job.StartTime.IsZero() {
+						fmt.Println("Status: delayed until it is allowed to start, as per its begin time or window")
+					} else {
+						fmt.Printf("Status: delayed following a temporary problem, will become ready soon (attempted at %s)\n", job.StartTime.Format(shortTimeFormat))
+					}
 				case jobqueue.JobStateReady:
-					fmt.Println("Status: ready to be picked up by a `wr runner`")
+					if job.PendingReason != "" {
+						fmt.Printf("Status: ready, but held back because %s\n", job.PendingReason)
+					} else {
+						fmt.Println("Status: ready to be picked up by a `wr runner`")
+					}
 				case jobqueue.JobStateDependent:
 					fmt.Println("Status: dependent on other jobs")
+				case jobqueue.JobStateHeld:
+					fmt.Println("Status: held - `wr release` to let it run")
 				case jobqueue.JobStateBuried:
 					fmt.Printf("Status: buried - you need to fix the problem and then `wr retry` (attempted at %s)\n", job.StartTime.Format(shortTimeFormat))
 				case jobqueue.JobStateReserved, jobqueue.JobStateRunning:
 					fmt.Printf("Status: running (started %s)\n", job.StartTime.Format(shortTimeFormat))
+				case jobqueue.JobStateSuspended:
+					fmt.Printf("Status: suspended - `wr resume-jobs` to continue it (started %s)\n", job.StartTime.Format(shortTimeFormat))
 				case jobqueue.JobStateLost:
 					fmt.Printf("Status: lost contact (started %s; lost %s)\n", job.StartTime.Format(shortTimeFormat), job.EndTime.Format(shortTimeFormat))
 				case jobqueue.JobStateComplete:
-					fmt.Printf("Status: complete (started %s; ended %s)\n", job.StartTime.Format(shortTimeFormat), job.EndTime.Format(shortTimeFormat))
+					if job.CachedFrom != "" {
+						fmt.Printf("Status: complete (at %s, reusing the cached results of %s)\n", job.EndTime.Format(shortTimeFormat), job.CachedFrom)
+					} else if job.Skipped {
+						fmt.Printf("Status: complete (skipped at %s since its outputs were up to date)\n", job.EndTime.Format(shortTimeFormat))
+					} else {
+						fmt.Printf("Status: complete (started %s; ended %s)\n", job.StartTime.Format(shortTimeFormat), job.EndTime.Format(shortTimeFormat))
+					}
 				}
 
 				if job.FailReason != "" {
 					fmt.Printf("Previous problem: %s\n", job.FailReason)
 				}
 
+				if len(job.History) > 0 {
+					fmt.Println("History:")
+					for _, event := range job.History {
+						fmt.Printf("  %s %s: %s\n", event.Time.Format(shortTimeFormat), event.Event, event.Detail)
+					}
+				}
+
 				var hostID string
 				if job.HostID != "" {
 					hostID = ", ID: " + job.HostID
@@ -316,7 +446,7 @@ name to just the first letter, eg. -o c):
 					if job.State != jobqueue.JobStateComplete {
 						prefix = "Stats of previous attempt"
 					}
-					fmt.Printf("%s: { Exit code: %d; Peak memory: %dMB; Peak disk: %dMB; Wall time: %s; CPU time: %s }\nHost: %s (IP: %s%s); Pid: %d\n", prefix, job.Exitcode, job.PeakRAM, job.PeakDisk, job.WallTime(), job.CPUtime, job.Host, job.HostIP, hostID, job.Pid)
+					fmt.Printf("%s: { Exit code: %d; Peak memory: %dMB; Peak disk: %dMB; Wall time: %s; CPU time: %s; CPU efficiency: %.0f%%; Read: %s; Written: %s; Context switches: %d }\nHost: %s (IP: %s%s); Pid: %d\n", prefix, job.Exitcode, job.PeakRAM, job.PeakDisk, job.WallTime(), job.CPUtime, job.CPUEfficiency()*100, bytefmt.ByteSize(uint64(job.BytesRead)), bytefmt.ByteSize(uint64(job.BytesWritten)), job.CtxSwitches, job.Host, job.HostIP, hostID, job.Pid)
 					if showextra && showStd && job.Exitcode != 0 {
 						stdout, errs := job.StdOut()
 						if errs != nil {
@@ -335,7 +465,7 @@ name to just the first letter, eg. -o c):
 							fmt.Printf("StdErr: [none]\n")
 						}
 					}
-				} else if job.State == jobqueue.JobStateRunning || job.State == jobqueue.JobStateLost {
+				} else if job.State == jobqueue.JobStateRunning || job.State == jobqueue.JobStateSuspended || job.State == jobqueue.JobStateLost {
 					fmt.Printf("Stats: { Wall time: %s }\nHost: %s (IP: %s%s); Pid: %d\n", job.WallTime(), job.Host, job.HostIP, hostID, job.Pid)
 					//*** we should be able to peek at STDOUT & STDERR, and see
 					// Peak memory during a run... but is that possible/ too
@@ -350,6 +480,10 @@ name to just the first letter, eg. -o c):
 					}
 				}
 
+				if len(job.CopiedFiles) > 0 {
+					fmt.Printf("Copied to manager: %s\n", strings.Join(job.CopiedFiles, ", "))
+				}
+
 				if showextra && showEnv {
 					env, erre := job.Env()
 					if erre != nil {
@@ -395,6 +529,38 @@ name to just the first letter, eg. -o c):
 	},
 }
 
+// showFairShares prints the current share of each fair-share party.
+func showFairShares(jq *jobqueue.Client) {
+	shares, err := jq.GetFairShares()
+	if err != nil {
+		die("could not get fair-share information: %s", err)
+	}
+	if len(shares) == 0 {
+		info("no commands have been run with fair-share scheduling enabled")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 2, 2, 3, ' ', 0)
+	_, err = fmt.Fprintln(w, "PARTY\tWEIGHT\tTARGET\tSHARE\tCPU_HOURS\tRUNNING")
+	if err != nil {
+		warn("failed to print header: %s", err)
+	}
+	for _, share := range shares {
+		party := share.Party
+		if party == "" {
+			party = "(none)"
+		}
+		_, err = fmt.Fprintf(w, "%s\t%g\t%.1f%%\t%.1f%%\t%.2f\t%d\n", party, share.Weight, share.Target*100, share.Share*100, share.Usage, share.Running)
+		if err != nil {
+			warn("failed to print line: %s", err)
+		}
+	}
+	err = w.Flush()
+	if err != nil {
+		warn("failed to flush output: %s", err)
+	}
+}
+
 func init() {
 	RootCmd.AddCommand(statusCmd)
 
@@ -404,13 +570,15 @@ func init() {
 	statusCmd.Flags().BoolVarP(&cmdIDIsSubStr, "search", "z", false, "treat -i as a substring to match against all report groups")
 	statusCmd.Flags().BoolVarP(&cmdIDIsInternal, "internal", "y", false, "treat -i as an internal job id")
 	statusCmd.Flags().StringVarP(&cmdLine, "cmdline", "l", "", "a command line you want the status of")
+	statusCmd.Flags().StringVar(&cmdArrayName, "array_name", "", "name of the job array you want the status of")
+	statusCmd.Flags().StringVar(&cmdArrayIndices, "indices", "", "with --array_name, only these members of the array [eg. \"1-10,20\"]")
 	statusCmd.Flags().StringVarP(&cmdCwd, "cwd", "c", "", "working dir that the command(s) specified by -l or -f were set to run in")
 	statusCmd.Flags().StringVarP(&mountJSON, "mount_json", "j", "", "mounts that the command(s) specified by -l or -f were set to use (JSON format)")
 	statusCmd.Flags().StringVar(&mountSimple, "mounts", "", "mounts that the command(s) specified by -l or -f were set to use (simple format)")
 	statusCmd.Flags().BoolVarP(&showBuried, "buried", "b", false, "in default or -i mode only, only show the status of buried commands")
 	statusCmd.Flags().BoolVarP(&showStd, "std", "s", false, "in -o d mode, except in -f mode, also show the most recent STDOUT and STDERR of incomplete commands")
 	statusCmd.Flags().BoolVarP(&showEnv, "env", "e", false, "in -o d mode, except in -f mode, also show the environment variables the command(s) ran with")
-	statusCmd.Flags().StringVarP(&outputFormat, "output", "o", "details", "['counts','summary','details','json'] output format")
+	statusCmd.Flags().StringVarP(&outputFormat, "output", "o", "details", "['counts','summary','details','json','fairshare'] output format")
 	statusCmd.Flags().IntVar(&statusLimit, "limit", 1, "in -o d mode, number of commands that share the same properties to display; 0 displays all")
 
 	statusCmd.Flags().IntVar(&timeoutint, "timeout", 120, "how long (seconds) to wait to get a reply from 'wr manager'")
@@ -427,6 +595,9 @@ func countGetJobArgs() int {
 	if cmdLine != "" {
 		set++
 	}
+	if cmdArrayName != "" {
+		set++
+	}
 	if cmdAll {
 		set++
 	}
@@ -437,6 +608,10 @@ func getJobs(jq *jobqueue.Client, cmdState jobqueue.JobState, all bool, statusLi
 	var jobs []*jobqueue.Job
 	var err error
 
+	if cmdArrayIndices != "" && cmdArrayName == "" {
+		die("--indices requires --array_name")
+	}
+
 	switch {
 	case all:
 		// get all jobs
@@ -455,6 +630,9 @@ func getJobs(jq *jobqueue.Client, cmdState jobqueue.JobState, all bool, statusLi
 			// get all jobs with this identifier (repgroup)
 			jobs, err = jq.GetByRepGroup(cmdIDStatus, cmdIDIsSubStr, statusLimit, cmdState, showStd, showEnv)
 		}
+	case cmdArrayName != "":
+		// get the members of this job array
+		jobs, err = jq.GetByArray(cmdArrayName, cmdArrayIndices, statusLimit, cmdState, showStd, showEnv)
 	case cmdFileStatus != "":
 		// parse the supplied commands
 		parsedJobs, _, _ := parseCmdFile(jq, false)
@@ -0,0 +1,173 @@
+// Copyright © 2026 Genome Research Limited
+// Author: Sendu Bala <sb10@sanger.ac.uk>.
+//
+//  This file is part of wr.
+//
+//  wr is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Lesser General Public License as published by
+//  the Free Software Foundation, either version 3 of the License, or
+//  (at your option) any later version.
+//
+//  wr is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Lesser General Public License for more details.
+//
+//  You should have received a copy of the GNU Lesser General Public License
+//  along with wr. If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"time"
+
+	"github.com/VertebrateResequencing/wr/jobqueue"
+	"github.com/spf13/cobra"
+)
+
+// suspendCmd represents the suspend command
+var suspendCmd = &cobra.Command{
+	Use:   "suspend",
+	Short: "Temporarily stop running commands",
+	Long: `You can temporarily stop commands you've previously added with "wr add"
+that are currently running using this command.
+
+Suspended commands (and any processes they spawned) are sent SIGSTOP. They stay
+on the machine they were running on, in "suspended" state, and will not be
+considered lost or have their time limits run down while suspended. Under the
+local scheduler, their memory and cpu reservations are made available to other
+commands until they are resumed with "wr resume-jobs".
+
+Note that the memory used by suspended commands is not actually freed, so other
+commands that get to run in their place could cause the machine to run out of
+memory.
+
+After suspending commands, there will be a delay before the commands "realise"
+they have been suspended and actually stop running.
+
+Specify one of the flags -f, -l, -i or -a to choose which commands you want to
+suspend. Amongst those, only running jobs will be affected.
+
+-i is the report group (-i) you supplied to "wr add" when you added the job(s)
+you want to now suspend. Combining with -z lets you suspend jobs in multiple
+report groups, assuming you have arranged that related groups share some
+substring. Alternatively -y lets you specify -i as the internal job id reported
+during "wr status".
+
+The file to provide -f is in the format taken by "wr add".
+
+In -f and -l mode you must provide the cwd the commands were set to run in, if
+CwdMatters (and must NOT be provided otherwise). Likewise provide the mounts
+options that was used when the command was added, if any. You can do this by
+using the -c and --mounts/--mounts_json options in -l mode, or by providing the
+same file you gave to "wr add" in -f mode.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		suspendOrResume(true)
+	},
+}
+
+// resumeJobsCmd represents the resume-jobs command
+var resumeJobsCmd = &cobra.Command{
+	Use:   "resume-jobs",
+	Short: "Continue suspended commands",
+	Long: `You can continue commands that you previously stopped with
+"wr suspend" using this command.
+
+Resumed commands (and any processes they spawned) are sent SIGCONT, and return
+to "running" state. There will be a delay before they actually continue.
+
+Specify one of the flags -f, -l, -i or -a to choose which commands you want to
+resume. Amongst those, only suspended jobs will be affected.
+
+-i is the report group (-i) you supplied to "wr add" when you added the job(s)
+you want to now resume. Combining with -z lets you resume jobs in multiple
+report groups, assuming you have arranged that related groups share some
+substring. Alternatively -y lets you specify -i as the internal job id reported
+during "wr status".
+
+The file to provide -f is in the format taken by "wr add".
+
+In -f and -l mode you must provide the cwd the commands were set to run in, if
+CwdMatters (and must NOT be provided otherwise). Likewise provide the mounts
+options that was used when the command was added, if any. You can do this by
+using the -c and --mounts/--mounts_json options in -l mode, or by providing the
+same file you gave to "wr add" in -f mode.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		suspendOrResume(false)
+	},
+}
+
+// suspendOrResume implements "wr suspend" and "wr resume-jobs".
+func suspendOrResume(suspend bool) {
+	set := countGetJobArgs()
+	if set > 1 {
+		die("-f, -i, -l and -a are mutually exclusive; only specify one of them")
+	}
+	if set == 0 {
+		die("1 of -f, -i, -l or -a is required")
+	}
+
+	timeout := time.Duration(timeoutint) * time.Second
+	jq := connect(timeout)
+	var err error
+	defer func() {
+		err = jq.Disconnect()
+		if err != nil {
+			warn("Disconnecting from the server failed: %s", err)
+		}
+	}()
+
+	jstate := jobqueue.JobStateRunning
+	if !suspend {
+		jstate = jobqueue.JobStateSuspended
+	}
+	jobs := getJobs(jq, jstate, cmdAll, 0, false, false)
+
+	if len(jobs) == 0 {
+		die("No matching jobs found")
+	}
+
+	jes := jobsToJobEssenses(jobs)
+	if suspend {
+		suspended, errs := jq.SuspendJobs(jes)
+		if errs != nil {
+			die("failed to suspend desired jobs: %s", errs)
+		}
+		info("Initiated the suspension of %d running commands (out of %d eligible)", suspended, len(jobs))
+		return
+	}
+
+	resumed, err := jq.ResumeJobs(jes)
+	if err != nil {
+		die("failed to resume desired jobs: %s", err)
+	}
+	info("Initiated the resumption of %d suspended commands (out of %d eligible)", resumed, len(jobs))
+}
+
+func init() {
+	RootCmd.AddCommand(suspendCmd)
+	RootCmd.AddCommand(resumeJobsCmd)
+
+	// flags specific to these sub-commands
+	suspendCmd.Flags().BoolVarP(&cmdAll, "all", "a", false, "suspend all running jobs")
+	suspendCmd.Flags().StringVarP(&cmdFileStatus, "file", "f", "", "file containing commands you want to suspend; - means read from STDIN")
+	suspendCmd.Flags().StringVarP(&cmdIDStatus, "identifier", "i", "", "identifier of the commands you want to suspend")
+	suspendCmd.Flags().BoolVarP(&cmdIDIsSubStr, "search", "z", false, "treat -i as a substring to match against all report groups")
+	suspendCmd.Flags().BoolVarP(&cmdIDIsInternal, "internal", "y", false, "treat -i as an internal job id")
+	suspendCmd.Flags().StringVarP(&cmdLine, "cmdline", "l", "", "a command line you want to suspend")
+	suspendCmd.Flags().StringVarP(&cmdCwd, "cwd", "c", "", "working dir that the command(s) specified by -l or -f were set to run in")
+	suspendCmd.Flags().StringVarP(&mountJSON, "mount_json", "j", "", "mounts that the command(s) specified by -l or -f were set to use (JSON format)")
+	suspendCmd.Flags().StringVar(&mountSimple, "mounts", "", "mounts that the command(s) specified by -l or -f were set to use (simple format)")
+	suspendCmd.Flags().IntVar(&timeoutint, "timeout", 120, "how long (seconds) to wait to get a reply from 'wr manager'")
+
+	resumeJobsCmd.Flags().BoolVarP(&cmdAll, "all", "a", false, "resume all suspended jobs")
+	resumeJobsCmd.Flags().StringVarP(&cmdFileStatus, "file", "f", "", "file containing commands you want to resume; - means read from STDIN")
+	resumeJobsCmd.Flags().StringVarP(&cmdIDStatus, "identifier", "i", "", "identifier of the commands you want to resume")
+	resumeJobsCmd.Flags().BoolVarP(&cmdIDIsSubStr, "search", "z", false, "treat -i as a substring to match against all report groups")
+	resumeJobsCmd.Flags().BoolVarP(&cmdIDIsInternal, "internal", "y", false, "treat -i as an internal job id")
+	resumeJobsCmd.Flags().StringVarP(&cmdLine, "cmdline", "l", "", "a command line you want to resume")
+	resumeJobsCmd.Flags().StringVarP(&cmdCwd, "cwd", "c", "", "working dir that the command(s) specified by -l or -f were set to run in")
+	resumeJobsCmd.Flags().StringVarP(&mountJSON, "mount_json", "j", "", "mounts that the command(s) specified by -l or -f were set to use (JSON format)")
+	resumeJobsCmd.Flags().StringVar(&mountSimple, "mounts", "", "mounts that the command(s) specified by -l or -f were set to use (simple format)")
+	resumeJobsCmd.Flags().IntVar(&timeoutint, "timeout", 120, "how long (seconds) to wait to get a reply from 'wr manager'")
+}
@@ -0,0 +1,189 @@
+// Copyright © 2026 Genome Research Limited
+// Author: Sendu Bala <sb10@sanger.ac.uk>.
+//
+//  This file is part of wr.
+//
+//  wr is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Lesser General Public License as published by
+//  the Free Software Foundation, either version 3 of the License, or
+//  (at your option) any later version.
+//
+//  wr is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Lesser General Public License for more details.
+//
+//  You should have received a copy of the GNU Lesser General Public License
+//  along with wr. If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// options for this cmd
+var userName string
+var userAdmin bool
+
+// userCmd represents the user command
+var userCmd = &cobra.Command{
+	Use:   "user",
+	Short: "Manage the users of a shared manager",
+	Long: `Manage the people who can use your manager.
+
+When several people share one manager, rather than them all using the token of
+the person who started the manager (which lets them do anything, including
+removing each other's commands), you can issue each of them their own token
+with the 'add' sub-command.
+
+Commands added by such a user belong to them. Unless they are an admin user,
+they can only modify, kill, suspend, resume, retry and remove their own
+commands, and can't stop, pause, drain or backup the manager, or manage users.
+Nor can they run commands themselves with "wr runner": only admins can reserve
+commands to run.
+
+The person who started the manager is always an admin, as is anyone using the
+manager's own token (such as the runners the manager spawns).`,
+}
+
+// add sub-command issues a new user with a token
+var userAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Create a user",
+	Long: `Create a new user of the manager, and get their token.
+
+The token is printed to STDOUT. Give it to the user, who should save it to the
+file pointed to by their managertokenfile config option (by default
+~/.wr_[deployment]/client.token), and set their managerhost and managerport
+config options to point to this manager.
+
+Use --admin to make the user an admin, who can act on everyone's commands and
+control the manager itself.
+
+Only admins can use this command.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if userName == "" {
+			die("--name is required")
+		}
+		jq := connect(time.Duration(timeoutint) * time.Second)
+		defer func() {
+			err := jq.Disconnect()
+			if err != nil {
+				warn("Disconnecting from the server failed: %s", err)
+			}
+		}()
+
+		token, err := jq.AddUser(userName, userAdmin)
+		if err != nil {
+			die("failed to add user %s: %s", userName, err)
+		}
+		fmt.Println(string(token))
+	},
+}
+
+// remove sub-command revokes a user's token
+var userRemoveCmd = &cobra.Command{
+	Use:   "remove",
+	Short: "Remove a user",
+	Long: `Remove a user of the manager, so that their token no longer works.
+
+Commands they added are unaffected, but only admins will be able to act on
+them.
+
+Only admins can use this command.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if userName == "" {
+			die("--name is required")
+		}
+		jq := connect(time.Duration(timeoutint) * time.Second)
+		defer func() {
+			err := jq.Disconnect()
+			if err != nil {
+				warn("Disconnecting from the server failed: %s", err)
+			}
+		}()
+
+		removed, err := jq.RemoveUser(userName)
+		if err != nil {
+			die("failed to remove user %s: %s", userName, err)
+		}
+		if !removed {
+			die("there is no user named %s", userName)
+		}
+		info("Removed user %s", userName)
+	},
+}
+
+// list sub-command shows all users
+var userListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List users",
+	Long: `List the users of the manager, with admin users marked as such.
+
+Only admins can use this command.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		jq := connect(time.Duration(timeoutint) * time.Second)
+		defer func() {
+			err := jq.Disconnect()
+			if err != nil {
+				warn("Disconnecting from the server failed: %s", err)
+			}
+		}()
+
+		users, err := jq.GetUsers()
+		if err != nil {
+			die("failed to get users: %s", err)
+		}
+		for _, user := range users {
+			if user.Admin {
+				fmt.Printf("%s (admin)\n", user.Name)
+			} else {
+				fmt.Println(user.Name)
+			}
+		}
+	},
+}
+
+// whoami sub-command shows who your token says you are
+var userWhoAmICmd = &cobra.Command{
+	Use:   "whoami",
+	Short: "Show which user you are",
+	Long:  `Show the name of the user that the manager thinks you are, based on your token.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		jq := connect(time.Duration(timeoutint) * time.Second)
+		defer func() {
+			err := jq.Disconnect()
+			if err != nil {
+				warn("Disconnecting from the server failed: %s", err)
+			}
+		}()
+
+		user, err := jq.WhoAmI()
+		if err != nil {
+			die("failed to find out who you are: %s", err)
+		}
+		if user.Admin {
+			fmt.Printf("%s (admin)\n", user.Name)
+		} else {
+			fmt.Println(user.Name)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(userCmd)
+	userCmd.AddCommand(userAddCmd)
+	userCmd.AddCommand(userRemoveCmd)
+	userCmd.AddCommand(userListCmd)
+	userCmd.AddCommand(userWhoAmICmd)
+
+	// flags specific to these sub-commands
+	userAddCmd.Flags().StringVarP(&userName, "name", "n", "", "name of the user to create")
+	userAddCmd.Flags().BoolVar(&userAdmin, "admin", false, "make the user an admin")
+	userRemoveCmd.Flags().StringVarP(&userName, "name", "n", "", "name of the user to remove")
+	userCmd.PersistentFlags().IntVar(&timeoutint, "timeout", 120, "how long (seconds) to wait to get a reply from 'wr manager'")
+}
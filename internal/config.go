@@ -55,6 +55,8 @@ type Config struct {
 	ManagerDbBkFile     string `default:"db_bk"`
 	ManagerTokenFile    string `default:"client.token"`
 	ManagerUploadDir    string `default:"uploads"`
+	ManagerCopyDir      string `default:"copied"`
+	ManagerLogDir       string `default:"logs"`
 	ManagerUmask        int    `default:"007"`
 	ManagerScheduler    string `default:"local"`
 	ManagerCAFile       string `default:"ca.pem"`
@@ -62,6 +64,13 @@ type Config struct {
 	ManagerKeyFile      string `default:"key.pem"`
 	ManagerCertDomain   string `default:"localhost"`
 	ManagerSetDomainIP  bool   `default:"false"`
+	FairShare           string `default:""`
+	FairShareHalfLife   string `default:"24h"`
+	FairShareWeights    string `default:""`
+	PriorityAging       string `default:""`
+	PriorityAgingMax    int    `default:"255"`
+	PreemptPriority     int    `default:"0"`
+	PreemptMode         string `default:"kill"`
 	RunnerExecShell     string `default:"bash"`
 	Deployment          string `default:"production"`
 	CloudFlavor         string `default:""`
@@ -198,6 +207,12 @@ func ConfigLoad(deployment string, useparentdir bool, logger log15.Logger) Confi
 	if !filepath.IsAbs(config.ManagerUploadDir) {
 		config.ManagerUploadDir = filepath.Join(config.ManagerDir, config.ManagerUploadDir)
 	}
+	if !filepath.IsAbs(config.ManagerCopyDir) {
+		config.ManagerCopyDir = filepath.Join(config.ManagerDir, config.ManagerCopyDir)
+	}
+	if !filepath.IsAbs(config.ManagerLogDir) {
+		config.ManagerLogDir = filepath.Join(config.ManagerDir, config.ManagerLogDir)
+	}
 
 	// if not explicitly set, calculate ports that no one else would be
 	// assigned by us (and hope no other software is using it...)
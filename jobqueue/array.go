@@ -0,0 +1,351 @@
+// Copyright © 2026 Genome Research Limited
+// Author: Sendu Bala <sb10@sanger.ac.uk>.
+//
+//  This file is part of wr.
+//
+//  wr is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Lesser General Public License as published by
+//  the Free Software Foundation, either version 3 of the License, or
+//  (at your option) any later version.
+//
+//  wr is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Lesser General Public License for more details.
+//
+//  You should have received a copy of the GNU Lesser General Public License
+//  along with wr. If not, see <http://www.gnu.org/licenses/>.
+
+package jobqueue
+
+// This file contains the implementation of job arrays: template Jobs that the
+// server expands in to many Jobs.
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ArrayIndexPlaceholder is replaced with a member's index in the Cmd, Cwd
+	// and RepGroup of a Job with an Array.
+	ArrayIndexPlaceholder = "{{index}}"
+
+	// ArrayListParam is the name of the single parameter of an Array made
+	// from a list of values.
+	ArrayListParam = "value"
+
+	// maxArrayMembers is the maximum number of Jobs a single Array can expand
+	// in to.
+	maxArrayMembers = 10000000
+)
+
+// validArrayParam is what the name of an Array parameter must match, so that
+// it can be used in an environment variable name.
+var validArrayParam = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// JobArray describes how a template Job is expanded by the server in to many
+// Jobs, one for each member of the array.
+//
+// An array is either numeric, with a member for each of Indices, or a parameter
+// sweep, with a member for every combination of the Values of its Params (the
+// first Param varying slowest), indexed from 1. A list of values is a sweep of
+// a single Param called "value".
+//
+// In each member's Cmd, Cwd and RepGroup, "{{index}}" is replaced with its
+// index, and "{{param}}" with its value of each Param. Its environment also has
+// WR_ARRAY_NAME and WR_ARRAY_INDEX set, along with WR_ARRAY_<PARAM> for each
+// Param (upper-cased).
+type JobArray struct {
+	// Name lets you address all the members of the array together. It
+	// defaults to the template Job's RepGroup.
+	Name string
+
+	Indices []int
+	Params  []string
+	Values  [][]string
+}
+
+// NewJobArray creates a JobArray from one of: a numeric indices specification
+// (as per ParseArrayIndices()), a list of values, or the path to a TSV file
+// with a header line of parameter names followed by lines of values, where
+// each column lists the possible values of its parameter (blank cells are
+// ignored, so columns can be of different lengths). Returns nil if none of
+// them are supplied.
+func NewJobArray(indices string, list []string, tsvPath string, name string) (*JobArray, error) {
+	supplied := 0
+	for _, set := range []bool{indices != "", len(list) > 0, tsvPath != ""} {
+		if set {
+			supplied++
+		}
+	}
+	if supplied == 0 {
+		return nil, nil
+	}
+	if supplied > 1 {
+		return nil, fmt.Errorf("only one of an array's indices, list or TSV file can be supplied")
+	}
+
+	var a *JobArray
+	switch {
+	case indices != "":
+		idx, err := ParseArrayIndices(indices)
+		if err != nil {
+			return nil, err
+		}
+		a = &JobArray{Indices: idx}
+	case len(list) > 0:
+		a = &JobArray{Params: []string{ArrayListParam}, Values: [][]string{list}}
+	default:
+		f, err := os.Open(tsvPath)
+		if err != nil {
+			return nil, err
+		}
+		a, err = parseArrayTSV(f)
+		if errc := f.Close(); err == nil {
+			err = errc
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", tsvPath, err)
+		}
+	}
+	a.Name = name
+	return a, a.validate()
+}
+
+// parseArrayTSV reads parameter names and their values from the given TSV
+// content.
+func parseArrayTSV(r io.Reader) (*JobArray, error) {
+	a := &JobArray{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		cols := strings.Split(line, "\t")
+		if a.Params == nil {
+			for _, col := range cols {
+				a.Params = append(a.Params, strings.TrimSpace(col))
+			}
+			a.Values = make([][]string, len(a.Params))
+			continue
+		}
+		if len(cols) > len(a.Params) {
+			return nil, fmt.Errorf("line [%s] has more columns than the header", line)
+		}
+		for i, col := range cols {
+			if col != "" {
+				a.Values[i] = append(a.Values[i], col)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if a.Params == nil {
+		return nil, fmt.Errorf("no header line")
+	}
+	return a, nil
+}
+
+// ParseArrayIndices parses a comma separated list of integers and inclusive
+// ranges of them, where ranges can have a step, eg. "1-10,15,20-100:10". The
+// returned indices are sorted and unique.
+func ParseArrayIndices(spec string) ([]int, error) {
+	seen := make(map[int]bool)
+	var indices []int
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return nil, fmt.Errorf("array indices [%s] contain an empty item", spec)
+		}
+
+		step := 1
+		if pos := strings.Index(part, ":"); pos != -1 {
+			var err error
+			step, err = strconv.Atoi(part[pos+1:])
+			if err != nil || step < 1 {
+				return nil, fmt.Errorf("array indices [%s] have a bad step in [%s]", spec, part)
+			}
+			part = part[:pos]
+		}
+
+		var start, end int
+		if part == "" {
+			return nil, fmt.Errorf("array indices [%s] contain an empty item", spec)
+		}
+		var err error
+		if pos := strings.Index(part[1:], "-"); pos != -1 {
+			start, err = strconv.Atoi(part[:pos+1])
+			if err == nil {
+				end, err = strconv.Atoi(part[pos+2:])
+			}
+		} else {
+			start, err = strconv.Atoi(part)
+			end = start
+		}
+		if err != nil || end < start {
+			return nil, fmt.Errorf("array indices [%s] have a bad item [%s]", spec, part)
+		}
+		if len(indices)+(end-start)/step >= maxArrayMembers {
+			return nil, fmt.Errorf("array indices [%s] have more than %d members", spec, maxArrayMembers)
+		}
+		for i := start; i <= end; i += step {
+			if !seen[i] {
+				seen[i] = true
+				indices = append(indices, i)
+			}
+		}
+	}
+	sort.Ints(indices)
+	return indices, nil
+}
+
+// validate checks that the array has members, and that its parameters are
+// sensible.
+func (a *JobArray) validate() error {
+	if len(a.Params) != len(a.Values) {
+		return fmt.Errorf("an array must have values for each of its parameters")
+	}
+	seen := make(map[string]bool)
+	for _, param := range a.Params {
+		if !validArrayParam.MatchString(param) {
+			return fmt.Errorf("array parameter [%s] must consist of letters, numbers and underscores, and not start with a number", param)
+		}
+		if param == "index" || seen[param] {
+			return fmt.Errorf("array parameter [%s] is reserved or used more than once", param)
+		}
+		seen[param] = true
+	}
+	size := a.size()
+	if size == 0 {
+		return fmt.Errorf("an array must have at least 1 member")
+	}
+	if size > maxArrayMembers {
+		return fmt.Errorf("an array can't have more than %d members", maxArrayMembers)
+	}
+	return nil
+}
+
+// size returns the number of members of the array.
+func (a *JobArray) size() int {
+	if len(a.Params) == 0 {
+		return len(a.Indices)
+	}
+	size := 1
+	for _, values := range a.Values {
+		size *= len(values)
+		if size > maxArrayMembers {
+			return maxArrayMembers + 1
+		}
+	}
+	return size
+}
+
+// member returns the index and parameter values of the i-th (from 0) member of
+// the array.
+func (a *JobArray) member(i int) (int, []string) {
+	if len(a.Params) == 0 {
+		return a.Indices[i], nil
+	}
+	values := make([]string, len(a.Params))
+	rem := i
+	for p := len(a.Params) - 1; p >= 0; p-- {
+		n := len(a.Values[p])
+		values[p] = a.Values[p][rem%n]
+		rem /= n
+	}
+	return i + 1, values
+}
+
+// expandJobArrays returns the given jobs, but with any that have an Array
+// replaced by the members of that Array.
+func expandJobArrays(jobs []*Job) ([]*Job, error) {
+	hasArray := false
+	for _, job := range jobs {
+		if job.Array != nil {
+			hasArray = true
+			break
+		}
+	}
+	if !hasArray {
+		return jobs, nil
+	}
+
+	var expanded []*Job
+	for _, job := range jobs {
+		if job.Array == nil {
+			expanded = append(expanded, job)
+			continue
+		}
+		members, err := job.arrayMembers()
+		if err != nil {
+			return nil, err
+		}
+		expanded = append(expanded, members...)
+	}
+	return expanded, nil
+}
+
+// arrayMembers expands this template Job in to a Job for each member of its
+// Array.
+func (j *Job) arrayMembers() ([]*Job, error) {
+	a := j.Array
+	err := a.validate()
+	if err != nil {
+		return nil, err
+	}
+	name := a.Name
+	if name == "" {
+		name = j.RepGroup
+	}
+
+	template, err := j.copy()
+	if err != nil {
+		return nil, err
+	}
+	template.Array = nil
+	template.ArrayName = name
+
+	size := a.size()
+	members := make([]*Job, size)
+	for i := 0; i < size; i++ {
+		index, values := a.member(i)
+		job, errc := template.copy()
+		if errc != nil {
+			return nil, errc
+		}
+		job.ArrayIndex = index
+
+		indexStr := strconv.Itoa(index)
+		pairs := []string{ArrayIndexPlaceholder, indexStr}
+		env := []string{"WR_ARRAY_NAME=" + name, "WR_ARRAY_INDEX=" + indexStr}
+		for p, param := range a.Params {
+			pairs = append(pairs, "{{"+param+"}}", values[p])
+			env = append(env, "WR_ARRAY_"+strings.ToUpper(param)+"="+values[p])
+		}
+		replacer := strings.NewReplacer(pairs...)
+		job.Cmd = replacer.Replace(j.Cmd)
+		job.Cwd = replacer.Replace(j.Cwd)
+		job.RepGroup = replacer.Replace(j.RepGroup)
+
+		if job.Cmd == j.Cmd && (!job.CwdMatters || job.Cwd == j.Cwd) {
+			// make sure each member has a unique key
+			job.Cmd += fmt.Sprintf(" # wr array %s[%d]", name, index)
+		}
+
+		err = job.EnvAddOverride(env)
+		if err != nil {
+			return nil, err
+		}
+		members[i] = job
+	}
+	return members, nil
+}
@@ -67,10 +67,8 @@ const (
 	CleanupAll BehaviourAction = 1 << iota
 
 	// Cleanup is a BehaviourAction that behaves exactly as CleanupAll in the
-	// case that no output files have been specified on the Job. If some have,
+	// case that no Outputs have been specified on the Job. If some have,
 	// everything except those files gets deleted. It takes no arguments.
-	// (NB: since output file specification has not yet been implemented, this
-	// is currently identical to CleanupAll.)
 	Cleanup
 
 	// Run is a BehaviourAction that runs a given command (supplied as a single
@@ -80,7 +78,9 @@ const (
 	// CopyToManager is a BehaviourAction that copies the given files (specified
 	// as a slice of string paths Arg to the Behaviour) from the Job's actual
 	// cwd to a configured location on the machine that the jobqueue server is
-	// running on. *** not yet implemented!
+	// running on. The files are stored in a sub-directory named after the Job's
+	// key, and the resulting paths are recorded in the Job's CopiedFiles. Only
+	// works for Jobs being run via Client.Execute().
 	CopyToManager
 
 	// Nothing is a BehaviourAction that does nothing. It allows you to define
@@ -135,7 +135,7 @@ func (b *Behaviour) fillBVJM(bvjm *bvjMapping) {
 		bvj = BehaviourViaJSON{Run: arg}
 	case CopyToManager:
 		var arg []string
-		if files, wasStrSlice := b.Arg.([]string); wasStrSlice {
+		if files, wasStrSlice := b.argStrings(); wasStrSlice {
 			arg = files
 		} else {
 			arg = []string{"!invalid!"}
@@ -187,12 +187,8 @@ func (b *Behaviour) String() string {
 
 // cleanup with all == true wipes out the Job's unique dir as aggressively as
 // possible, along with all empty parent dirs up to Cwd. Without all, will keep
-// files designated as outputs (*** designation not yet implemented).
+// files designated as the Job's Outputs.
 func (b *Behaviour) cleanup(j *Job, all bool) error {
-	if !all {
-		// *** not yet implemented, we just wipe everything!
-	}
-
 	if j.ActualCwd == "" {
 		// must be a CwdMatters job, or somehow ActualCwd didn't get set; we do
 		// nothing in this case
@@ -204,11 +200,16 @@ func (b *Behaviour) cleanup(j *Job, all bool) error {
 	// dirs (that we don't want to delete).
 	workSpace := filepath.Dir(j.ActualCwd)
 
-	if len(j.MountConfigs) > 0 {
+	var keepDirs []string
+	if !all {
+		keepDirs = j.outputsInActualCwd()
+	}
+
+	if len(j.MountConfigs) > 0 || len(keepDirs) > 0 {
 		// if we have mounts, we don't want to delete the cache dirs or any
-		// mounted directories, so we'll have to go through and delete
-		// everything else manually
-		var keepDirs []string
+		// mounted directories, and if we have outputs we don't want to delete
+		// those, so we'll have to go through and delete everything else
+		// manually
 		var keepActualCwd bool
 		for _, mc := range j.MountConfigs {
 			if mc.Mount == "" {
@@ -288,16 +289,64 @@ func (b *Behaviour) run(j *Job) error {
 }
 
 // copyToManager copies the files specified in the Arg slice to the configured
-// location on the manager's machine.
+// location on the manager's machine. Relative paths are taken to be relative to
+// the Job's actual cwd, and keep that relative path on the manager. Absolute
+// paths outside of the actual cwd are stored using just their basename.
 func (b *Behaviour) copyToManager(j *Job) error {
-	_, wasStrSlice := b.Arg.([]string)
+	files, wasStrSlice := b.argStrings()
 	if !wasStrSlice {
 		return fmt.Errorf("Arg %s is type %T, not []string", b.Arg, b.Arg)
 	}
 
-	// *** not yet implemented
+	if j.client == nil {
+		return fmt.Errorf("copy to manager behaviour failed: job is not being executed by a client")
+	}
+
+	actualCwd := j.ActualCwd
+	if actualCwd == "" {
+		actualCwd = j.Cwd
+	}
 
-	return nil
+	var merr *multierror.Error
+	for _, file := range files {
+		local := file
+		if !filepath.IsAbs(local) {
+			local = filepath.Join(actualCwd, local)
+		}
+
+		name, err := filepath.Rel(actualCwd, local)
+		if err != nil || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			name = filepath.Base(local)
+		}
+
+		_, err = j.client.copyToManager(j, local, name)
+		if err != nil {
+			merr = multierror.Append(merr, fmt.Errorf("copy to manager behaviour failed for %s: %s", file, err))
+		}
+	}
+
+	return merr.ErrorOrNil()
+}
+
+// argStrings returns our Arg as a []string, and true if that was possible. (A
+// []string Arg becomes an []interface{} after being sent to the server and
+// back.)
+func (b *Behaviour) argStrings() ([]string, bool) {
+	switch arg := b.Arg.(type) {
+	case []string:
+		return arg, true
+	case []interface{}:
+		strs := make([]string, len(arg))
+		for i, a := range arg {
+			str, wasStr := a.(string)
+			if !wasStr {
+				return nil, false
+			}
+			strs[i] = str
+		}
+		return strs, true
+	}
+	return nil, false
 }
 
 // Behaviours are a slice of Behaviour.
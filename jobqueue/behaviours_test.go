@@ -78,11 +78,12 @@ func TestBehaviours(t *testing.T) {
 		})
 
 		Convey("Individual Behaviour Trigger() correctly", func() {
-			err = b7.Trigger(OnSuccess, job1)
+			err = b7.Trigger(OnFailure, job1)
 			So(err, ShouldBeNil)
+			err = b7.Trigger(OnSuccess, job1)
+			So(err, ShouldNotBeNil) // since job1 isn't being Execute()d by a client
 			err = b8.Trigger(OnSuccess, job1)
 			So(err, ShouldNotBeNil)
-			//*** CopyToManager not yet implemented, so no proper tests for it yet
 
 			err = b6.Trigger(OnSuccess, job1)
 			So(err, ShouldNotBeNil)
@@ -147,6 +148,38 @@ func TestBehaviours(t *testing.T) {
 			}
 		})
 
+		Convey("Cleanup keeps declared Outputs, but CleanupAll does not", func() {
+			subDir := filepath.Join(actualCwd, "sub")
+			os.MkdirAll(subDir, os.ModePerm)
+			os.OpenFile(filepath.Join(subDir, "c.file"), os.O_RDONLY|os.O_CREATE, 0666)
+			job3 := &Job{Cwd: cwd, ActualCwd: actualCwd, Outputs: []string{"a.file", "sub/c.file", "/abs/d.file"}}
+
+			err = b9.Trigger(OnSuccess, job3)
+			So(err, ShouldBeNil)
+			_, err = os.Stat(filepath.Join(actualCwd, "a.file"))
+			So(err, ShouldBeNil)
+			_, err = os.Stat(filepath.Join(subDir, "c.file"))
+			So(err, ShouldBeNil)
+			_, err = os.Stat(filepath.Join(actualCwd, "b.file"))
+			So(err, ShouldNotBeNil)
+
+			err = b1.Trigger(OnExit, job3)
+			So(err, ShouldBeNil)
+			_, err = os.Stat(actualCwd)
+			So(err, ShouldNotBeNil)
+			_, err = os.Stat(adir)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Cleanup with no declared Outputs is the same as CleanupAll", func() {
+			err = b9.Trigger(OnSuccess, job1)
+			So(err, ShouldBeNil)
+			_, err = os.Stat(actualCwd)
+			So(err, ShouldNotBeNil)
+			_, err = os.Stat(adir)
+			So(err, ShouldNotBeNil)
+		})
+
 		Convey("Behaviours are triggered in order b2,b4, as specified", func() {
 			bs := Behaviours{b2, b4}
 			err = bs.Trigger(true, job1)
@@ -0,0 +1,341 @@
+// Copyright © 2026 Genome Research Limited
+// Author: Sendu Bala <sb10@sanger.ac.uk>.
+//
+//  This file is part of wr.
+//
+//  wr is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Lesser General Public License as published by
+//  the Free Software Foundation, either version 3 of the License, or
+//  (at your option) any later version.
+//
+//  wr is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Lesser General Public License for more details.
+//
+//  You should have received a copy of the GNU Lesser General Public License
+//  along with wr. If not, see <http://www.gnu.org/licenses/>.
+
+package jobqueue
+
+// This file contains the implementation of running a Job's Cmd in its own
+// cgroup (v2), so that the resources used by it and all its child processes
+// (even orphaned ones) can be limited and accounted for precisely.
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/VertebrateResequencing/wr/jobqueue/scheduler"
+)
+
+// cgroupCPUPeriod is the period, in microseconds, of the CPU limits we give
+// cgroups.
+const cgroupCPUPeriod = 100000
+
+// cgroupRunnerPrefix is the prefix of the name of the cgroup a runner moves
+// itself in to.
+const cgroupRunnerPrefix = "wr_runner_"
+
+var (
+	// cgroupRoot is where the cgroup v2 hierarchy is mounted.
+	cgroupRoot = "/sys/fs/cgroup"
+
+	// procSelfCgroup is the file that tells us what cgroup we're in.
+	procSelfCgroup = "/proc/self/cgroup"
+
+	// cgroupParent is the cgroup we create Job cgroups in, found the first
+	// time we need it.
+	cgroupParent struct {
+		sync.Once
+		dir string
+		cpu bool
+	}
+)
+
+// jobCgroup is a cgroup that a Job's Cmd runs in. A nil *jobCgroup (for when
+// cgroups aren't available) does nothing, and reports no usage.
+type jobCgroup struct {
+	dir string
+	fd  *os.File
+}
+
+// newJobCgroup creates a cgroup with the given name for a Job's Cmd to run in,
+// limited to the given Requirements' Cores and, with
+// ClientPercentMemoryHeadroom to spare, RAM. If cgroups can't be used on this
+// system (not linux, no cgroup v2, no memory controller, a kernel older than
+// 5.19, or no permission), returns nil.
+//
+// To be able to create cgroups, we move ourselves in to a child of the cgroup
+// we started in, which must not contain other processes. Running the runner in
+// a cgroup that has been delegated to the user (eg. by systemd with
+// Delegate=yes) is the easiest way to achieve that.
+func newJobCgroup(name string, reqs *scheduler.Requirements) (*jobCgroup, error) {
+	cgroupParent.Do(func() {
+		cgroupParent.dir, cgroupParent.cpu = findCgroupParent()
+	})
+	if cgroupParent.dir == "" {
+		return nil, nil
+	}
+	return createJobCgroup(cgroupParent.dir, cgroupParent.cpu, name, reqs)
+}
+
+// createJobCgroup does the work of newJobCgroup() in the given parent cgroup,
+// setting a CPU limit if cpu is true.
+func createJobCgroup(parent string, cpu bool, name string, reqs *scheduler.Requirements) (*jobCgroup, error) {
+	dir := filepath.Join(parent, name)
+	err := os.Mkdir(dir, 0755)
+	if err != nil {
+		return nil, err
+	}
+	cg := &jobCgroup{dir: dir}
+
+	// memory.peak tells us the kernel is new enough for everything we do,
+	// including starting processes directly in a cgroup and cgroup.kill
+	if _, err = os.Stat(cg.file("memory.peak")); err != nil {
+		return nil, cg.remove()
+	}
+
+	if limit := cgroupMemoryLimit(reqs.RAM); limit > 0 {
+		err = cg.write("memory.max", strconv.FormatInt(limit, 10))
+		if err == nil {
+			// (swap accounting may not be turned on)
+			if errs := cg.write("memory.swap.max", "0"); errs != nil && !os.IsNotExist(errs) {
+				err = errs
+			}
+		}
+	}
+	if err == nil && cpu && reqs.Cores > 0 {
+		quota := int(reqs.Cores * cgroupCPUPeriod)
+		if quota < 1000 {
+			quota = 1000
+		}
+		err = cg.write("cpu.max", fmt.Sprintf("%d %d", quota, cgroupCPUPeriod))
+	}
+	if err == nil {
+		cg.fd, err = os.Open(dir)
+	}
+	if err != nil {
+		if errr := cg.remove(); errr != nil {
+			err = fmt.Errorf("%s (and removing the cgroup failed: %s)", err, errr)
+		}
+		return nil, err
+	}
+	return cg, nil
+}
+
+// cgroupMemoryLimit returns the memory limit in bytes that we give the cgroup
+// of a Job that requires the given RAM in MB. Since RAM requirements are often
+// estimates (learned from previous runs that may not have hit their peak in
+// between our checks), we give ClientPercentMemoryHeadroom percent extra
+// before the kernel kills anything; the Job will still be treated as having
+// run out of memory if it fails after using more than its requirement. Returns
+// 0, meaning no limit, if ClientPercentMemoryHeadroom is negative.
+func cgroupMemoryLimit(ram int) int64 {
+	if ram <= 0 || ClientPercentMemoryHeadroom < 0 {
+		return 0
+	}
+	return int64(ram) * int64(100+ClientPercentMemoryHeadroom) / 100 * 1024 * 1024
+}
+
+// findCgroupParent returns the cgroup we can create Job cgroups in, and if the
+// cpu controller is available in it, moving ourselves in to a child cgroup if
+// necessary. Returns an empty string if that isn't possible.
+func findCgroupParent() (string, bool) {
+	if runtime.GOOS != "linux" {
+		return "", false
+	}
+	if _, err := os.Stat(filepath.Join(cgroupRoot, "cgroup.controllers")); err != nil {
+		return "", false
+	}
+
+	b, err := ioutil.ReadFile(procSelfCgroup)
+	if err != nil {
+		return "", false
+	}
+	var own string
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		if strings.HasPrefix(scanner.Text(), "0::") {
+			own = filepath.Join(cgroupRoot, strings.TrimPrefix(scanner.Text(), "0::"))
+			break
+		}
+	}
+	if own == "" {
+		return "", false
+	}
+
+	b, err = ioutil.ReadFile(filepath.Join(own, "cgroup.controllers"))
+	if err != nil {
+		return "", false
+	}
+	controllers := make(map[string]bool)
+	for _, controller := range strings.Fields(string(b)) {
+		controllers[controller] = true
+	}
+	if !controllers["memory"] {
+		return "", false
+	}
+
+	// cgroups (other than the root) can't both contain processes and have
+	// controllers enabled for their children, so we move ourselves in to a
+	// child, tidying up after any previous runners that did the same
+	stale, err := filepath.Glob(filepath.Join(own, cgroupRunnerPrefix+"*"))
+	if err != nil {
+		return "", false
+	}
+	for _, dir := range stale {
+		// (this fails harmlessly for cgroups still in use)
+		_ = os.Remove(dir)
+	}
+	pid := strconv.Itoa(os.Getpid())
+	leaf := filepath.Join(own, cgroupRunnerPrefix+pid)
+	if err = os.Mkdir(leaf, 0755); err != nil {
+		return "", false
+	}
+	if err = writeCgroupFile(filepath.Join(leaf, "cgroup.procs"), pid); err != nil {
+		_ = os.Remove(leaf)
+		return "", false
+	}
+
+	control := filepath.Join(own, "cgroup.subtree_control")
+	if err = writeCgroupFile(control, "+memory"); err != nil {
+		// probably other processes are in our original cgroup; move back
+		if errw := writeCgroupFile(filepath.Join(own, "cgroup.procs"), pid); errw == nil {
+			_ = os.Remove(leaf)
+		}
+		return "", false
+	}
+	cpu := controllers["cpu"] && writeCgroupFile(control, "+cpu") == nil
+	return own, cpu
+}
+
+// writeCgroupFile writes the given value to the given cgroup interface file,
+// which must already exist.
+func writeCgroupFile(path, value string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	_, err = f.WriteString(value)
+	if errc := f.Close(); err == nil {
+		err = errc
+	}
+	return err
+}
+
+// file returns the path to one of our cgroup's interface files.
+func (cg *jobCgroup) file(name string) string {
+	return filepath.Join(cg.dir, name)
+}
+
+// write writes the given value to one of our cgroup's interface files.
+func (cg *jobCgroup) write(name, value string) error {
+	return writeCgroupFile(cg.file(name), value)
+}
+
+// keyedValue returns the value of the given key in one of our cgroup's flat
+// keyed interface files (like cpu.stat).
+func (cg *jobCgroup) keyedValue(name, key string) (int64, error) {
+	b, err := ioutil.ReadFile(cg.file(name))
+	if err != nil {
+		return 0, err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == key {
+			return strconv.ParseInt(fields[1], 10, 64)
+		}
+	}
+	return 0, fmt.Errorf("%s not found in %s", key, cg.file(name))
+}
+
+// apply makes the given cmd start in our cgroup.
+func (cg *jobCgroup) apply(cmd *exec.Cmd) {
+	if cg == nil {
+		return
+	}
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	useCgroupFD(cmd.SysProcAttr, int(cg.fd.Fd()))
+}
+
+// anonMemory returns the current anonymous memory usage in MB of all the
+// processes in our cgroup. Unlike memory.current and memory.peak, this excludes
+// page cache charged to them (which the kernel can reclaim, and would make
+// Cmds that read or write large files look like they need lots of RAM).
+func (cg *jobCgroup) anonMemory() (int, error) {
+	if cg == nil {
+		return 0, nil
+	}
+	used, err := cg.keyedValue("memory.stat", "anon")
+	return int(used / 1024 / 1024), err
+}
+
+// cpuTime returns the total CPU time used by all the processes that have been
+// in our cgroup.
+func (cg *jobCgroup) cpuTime() (time.Duration, error) {
+	if cg == nil {
+		return 0, nil
+	}
+	usec, err := cg.keyedValue("cpu.stat", "usage_usec")
+	return time.Duration(usec) * time.Microsecond, err
+}
+
+// oomKilled tells you if the kernel killed any of the processes in our cgroup
+// for using more than its memory limit.
+func (cg *jobCgroup) oomKilled() bool {
+	if cg == nil {
+		return false
+	}
+	kills, err := cg.keyedValue("memory.events", "oom_kill")
+	return err == nil && kills > 0
+}
+
+// kill kills all the processes in our cgroup.
+func (cg *jobCgroup) kill() error {
+	if cg == nil {
+		return nil
+	}
+	return cg.write("cgroup.kill", "1")
+}
+
+// remove kills any processes left in our cgroup, then removes it.
+func (cg *jobCgroup) remove() error {
+	if cg == nil {
+		return nil
+	}
+	if cg.fd != nil {
+		if err := cg.fd.Close(); err != nil {
+			return err
+		}
+		cg.fd = nil
+	}
+	if err := cg.kill(); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	// it takes a moment for killed processes to leave the cgroup
+	var err error
+	for i := 0; i < 50; i++ {
+		err = os.Remove(cg.dir)
+		if err == nil || os.IsNotExist(err) {
+			return nil
+		}
+		<-time.After(100 * time.Millisecond)
+	}
+	return err
+}
@@ -50,24 +50,28 @@ import (
 
 // FailReason* are the reasons for cmd line failure stored on Jobs
 const (
-	FailReasonEnv      = "failed to get environment variables"
-	FailReasonCwd      = "working directory does not exist"
-	FailReasonStart    = "command failed to start"
-	FailReasonCPerm    = "command permission problem"
-	FailReasonCFound   = "command not found"
-	FailReasonCExit    = "command invalid exit code"
-	FailReasonExit     = "command exited non-zero"
-	FailReasonRAM      = "command used too much RAM"
-	FailReasonDisk     = "ran out of disk space"
-	FailReasonTime     = "command used too much time"
-	FailReasonDocker   = "could not interact with docker"
-	FailReasonAbnormal = "command failed to complete normally"
-	FailReasonLost     = "lost contact with runner"
-	FailReasonSignal   = "runner received a signal to stop"
-	FailReasonResource = "resource requirements cannot be met"
-	FailReasonMount    = "mounting of remote file system(s) failed"
-	FailReasonUpload   = "failed to upload files to remote file system"
-	FailReasonKilled   = "killed by user request"
+	FailReasonEnv       = "failed to get environment variables"
+	FailReasonCwd       = "working directory does not exist"
+	FailReasonStart     = "command failed to start"
+	FailReasonCPerm     = "command permission problem"
+	FailReasonCFound    = "command not found"
+	FailReasonCExit     = "command invalid exit code"
+	FailReasonExit      = "command exited non-zero"
+	FailReasonRAM       = "command used too much RAM"
+	FailReasonDisk      = "ran out of disk space"
+	FailReasonTime      = "command used too much time"
+	FailReasonDocker    = "could not interact with docker"
+	FailReasonContainer = "could not run the container"
+	FailReasonAbnormal  = "command failed to complete normally"
+	FailReasonLost      = "lost contact with runner"
+	FailReasonSignal    = "runner received a signal to stop"
+	FailReasonResource  = "resource requirements cannot be met"
+	FailReasonMount     = "mounting of remote file system(s) failed"
+	FailReasonUpload    = "failed to upload files to remote file system"
+	FailReasonKilled    = "killed by user request"
+	FailReasonOutput    = "command did not create its declared output(s)"
+	FailReasonDeps      = "dependencies can never be satisfied"
+	FailReasonPreempt   = "preempted by a higher priority job"
 )
 
 // lsfEmulationDir is the name of the directory we store our LSF emulation
@@ -81,15 +85,17 @@ const localhost = "localhost"
 // probably shouldn't change them (*** and they should probably be re-factored
 // as fields of a config struct...)
 var (
-	ClientTouchInterval               = 15 * time.Second
-	ClientReleaseDelay                = 30 * time.Second
-	ClientPercentMemoryKill           = 90
-	ClientRetryWait                   = 15 * time.Second
-	ClientRetryTime                   = 24 * time.Hour
-	RAMIncreaseMin            float64 = 1000
-	RAMIncreaseMultLow                = 2.0
-	RAMIncreaseMultHigh               = 1.3
-	RAMIncreaseMultBreakpoint float64 = 8192
+	ClientTouchInterval                 = 15 * time.Second
+	ClientOutputInterval                = 1 * time.Second
+	ClientReleaseDelay                  = 30 * time.Second
+	ClientPercentMemoryKill             = 90
+	ClientPercentMemoryHeadroom         = 25
+	ClientRetryWait                     = 15 * time.Second
+	ClientRetryTime                     = 24 * time.Hour
+	RAMIncreaseMin              float64 = 1000
+	RAMIncreaseMultLow                  = 2.0
+	RAMIncreaseMultHigh                 = 1.3
+	RAMIncreaseMultBreakpoint   float64 = 8192
 )
 
 // clientRequest is the struct that clients send to the server over the network
@@ -117,8 +123,13 @@ type clientRequest struct {
 	Path                    string // desired path File should be stored at, can be blank
 	Timeout                 time.Duration
 	Token                   []byte
+	Output                  *JobOutput
 	ConfirmDeadCloudServers bool
 	CloudServerID           string
+	User                    *User
+	Quota                   *Quota
+	Cron                    *Cron
+	Indices                 string
 }
 
 // Client represents the client side of the socket that the jobqueue server is
@@ -157,7 +168,10 @@ type envStr struct {
 // for.
 //
 // token is the authentication token that Serve() returned when the server was
-// started.
+// started, which makes you an admin, or one issued to a particular User by
+// AddUser(). Non-admin Users can only Kick(), Delete(), Modify(), Kill(),
+// SuspendJobs() and ResumeJobs() the jobs they Add()ed themselves, and can't
+// control the server itself.
 //
 // Timeout determines how long to wait for a response from the server, not only
 // while connecting, but for all subsequent interactions with it using the
@@ -453,9 +467,27 @@ func (c *Client) ReserveScheduled(timeout time.Duration, schedulerGroup string)
 // Cmd and returning Error.Err(FailReasonSignal); you should check for this and
 // exit your process. Finally it calls Unmount() and TriggerBehaviours().
 //
+// If the Job has Cache turned on and an identical Job previously completed,
+// the Cmd is not run; instead the Outputs of that Job are copied to this Job's
+// Outputs and the Job is Archive()d.
+//
 // If Kill() is called while executing the Cmd, the next internal Touch() call
 // will result in the Cmd being killed and the job being Bury()ied.
 //
+// If the Job has a TimeLimit, the Cmd and its child processes are sent its
+// Signal when the limit is near, and SIGKILL when it is reached, with the Job
+// being Release()d or Bury()ied with FailReasonTime.
+//
+// On linux systems with cgroup v2, where we are able to create cgroups, the Cmd
+// is run in its own cgroup, limited to the Job's Requirements.Cores and to
+// ClientPercentMemoryHeadroom percent more than its Requirements.RAM (or not
+// limited by RAM at all if that is negative). RAM usage and CPU time are then
+// also read from the cgroup, which accounts for orphaned child processes (RAM
+// usage excludes the page cache, so only reflects memory the Cmd actually
+// needs), and if the kernel kills anything for exceeding the RAM limit the Job
+// fails with FailReasonRAM (and so gets retried with more RAM). Any processes
+// left in the cgroup after the Cmd exits are killed.
+//
 // If no error is returned, the Cmd will have run OK, exited with status 0, and
 // been Archive()d from the queue while being placed in the permanent store.
 // Otherwise, it will have been Release()d or Bury()ied as appropriate.
@@ -464,8 +496,8 @@ func (c *Client) ReserveScheduled(timeout time.Duration, schedulerGroup string)
 // (something that understands the command "set -o pipefail").
 //
 // You have to have been the one to Reserve() the supplied Job, or this will
-// immediately return an error. NB: the peak RAM tracking assumes we are running
-// on a modern linux system with /proc/*/smaps.
+// immediately return an error. NB: without cgroups, the peak RAM tracking
+// assumes we are running on a modern linux system with /proc/*/smaps.
 func (c *Client) Execute(job *Job, shell string) error {
 	logger := c.Logger.New("job", job.Key())
 
@@ -477,6 +509,9 @@ func (c *Client) Execute(job *Job, shell string) error {
 		return Error{"Execute", job.Key(), ErrMustReserve}
 	}
 
+	// Behaviours like CopyToManager need to talk to the server via us
+	job.client = c
+
 	// we support arbitrary shell commands that may include semi-colons,
 	// quoted stuff and pipes, so it's best if we just pass it to bash
 	jc := job.Cmd
@@ -485,21 +520,22 @@ func (c *Client) Execute(job *Job, shell string) error {
 	}
 	cmd := exec.Command(shell, "-c", jc) // #nosec Our whole purpose is to allow users to run arbitrary commands via us...
 
-	// we'll filter STDERR/OUT of the cmd to keep only the first and last line
-	// of any contiguous block of \r terminated lines (to mostly eliminate
-	// progress bars), and  we'll store only up to 4kb of their head and tail
-	errReader, err := cmd.StderrPipe()
-	if err != nil {
-		return fmt.Errorf("failed to create a pipe for STDERR from cmd [%s]: %s", jc, err)
-	}
-	stderr := &prefixSuffixSaver{N: 4096}
-	stderrWait := stdFilter(errReader, stderr)
-	outReader, err := cmd.StdoutPipe()
+	// run the cmd in its own process group, so that SuspendJobs() can stop
+	// and continue it along with everything it spawns
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	// if desired, we'll write the complete, unfiltered STDERR/OUT of the cmd
+	// to log files
+	logs, err := newAttemptLogs(job)
 	if err != nil {
-		return fmt.Errorf("failed to create a pipe for STDOUT from cmd [%s]: %s", jc, err)
+		return fmt.Errorf("failed to create log files for cmd [%s]: %s", jc, err)
 	}
-	stdout := &prefixSuffixSaver{N: 4096}
-	stdoutWait := stdFilter(outReader, stdout)
+	defer func() {
+		errc := logs.cleanup()
+		if errc != nil {
+			logger.Warn("failed to clean up log files", "err", errc)
+		}
+	}()
 
 	// we'll run the command from the desired directory, which must exist or
 	// it will fail
@@ -665,6 +701,14 @@ func (c *Client) Execute(job *Job, shell string) error {
 		}
 		return fmt.Errorf("failed to extract environment variables for job [%s]: %s%s", job.Key(), err, extra)
 	}
+
+	// if we're allowed to reuse the results of an identical Cmd, the
+	// environment that identifies it is the one it was added with, not
+	// including the job-specific variables we add below
+	if job.Cache {
+		job.calculateCacheKey(env, logger)
+	}
+
 	if tmpDir != "" {
 		// (this works fine even if tmpDir has a space in one of the dir names)
 		env = envOverride(env, []string{"TMPDIR=" + tmpDir})
@@ -683,6 +727,19 @@ func (c *Client) Execute(job *Job, shell string) error {
 			env = envOverride(env, []string{"HOME=" + actualCwd})
 		}
 	}
+
+	// if an identical Cmd previously completed with Cache turned on, we can
+	// reuse its results instead of running it again
+	if job.CacheKey != "" {
+		reused, errc := c.reuseCachedResults(job, actualCwd)
+		if reused {
+			return errc
+		}
+		if errc != nil {
+			logger.Warn("could not reuse cached results", "err", errc)
+		}
+	}
+
 	if prependPath != "" {
 		// alter env PATH to have prependPath come first
 		override := []string{"PATH=" + prependPath}
@@ -728,13 +785,76 @@ func (c *Client) Execute(job *Job, shell string) error {
 			"LSF_BINDIR=" + prependPath,
 		})
 	}
+
+	// we'll filter STDERR/OUT of the cmd to keep only the first and last line
+	// of any contiguous block of \r terminated lines (to mostly eliminate
+	// progress bars), and  we'll store only up to 4kb of their head and tail
+	errPipe, err := cmd.StderrPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create a pipe for STDERR from cmd [%s]: %s", jc, err)
+	}
+	errReader, err := logs.tee(errPipe, LogStderr)
+	if err != nil {
+		return fmt.Errorf("failed to create a log file for STDERR from cmd [%s]: %s", jc, err)
+	}
+	liveStderr := &outputBuffer{}
+	errReader = io.TeeReader(errReader, liveStderr)
+	stderr := &prefixSuffixSaver{N: 4096}
+	stderrWait := stdFilter(errReader, stderr)
+	outPipe, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create a pipe for STDOUT from cmd [%s]: %s", jc, err)
+	}
+	outReader, err := logs.tee(outPipe, LogStdout)
+	if err != nil {
+		return fmt.Errorf("failed to create a log file for STDOUT from cmd [%s]: %s", jc, err)
+	}
+	liveStdout := &outputBuffer{}
+	outReader = io.TeeReader(outReader, liveStdout)
+	stdout := &prefixSuffixSaver{N: 4096}
+	stdoutWait := stdFilter(outReader, stdout)
+
 	cmd.Env = env
 
+	// if the cmd should run inside a container, have the container runtime run
+	// it for us
+	dockerName := job.MonitorDocker
+	var containerName string
+	if job.Container != nil {
+		binds := append(job.mountPoints(onCwd), tmpDir)
+		var errw error
+		containerName, errw = job.Container.wrap(cmd, shell, jc, cmd.Dir, binds, job.Requirements, job.Key())
+		if errw != nil {
+			buryErr := fmt.Errorf("failed to run container %s: %s", job.Container, errw)
+			extra := ""
+			errb := c.Bury(job, nil, FailReasonContainer, buryErr)
+			if errb != nil {
+				extra = fmt.Sprintf(" (and burying the job failed: %s)", errb)
+			}
+			_, erru := job.Unmount(true)
+			if erru != nil {
+				extra += fmt.Sprintf(" (and unmounting the job failed: %s)", erru)
+			}
+			return fmt.Errorf("%s%s", buryErr, extra)
+		}
+		if containerName != "" {
+			// we monitor (and kill) docker containers we run ourselves, and
+			// remove them once we've checked how they exited
+			dockerName = containerName
+			defer func() {
+				errr := removeDockerContainer(containerName)
+				if errr != nil {
+					logger.Warn("failed to remove docker container", "name", containerName, "err", errr)
+				}
+			}()
+		}
+	}
+
 	// if docker monitoring has been requested, try and get the docker client
 	// now and fail early if we can't
 	var dockerClient *internal.DockerClient
 	var monitorDocker, getFirstDockerContainer bool
-	if job.MonitorDocker != "" {
+	if dockerName != "" {
 		monitorDocker = true
 		dockerClient, err = internal.NewDockerClient()
 		if err != nil {
@@ -748,7 +868,7 @@ func (c *Client) Execute(job *Job, shell string) error {
 
 		// if we've been asked to monitor the first container that appears,
 		// remember existing containers
-		if job.MonitorDocker == "?" {
+		if dockerName == "?" {
 			getFirstDockerContainer = true
 			errc := dockerClient.RememberCurrentContainerIDs()
 			if errc != nil {
@@ -762,6 +882,21 @@ func (c *Client) Execute(job *Job, shell string) error {
 		}
 	}
 
+	// where possible, run the cmd in its own cgroup, so that we can limit and
+	// precisely account for the resources used by it and all its child
+	// processes; otherwise we fall back on polling their memory usage
+	cg, err := newJobCgroup(fmt.Sprintf("wr_%s_%d", job.Key(), time.Now().UnixNano()), job.Requirements)
+	if err != nil {
+		logger.Warn("could not create a cgroup for the cmd", "err", err)
+	}
+	cg.apply(cmd)
+	defer func() {
+		errr := cg.remove()
+		if errr != nil {
+			logger.Warn("failed to remove the cmd's cgroup", "err", errr)
+		}
+	}()
+
 	// intercept certain signals (under LSF and SGE, SIGUSR2 may mean out-of-
 	// time, but there's no reliable way of knowing out-of-memory, so we will
 	// just treat them all the same)
@@ -811,12 +946,23 @@ func (c *Client) Execute(job *Job, shell string) error {
 	// too much resources, every 15s. Also check for signals
 	peakmem := 0
 	var peakdisk int64
+	var bytesRead, bytesWritten int64
 	dockerCPU := 0
 	ticker := time.NewTicker(ClientTouchInterval) //*** this should be less than the ServerItemTTR set when the server started, not a fixed value
 	memTicker := time.NewTicker(1 * time.Second)  // we need to check on memory usage frequently
+	profile := newResourceProfiler(1 * time.Second)
+	outputTicker := time.NewTicker(ClientOutputInterval)
+
+	// if the job has a hard TimeLimit, we'll signal it when that is near and
+	// kill it when that is reached
+	timeLimitTimer := newTimeLimitTimer(job.TimeLimit)
+	defer timeLimitTimer.stop()
+
 	machineRAM := 0
 	ranoutMem := false
 	ranoutTime := false
+	timeLimitSignalled := false
+	ranoutTimeLimit := false
 	ranoutDisk := false
 	signalled := false
 	killCalled := false
@@ -869,6 +1015,14 @@ func (c *Client) Execute(job *Job, shell string) error {
 				}
 			}
 
+			// and everything in the cmd's cgroup, including orphaned children
+			errg := cg.kill()
+			if errk == nil {
+				errk = errg
+			} else if errg != nil {
+				errk = fmt.Errorf("%s, and killing the cgroup failed: %s", errk.Error(), errg.Error())
+			}
+
 			for _, child := range children {
 				// try and kill any children in case the above didn't already
 				// result in their death
@@ -883,6 +1037,36 @@ func (c *Client) Execute(job *Job, shell string) error {
 			return errk
 		}
 
+		signalCmd := func(sig syscall.Signal) error {
+			children, errc := getChildProcesses(int32(cmd.Process.Pid))
+			errs := cmd.Process.Signal(sig)
+			if errc != nil {
+				if errs == nil {
+					errs = errc
+				} else {
+					errs = fmt.Errorf("%s, and getting child processes failed: %s", errs.Error(), errc.Error())
+				}
+			}
+			for _, child := range children {
+				errc = child.SendSignal(sig)
+				if errs == nil {
+					errs = errc
+				} else if errc != nil {
+					errs = fmt.Errorf("%s, and signalling its child process failed: %s", errs.Error(), errc.Error())
+				}
+			}
+			return errs
+		}
+
+		// suspendCmd stops or continues the cmd's whole process group
+		suspendCmd := func(suspend bool) error {
+			sig := syscall.SIGCONT
+			if suspend {
+				sig = syscall.SIGSTOP
+			}
+			return syscall.Kill(-cmd.Process.Pid, sig)
+		}
+
 	CHECKING:
 		for {
 			select {
@@ -891,11 +1075,11 @@ func (c *Client) Execute(job *Job, shell string) error {
 				stateMutex.Lock()
 				signalled = true
 				stateMutex.Unlock()
-				errc := errReader.Close()
+				errc := errPipe.Close()
 				if errc != nil {
 					closeErr = errc
 				}
-				errc = outReader.Close()
+				errc = outPipe.Close()
 				if errc != nil {
 					closeErr = errc
 				}
@@ -908,19 +1092,22 @@ func (c *Client) Execute(job *Job, shell string) error {
 					// getting signalled later, we now know it may be because we
 					// used too much time
 				}
+
+				// let the server know how our resource usage is changing
+				job.ResourceProfile = profile.samples()
 				stateMutex.Unlock()
 
-				kc, errf := c.Touch(job)
+				kc, sc, errf := c.touch(job)
 				if kc {
 					killErr = killCmd()
 					stateMutex.Lock()
 					killCalled = true
 					stateMutex.Unlock()
-					errc := errReader.Close()
+					errc := errPipe.Close()
 					if errc != nil {
 						closeErr = errc
 					}
-					errc = outReader.Close()
+					errc = outPipe.Close()
 					if errc != nil {
 						closeErr = errc
 					}
@@ -932,6 +1119,58 @@ func (c *Client) Execute(job *Job, shell string) error {
 					logger.Warn("could not touch", "err", errf)
 					continue
 				}
+				if sc != job.Suspended {
+					errs := suspendCmd(sc)
+					if errs != nil {
+						logger.Warn("could not suspend or resume cmd", "suspend", sc, "err", errs)
+						continue
+					}
+					job.Suspended = sc
+					if sc {
+						timeLimitTimer.pause()
+					} else {
+						timeLimitTimer.resume()
+					}
+
+					// let the server know straight away
+					_, _, errf = c.touch(job)
+					if errf != nil {
+						logger.Warn("could not touch", "err", errf)
+					}
+				}
+			case <-timeLimitTimer.C():
+				if !timeLimitTimer.fired() {
+					// warn the cmd that it is about to reach its hard time
+					// limit, so it can checkpoint or clean up
+					errs := signalCmd(job.TimeLimit.signal())
+					if errs != nil {
+						logger.Warn("could not signal cmd nearing its time limit", "err", errs)
+					}
+					stateMutex.Lock()
+					timeLimitSignalled = true
+					stateMutex.Unlock()
+					continue
+				}
+
+				killErr = killCmd()
+				stateMutex.Lock()
+				ranoutTimeLimit = true
+				stateMutex.Unlock()
+				errc := errPipe.Close()
+				if errc != nil {
+					closeErr = errc
+				}
+				errc = outPipe.Close()
+				if errc != nil {
+					closeErr = errc
+				}
+				break CHECKING
+			case <-outputTicker.C:
+				// let anyone following our output see what's new
+				errf := c.sendOutput(job, liveStdout, liveStderr)
+				if errf != nil {
+					logger.Warn("could not send output", "err", errf)
+				}
 			case <-memTicker.C:
 				// always see if we've run out of disk space on the machine, in
 				// which case abort
@@ -945,6 +1184,12 @@ func (c *Client) Execute(job *Job, shell string) error {
 
 				// get current memory usage
 				mem, errf := currentMemory(job.Pid)
+				currentMem := mem
+
+				// the cgroup, if any, also knows about orphaned children
+				if cgMem, errm := cg.anonMemory(); errm == nil && cgMem > mem {
+					mem = cgMem
+				}
 
 				// deal with docker monitoring
 				var cpuS int
@@ -955,9 +1200,9 @@ func (c *Client) Execute(job *Job, shell string) error {
 							// look for a new container
 							dockerContainerID, errg = dockerClient.GetNewDockerContainerID()
 						} else {
-							// job.MonitorDocker might be a file path or name of
-							// a new container
-							dockerContainerID, errg = dockerClient.GetNewDockerContainerIDByName(job.MonitorDocker, cmd.Dir)
+							// dockerName might be a file path or name of a new
+							// container
+							dockerContainerID, errg = dockerClient.GetNewDockerContainerIDByName(dockerName, cmd.Dir)
 						}
 						if errg != nil {
 							if myerr == nil {
@@ -974,6 +1219,9 @@ func (c *Client) Execute(job *Job, shell string) error {
 							if dockerMem > mem {
 								mem = dockerMem
 							}
+							if dockerMem > currentMem {
+								currentMem = dockerMem
+							}
 							cpuS = thisDockerCPU
 						}
 					}
@@ -982,6 +1230,17 @@ func (c *Client) Execute(job *Job, shell string) error {
 				// get current disk usage
 				disk, errd := diskUsageCheck()
 
+				// and how much I/O has been done so far; these only go up, but
+				// we can miss processes that have exited without being waited
+				// for, so keep the highest we've seen
+				read, written, erri := currentIO(job.Pid)
+
+				// and CPU time so far, for our resource profile
+				cpu, errc := cg.cpuTime()
+				if cg == nil || errc != nil {
+					cpu, errc = currentCPU(job.Pid)
+				}
+
 				// now update peaks
 				stateMutex.Lock()
 				if errf == nil && mem > peakmem {
@@ -1014,6 +1273,24 @@ func (c *Client) Execute(job *Job, shell string) error {
 				if errd == nil && disk > peakdisk {
 					peakdisk = disk
 				}
+				if erri == nil {
+					if read > bytesRead {
+						bytesRead = read
+					}
+					if written > bytesWritten {
+						bytesWritten = written
+					}
+				}
+				if errf == nil {
+					sample := &ResourceSample{Time: time.Now(), RAM: currentMem}
+					if errd == nil {
+						sample.Disk = disk
+					}
+					if errc == nil {
+						sample.CPU = cpu + time.Duration(cpuS)*time.Second
+					}
+					profile.add(sample)
+				}
 				stateMutex.Unlock()
 			case <-stopChecking:
 				break CHECKING
@@ -1025,6 +1302,15 @@ func (c *Client) Execute(job *Job, shell string) error {
 	errsew := <-stderrWait
 	errsow := <-stdoutWait
 	err = cmd.Wait()
+	outputTicker.Stop()
+	errf := c.sendOutput(job, liveStdout, liveStderr)
+	if errf != nil {
+		logger.Warn("could not send output", "err", errf)
+	}
+	errl := logs.finish(c, job)
+	if errl != nil {
+		logger.Warn("failed to store log files", "err", errl)
+	}
 	ticker.Stop()
 	memTicker.Stop()
 	stopChecking <- true
@@ -1036,7 +1322,8 @@ func (c *Client) Execute(job *Job, shell string) error {
 	// know if we use too much memory and kill during a run), our method might
 	// miss a peak that cmd.ProcessState can tell us about, so use that if
 	// higher
-	peakRSS := cmd.ProcessState.SysUsage().(*syscall.Rusage).Maxrss
+	rusage := cmd.ProcessState.SysUsage().(*syscall.Rusage)
+	peakRSS := rusage.Maxrss
 	var peakRSSMB int
 	if runtime.GOOS == "darwin" {
 		// Maxrss values are bytes
@@ -1048,6 +1335,23 @@ func (c *Client) Execute(job *Job, shell string) error {
 	if peakRSSMB > peakmem {
 		peakmem = peakRSSMB
 	}
+	cpuTime := cmd.ProcessState.SystemTime() + cmd.ProcessState.UserTime()
+	if cgCPU, errc := cg.cpuTime(); errc == nil && cgCPU > cpuTime {
+		cpuTime = cgCPU
+	}
+
+	// likewise, cmd and the children it waited for may have done I/O since
+	// our last check (on linux the block counts are of 512 byte sectors, the
+	// same accounting as /proc/<pid>/io)
+	if runtime.GOOS == "linux" {
+		if read := int64(rusage.Inblock) * 512; read > bytesRead {
+			bytesRead = read
+		}
+		if written := int64(rusage.Oublock) * 512; written > bytesWritten {
+			bytesWritten = written
+		}
+	}
+	ctxSwitches := int64(rusage.Nvcsw) + int64(rusage.Nivcsw)
 
 	// include our own memory usage in the peakmem of the command, since the
 	// peak memory is used to schedule us in the job scheduler, which may
@@ -1095,7 +1399,24 @@ func (c *Client) Execute(job *Job, shell string) error {
 				myerr = fmt.Errorf("command [%s] exited with code %d (invalid exit code), which seems permanent, so it has been buried", job.Cmd, exitcode)
 			default:
 				dorelease = true
-				if ranoutMem {
+				if !killCalled && !signalled && !ranoutTimeLimit {
+					// docker or the kernel may have killed the cmd for
+					// exceeding its RAM limit
+					if containerName != "" {
+						oom, erro := dockerOOMKilled(containerName)
+						if erro != nil {
+							logger.Warn("failed to inspect docker container", "name", containerName, "err", erro)
+						}
+						ranoutMem = oom
+					}
+					if cg.oomKilled() {
+						ranoutMem = true
+					}
+				}
+				if ranoutTimeLimit || (timeLimitSignalled && !signalled) {
+					failreason = FailReasonTime
+					myerr = Error{"Execute", job.Key(), FailReasonTime}
+				} else if ranoutMem {
 					failreason = FailReasonRAM
 					myerr = Error{"Execute", job.Key(), FailReasonRAM}
 				} else if ranoutDisk {
@@ -1113,6 +1434,9 @@ func (c *Client) Execute(job *Job, shell string) error {
 					dobury = true
 					failreason = FailReasonKilled
 					myerr = Error{"Execute", job.Key(), FailReasonKilled}
+				} else if job.Container.usesDocker() && exitcode == 125 {
+					failreason = FailReasonContainer
+					myerr = fmt.Errorf("docker failed to run container %s for command [%s]%s", job.Container, job.Cmd, mayBeTemp)
 				} else {
 					failreason = FailReasonExit
 					myerr = fmt.Errorf("command [%s] exited with code %d%s", job.Cmd, exitcode, mayBeTemp)
@@ -1126,10 +1450,25 @@ func (c *Client) Execute(job *Job, shell string) error {
 			myerr = fmt.Errorf("command [%s] failed to complete normally (%v)%s", job.Cmd, err, mayBeTemp)
 		}
 	} else {
-		// the command worked fine
+		// the command worked fine, but might not have made its outputs
 		exitcode = cmd.ProcessState.Sys().(syscall.WaitStatus).ExitStatus()
-		doarchive = true
-		myerr = nil
+		if missing := job.MissingOutputs(); len(missing) > 0 {
+			dobury = true
+			failreason = FailReasonOutput
+			myerr = fmt.Errorf("command [%s] exited 0 but did not create its declared output(s) [%s], so it has been buried", job.Cmd, strings.Join(missing, ", "))
+		} else {
+			doarchive = true
+			myerr = nil
+		}
+	}
+
+	// the job's RetryPolicy can override our own view of which failures are
+	// worth retrying
+	if (dobury || dorelease) && failreason != FailReasonKilled {
+		if retries, ruled := job.RetryPolicy.retriesFor(failreason); ruled {
+			dobury = job.FailReasonCounts[failreason] >= retries
+			dorelease = !dobury
+		}
 	}
 
 	finalStdErr := bytes.TrimSpace(stderr.Bytes())
@@ -1185,7 +1524,7 @@ func (c *Client) Execute(job *Job, shell string) error {
 	// try and unmount now, because if we fail to upload files, we'll have to
 	// start over
 	addMountLogs := dobury || dorelease
-	logs, unmountErr := job.Unmount()
+	mountLogs, unmountErr := job.Unmount()
 	if unmountErr != nil {
 		if strings.Contains(unmountErr.Error(), "failed to upload") {
 			if !dobury {
@@ -1208,9 +1547,9 @@ func (c *Client) Execute(job *Job, shell string) error {
 	ticker2.Stop()
 	stopChecking2 <- true
 
-	if addMountLogs && logs != "" {
+	if addMountLogs && mountLogs != "" {
 		finalStdErr = append(finalStdErr, "\n\nMount logs:\n"...)
-		finalStdErr = append(finalStdErr, logs...)
+		finalStdErr = append(finalStdErr, mountLogs...)
 	}
 
 	if (dobury || dorelease) && berr != nil {
@@ -1250,11 +1589,19 @@ func (c *Client) Execute(job *Job, shell string) error {
 		Exitcode: exitcode,
 		PeakRAM:  peakmem,
 		PeakDisk: peakdisk,
-		CPUtime:  cmd.ProcessState.SystemTime() + cmd.ProcessState.UserTime() + time.Duration(dockerCPU)*time.Second,
+		CPUtime:  cpuTime + time.Duration(dockerCPU)*time.Second,
 		EndTime:  endTime,
 		Stdout:   finalStdOut,
 		Stderr:   finalStdErr,
 		Exited:   true,
+
+		BytesRead:    bytesRead,
+		BytesWritten: bytesWritten,
+		CtxSwitches:  ctxSwitches,
+
+		ResourceProfile: profile.samples(),
+
+		CacheKey: job.CacheKey,
 	}
 	for {
 		if time.Now().After(retryEnd) {
@@ -1382,6 +1729,63 @@ func (c *Client) createLSFSymlinks(prependPath string, job *Job) error {
 	return nil
 }
 
+// reuseCachedResults is used by Execute() to complete a job without running
+// its Cmd, by copying the Outputs of a previously completed job with the same
+// CacheKey. It returns false if there were no such results to reuse, in which
+// case the Cmd should be run as normal. Otherwise, unless an error is also
+// returned, the job will have been Archive()d.
+func (c *Client) reuseCachedResults(job *Job, actualCwd string) (bool, error) {
+	resp, err := c.request(&clientRequest{Method: "jcached", Job: job})
+	if err != nil {
+		return false, err
+	}
+	cr := resp.CachedResult
+	if cr == nil || cr.JobKey == job.Key() {
+		return false, nil
+	}
+
+	err = job.reuseCachedOutputs(cr)
+	if err != nil {
+		return false, err
+	}
+
+	err = c.Started(job, os.Getpid())
+	if err != nil {
+		return true, err
+	}
+
+	var myerr error
+	berr := job.TriggerBehaviours(true)
+	if berr != nil {
+		myerr = fmt.Errorf("behaviour(s) had problem(s): %s", berr)
+	}
+	_, uerr := job.Unmount()
+	if uerr != nil {
+		if myerr != nil {
+			myerr = fmt.Errorf("%s; unmounting also caused problem(s): %s", myerr.Error(), uerr.Error())
+		} else {
+			myerr = uerr
+		}
+	}
+
+	jes := &JobEndState{
+		Cwd:        actualCwd,
+		Exitcode:   0,
+		EndTime:    time.Now(),
+		Exited:     true,
+		CacheKey:   job.CacheKey,
+		CachedFrom: cr.JobKey,
+	}
+	err = c.Archive(job, jes)
+	if err != nil {
+		if myerr != nil {
+			err = fmt.Errorf("%s; %s", err.Error(), myerr.Error())
+		}
+		return true, err
+	}
+	return true, myerr
+}
+
 // Started updates a Job on the server with information that you've started
 // running the Job's Cmd. Started also figures out some host name, ip and
 // possibly id (in cloud situations) to associate with the job, so that if
@@ -1412,13 +1816,21 @@ func (c *Client) Started(job *Job, pid int) error {
 // is true, you stop doing what you're doing and bury the job, since this means
 // that Kill() has been called for this job.
 func (c *Client) Touch(job *Job) (bool, error) {
+	killCalled, _, err := c.touch(job)
+	return killCalled, err
+}
+
+// touch is like Touch(), but also returns true in the second bool if the job
+// should currently be suspended, following a SuspendJobs() call. The job's
+// Suspended property tells the server if it actually is.
+func (c *Client) touch(job *Job) (bool, bool, error) {
 	c.teMutex.Lock()
 	defer c.teMutex.Unlock()
 	resp, err := c.request(&clientRequest{Method: "jtouch", Job: job})
 	if err != nil {
-		return false, err
+		return false, false, err
 	}
-	return resp.KillCalled, err
+	return resp.KillCalled, resp.SuspendCalled, err
 }
 
 // JobEndState is used to describe the state of a job after it has (tried to)
@@ -1438,6 +1850,21 @@ type JobEndState struct {
 	Stdout   []byte
 	Stderr   []byte
 	Exited   bool
+
+	// BytesRead and BytesWritten are the bytes the Cmd caused to be read from
+	// and written to storage, and CtxSwitches is the number of voluntary
+	// and involuntary context switches it made.
+	BytesRead    int64
+	BytesWritten int64
+	CtxSwitches  int64
+
+	// ResourceProfile is how the Cmd's resource usage changed while it ran.
+	ResourceProfile []*ResourceSample
+
+	// CacheKey is the Job's CacheKey, and CachedFrom is the key of the Job
+	// whose cached results were reused instead of running the Cmd.
+	CacheKey   string
+	CachedFrom string
 }
 
 // ended updates a Job for the benefit of the client only; this has no effect on
@@ -1454,6 +1881,12 @@ func (c *Client) ended(job *Job, jes *JobEndState) error {
 	job.PeakRAM = jes.PeakRAM
 	job.PeakDisk = jes.PeakDisk
 	job.CPUtime = jes.CPUtime
+	job.BytesRead = jes.BytesRead
+	job.BytesWritten = jes.BytesWritten
+	job.CtxSwitches = jes.CtxSwitches
+	job.ResourceProfile = jes.ResourceProfile
+	job.CacheKey = jes.CacheKey
+	job.CachedFrom = jes.CachedFrom
 	job.EndTime = jes.EndTime
 	if jes.Cwd != "" {
 		job.ActualCwd = jes.Cwd
@@ -1496,10 +1929,12 @@ func (c *Client) Archive(job *Job, jes *JobEndState) error {
 // Release places a job back on the jobqueue, for use when you can't handle the
 // job right now (eg. there was a suspected transient error) but maybe someone
 // else can later. Note that you must reserve a job before you can release it.
-// You can only Release() the same job as many times as its Retries value if it
-// has been run and failed; a subsequent call to Release() will instead result
-// in a Bury(). (If the job's Cmd was not run, you can Release() an unlimited
-// number of times.)
+// You can only Release() the same job as many times as its Retries value (or
+// the retries its RetryPolicy gives for the failreason) if it has been run and
+// failed; a subsequent call to Release() will instead result in a Bury(). (If
+// the job's Cmd was not run, you can Release() an unlimited number of times.)
+// The delay before the job can be reserved again is also determined by its
+// RetryPolicy.
 func (c *Client) Release(job *Job, jes *JobEndState, failreason string) error {
 	err := c.ended(job, jes)
 	if err != nil {
@@ -1514,10 +1949,7 @@ func (c *Client) Release(job *Job, jes *JobEndState, failreason string) error {
 	}
 
 	// update our process with what the server would have done
-	if job.Exited && job.Exitcode != 0 {
-		job.UntilBuried--
-	}
-	if job.UntilBuried <= 0 {
+	if job.noteFailure(failreason, job.Exited && job.Exitcode != 0) {
 		job.State = JobStateBuried
 	} else {
 		job.State = JobStateDelayed
@@ -1595,6 +2027,74 @@ func (c *Client) Kill(jes []*JobEssence) (int, error) {
 	return resp.Existed, err
 }
 
+// SuspendJobs will cause the next Touch() during an Execute() of the job(s)
+// described by the input to stop (SIGSTOP) the process group of the job's Cmd.
+// Suspended jobs are in the "suspended" state; they remain reserved by their
+// runner and will not time out, but release their resources back to the local
+// scheduler while they are stopped. Like Kill(), there could be a delay before
+// jobs actually get suspended.
+//
+// SuspendJobs returns a count of jobs that were eligible to be suspended (those
+// in running state). Errors will only be related to not being able to contact
+// the server.
+func (c *Client) SuspendJobs(jes []*JobEssence) (int, error) {
+	keys := c.jesToKeys(jes)
+	resp, err := c.request(&clientRequest{Method: "jsuspend", Keys: keys})
+	if err != nil {
+		return 0, err
+	}
+	return resp.Existed, err
+}
+
+// ResumeJobs is the opposite of SuspendJobs(), causing the process group of the
+// Cmd of the job(s) described by the input to be continued (SIGCONT), such
+// that they return to the "running" state.
+//
+// ResumeJobs returns a count of jobs that were eligible to be resumed (those in
+// running or suspended state). Errors will only be related to not being able
+// to contact the server.
+func (c *Client) ResumeJobs(jes []*JobEssence) (int, error) {
+	keys := c.jesToKeys(jes)
+	resp, err := c.request(&clientRequest{Method: "jresume", Keys: keys})
+	if err != nil {
+		return 0, err
+	}
+	return resp.Existed, err
+}
+
+// HoldJobs moves the job(s) described by the input that are waiting to run (in
+// the delayed, ready or dependent state) in to the "held" state. Held jobs keep
+// their place in the queue, their dependencies and limit groups, but are never
+// reserved or scheduled until released with UnholdJobs(). Unlike burying, this
+// doesn't count as a failure or use up any retries.
+//
+// HoldJobs returns a count of jobs that were eligible to be held. Errors will
+// only be related to not being able to contact the server.
+func (c *Client) HoldJobs(jes []*JobEssence) (int, error) {
+	keys := c.jesToKeys(jes)
+	resp, err := c.request(&clientRequest{Method: "jhold", Keys: keys})
+	if err != nil {
+		return 0, err
+	}
+	return resp.Existed, err
+}
+
+// UnholdJobs is the opposite of HoldJobs(), releasing held job(s) described by
+// the input so that they return to the dependent state if they still have
+// unsatisfied dependencies, or otherwise become ready to run.
+//
+// UnholdJobs returns a count of jobs that were eligible to be released (those
+// in the held state). Errors will only be related to not being able to contact
+// the server.
+func (c *Client) UnholdJobs(jes []*JobEssence) (int, error) {
+	keys := c.jesToKeys(jes)
+	resp, err := c.request(&clientRequest{Method: "junhold", Keys: keys})
+	if err != nil {
+		return 0, err
+	}
+	return resp.Existed, err
+}
+
 // GetByEssence gets a Job given a JobEssence to describe it. With the boolean
 // args set to true, this is the only way to get a Job that StdOut() and
 // StdErr() will work on, and one of 2 ways that Env() will work (the other
@@ -1622,6 +2122,19 @@ func (c *Client) GetByEssences(jes []*JobEssence) ([]*Job, error) {
 	return resp.Jobs, err
 }
 
+// Explain explains why the Jobs described by the given JobEssences are in their
+// current state, in particular giving the concrete reasons that any of them
+// that haven't started running yet are not running. Jobs that don't exist are
+// ignored.
+func (c *Client) Explain(jes []*JobEssence) ([]*JobExplanation, error) {
+	keys := c.jesToKeys(jes)
+	resp, err := c.request(&clientRequest{Method: "explain", Keys: keys})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Explanations, err
+}
+
 // jesToKeys deals with the jes arg that GetByEccences(), Kick() and Delete()
 // take.
 func (c *Client) jesToKeys(jes []*JobEssence) []string {
@@ -1654,6 +2167,18 @@ func (c *Client) GetByRepGroup(repgroup string, subStr bool, limit int, state Jo
 	return resp.Jobs, err
 }
 
+// GetByArray gets all Jobs that are members of the job array with the given
+// name (see JobArray). If indices is supplied, in the format taken by
+// ParseArrayIndices(), only the members with those indices are returned. The
+// other args are as in GetByRepGroup().
+func (c *Client) GetByArray(name string, indices string, limit int, state JobState, getStd bool, getEnv bool) ([]*Job, error) {
+	resp, err := c.request(&clientRequest{Method: "getba", Job: &Job{ArrayName: name}, Indices: indices, Limit: limit, State: state, GetStd: getStd, GetEnv: getEnv})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Jobs, err
+}
+
 // GetIncomplete gets all Jobs that are currently in the jobqueue, ie. excluding
 // those that are complete and have been Archive()d. The args are as in
 // GetByRepGroup().
@@ -1679,6 +2204,122 @@ func (c *Client) GetOrSetLimitGroup(group string) (int, error) {
 	return resp.Limit, err
 }
 
+// AddUser creates a new User with the given name, returning the token they
+// should supply to Connect(). Only admins can do this, and the name must not
+// already be in use.
+func (c *Client) AddUser(name string, admin bool) ([]byte, error) {
+	resp, err := c.request(&clientRequest{Method: "uadd", User: &User{Name: name, Admin: admin}})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Token, err
+}
+
+// RemoveUser revokes the token of the User with the given name. Their jobs are
+// unaffected. Only admins can do this. Returns false if there was no such
+// user.
+func (c *Client) RemoveUser(name string) (bool, error) {
+	resp, err := c.request(&clientRequest{Method: "udel", User: &User{Name: name}})
+	if err != nil {
+		return false, err
+	}
+	return resp.Existed == 1, err
+}
+
+// GetUsers returns all the Users known to the server, starting with the admin
+// that owns the server. Only admins can do this.
+func (c *Client) GetUsers() ([]*User, error) {
+	resp, err := c.request(&clientRequest{Method: "ulist"})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Users, err
+}
+
+// WhoAmI returns the User that the server thinks you are, based on the token
+// you supplied to Connect().
+func (c *Client) WhoAmI() (*User, error) {
+	resp, err := c.request(&clientRequest{Method: "whoami"})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Users) != 1 {
+		return nil, Error{"WhoAmI", "", ErrUnknown}
+	}
+	return resp.Users[0], err
+}
+
+// GetFairShares tells you the current share of the available resources that
+// each owner or RepGroup has been getting, when the server was started with
+// fair-share scheduling enabled. Otherwise you get an error with
+// ErrNoFairShare.
+func (c *Client) GetFairShares() ([]*FairShare, error) {
+	resp, err := c.request(&clientRequest{Method: "fairshare"})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Shares, err
+}
+
+// SetQuota limits the resources that the running Jobs of an owner or RepGroup
+// can use at once, replacing any previous Quota for them. A Quota with all of
+// Cores, RAM and Count set to 0 removes the quota. Only admins can do this.
+func (c *Client) SetQuota(q *Quota) error {
+	err := q.validate()
+	if err != nil {
+		return err
+	}
+	_, err = c.request(&clientRequest{Method: "quotaset", Quota: q})
+	return err
+}
+
+// GetQuotas returns all the Quotas that have been set, along with how much of
+// each is currently being used.
+func (c *Client) GetQuotas() ([]*Quota, error) {
+	resp, err := c.request(&clientRequest{Method: "quotas"})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Quotas, err
+}
+
+// AddCron stores a Cron on the server, which will then add a new Job based on
+// the Cron's Job on its Schedule, with the given environment variables (as for
+// Add()), replacing any previous Cron with the same Name. Unless you are an
+// admin, you can only replace your own Crons.
+func (c *Client) AddCron(cron *Cron, envVars []string) error {
+	err := cron.validate()
+	if err != nil {
+		return err
+	}
+	compressed, err := c.CompressEnv(envVars)
+	if err != nil {
+		return err
+	}
+	_, err = c.request(&clientRequest{Method: "cronadd", Cron: cron, Env: compressed})
+	return err
+}
+
+// GetCrons returns all the Crons that have been added, sorted by Name.
+func (c *Client) GetCrons() ([]*Cron, error) {
+	resp, err := c.request(&clientRequest{Method: "crons"})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Crons, err
+}
+
+// RemoveCron removes the Cron with the given name, so that it adds no more
+// Jobs. Jobs it already added are unaffected. Unless you are an admin, you can
+// only remove your own Crons. Returns false if there was no such Cron.
+func (c *Client) RemoveCron(name string) (bool, error) {
+	resp, err := c.request(&clientRequest{Method: "crondel", Cron: &Cron{Name: name}})
+	if err != nil {
+		return false, err
+	}
+	return resp.Existed > 0, err
+}
+
 // UploadFile uploads a local file to the machine where the server is running,
 // so you can add cloud jobs that need a script or config file on your local
 // machine to be copied over to created cloud instances.
@@ -1705,6 +2346,103 @@ func (c *Client) UploadFile(local, remote string) (string, error) {
 	return resp.Path, err
 }
 
+// copyToManager uploads the given local file to the machine where the server
+// is running, storing it under the given relative name in a sub-directory of
+// the server's configured CopyDir named after the job's key. The job must be
+// Reserve()d by us and still running. Returns the absolute path of the copy on
+// the server's machine.
+func (c *Client) copyToManager(job *Job, local, name string) (string, error) {
+	compressed, err := compressFile(local)
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.request(&clientRequest{Method: "jcopy", Job: job, File: compressed, Path: name})
+	if err != nil {
+		return "", err
+	}
+	return resp.Path, err
+}
+
+// uploadLog uploads the given local log file to the machine where the server
+// is running, storing it with the given name (as returned by LogFileName()) in
+// a sub-directory of the server's configured LogDir named after the job's key.
+// The job must be Reserve()d by us and still running.
+func (c *Client) uploadLog(job *Job, local, name string) error {
+	compressed, err := compressFile(local)
+	if err != nil {
+		return err
+	}
+	_, err = c.request(&clientRequest{Method: "jlog", Job: job, File: compressed, Path: name})
+	return err
+}
+
+// sendOutput sends the output collected in the given buffers since the last
+// call to the server, so that anyone following the given running job's output
+// can see it. The job must be Reserve()d by us and still running.
+func (c *Client) sendOutput(job *Job, stdout, stderr *outputBuffer) error {
+	out := &JobOutput{Stdout: stdout.take(), Stderr: stderr.take()}
+	if len(out.Stdout) == 0 && len(out.Stderr) == 0 {
+		return nil
+	}
+	_, err := c.request(&clientRequest{Method: "jout", Job: job, Output: out})
+	return err
+}
+
+// GetOutput returns the STDOUT and STDERR of the given running Job's Cmd that
+// was produced after the given positions in those streams. To follow a Job's
+// output as it is produced, start with offsets of 0 and then repeatedly call
+// this with the StdoutOffset and StderrOffset of the previously returned
+// JobOutput, until its Running is false. (Only the most recent LiveOutputMax
+// bytes of each stream are available, and not for long after the Cmd stops
+// running; use LogDir or LogToManager if you need the complete output.)
+func (c *Client) GetOutput(job *Job, stdoutOffset, stderrOffset int64) (*JobOutput, error) {
+	resp, err := c.request(&clientRequest{Method: "getout", Keys: []string{job.Key()}, Output: &JobOutput{StdoutOffset: stdoutOffset, StderrOffset: stderrOffset}})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Output, nil
+}
+
+// GetLog returns the complete STDOUT or STDERR (stream being one of the Log*
+// constants) of the given attempt at running the given Job's Cmd, as captured
+// because the Job had LogDir or LogToManager set. An attempt of 0 means the
+// most recent attempt.
+//
+// If the Job has LogToManager set, the log is retrieved from the server.
+// Otherwise it is read from the Job's LogDir, which only works if that is
+// accessible from this machine, eg. because it is on a shared disk, or you are
+// on the Job's Host.
+func (c *Client) GetLog(job *Job, attempt uint32, stream string) ([]byte, error) {
+	if job.LogDir == "" && !job.LogToManager {
+		return nil, fmt.Errorf("the job was not set to capture its output to log files")
+	}
+	if stream != LogStdout && stream != LogStderr {
+		return nil, fmt.Errorf("invalid log stream '%s'", stream)
+	}
+	if attempt == 0 {
+		attempt = job.Attempts
+	}
+	if attempt == 0 || attempt > job.Attempts {
+		return nil, fmt.Errorf("the job has only been attempted %d times", job.Attempts)
+	}
+	name := LogFileName(attempt, stream)
+
+	if job.LogToManager {
+		resp, err := c.request(&clientRequest{Method: "getlog", Keys: []string{job.Key()}, Path: name})
+		if err != nil {
+			return nil, err
+		}
+		return decompress(resp.File)
+	}
+
+	path := filepath.Join(internal.TildaToHome(job.LogDir), job.Key(), name)
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read log file %s (written on host %s): %s", path, job.Host, err)
+	}
+	return content, nil
+}
+
 // GetBadCloudServers (if the server is running with a cloud scheduler) returns
 // servers that are currently non-responsive and might be dead.
 func (c *Client) GetBadCloudServers() ([]*BadServer, error) {
@@ -0,0 +1,230 @@
+// Copyright © 2026 Genome Research Limited
+// Author: Sendu Bala <sb10@sanger.ac.uk>.
+//
+//  This file is part of wr.
+//
+//  wr is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Lesser General Public License as published by
+//  the Free Software Foundation, either version 3 of the License, or
+//  (at your option) any later version.
+//
+//  wr is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Lesser General Public License for more details.
+//
+//  You should have received a copy of the GNU Lesser General Public License
+//  along with wr. If not, see <http://www.gnu.org/licenses/>.
+
+package jobqueue
+
+// This file contains the implementation of running a Job's Cmd inside a
+// container.
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/VertebrateResequencing/wr/jobqueue/scheduler"
+)
+
+// ContainerRuntime* are the software that can be used to run Containers.
+const (
+	ContainerRuntimeDocker      = "docker"
+	ContainerRuntimeSingularity = "singularity"
+	ContainerRuntimeApptainer   = "apptainer"
+)
+
+// containerHostOnlyEnv are the environment variables that describe the host,
+// and so aren't passed through to docker containers.
+var containerHostOnlyEnv = map[string]bool{
+	"PATH":     true,
+	"HOSTNAME": true,
+}
+
+// Container describes a container image that a Job's Cmd should be run inside
+// of, instead of directly on the host.
+//
+// The Cmd is run by the same shell as normal (which must exist in the image),
+// as the same user, with the actual working directory (and with CwdMatters
+// false, the TMPDIR next to it) and the mount points of any MountConfigs bind
+// mounted at the same paths.
+//
+// With docker, the Cmd has the same environment variables as normal (apart
+// from PATH and HOSTNAME, which come from the image), the container is limited
+// to the Job's Requirements.RAM and Cores, and its peak RAM and CPU usage are
+// monitored (so MonitorDocker is ignored). Since a RAM limit makes docker kill
+// the container, a container killed for any reason other than wr killing it is
+// treated as having used too much RAM, and the Job retried with more. docker
+// must be installed on the machine where the Job will run, and the user must
+// be able to use it.
+//
+// With singularity or apptainer, which are suited to HPC nodes without a
+// docker daemon, the environment is passed through as the runtime normally
+// does, and resource limits are left to the job scheduler. The Image can be a
+// path to an image file or any URI the runtime understands, such as
+// "docker://ubuntu:22.04".
+type Container struct {
+	// Image is the container image to use.
+	Image string
+
+	// Runtime is one of the ContainerRuntime* constants. The empty string
+	// means use ContainerRuntimeDocker.
+	Runtime string
+}
+
+// NewContainer creates a Container, checking that the given values are valid.
+// The runtime is case insensitive. An empty image results in a nil Container.
+func NewContainer(image, runtime string) (*Container, error) {
+	runtime = strings.ToLower(runtime)
+	if image == "" {
+		if runtime != "" {
+			return nil, fmt.Errorf("a container runtime can't be specified without a container image")
+		}
+		return nil, nil
+	}
+
+	switch runtime {
+	case "", ContainerRuntimeDocker, ContainerRuntimeSingularity, ContainerRuntimeApptainer:
+	default:
+		return nil, fmt.Errorf("container runtime '%s' is not one of docker, singularity or apptainer", runtime)
+	}
+
+	return &Container{Image: image, Runtime: runtime}, nil
+}
+
+// runtime returns the Runtime, defaulted to ContainerRuntimeDocker.
+func (c *Container) runtime() string {
+	if c.Runtime == "" {
+		return ContainerRuntimeDocker
+	}
+	return c.Runtime
+}
+
+// usesDocker tells you if this Container will be run by docker.
+func (c *Container) usesDocker() bool {
+	return c != nil && c.runtime() == ContainerRuntimeDocker
+}
+
+// String returns a short human readable description of the container, suitable
+// for display in status output.
+func (c *Container) String() string {
+	if c == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s (%s)", c.Image, c.runtime())
+}
+
+// wrap alters the given cmd, which should have been created to run the given
+// shell command line with the given shell, so that instead it runs the
+// container runtime, which will run the command line inside our Image. dir is
+// the directory the command should run in, and binds are other directories
+// that it needs access to. cmd.Env should already have been set.
+//
+// For docker, returns the name given to the container.
+func (c *Container) wrap(cmd *exec.Cmd, shell, jc, dir string, binds []string, reqs *scheduler.Requirements, key string) (string, error) {
+	runtime := c.runtime()
+	path, err := exec.LookPath(runtime)
+	if err != nil {
+		return "", err
+	}
+
+	binds = containerBinds(dir, binds)
+
+	var name string
+	var args []string
+	if runtime == ContainerRuntimeDocker {
+		name = "wr_" + key + "_" + strconv.FormatInt(time.Now().UnixNano(), 36)
+		args, err = c.dockerArgs(name, dir, binds, cmd.Env, reqs)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		args = []string{runtime, "exec", "--pwd", dir, "--bind", strings.Join(binds, ",")}
+	}
+	args = append(args, c.Image, shell, "-c", jc)
+
+	cmd.Path = path
+	cmd.Args = args
+	return name, nil
+}
+
+// dockerArgs returns the args for running our Image with docker (up to but
+// not including the Image).
+func (c *Container) dockerArgs(name, dir string, binds []string, env []string, reqs *scheduler.Requirements) ([]string, error) {
+	args := []string{ContainerRuntimeDocker, "run", "--name", name,
+		"--user", strconv.Itoa(os.Getuid()) + ":" + strconv.Itoa(os.Getgid())}
+
+	groups, err := os.Getgroups()
+	if err != nil {
+		return nil, err
+	}
+	for _, gid := range groups {
+		args = append(args, "--group-add", strconv.Itoa(gid))
+	}
+
+	args = append(args, "--workdir", dir)
+	for _, bind := range binds {
+		args = append(args, "--volume", bind+":"+bind)
+	}
+
+	// (giving just the names of environment variables has docker take their
+	// values from its own environment, which is our cmd.Env, so that values
+	// don't appear in the process list)
+	for _, envvar := range env {
+		pair := strings.SplitN(envvar, "=", 2)
+		if len(pair) != 2 || containerHostOnlyEnv[pair[0]] {
+			continue
+		}
+		args = append(args, "--env", pair[0])
+	}
+
+	if reqs.RAM > 0 {
+		ram := strconv.Itoa(reqs.RAM) + "m"
+		args = append(args, "--memory", ram, "--memory-swap", ram)
+	}
+	if reqs.Cores > 0 {
+		args = append(args, "--cpus", strconv.FormatFloat(reqs.Cores, 'f', -1, 64))
+	}
+	return args, nil
+}
+
+// dockerOOMKilled asks docker if it killed the container with the given name
+// (as returned by wrap()) for exceeding its memory limit.
+func dockerOOMKilled(name string) (bool, error) {
+	out, err := exec.Command(ContainerRuntimeDocker, "inspect", "--format", "{{.State.OOMKilled}}", name).Output() // #nosec
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(string(out)) == "true", nil
+}
+
+// removeDockerContainer removes the stopped container with the given name (as
+// returned by wrap()). We don't have docker remove our containers itself, so
+// that we can call dockerOOMKilled() after they exit.
+func removeDockerContainer(name string) error {
+	out, err := exec.Command(ContainerRuntimeDocker, "rm", "--force", name).CombinedOutput() // #nosec
+	if err != nil && !strings.Contains(string(out), "No such container") {
+		return fmt.Errorf("%s: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// containerBinds returns dir followed by the given binds, without duplicates
+// or empty strings.
+func containerBinds(dir string, binds []string) []string {
+	seen := make(map[string]bool)
+	var unique []string
+	for _, bind := range append([]string{dir}, binds...) {
+		if bind == "" || seen[bind] {
+			continue
+		}
+		seen[bind] = true
+		unique = append(unique, bind)
+	}
+	return unique
+}
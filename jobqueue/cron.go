@@ -0,0 +1,589 @@
+// Copyright © 2026 Genome Research Limited
+// Author: Sendu Bala <sb10@sanger.ac.uk>.
+//
+//  This file is part of wr.
+//
+//  wr is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Lesser General Public License as published by
+//  the Free Software Foundation, either version 3 of the License, or
+//  (at your option) any later version.
+//
+//  wr is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Lesser General Public License for more details.
+//
+//  You should have received a copy of the GNU Lesser General Public License
+//  along with wr. If not, see <http://www.gnu.org/licenses/>.
+
+package jobqueue
+
+// This file contains the implementation of Crons: Jobs that the manager adds
+// to itself on a recurring schedule.
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/VertebrateResequencing/wr/internal"
+)
+
+// CronOverlap* constants are the things a Cron can do when it is due to add a
+// new Job while the one it added previously hasn't finished yet (it is still in
+// the queue, including if it was buried).
+const (
+	// CronOverlapSkip means no new Job is added; the next one will be added at
+	// the next scheduled time as normal.
+	CronOverlapSkip = "skip"
+
+	// CronOverlapQueue means the new Job is added as soon as the previous one
+	// finishes. Further scheduled times that pass in the mean time are
+	// skipped.
+	CronOverlapQueue = "queue"
+
+	// CronOverlapAllow means the new Job is added and can run at the same time
+	// as the previous one.
+	CronOverlapAllow = "allow"
+)
+
+// these global variables are primarily exported for testing purposes; you
+// probably shouldn't change them
+var (
+	// ServerCronCheckInterval is how often we check if any Crons are due to
+	// add a new Job.
+	ServerCronCheckInterval = 10 * time.Second
+)
+
+// validCronName is what the name of a Cron must match.
+var validCronName = regexp.MustCompile(`^[A-Za-z0-9_.@-]+$`)
+
+// cronDescriptors are the shorthand schedules that ParseCronSchedule()
+// understands.
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronMonths and cronDays let users refer to months and days of the week by
+// their 3 letter names.
+var cronMonths = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var cronDays = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+// cronField describes the allowed values of one field of a cron schedule.
+type cronField struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: cronMonths},
+	{name: "day of week", min: 0, max: 7, names: cronDays},
+}
+
+// CronSchedule is a parsed standard cron schedule, as created by
+// ParseCronSchedule().
+type CronSchedule struct {
+	minute  uint64
+	hour    uint64
+	dom     uint64
+	month   uint64
+	dow     uint64
+	domStar bool
+	dowStar bool
+}
+
+// ParseCronSchedule parses a standard 5 field cron schedule like
+// "30 2 * * Mon-Fri" (minute, hour, day of month, month and day of week), where
+// each field can be *, a number, a range like 1-5, a list like 1,3,5, and can
+// have a step like */15 or 0-30/10. Months and days of the week can be given
+// as their 3 letter names, and Sunday is day 0 or 7. As with cron, if both day
+// of month and day of week are restricted, either matching will do.
+//
+// The shorthands @yearly, @annually, @monthly, @weekly, @daily, @midnight and
+// @hourly are also understood. Times are in the manager's local time zone.
+func ParseCronSchedule(spec string) (*CronSchedule, error) {
+	spec = strings.TrimSpace(spec)
+	if expanded, exists := cronDescriptors[strings.ToLower(spec)]; exists {
+		spec = expanded
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("cron schedule '%s' should have 5 fields (minute hour day-of-month month day-of-week)", spec)
+	}
+
+	bits := make([]uint64, len(fields))
+	for i, field := range fields {
+		var err error
+		bits[i], err = cronFields[i].parse(field)
+		if err != nil {
+			return nil, fmt.Errorf("cron schedule '%s' has a bad %s field: %s", spec, cronFields[i].name, err)
+		}
+	}
+
+	// Sunday can be 0 or 7
+	dow := bits[4]
+	if dow&(1<<7) != 0 {
+		dow = (dow | 1) &^ (1 << 7)
+	}
+
+	return &CronSchedule{
+		minute:  bits[0],
+		hour:    bits[1],
+		dom:     bits[2],
+		month:   bits[3],
+		dow:     dow,
+		domStar: fields[2] == "*",
+		dowStar: fields[4] == "*",
+	}, nil
+}
+
+// parse converts one field of a cron schedule in to a bitset of the values it
+// allows.
+func (f cronField) parse(field string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangeDesc, step := part, 1
+		if i := strings.Index(part, "/"); i != -1 {
+			rangeDesc = part[:i]
+			var err error
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step < 1 {
+				return 0, fmt.Errorf("'%s' does not have a valid step", part)
+			}
+		}
+
+		var start, end int
+		switch {
+		case rangeDesc == "*":
+			start, end = f.min, f.max
+		case strings.Contains(rangeDesc, "-"):
+			ends := strings.SplitN(rangeDesc, "-", 2)
+			var err error
+			start, err = f.value(ends[0])
+			if err != nil {
+				return 0, err
+			}
+			end, err = f.value(ends[1])
+			if err != nil {
+				return 0, err
+			}
+			if end < start {
+				return 0, fmt.Errorf("'%s' is a backwards range", rangeDesc)
+			}
+		default:
+			var err error
+			start, err = f.value(rangeDesc)
+			if err != nil {
+				return 0, err
+			}
+			end = start
+			if step > 1 {
+				end = f.max
+			}
+		}
+
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// value converts a number or name in to a value allowed by this field.
+func (f cronField) value(desc string) (int, error) {
+	if v, exists := f.names[strings.ToLower(desc)]; exists {
+		return v, nil
+	}
+	v, err := strconv.Atoi(desc)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("'%s' is not a value between %d and %d", desc, f.min, f.max)
+	}
+	return v, nil
+}
+
+// Next returns the first time after the given one that the schedule fires, in
+// the local time zone. It returns the zero time if the schedule never fires
+// (eg. "0 0 30 Feb *").
+func (cs *CronSchedule) Next(t time.Time) time.Time {
+	t = t.Local()
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute()+1, 0, 0, time.Local)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if cs.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.Local)
+			continue
+		}
+		if !cs.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.Local)
+			continue
+		}
+		if cs.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, time.Local)
+			continue
+		}
+		if cs.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches tells you if the schedule fires on the day of the given time.
+func (cs *CronSchedule) dayMatches(t time.Time) bool {
+	domMatch := cs.dom&(1<<uint(t.Day())) != 0
+	dowMatch := cs.dow&(1<<uint(t.Weekday())) != 0
+	if cs.domStar || cs.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// Cron describes a Job that the manager adds to itself on a recurring
+// schedule. Each time it is added, its environment gets WR_CRON_NAME set to
+// the Cron's Name, and WR_CRON_TIME set to the time it was scheduled for, in
+// RFC3339 format.
+type Cron struct {
+	// Name uniquely identifies the Cron.
+	Name string
+
+	// Schedule is a standard cron schedule; see ParseCronSchedule().
+	Schedule string
+
+	// Overlap is one of the CronOverlap* constants, defaulting to
+	// CronOverlapSkip.
+	Overlap string
+
+	// Job is the template for the Jobs that will be added. Its RepGroup
+	// defaults to the Cron's Name. With CronOverlapAllow, so that the Jobs
+	// don't clash with each other, each Job's Cmd is suffixed with a shell
+	// comment noting the time it was scheduled for.
+	Job *Job
+
+	// The remaining properties are set by the server; it is meaningless to
+	// set these yourself.
+
+	// the user that added the Cron, who will own its Jobs.
+	Owner string
+	// the key of the environment its Jobs will use.
+	EnvKey string
+	// when it is next due to add a Job.
+	Next time.Time
+	// when it last added a Job.
+	Last time.Time
+	// the key of the last Job it added.
+	LastKey string
+	// true if, with CronOverlapQueue, a Job is waiting to be added once the
+	// last one finishes.
+	Pending bool
+	// the scheduled time of the pending Job.
+	PendingAt time.Time
+
+	schedule *CronSchedule
+}
+
+// validate checks that the Cron has a sensible name, schedule, overlap policy
+// and Job, and sets its schedule.
+func (c *Cron) validate() error {
+	if !validCronName.MatchString(c.Name) {
+		return fmt.Errorf("cron names can only contain letters, numbers, dots, dashes, underscores and @")
+	}
+	schedule, err := ParseCronSchedule(c.Schedule)
+	if err != nil {
+		return err
+	}
+	switch c.Overlap {
+	case "":
+		c.Overlap = CronOverlapSkip
+	case CronOverlapSkip, CronOverlapQueue, CronOverlapAllow:
+	default:
+		return fmt.Errorf("cron overlap must be one of '%s', '%s' or '%s', not '%s'", CronOverlapSkip, CronOverlapQueue, CronOverlapAllow, c.Overlap)
+	}
+	if c.Job == nil || c.Job.Cmd == "" {
+		return fmt.Errorf("cron %s needs a job with a command", c.Name)
+	}
+	if c.Job.Array != nil {
+		return fmt.Errorf("cron %s can't have a job array", c.Name)
+	}
+	c.schedule = schedule
+	return nil
+}
+
+// instance creates a new Job from our template Job, for the given scheduled
+// time.
+func (c *Cron) instance(at time.Time) (*Job, error) {
+	job, err := c.Job.copy()
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Overlap == CronOverlapAllow {
+		job.Cmd += " # wr cron " + at.Format(time.RFC3339)
+	}
+	err = job.EnvAddOverride([]string{"WR_CRON_NAME=" + c.Name, "WR_CRON_TIME=" + at.Format(time.RFC3339)})
+	return job, err
+}
+
+// ToStatus returns a summary of the Cron, as used by the REST API.
+func (c *Cron) ToStatus() CronStatus {
+	cs := CronStatus{
+		Name:     c.Name,
+		Schedule: c.Schedule,
+		Overlap:  c.Overlap,
+		Owner:    c.Owner,
+		Pending:  c.Pending,
+	}
+	if c.Job != nil {
+		cs.RepGroup = c.Job.RepGroup
+		cs.Cmd = c.Job.Cmd
+		cs.Cwd = c.Job.Cwd
+	}
+	if !c.Next.IsZero() {
+		cs.Next = c.Next.Format(time.RFC3339)
+	}
+	if !c.Last.IsZero() {
+		cs.Last = c.Last.Format(time.RFC3339)
+	}
+	return cs
+}
+
+// crons holds all the Crons that have been added, and stores them in the
+// database.
+type crons struct {
+	sync.Mutex
+	defs map[string]*Cron
+	db   *db
+}
+
+// newCrons creates a crons, loading previously added Crons from the database.
+// Crons that were due to add a Job while we weren't running will do so the
+// next time they are checked, once.
+func newCrons(db *db) (*crons, error) {
+	stored, err := db.retrieveCrons()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	defs := make(map[string]*Cron, len(stored))
+	for _, c := range stored {
+		c.schedule, err = ParseCronSchedule(c.Schedule)
+		if err != nil {
+			return nil, err
+		}
+		if c.Next.IsZero() {
+			c.Next = c.schedule.Next(now)
+		}
+		defs[c.Name] = c
+	}
+	return &crons{defs: defs, db: db}, nil
+}
+
+// get returns copies of all the Crons that have been added, sorted by name.
+func (cs *crons) get() []*Cron {
+	cs.Lock()
+	defer cs.Unlock()
+	defs := make([]*Cron, 0, len(cs.defs))
+	for _, c := range cs.defs {
+		cp := *c
+		defs = append(defs, &cp)
+	}
+	sort.Slice(defs, func(i, j int) bool {
+		return defs[i].Name < defs[j].Name
+	})
+	return defs
+}
+
+// addCron stores the given Cron, which will add a Job using the given
+// compressed environment (as per Client.CompressEnv()) on its schedule,
+// replacing any previous Cron with the same name (though its overlap policy
+// will still consider the last Job that one added). Users who aren't admins can
+// only replace their own Crons.
+func (s *Server) addCron(c *Cron, env []byte, user *User) error {
+	err := c.validate()
+	if err != nil {
+		return err
+	}
+
+	s.crons.Lock()
+	defer s.crons.Unlock()
+	existing, exists := s.crons.defs[c.Name]
+	if exists && !user.Admin && existing.Owner != user.Name {
+		return fmt.Errorf("cron %s belongs to %s", c.Name, existing.Owner)
+	}
+
+	envkey, err := s.db.storeEnv(env)
+	if err != nil {
+		return err
+	}
+
+	if c.Job.RepGroup == "" {
+		c.Job.RepGroup = c.Name
+	}
+	c.Owner = user.Name
+	c.EnvKey = envkey
+	c.Last = time.Time{}
+	c.LastKey = ""
+	if exists {
+		// so that we still don't overlap with the replaced Cron's last Job
+		c.Last = existing.Last
+		c.LastKey = existing.LastKey
+	}
+	c.Pending = false
+	c.PendingAt = time.Time{}
+	c.Next = c.schedule.Next(time.Now())
+
+	err = s.db.storeCron(c)
+	if err != nil {
+		return err
+	}
+	s.crons.defs[c.Name] = c
+	return nil
+}
+
+// removeCron removes the Cron with the given name, so that it adds no more
+// Jobs. Jobs it already added are unaffected. Users who aren't admins can only
+// remove their own Crons. Returns false if there was no such Cron.
+func (s *Server) removeCron(name string, user *User) (bool, error) {
+	s.crons.Lock()
+	defer s.crons.Unlock()
+	c, exists := s.crons.defs[name]
+	if !exists {
+		return false, nil
+	}
+	if !user.Admin && c.Owner != user.Name {
+		return false, fmt.Errorf("cron %s belongs to %s", name, c.Owner)
+	}
+
+	err := s.db.removeCron(name)
+	if err != nil {
+		return false, err
+	}
+	delete(s.crons.defs, name)
+	return true, nil
+}
+
+// startCronFiring begins periodically adding the Jobs of Crons that are due.
+func (s *Server) startCronFiring() {
+	s.wg.Add(1)
+	go func() {
+		defer internal.LogPanic(s.Logger, "jobqueue cron firing", true)
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(ServerCronCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.fireCrons(time.Now())
+			case <-s.stopClientHandling:
+				return
+			}
+		}
+	}()
+}
+
+// fireCrons adds the Jobs of the Crons that are due at the given time, and of
+// those that were waiting for their previous Job to finish.
+func (s *Server) fireCrons(now time.Time) {
+	s.crons.Lock()
+	defer s.crons.Unlock()
+	for _, c := range s.crons.defs {
+		changed := false
+		if c.Pending && !s.cronJobIncomplete(c) {
+			s.addCronJob(c, c.PendingAt)
+			c.Pending = false
+			c.PendingAt = time.Time{}
+			changed = true
+		}
+
+		if !c.Next.IsZero() && !now.Before(c.Next) {
+			s.fireCron(c, c.Next)
+			c.Next = c.schedule.Next(now)
+			changed = true
+		}
+
+		if changed {
+			err := s.db.storeCron(c)
+			if err != nil {
+				s.Error("failed to store cron", "name", c.Name, "err", err)
+			}
+		}
+	}
+}
+
+// fireCron adds a Job for the given Cron that was scheduled at the given time,
+// as per its overlap policy. You must hold the crons lock before calling this.
+func (s *Server) fireCron(c *Cron, at time.Time) {
+	if c.Overlap != CronOverlapAllow && s.cronJobIncomplete(c) {
+		if s.jobIsBuried(c.LastKey) {
+			s.Warn("cron job held up by buried previous job", "name", c.Name, "at", at, "key", c.LastKey)
+		}
+		if c.Overlap == CronOverlapQueue && !c.Pending {
+			c.Pending = true
+			c.PendingAt = at
+			s.Debug("cron job queued behind previous job", "name", c.Name, "at", at)
+		} else {
+			s.Debug("cron job skipped due to previous job", "name", c.Name, "at", at)
+		}
+		return
+	}
+	s.addCronJob(c, at)
+}
+
+// cronJobIncomplete tells you if the last Job added by the given Cron is still
+// in the queue. A buried Job counts as incomplete: until it is kicked or
+// deleted, a new Job with the same key could not be added anyway.
+func (s *Server) cronJobIncomplete(c *Cron) bool {
+	if c.LastKey == "" {
+		return false
+	}
+	_, err := s.q.Get(c.LastKey)
+	return err == nil
+}
+
+// addCronJob adds a new Job for the given Cron, for the given scheduled time.
+// You must hold the crons lock before calling this.
+func (s *Server) addCronJob(c *Cron, at time.Time) {
+	job, err := c.instance(at)
+	if err != nil {
+		s.Error("failed to create cron job", "name", c.Name, "err", err)
+		return
+	}
+
+	added, dups, _, _, err := s.createJobs([]*Job{job}, c.EnvKey, false, &User{Name: c.Owner})
+	if err != nil {
+		s.Error("failed to add cron job", "name", c.Name, "err", err)
+		return
+	}
+	if added == 0 {
+		s.Warn("cron job not added since it is already in the queue", "name", c.Name, "at", at, "dups", dups)
+		return
+	}
+	s.Debug("added cron job", "name", c.Name, "at", at)
+	c.Last = at
+	c.LastKey = job.Key()
+}
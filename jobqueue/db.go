@@ -55,6 +55,7 @@ const (
 var (
 	bucketJobsLive     = []byte("jobslive")
 	bucketJobsComplete = []byte("jobscomplete")
+	bucketResultCache  = []byte("resultCache")
 	bucketRTK          = []byte("repgroupToKey")
 	bucketRGs          = []byte("repgroups")
 	bucketLGs          = []byte("limitgroups")
@@ -66,6 +67,10 @@ var (
 	bucketJobRAM       = []byte("jobRAM")
 	bucketJobDisk      = []byte("jobDisk")
 	bucketJobSecs      = []byte("jobSecs")
+	bucketUsers        = []byte("users")
+	bucketFairShare    = []byte("fairshare")
+	bucketQuotas       = []byte("quotas")
+	bucketCrons        = []byte("crons")
 	wipeDevDBOnInit    = true
 	forceBackups       = false
 )
@@ -93,6 +98,15 @@ func (s sobsd) Less(i, j int) bool {
 	return cmp == -1
 }
 
+// cachedResult is stored in bucketResultCache to describe where the results of
+// a completed job with Cache turned on can be found, for reuse by later jobs
+// with the same CacheKey.
+type cachedResult struct {
+	JobKey    string
+	ActualCwd string
+	Outputs   []string
+}
+
 // sobsdStorer is the kind of function that stores the contents of a sobsd in
 // a particular bucket
 type sobsdStorer func(bucket []byte, encodes sobsd) (err error)
@@ -247,6 +261,10 @@ func initDB(dbFile string, dbBkFile string, deployment string, logger log15.Logg
 		if errf != nil {
 			return fmt.Errorf("create bucket %s: %s", bucketJobsComplete, errf)
 		}
+		_, errf = tx.CreateBucketIfNotExists(bucketResultCache)
+		if errf != nil {
+			return fmt.Errorf("create bucket %s: %s", bucketResultCache, errf)
+		}
 		_, errf = tx.CreateBucketIfNotExists(bucketRTK)
 		if errf != nil {
 			return fmt.Errorf("create bucket %s: %s", bucketRTK, errf)
@@ -291,6 +309,22 @@ func initDB(dbFile string, dbBkFile string, deployment string, logger log15.Logg
 		if errf != nil {
 			return fmt.Errorf("create bucket %s: %s", bucketJobSecs, errf)
 		}
+		_, errf = tx.CreateBucketIfNotExists(bucketUsers)
+		if errf != nil {
+			return fmt.Errorf("create bucket %s: %s", bucketUsers, errf)
+		}
+		_, errf = tx.CreateBucketIfNotExists(bucketFairShare)
+		if errf != nil {
+			return fmt.Errorf("create bucket %s: %s", bucketFairShare, errf)
+		}
+		_, errf = tx.CreateBucketIfNotExists(bucketQuotas)
+		if errf != nil {
+			return fmt.Errorf("create bucket %s: %s", bucketQuotas, errf)
+		}
+		_, errf = tx.CreateBucketIfNotExists(bucketCrons)
+		if errf != nil {
+			return fmt.Errorf("create bucket %s: %s", bucketCrons, errf)
+		}
 		return nil
 	})
 	if err != nil {
@@ -377,11 +411,158 @@ func (db *db) retrieveLimitGroup(group string) int {
 	return int(binary.BigEndian.Uint64(v))
 }
 
+// storeUser stores a User in a dedicated bucket, keyed on the hash of the token
+// that was issued to them.
+func (db *db) storeUser(hash string, user *User) error {
+	var encoded []byte
+	enc := codec.NewEncoderBytes(&encoded, db.ch)
+	err := enc.Encode(user)
+	if err != nil {
+		return err
+	}
+	return db.store(bucketUsers, hash, encoded)
+}
+
+// deleteUser removes a User stored with storeUser().
+func (db *db) deleteUser(hash string) error {
+	return db.bolt.Batch(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketUsers).Delete([]byte(hash))
+	})
+}
+
+// retrieveUsers gets all the Users stored with storeUser(), keyed on the hashes
+// they were stored under.
+func (db *db) retrieveUsers() (map[string]*User, error) {
+	users := make(map[string]*User)
+	err := db.bolt.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketUsers)
+		return b.ForEach(func(k, v []byte) error {
+			dec := codec.NewDecoderBytes(v, db.ch)
+			user := &User{}
+			errd := dec.Decode(user)
+			if errd != nil {
+				return errd
+			}
+			users[string(k)] = user
+			return nil
+		})
+	})
+	return users, err
+}
+
+// storeFairShareUsage stores the usage of a fair-share party, for parties
+// divided by the given FairShare* constant.
+func (db *db) storeFairShareUsage(by, party string, pu *partyUsage) error {
+	var encoded []byte
+	enc := codec.NewEncoderBytes(&encoded, db.ch)
+	err := enc.Encode(pu)
+	if err != nil {
+		return err
+	}
+	return db.store(bucketFairShare, by+dbDelimiter+party, encoded)
+}
+
+// retrieveFairShareUsages gets all the usages stored with storeFairShareUsage()
+// for the given FairShare* constant, keyed on party.
+func (db *db) retrieveFairShareUsages(by string) (map[string]*partyUsage, error) {
+	usages := make(map[string]*partyUsage)
+	prefix := []byte(by + dbDelimiter)
+	err := db.bolt.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bucketFairShare).Cursor()
+		for k, v := c.Seek(prefix); bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			dec := codec.NewDecoderBytes(v, db.ch)
+			pu := &partyUsage{}
+			err := dec.Decode(pu)
+			if err != nil {
+				return err
+			}
+			usages[string(k[len(prefix):])] = pu
+		}
+		return nil
+	})
+	return usages, err
+}
+
+// storeQuota stores a Quota under the given key.
+func (db *db) storeQuota(key string, q *Quota) error {
+	var encoded []byte
+	enc := codec.NewEncoderBytes(&encoded, db.ch)
+	err := enc.Encode(q)
+	if err != nil {
+		return err
+	}
+	return db.store(bucketQuotas, key, encoded)
+}
+
+// removeQuota removes a Quota that was stored with storeQuota().
+func (db *db) removeQuota(key string) error {
+	return db.bolt.Batch(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketQuotas).Delete([]byte(key))
+	})
+}
+
+// retrieveQuotas gets all the Quotas stored with storeQuota().
+func (db *db) retrieveQuotas() ([]*Quota, error) {
+	var quotas []*Quota
+	err := db.bolt.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketQuotas)
+		return b.ForEach(func(k, v []byte) error {
+			dec := codec.NewDecoderBytes(v, db.ch)
+			q := &Quota{}
+			errd := dec.Decode(q)
+			if errd != nil {
+				return errd
+			}
+			quotas = append(quotas, q)
+			return nil
+		})
+	})
+	return quotas, err
+}
+
+// storeCron stores a Cron under its name.
+func (db *db) storeCron(c *Cron) error {
+	var encoded []byte
+	enc := codec.NewEncoderBytes(&encoded, db.ch)
+	err := enc.Encode(c)
+	if err != nil {
+		return err
+	}
+	return db.store(bucketCrons, c.Name, encoded)
+}
+
+// removeCron removes a Cron that was stored with storeCron().
+func (db *db) removeCron(name string) error {
+	return db.bolt.Batch(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketCrons).Delete([]byte(name))
+	})
+}
+
+// retrieveCrons gets all the Crons stored with storeCron().
+func (db *db) retrieveCrons() ([]*Cron, error) {
+	var crons []*Cron
+	err := db.bolt.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketCrons)
+		return b.ForEach(func(k, v []byte) error {
+			dec := codec.NewDecoderBytes(v, db.ch)
+			c := &Cron{}
+			errd := dec.Decode(c)
+			if errd != nil {
+				return errd
+			}
+			crons = append(crons, c)
+			return nil
+		})
+	})
+	return crons, err
+}
+
 // storeNewJobs stores jobs in the live bucket, where they will only be used for
-// disaster recovery. It also stores a lookup from the Job.RepGroup to the Job's
-// key, and since this is independent, and we call this prior to checking for
-// dups, we allow the same job to be looked up by multiple RepGroups. Likewise,
-// we store a lookup for the Job.DepGroups and .Dependencies.DepGroups().
+// disaster recovery. It also stores a lookup from the Job.RepGroup (and
+// ArrayName) to the Job's key, and since this is independent, and we call this
+// prior to checking for dups, we allow the same job to be looked up by multiple
+// RepGroups. Likewise, we store a lookup for the Job.DepGroups and
+// .Dependencies.DepGroups().
 //
 // If ignoreAdded is true, jobs that have already completed will be ignored
 // along with those that have been added and the returned alreadyAdded value
@@ -522,6 +703,11 @@ func (db *db) prepareNewJobs(jobs []*Job, ignoreAdded bool) (encodedJobs, rgLook
 		job.RLock()
 		rgLookups = append(rgLookups, [2][]byte{db.generateLookupKey(job.RepGroup, key), nil})
 		repGroups[job.RepGroup] = true
+		if job.ArrayName != "" && job.ArrayName != job.RepGroup {
+			// job arrays can be looked up by name as if it were a RepGroup
+			rgLookups = append(rgLookups, [2][]byte{db.generateLookupKey(job.ArrayName, key), nil})
+			repGroups[job.ArrayName] = true
+		}
 
 		for _, depGroup := range job.DepGroups {
 			if depGroup != "" {
@@ -637,11 +823,23 @@ func (db *db) archiveJob(key string, job *Job) error {
 	enc := codec.NewEncoderBytes(&encoded, db.ch)
 	job.RLock()
 	err := enc.Encode(job)
+	cacheKey := job.CacheKey
+	cached := job.CachedFrom != ""
+	cr := &cachedResult{JobKey: key, ActualCwd: job.ActualCwd, Outputs: job.OutputPaths()}
 	job.RUnlock()
 	if err != nil {
 		return err
 	}
 
+	var encodedCR []byte
+	if cacheKey != "" {
+		enc = codec.NewEncoderBytes(&encodedCR, db.ch)
+		err = enc.Encode(cr)
+		if err != nil {
+			return err
+		}
+	}
+
 	err = db.bolt.Batch(func(tx *bolt.Tx) error {
 		bo := tx.Bucket(bucketStdO)
 		be := tx.Bucket(bucketStdE)
@@ -667,6 +865,19 @@ func (db *db) archiveJob(key string, job *Job) error {
 			return errf
 		}
 
+		if cacheKey != "" {
+			b = tx.Bucket(bucketResultCache)
+			errf = b.Put([]byte(cacheKey), encodedCR)
+			if errf != nil {
+				return errf
+			}
+		}
+
+		if cached {
+			// the Cmd wasn't run, so there's no resource usage to learn from
+			return nil
+		}
+
 		b = tx.Bucket(bucketJobRAM)
 		errf = b.Put([]byte(fmt.Sprintf("%s%s%20d", job.ReqGroup, dbDelimiter, job.PeakRAM)), []byte(strconv.Itoa(job.PeakRAM)))
 		if errf != nil {
@@ -687,6 +898,59 @@ func (db *db) archiveJob(key string, job *Job) error {
 	return err
 }
 
+// retrieveCachedResult gets the details of the results of the job that most
+// recently completed with the given Job.CacheKey. Returns nil if there isn't
+// one.
+func (db *db) retrieveCachedResult(cacheKey string) (*cachedResult, error) {
+	encoded := db.retrieve(bucketResultCache, cacheKey)
+	if encoded == nil {
+		return nil, nil
+	}
+	dec := codec.NewDecoderBytes(encoded, db.ch)
+	cr := &cachedResult{}
+	err := dec.Decode(cr)
+	return cr, err
+}
+
+// archiveSkippedJobs moves the given jobs from the live bucket to the complete
+// bucket, for use when jobs were marked complete without being run. Unlike
+// archiveJob(), their (non-existent) resource usage is not recorded.
+func (db *db) archiveSkippedJobs(jobs []*Job) error {
+	var encodedJobs sobsd
+	for _, job := range jobs {
+		var encoded []byte
+		enc := codec.NewEncoderBytes(&encoded, db.ch)
+		job.RLock()
+		err := enc.Encode(job)
+		job.RUnlock()
+		if err != nil {
+			return err
+		}
+		encodedJobs = append(encodedJobs, [2][]byte{[]byte(job.Key()), encoded})
+	}
+	sort.Sort(encodedJobs)
+
+	err := db.bolt.Batch(func(tx *bolt.Tx) error {
+		bl := tx.Bucket(bucketJobsLive)
+		bc := tx.Bucket(bucketJobsComplete)
+		for _, kv := range encodedJobs {
+			errf := bl.Delete(kv[0])
+			if errf != nil {
+				return errf
+			}
+			errf = bc.Put(kv[0], kv[1])
+			if errf != nil {
+				return errf
+			}
+		}
+		return nil
+	})
+
+	db.backgroundBackup()
+
+	return err
+}
+
 // deleteLiveJob remove a job from the live bucket, for use when jobs were
 // added in error.
 func (db *db) deleteLiveJob(key string) {
@@ -910,6 +1174,43 @@ func (db *db) retrieveIncompleteJobKeysByDepGroup(depgroup string) ([]string, er
 	return jobKeys, err
 }
 
+// checkIfComplete tells you if a job with the given key is currently in the
+// complete bucket, and not also in the live bucket (ie. it completed and has not
+// been added again since).
+func (db *db) checkIfComplete(key string) (bool, error) {
+	var isComplete bool
+	err := db.bolt.View(func(tx *bolt.Tx) error {
+		newJobBucket := tx.Bucket(bucketJobsLive)
+		completeJobBucket := tx.Bucket(bucketJobsComplete)
+		if newJobBucket.Get([]byte(key)) == nil && completeJobBucket.Get([]byte(key)) != nil {
+			isComplete = true
+		}
+		return nil
+	})
+	return isComplete, err
+}
+
+// checkIfDepGroupHasComplete tells you if any job with the given DepGroup is
+// in the complete bucket and not also in the live bucket.
+func (db *db) checkIfDepGroupHasComplete(depgroup string) (bool, error) {
+	var hasComplete bool
+	err := db.bolt.View(func(tx *bolt.Tx) error {
+		newJobBucket := tx.Bucket(bucketJobsLive)
+		completeJobBucket := tx.Bucket(bucketJobsComplete)
+		lookupBucket := tx.Bucket(bucketDTK).Cursor()
+		prefix := []byte(depgroup + dbDelimiter)
+		for k, _ := lookupBucket.Seek(prefix); bytes.HasPrefix(k, prefix); k, _ = lookupBucket.Next() {
+			key := bytes.TrimPrefix(k, prefix)
+			if newJobBucket.Get(key) == nil && completeJobBucket.Get(key) != nil {
+				hasComplete = true
+				break
+			}
+		}
+		return nil
+	})
+	return hasComplete, err
+}
+
 // storeEnv stores a clientRequest.Env in db unless cached, which means it must
 // already be there. Returns a key by which the stored Env can be retrieved.
 func (db *db) storeEnv(env []byte) (string, error) {
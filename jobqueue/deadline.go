@@ -0,0 +1,193 @@
+// Copyright © 2026 Genome Research Limited
+// Author: Sendu Bala <sb10@sanger.ac.uk>.
+//
+//  This file is part of wr.
+//
+//  wr is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Lesser General Public License as published by
+//  the Free Software Foundation, either version 3 of the License, or
+//  (at your option) any later version.
+//
+//  wr is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Lesser General Public License for more details.
+//
+//  You should have received a copy of the GNU Lesser General Public License
+//  along with wr. If not, see <http://www.gnu.org/licenses/>.
+
+package jobqueue
+
+// This file contains the implementation of Job Deadlines: moving Jobs that are
+// at risk of missing their Deadline up the queue, and warning about RepGroups
+// that are predicted to miss theirs.
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/VertebrateResequencing/wr/internal"
+	"github.com/VertebrateResequencing/wr/queue"
+)
+
+// deadlineBoost is the effective priority Jobs at risk of missing their
+// Deadline are given in the queue.
+const deadlineBoost uint8 = 255
+
+// these global variables are primarily exported for testing purposes; you
+// probably shouldn't change them
+var (
+	// ServerDeadlineCheckInterval is how often we look for Jobs at risk of
+	// missing their Deadline.
+	ServerDeadlineCheckInterval = 1 * time.Minute
+
+	// DeadlineRiskFactor is how many times its expected run time the time
+	// left before a Job's Deadline must be for it not to be at risk of
+	// missing it.
+	DeadlineRiskFactor = 2.0
+)
+
+// deadlineGroup summarises the Jobs with a Deadline in a RepGroup.
+type deadlineGroup struct {
+	deadline time.Time // the earliest Deadline of the group's Jobs
+	finish   time.Time // when the last of the group's Jobs is predicted to finish
+}
+
+// startDeadlineChecking begins periodically looking for Jobs at risk of
+// missing their Deadline.
+func (s *Server) startDeadlineChecking() {
+	s.wg.Add(1)
+	go func() {
+		defer internal.LogPanic(s.Logger, "jobqueue deadline checking", true)
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(ServerDeadlineCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.deadlineCheck(time.Now())
+			case <-s.stopClientHandling:
+				return
+			}
+		}
+	}()
+}
+
+// deadlineCheck predicts when each incomplete Job with a Deadline will finish,
+// based on its expected run time. Those at risk of missing their Deadline are
+// boosted to the front of the queue (and those no longer at risk have their
+// boost removed), and a scheduler issue is raised for each RepGroup with a Job
+// predicted to finish after its Deadline.
+//
+// The predictions ignore time spent waiting for dependencies, other Jobs or
+// free resources, so are a best case; a RepGroup may miss its Deadline without
+// being warned about.
+func (s *Server) deadlineCheck(now time.Time) {
+	expected := make(map[string]time.Duration)
+	groups := make(map[string]*deadlineGroup)
+	boosted := make(map[string]bool)
+	for _, item := range s.q.AllItems() {
+		job := item.Data.(*Job)
+		job.RLock()
+		deadline := job.Deadline
+		repGroup := job.RepGroup
+		reqGroup := job.ReqGroup
+		reqTime := job.Requirements.Time
+		start := job.StartTime
+		job.RUnlock()
+		if deadline.IsZero() {
+			continue
+		}
+
+		stats := item.Stats()
+		if stats.State == queue.ItemStateBury {
+			// buried jobs won't run again without user intervention, so
+			// there's no point boosting them
+			continue
+		}
+
+		runTime, cached := expected[reqGroup]
+		if !cached {
+			runTime = s.expectedRunTime(reqGroup, reqTime)
+			expected[reqGroup] = runTime
+		}
+
+		var finish time.Time
+		switch {
+		case stats.State == queue.ItemStateRun && !start.IsZero():
+			finish = start.Add(runTime)
+		case stats.State == queue.ItemStateDelay && item.ReadyAt().After(now):
+			finish = item.ReadyAt().Add(runTime)
+		default:
+			finish = now.Add(runTime)
+		}
+
+		if stats.State != queue.ItemStateRun && float64(deadline.Sub(now)) < float64(runTime)*DeadlineRiskFactor {
+			boosted[item.Key] = true
+		}
+
+		dg, existed := groups[repGroup]
+		if !existed {
+			groups[repGroup] = &deadlineGroup{deadline: deadline, finish: finish}
+			continue
+		}
+		if deadline.Before(dg.deadline) {
+			dg.deadline = deadline
+		}
+		if finish.After(dg.finish) {
+			dg.finish = finish
+		}
+	}
+
+	s.setDeadlineBoosts(boosted)
+
+	for repGroup, dg := range groups {
+		if dg.finish.After(dg.deadline) {
+			s.addSchedulerIssue(fmt.Sprintf("RepGroup %s is predicted to miss its deadline of %s", repGroup, dg.deadline.Format(time.RFC3339)))
+		}
+	}
+}
+
+// expectedRunTime returns how long we expect Jobs in the given ReqGroup to
+// run for: the time learned from previous runs if we have any, otherwise the
+// given estimated time.
+func (s *Server) expectedRunTime(reqGroup string, estimate time.Duration) time.Duration {
+	secs, err := s.db.recommendedReqGroupTime(reqGroup)
+	if err != nil || secs <= 0 {
+		return estimate
+	}
+	return time.Duration(secs) * time.Second
+}
+
+// setDeadlineBoosts boosts the queue items with the given keys, and removes
+// the boost from any previously boosted items that are no longer at risk.
+func (s *Server) setDeadlineBoosts(keys map[string]bool) {
+	s.dlmutex.Lock()
+	defer s.dlmutex.Unlock()
+	for key := range s.deadlineBoosts {
+		if keys[key] {
+			continue
+		}
+		err := s.q.SetPriorityBoost(key, 0)
+		if qerr, ok := err.(queue.Error); err != nil && (!ok || qerr.Err != queue.ErrNotFound) {
+			s.Warn("failed to remove deadline boost", "key", key, "err", err)
+		}
+	}
+	for key := range keys {
+		err := s.q.SetPriorityBoost(key, deadlineBoost)
+		if err != nil {
+			s.Warn("failed to boost job at risk of missing its deadline", "key", key, "err", err)
+			delete(keys, key)
+		}
+	}
+	s.deadlineBoosts = keys
+}
+
+// deadlineBoosted tells you if the Job with the given key has been moved up
+// the queue because it is at risk of missing its Deadline.
+func (s *Server) deadlineBoosted(key string) bool {
+	s.dlmutex.RLock()
+	defer s.dlmutex.RUnlock()
+	return s.deadlineBoosts[key]
+}
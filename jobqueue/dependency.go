@@ -20,6 +20,47 @@ package jobqueue
 
 // This file contains the dependency related code.
 
+import (
+	"fmt"
+	"strings"
+
+	"github.com/VertebrateResequencing/wr/queue"
+)
+
+// Dep* constants are the possible types of a Dependency. DepAfterOK (the
+// default) means the dependent Job will start once the Job(s) it depends on
+// complete successfully. DepAfterNotOK means it will start once they are
+// buried (ie. they failed and will not be retried automatically); if they
+// instead complete successfully, the dependent Job will be buried with
+// FailReasonDeps. DepAfterAny means it will start once they are either
+// complete or buried.
+const (
+	DepAfterOK    = "afterok"
+	DepAfterNotOK = "afternotok"
+	DepAfterAny   = "afterany"
+)
+
+// depTypeToQueue converts our Dep* constants to queue.DependencyType values.
+var depTypeToQueue = map[string]queue.DependencyType{
+	"":            queue.DependencyAfterOK,
+	DepAfterOK:    queue.DependencyAfterOK,
+	DepAfterNotOK: queue.DependencyAfterNotOK,
+	DepAfterAny:   queue.DependencyAfterAny,
+}
+
+// SplitDependencyType takes a dependency specification that might be prefixed
+// with one of our Dep* constants and a colon, eg. "afternotok:mydepgroup", and
+// returns the type (DepAfterOK if there was no such prefix) and the remainder
+// of the specification.
+func SplitDependencyType(spec string) (string, string) {
+	for _, depType := range []string{DepAfterOK, DepAfterNotOK, DepAfterAny} {
+		if strings.HasPrefix(spec, depType+":") {
+			return depType, strings.TrimPrefix(spec, depType+":")
+		}
+	}
+	return DepAfterOK, spec
+}
+
 // Dependencies is a slice of *Dependency, for use in Job.Dependencies. It
 // describes the jobs that must be complete before the Job you associate this
 // with will start.
@@ -31,17 +72,36 @@ type Dependencies []*Dependency
 // call this and update every time a new Job is added with with one of our
 // DepGroups() in its *Job.DepGroups. It will only return keys for jobs that
 // are incomplete (they could have been Archive()d in the past if they are now
-// being re-run).
-func (d Dependencies) incompleteJobKeys(db *db) ([]string, error) {
+// being re-run), and for DepAfterNotOK and DepAfterAny dependencies, only
+// those that are not already buried according to the supplied function.
+//
+// It also returns the queue.DependencyType of each key that isn't
+// queue.DependencyAfterOK, and true if a DepAfterNotOK dependency refers to a
+// job that already completed, meaning it can never be satisfied. (In that case
+// the key of the completed job is also returned, so that the dependency will
+// be satisfied should that job be re-run and fail.)
+func (d Dependencies) incompleteJobKeys(db *db, buried func(key string) bool) ([]string, map[string]queue.DependencyType, bool, error) {
 	// we initially store in a map to avoid duplicates
 	jobKeys := make(map[string]bool)
+	var types map[string]queue.DependencyType
+	var unsatisfiable bool
 	for _, dep := range d {
-		keys, err := dep.incompleteJobKeys(db)
+		keys, never, err := dep.incompleteJobKeys(db, buried)
 		if err != nil {
-			return []string{}, err
+			return []string{}, nil, false, err
+		}
+		if never {
+			unsatisfiable = true
 		}
+		qt := dep.queueType()
 		for _, key := range keys {
 			jobKeys[key] = true
+			if qt != queue.DependencyAfterOK {
+				if types == nil {
+					types = make(map[string]queue.DependencyType)
+				}
+				types[key] = qt
+			}
 		}
 	}
 
@@ -52,7 +112,17 @@ func (d Dependencies) incompleteJobKeys(db *db) ([]string, error) {
 		i++
 	}
 
-	return keys, nil
+	return keys, types, unsatisfiable, nil
+}
+
+// validate checks that our constituent Dependency structs have valid Types.
+func (d Dependencies) validate() error {
+	for _, dep := range d {
+		if _, valid := depTypeToQueue[dep.Type]; !valid {
+			return fmt.Errorf("dependency type '%s' is not one of %s, %s or %s", dep.Type, DepAfterOK, DepAfterNotOK, DepAfterAny)
+		}
+	}
+	return nil
 }
 
 // DepGroups returns all the DepGroups of our constituent Dependency structs.
@@ -67,25 +137,40 @@ func (d Dependencies) DepGroups() []string {
 }
 
 // Stringify converts our constituent Dependency structs in to a slice of
-// strings, each of which could be JobEssence or DepGroup based.
+// strings, each of which could be JobEssence or DepGroup based. Dependencies
+// with a Type other than DepAfterOK are prefixed with that type and a colon.
 func (d Dependencies) Stringify() []string {
-	var strings []string
+	var strs []string
 	for _, dep := range d {
+		var str string
 		if dep.DepGroup != "" {
-			strings = append(strings, dep.DepGroup)
+			str = dep.DepGroup
 		} else if dep.Essence != nil {
-			strings = append(strings, dep.Essence.Stringify())
+			str = dep.Essence.Stringify()
+		} else {
+			continue
 		}
+		if dep.Type != "" && dep.Type != DepAfterOK {
+			str = dep.Type + ":" + str
+		}
+		strs = append(strs, str)
 	}
-	return strings
+	return strs
 }
 
 // Dependency is a struct that describes a Job purely in terms of a JobEssence,
 // or in terms of a Job's DepGroup, for use in Dependencies. If DepGroup is
-// specified, then Essence is ignored.
+// specified, then Essence is ignored. Type is one of the Dep* constants,
+// defaulting to DepAfterOK if blank.
 type Dependency struct {
 	Essence  *JobEssence
 	DepGroup string
+	Type     string
+}
+
+// queueType returns the queue.DependencyType corresponding to our Type.
+func (d *Dependency) queueType() queue.DependencyType {
+	return depTypeToQueue[d.Type]
 }
 
 // incompleteJobKeys calculates the job keys that this dependency refers to. For
@@ -93,23 +178,53 @@ type Dependency struct {
 // same key you'd get from *Job.key() on a Job made with the same essence.
 // For a Dependency made with a DepGroup, you will get the *Job.key()s of all
 // the jobs in the queue and database that have that DepGroup in their
-// DepGroups. You will only get keys for jobs that are currently in the queue.
-func (d *Dependency) incompleteJobKeys(db *db) ([]string, error) {
+// DepGroups. You will only get keys for jobs that are currently in the queue
+// (and not buried, unless we are DepAfterOK), except that for DepAfterNotOK,
+// completed jobs make the returned bool true (see
+// Dependencies.incompleteJobKeys()).
+func (d *Dependency) incompleteJobKeys(db *db, buried func(key string) bool) ([]string, bool, error) {
+	depType := d.queueType()
 	if d.DepGroup != "" {
 		keys, err := db.retrieveIncompleteJobKeysByDepGroup(d.DepGroup)
-		return keys, err
+		if err != nil || depType == queue.DependencyAfterOK {
+			return keys, false, err
+		}
+
+		var unburied []string
+		for _, key := range keys {
+			if !buried(key) {
+				unburied = append(unburied, key)
+			}
+		}
+
+		var never bool
+		if depType == queue.DependencyAfterNotOK {
+			never, err = db.checkIfDepGroupHasComplete(d.DepGroup)
+		}
+		return unburied, never, err
 	}
 	if d.Essence != nil {
 		jobKey := d.Essence.Key()
 		live, err := db.checkIfLive(jobKey)
 		if err != nil {
-			return []string{}, err
+			return []string{}, false, err
 		}
 		if live {
-			return []string{jobKey}, nil
+			if depType != queue.DependencyAfterOK && buried(jobKey) {
+				return []string{}, false, nil
+			}
+			return []string{jobKey}, false, nil
+		}
+
+		if depType == queue.DependencyAfterNotOK {
+			complete, err := db.checkIfComplete(jobKey)
+			if err != nil || !complete {
+				return []string{}, false, err
+			}
+			return []string{jobKey}, true, nil
 		}
 	}
-	return []string{}, nil
+	return []string{}, false, nil
 }
 
 // NewEssenceDependency makes it a little easier to make a new *Dependency based
@@ -122,9 +237,13 @@ func NewEssenceDependency(cmd string, cwd string) *Dependency {
 }
 
 // NewDepGroupDependency makes it a little easier to make a new *Dependency
-// based on a dep group, for use in NewDependencies().
+// based on a dep group, for use in NewDependencies(). The depgroup can be
+// prefixed with one of the Dep* constants and a colon (eg.
+// "afternotok:mydepgroup") to set the Type of the Dependency.
 func NewDepGroupDependency(depgroup string) *Dependency {
+	depType, depgroup := SplitDependencyType(depgroup)
 	return &Dependency{
 		DepGroup: depgroup,
+		Type:     depType,
 	}
 }
@@ -0,0 +1,238 @@
+// Copyright © 2026 Genome Research Limited
+// Author: Sendu Bala <sb10@sanger.ac.uk>.
+//
+//  This file is part of wr.
+//
+//  wr is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Lesser General Public License as published by
+//  the Free Software Foundation, either version 3 of the License, or
+//  (at your option) any later version.
+//
+//  wr is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Lesser General Public License for more details.
+//
+//  You should have received a copy of the GNU Lesser General Public License
+//  along with wr. If not, see <http://www.gnu.org/licenses/>.
+
+package jobqueue
+
+// This file contains the implementation of explaining why a Job is (or isn't)
+// running.
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/VertebrateResequencing/wr/queue"
+)
+
+const (
+	// explainIssueAge is how recently a scheduler must have reported a problem
+	// for us to think it might be stopping Jobs from running.
+	explainIssueAge = 10 * time.Minute
+
+	// explainMaxDeps is the maximum number of unresolved dependencies we
+	// describe individually.
+	explainMaxDeps = 10
+)
+
+// JobExplanation describes why a Job is in its current state. In particular,
+// for a Job that hasn't started running, Reasons describes everything we know
+// of that is currently stopping it from running.
+type JobExplanation struct {
+	Key     string
+	Cmd     string
+	State   JobState
+	Reasons []string
+}
+
+// add appends a reason, formatted as per fmt.Sprintf().
+func (e *JobExplanation) add(format string, a ...interface{}) {
+	e.Reasons = append(e.Reasons, fmt.Sprintf(format, a...))
+}
+
+// explainJobs does the server side of Client.Explain(), returning an
+// explanation for each of the jobs with the given keys that exist. The string
+// return values are as for getJobsByKeys().
+func (s *Server) explainJobs(keys []string) ([]*JobExplanation, string, string) {
+	jobs, srerr, qerr := s.getJobsByKeys(keys, false, false)
+	if srerr != "" {
+		return nil, srerr, qerr
+	}
+
+	exps := make([]*JobExplanation, len(jobs))
+	for i, job := range jobs {
+		exps[i] = s.explainJob(job)
+	}
+	return exps, "", ""
+}
+
+// explainJob explains the given job, which should be a copy of one of our jobs
+// as returned by itemToJob() (or a complete job from the db).
+func (s *Server) explainJob(job *Job) *JobExplanation {
+	exp := &JobExplanation{Key: job.Key(), Cmd: job.Cmd, State: job.State}
+
+	var item *queue.Item
+	if job.State != JobStateComplete {
+		var err error
+		item, err = s.q.Get(job.Key())
+		if err != nil {
+			// it must have just completed or been deleted
+			exp.add("it is no longer in the queue")
+			return exp
+		}
+	}
+
+	switch job.State {
+	case JobStateComplete:
+		exp.add("it has already completed successfully")
+	case JobStateReserved:
+		exp.add("it has been picked up by a runner, and should start running shortly")
+	case JobStateRunning:
+		exp.add("it is running on %s (pid %d), and started at %s", job.Host, job.Pid, job.StartTime.Format(time.RFC3339))
+	case JobStateSuspended:
+		exp.add("it was suspended while running on %s; use \"wr resume-jobs\" to continue it", job.Host)
+	case JobStateLost:
+		exp.add("contact was lost with the runner on %s; the cmd may still be running there, or may have died", job.Host)
+	case JobStateDelayed:
+		now := time.Now()
+		if notBefore := job.notBefore(now); notBefore.After(now) {
+			exp.add("it isn't allowed to start until %s, due to its begin time or window", notBefore.Format(time.RFC3339))
+		} else {
+			exp.add("it failed (%s), and will be retried after %s", job.FailReason, item.ReadyAt().Format(time.RFC3339))
+		}
+	case JobStateBuried:
+		switch job.FailReason {
+		case FailReasonResource:
+			exp.add("its resource requirements (%dMB memory, %s time, %s cores, %dGB disk) are more than the %s scheduler can ever provide", job.Requirements.RAM, job.Requirements.Time, strconv.FormatFloat(job.Requirements.Cores, 'f', -1, 64), job.Requirements.Disk, s.ServerInfo.Scheduler)
+		case FailReasonDeps:
+			exp.add("it depends on other jobs in a way that can never be satisfied")
+		default:
+			exp.add("it failed (%s) and has no more retries; fix the problem and then use \"wr retry\"", job.FailReason)
+		}
+	case JobStateHeld:
+		exp.add("it has been held; use \"wr release\" to let it run")
+	case JobStateDependent:
+		s.explainDependent(item, exp)
+	case JobStateReady:
+		s.explainReady(item, exp)
+	}
+
+	return exp
+}
+
+// explainDependent adds reasons for the given dependent item not being ready.
+func (s *Server) explainDependent(item *queue.Item, exp *JobExplanation) {
+	deps := item.UnresolvedDependencies()
+	if len(deps) == 0 {
+		exp.add("its dependencies have been resolved, so it should become ready shortly")
+		return
+	}
+
+	for i, key := range deps {
+		if i == explainMaxDeps {
+			exp.add("it is waiting for %d other jobs that it depends on", len(deps)-explainMaxDeps)
+			break
+		}
+
+		var waiting string
+		switch item.DependencyType(key) {
+		case queue.DependencyAfterNotOK:
+			waiting = "to fail"
+		case queue.DependencyAfterAny:
+			waiting = "to finish"
+		default:
+			waiting = "to complete successfully"
+		}
+
+		depItem, err := s.q.Get(key)
+		if err != nil {
+			exp.add("it is waiting for job %s %s, but that is not currently in the queue", key, waiting)
+			continue
+		}
+		depJob := depItem.Data.(*Job)
+		depJob.RLock()
+		cmd := depJob.Cmd
+		state := s.itemStateToJobState(depItem.Stats().State, depJob.Lost, depJob.Suspended)
+		depJob.RUnlock()
+		exp.add("it is waiting for [%s] (%s) %s; it is currently %s", cmd, key, waiting, state)
+	}
+}
+
+// explainReady adds reasons for the given ready item not having been reserved
+// by a runner yet.
+func (s *Server) explainReady(item *queue.Item, exp *JobExplanation) {
+	job := item.Data.(*Job)
+	job.RLock()
+	pendingReason := job.PendingReason
+	limitGroups := job.LimitGroups
+	deadline := job.Deadline
+	job.RUnlock()
+
+	if s.deadlineBoosted(item.Key) {
+		exp.add("it is at risk of missing its deadline of %s, so has been moved to the front of the queue", deadline.Format(time.RFC3339))
+	}
+
+	s.ssmutex.RLock()
+	drain, mode := s.drain, s.ServerInfo.Mode
+	s.ssmutex.RUnlock()
+	if drain {
+		if mode == ServerModePause {
+			exp.add("the manager has been paused; use \"wr manager resume\" to let jobs start again")
+		} else {
+			exp.add("the manager is being drained, so no more jobs will be started")
+		}
+	}
+
+	if pendingReason != "" {
+		exp.add("it is being held back because %s", pendingReason)
+	}
+
+	for _, group := range limitGroups {
+		current, limit := s.limiter.GetCount(group)
+		if limit >= 0 && current >= uint(limit) {
+			exp.add("its limit group %s is full (%d running, limit %d)", group, current, limit)
+		}
+	}
+
+	s.racmutex.RLock()
+	rc := s.rc
+	s.racmutex.RUnlock()
+	if rc == "" {
+		exp.add("the manager is not scheduling runners, so it will only run once you start a runner yourself")
+	} else {
+		group := job.getSchedulerGroup()
+		s.sgcmutex.Lock()
+		count := s.sgroupcounts[group]
+		req := s.sgtr[group]
+		s.sgcmutex.Unlock()
+
+		switch {
+		case count <= 0 || req == nil:
+			exp.add("no runners are currently requested for its scheduler group (%s)", group)
+		case s.scheduler.Starved(fmt.Sprintf(rc, group, s.ServerInfo.Deployment, s.ServerInfo.Addr, s.ServerInfo.Host, s.scheduler.ReserveTimeout(req), int(s.scheduler.MaxQueueTime(req).Minutes())), req):
+			exp.add("there are not enough free resources to run it right now; %d runners for its scheduler group (%s) are waiting for others to finish", count, group)
+		default:
+			exp.add("%d runners have been requested from the %s scheduler for its scheduler group (%s), but haven't picked it up yet", count, s.ServerInfo.Scheduler, group)
+		}
+	}
+
+	s.simutex.RLock()
+	for _, si := range s.schedIssues {
+		if strings.Contains(strings.ToLower(si.Msg), "quota") && time.Since(time.Unix(si.LastDate, 0)) < explainIssueAge {
+			exp.add("the scheduler recently reported a quota problem: %s", si.Msg)
+		}
+	}
+	s.simutex.RUnlock()
+
+	s.bsmutex.RLock()
+	bad := len(s.badServers)
+	s.bsmutex.RUnlock()
+	if bad > 0 {
+		exp.add("%d cloud servers have gone bad, which may reduce the capacity available to run it; see \"wr cloud servers\"", bad)
+	}
+}
@@ -0,0 +1,243 @@
+// Copyright © 2026 Genome Research Limited
+// Author: Sendu Bala <sb10@sanger.ac.uk>.
+//
+//  This file is part of wr.
+//
+//  wr is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Lesser General Public License as published by
+//  the Free Software Foundation, either version 3 of the License, or
+//  (at your option) any later version.
+//
+//  wr is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Lesser General Public License for more details.
+//
+//  You should have received a copy of the GNU Lesser General Public License
+//  along with wr. If not, see <http://www.gnu.org/licenses/>.
+
+package jobqueue
+
+// This file contains the implementation of fair-share scheduling, where the
+// order in which ready Jobs are reserved takes in to account how much each
+// owner or RepGroup has been running recently.
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FairShare* constants are the ways ServerConfig.FairShare can divide Jobs in
+// to parties.
+const (
+	FairShareOwner    = "owner"
+	FairShareRepGroup = "repgroup"
+)
+
+// FairShareDefaultHalfLife is the half-life used when ServerConfig.FairShare is
+// set but FairShareHalfLife isn't.
+const FairShareDefaultHalfLife = 24 * time.Hour
+
+// FairShare describes the share of the available resources that one party (an
+// owner or RepGroup, depending on the server's configuration) has been getting,
+// as returned by Client.GetFairShares().
+type FairShare struct {
+	// Party is the owner or RepGroup.
+	Party string
+
+	// Weight is how much the party is entitled to relative to other parties,
+	// where 1 is normal.
+	Weight float64
+
+	// Usage is the number of CPU-hours (cores multiplied by wall time) the
+	// party's Jobs have used, with usage further in the past counting for
+	// less.
+	Usage float64
+
+	// Share is the party's fraction of the Usage of all parties.
+	Share float64
+
+	// Target is the fraction of the Usage of all parties that the party is
+	// entitled to, based on its Weight.
+	Target float64
+
+	// Running is the number of the party's Jobs that are currently running.
+	Running int
+}
+
+// partyUsage is what we store for each party.
+type partyUsage struct {
+	Usage   float64
+	Updated time.Time
+}
+
+// fairShare tracks the recent usage of each party.
+type fairShare struct {
+	sync.RWMutex
+	by       string
+	halfLife time.Duration
+	weights  map[string]float64
+	usages   map[string]*partyUsage
+	db       *db
+}
+
+// newFairShare creates a fairShare that divides jobs in to parties by one of
+// the FairShare* constants, loading prior usage from the database.
+func newFairShare(by string, halfLife time.Duration, weights map[string]float64, db *db) (*fairShare, error) {
+	if by != FairShareOwner && by != FairShareRepGroup {
+		return nil, fmt.Errorf("fair-share must be one of '%s' or '%s', not '%s'", FairShareOwner, FairShareRepGroup, by)
+	}
+	if halfLife < 0 {
+		return nil, fmt.Errorf("fair-share half-life can't be negative")
+	}
+	if halfLife == 0 {
+		halfLife = FairShareDefaultHalfLife
+	}
+	for party, weight := range weights {
+		if weight <= 0 {
+			return nil, fmt.Errorf("fair-share weight of %s must be greater than 0", party)
+		}
+	}
+
+	usages, err := db.retrieveFairShareUsages(by)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fairShare{by: by, halfLife: halfLife, weights: weights, usages: usages, db: db}, nil
+}
+
+// ParseFairShareWeights parses weights supplied by users in the form
+// "party1=2,party2=0.5" for use as ServerConfig.FairShareWeights. An empty
+// string results in nil weights.
+func ParseFairShareWeights(weights string) (map[string]float64, error) {
+	if weights == "" {
+		return nil, nil
+	}
+	parsed := make(map[string]float64)
+	for _, pw := range strings.Split(weights, ",") {
+		var party string
+		var weight float64
+		parts := strings.Split(pw, "=")
+		if len(parts) == 2 {
+			party = strings.TrimSpace(parts[0])
+			var err error
+			weight, err = strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+			if err != nil {
+				party = ""
+			}
+		}
+		if party == "" || weight <= 0 {
+			return nil, fmt.Errorf("fair-share weight '%s' was not specified as party=weight, with a weight greater than 0", pw)
+		}
+		parsed[party] = weight
+	}
+	return parsed, nil
+}
+
+// party returns the party the given queue item data (a *Job) belongs to. We
+// don't lock the Job, since we're called by the queue while jobs might be
+// locked, and a Job's Owner and RepGroup never change once it is queued.
+func (fs *fairShare) party(data interface{}) string {
+	job := data.(*Job)
+	if fs.by == FairShareOwner {
+		return job.Owner
+	}
+	return job.RepGroup
+}
+
+// weight returns the weight of the given party.
+func (fs *fairShare) weight(party string) float64 {
+	if weight, set := fs.weights[party]; set {
+		return weight
+	}
+	return 1
+}
+
+// decayed returns the given party's usage as of the given time. You must hold
+// at least the read lock before calling this.
+func (fs *fairShare) decayed(party string, now time.Time) float64 {
+	pu, exists := fs.usages[party]
+	if !exists {
+		return 0
+	}
+	return pu.Usage * math.Pow(0.5, float64(now.Sub(pu.Updated))/float64(fs.halfLife))
+}
+
+// usage returns the current usage of the given party, scaled by its weight, to
+// decide which party's jobs should be reserved first.
+func (fs *fairShare) usage(party string) float64 {
+	fs.RLock()
+	defer fs.RUnlock()
+	return fs.decayed(party, time.Now()) / fs.weight(party)
+}
+
+// noteUsage adds the CPU-hours used by the given Job's most recent run to the
+// usage of its party.
+func (fs *fairShare) noteUsage(job *Job) error {
+	job.RLock()
+	var cpuHours float64
+	if !job.StartTime.IsZero() && job.EndTime.After(job.StartTime) && job.Requirements != nil {
+		cpuHours = job.Requirements.Cores * job.EndTime.Sub(job.StartTime).Hours()
+	}
+	job.RUnlock()
+	if cpuHours <= 0 {
+		return nil
+	}
+
+	party := fs.party(job)
+	fs.Lock()
+	now := time.Now()
+	pu := &partyUsage{Usage: fs.decayed(party, now) + cpuHours, Updated: now}
+	fs.usages[party] = pu
+	fs.Unlock()
+	return fs.db.storeFairShareUsage(fs.by, party, pu)
+}
+
+// shares returns the current FairShare of every party that has any usage or
+// running jobs, given the data of the running items in the queue.
+func (fs *fairShare) shares(running []interface{}) []*FairShare {
+	counts := make(map[string]int)
+	for _, data := range running {
+		counts[fs.party(data)]++
+	}
+
+	fs.RLock()
+	defer fs.RUnlock()
+	now := time.Now()
+	byParty := make(map[string]*FairShare)
+	for party := range fs.usages {
+		byParty[party] = &FairShare{Party: party, Usage: fs.decayed(party, now)}
+	}
+	for party, count := range counts {
+		if _, exists := byParty[party]; !exists {
+			byParty[party] = &FairShare{Party: party}
+		}
+		byParty[party].Running = count
+	}
+
+	var totalUsage, totalWeight float64
+	shares := make([]*FairShare, 0, len(byParty))
+	for party, share := range byParty {
+		share.Weight = fs.weight(party)
+		totalUsage += share.Usage
+		totalWeight += share.Weight
+		shares = append(shares, share)
+	}
+	for _, share := range shares {
+		if totalUsage > 0 {
+			share.Share = share.Usage / totalUsage
+		}
+		share.Target = share.Weight / totalWeight
+	}
+
+	sort.Slice(shares, func(i, j int) bool {
+		return shares[i].Party < shares[j].Party
+	})
+	return shares
+}
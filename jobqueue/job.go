@@ -21,10 +21,12 @@ package jobqueue
 // This file contains the job related code.
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -32,11 +34,13 @@ import (
 	"time"
 
 	"github.com/VertebrateResequencing/muxfys"
+	"github.com/VertebrateResequencing/wr/internal"
 	"github.com/VertebrateResequencing/wr/jobqueue/scheduler"
 	"github.com/VertebrateResequencing/wr/limiter"
 	"github.com/VertebrateResequencing/wr/queue"
 	"github.com/gofrs/uuid"
 	"github.com/hashicorp/go-multierror"
+	"github.com/inconshreveable/log15"
 	"github.com/ugorji/go/codec"
 )
 
@@ -46,9 +50,10 @@ type JobState string
 // JobState* constants represent all the possible job states. The fake "new" and
 // "deleted" states are for the benefit of the web interface (jstateCount).
 // "lost" is also a "fake" state indicating the job was running and we lost
-// contact with it; it may be dead. "unknown" is an error case that shouldn't
-// happen. "deletable" is a meta state that can be used when filtering jobs to
-// mean !(running|complete).
+// contact with it; it may be dead. "suspended" is similarly a "fake" state
+// indicating the job is running but its Cmd has been stopped by SuspendJobs().
+// "unknown" is an error case that shouldn't happen. "deletable" is a meta state
+// that can be used when filtering jobs to mean !(running|complete).
 const (
 	JobStateNew       JobState = "new"
 	JobStateDelayed   JobState = "delayed"
@@ -56,8 +61,10 @@ const (
 	JobStateReserved  JobState = "reserved"
 	JobStateRunning   JobState = "running"
 	JobStateLost      JobState = "lost"
+	JobStateSuspended JobState = "suspended"
 	JobStateBuried    JobState = "buried"
 	JobStateDependent JobState = "dependent"
+	JobStateHeld      JobState = "held"
 	JobStateComplete  JobState = "complete"
 	JobStateDeleted   JobState = "deleted"
 	JobStateDeletable JobState = "deletable"
@@ -80,6 +87,7 @@ var subqueueToJobState = map[queue.SubQueue]JobState{
 	queue.SubQueueRun:       JobStateRunning,
 	queue.SubQueueBury:      JobStateBuried,
 	queue.SubQueueDependent: JobStateDependent,
+	queue.SubQueueHold:      JobStateHeld,
 	queue.SubQueueRemoved:   JobStateComplete,
 }
 
@@ -90,9 +98,30 @@ var itemsStateToJobState = map[queue.ItemState]JobState{
 	queue.ItemStateRun:       JobStateReserved,
 	queue.ItemStateBury:      JobStateBuried,
 	queue.ItemStateDependent: JobStateDependent,
+	queue.ItemStateHold:      JobStateHeld,
 	queue.ItemStateRemoved:   JobStateComplete,
 }
 
+// JobEvent* constants are the kinds of JobEvent that can be recorded in a Job's
+// History.
+const (
+	JobEventPreempted = "preempted"
+	JobEventResumed   = "resumed"
+)
+
+// JobEvent describes something notable that happened to a Job while it was in
+// the queue.
+type JobEvent struct {
+	// Time is when the event happened.
+	Time time.Time
+
+	// Event is one of the JobEvent* constants.
+	Event string
+
+	// Detail is a human readable explanation of the event.
+	Detail string
+}
+
 // Job is a struct that represents a command that needs to be run and some
 // associated metadata. If you get a Job back from the server (via Reserve() or
 // Get*()), you should treat the properties as read-only: changing them will
@@ -126,6 +155,15 @@ type Job struct {
 	// together when reporting on their status etc.
 	RepGroup string
 
+	// Array, if set, makes this a template Job that the server expands in to
+	// a Job for each member of the array; see JobArray.
+	Array *JobArray
+
+	// ArrayName and ArrayIndex are set on the Jobs the server creates from a
+	// template Job's Array, to say which array they are a member of.
+	ArrayName  string
+	ArrayIndex int
+
 	// ReqGroup is a string that you supply to group together all commands that
 	// you expect to have similar resource requirements.
 	ReqGroup string
@@ -153,6 +191,31 @@ type Job struct {
 	// Retries is the number of times to retry running a Cmd if it fails.
 	Retries uint8
 
+	// RetryPolicy, if set, controls how long the Job is delayed before being
+	// retried after a failure, and lets you override Retries depending on why
+	// the Job failed.
+	RetryPolicy *RetryPolicy
+
+	// TimeLimit, if set, is a hard limit on how long Cmd may run for, after
+	// which it is killed. (Requirements.Time, in contrast, is only used to help
+	// schedule the Job.)
+	TimeLimit *TimeLimit
+
+	// BeginAt, if set, is the earliest time that the Job can start running.
+	// Until then it waits in the delayed state.
+	BeginAt time.Time
+
+	// Window, if set, restricts the times that the Job can start running to
+	// the recurring periods it describes. Outside of them it waits in the
+	// delayed state.
+	Window *Window
+
+	// Deadline, if set, is the time by which the Job should have completed.
+	// When it is predicted (from its expected run time) to be at risk of
+	// missing this, it is reserved ahead of other Jobs; when it is predicted
+	// to miss it, a warning is raised for its RepGroup.
+	Deadline time.Time
+
 	// LimitGroups are names of limit groups that this job belongs to. If any
 	// of these groups are defined (elsewhere) to have a limit, then if as many
 	// other jobs as the limit are currently running, this job will not start
@@ -211,10 +274,62 @@ type Job struct {
 	// monitoring of multiple docker containers run by a single Cmd.
 	MonitorDocker string
 
+	// Container, if set, has Cmd run inside the given container image, instead
+	// of directly on the host. See Container for details.
+	Container *Container
+
+	// Inputs is an optional list of the paths to the files that Cmd reads.
+	// Relative paths are taken to be relative to Cwd.
+	Inputs []string
+
+	// Outputs is an optional list of the paths to the files that Cmd creates.
+	// Relative paths are taken to be relative to the actual working directory
+	// (ActualCwd when CwdMatters is false, otherwise Cwd). If Cmd exits 0 but
+	// any of these files do not exist, the Job will be buried with
+	// FailReasonOutput. The Cleanup Behaviour will not delete these files.
+	Outputs []string
+
+	// Cache turns on the reuse of results. If a Job with the same Cmd, the
+	// same environment variables and the same Inputs (judged by their MD5
+	// checksums) previously completed with Cache turned on, then when this Job
+	// is executed its Cmd will not actually be run: instead the Outputs of that
+	// previous Job (which must still exist) will be copied to this Job's
+	// Outputs, and it will be marked as complete.
+	Cache bool
+
+	// LogDir turns on the capture of the complete STDOUT and STDERR of every
+	// attempt at running Cmd (normally only their head and tail are kept). They
+	// are written to files named like "2.stdout" and "2.stderr" (for the second
+	// attempt) in a sub-directory named after the Job's key inside this
+	// directory, on the machine that runs Cmd.
+	LogDir string
+
+	// LogToManager turns on the capture of the complete STDOUT and STDERR of
+	// every attempt at running Cmd, as for LogDir, but the files are uploaded
+	// to the server's LogDir. (If LogDir is also set, they are written there as
+	// well.)
+	LogToManager bool
+
+	// Held makes the Job start in the "held" state when it is added: it keeps
+	// its place in the queue, its Dependencies and its LimitGroups, but is
+	// never reserved or scheduled until it is released with
+	// Client.UnholdJobs(). (Jobs can also be held after being added, using
+	// Client.HoldJobs().)
+	Held bool
+
 	// The remaining properties are used to record information about what
 	// happened when Cmd was executed, or otherwise provide its current state.
 	// It is meaningless to set these yourself.
 
+	// the name of the User that added the job.
+	Owner string
+	// the priority the job is considered to have while it is ready to run;
+	// the same as Priority unless the server ages the priority of waiting
+	// jobs.
+	EffectivePriority uint8
+	// if the job is ready to run but is being held back (eg. because its
+	// owner is at their quota), this says why.
+	PendingReason string
 	// the actual working directory used, which would have been created with a
 	// unique name if CwdMatters = false
 	ActualCwd string
@@ -229,6 +344,12 @@ type Job struct {
 	Exitcode int
 	// true if the job was running but we've lost contact with it
 	Lost bool
+	// true if the job is running but its Cmd has been stopped following a
+	// SuspendJobs() call.
+	Suspended bool
+	// notable things that happened to the job while it was in the queue, such
+	// as it being preempted by a higher priority job, oldest first.
+	History []*JobEvent
 	// if the job failed to complete successfully, this will hold one of the
 	// FailReason* strings. Also set if Lost == true.
 	FailReason string
@@ -246,6 +367,28 @@ type Job struct {
 	EndTime time.Time
 	// CPU time used.
 	CPUtime time.Duration
+	// bytes caused to be read from storage by the Cmd and its children.
+	BytesRead int64
+	// bytes caused to be written to storage by the Cmd and its children.
+	BytesWritten int64
+	// voluntary and involuntary context switches made by the Cmd and its
+	// children.
+	CtxSwitches int64
+	// how the RAM, disk and CPU usage of the Cmd changed while it ran (or
+	// while it has been running, updated with each touch), oldest first.
+	ResourceProfile []*ResourceSample
+	// true if the Cmd was never run because its Outputs were already up to
+	// date with respect to its Inputs when the job was added.
+	Skipped bool
+	// if Cache was true, this describes the job's Cmd, environment and Inputs;
+	// it is calculated by the runner just before it would run the Cmd.
+	CacheKey string
+	// if the results of a previously completed job were reused (see Cache),
+	// this is the key of that job.
+	CachedFrom string
+	// paths on the manager's machine of the files that were copied there by a
+	// CopyToManager Behaviour.
+	CopiedFiles []string
 	// to read, call job.StdErr() instead; if the job ran, its (truncated)
 	// STDERR will be here.
 	StdErrC []byte
@@ -268,6 +411,9 @@ type Job struct {
 	Attempts uint32
 	// remaining number of Release()s allowed before being buried instead.
 	UntilBuried uint8
+	// number of times the Cmd failed for each FailReason since the job was
+	// added or last kicked, used to obey RetryPolicy.
+	FailReasonCounts map[string]int
 	// we note which client reserved this job, for validating if that client has
 	// permission to do other stuff to this Job; the server only ever sets this
 	// on Reserve(), so clients can't cheat by changing this on their end.
@@ -296,9 +442,26 @@ type Job struct {
 	// later; this is purely client side.
 	mountedFS []*muxfys.MuxFys
 
+	// client is the Client that is Execute()ing this job, which Behaviours
+	// such as CopyToManager use to talk to the server; this is purely client
+	// side.
+	client *Client
+
 	// killCalled is set for running jobs if Kill() is called on them.
 	killCalled bool
 
+	// suspendCalled is set for running jobs if SuspendJobs() is called on
+	// them, and unset if ResumeJobs() is called on them.
+	suspendCalled bool
+
+	// suspendedReq is the Requirements we told the scheduler were no longer in
+	// use when the job was Suspended.
+	suspendedReq *scheduler.Requirements
+
+	// preemptedBy is the key of the higher priority job that this running job
+	// is being killed or suspended to make room for.
+	preemptedBy string
+
 	// incrementedLimitGroups notes that we have incremented limit groups for
 	// this job, so they should be decremented when the job finishes running.
 	incrementedLimitGroups []string
@@ -320,6 +483,18 @@ func (j *Job) WallTime() time.Duration {
 	return d
 }
 
+// CPUEfficiency returns the CPU time used divided by what the Job could have
+// used given its WallTime() and Requirements.Cores, so that 1 means all
+// requested cores were kept busy. Returns 0 if the Job hasn't started, or
+// didn't request any cores.
+func (j *Job) CPUEfficiency() float64 {
+	wall := j.WallTime()
+	if wall <= 0 || j.Requirements == nil || j.Requirements.Cores <= 0 {
+		return 0
+	}
+	return j.CPUtime.Seconds() / (wall.Seconds() * j.Requirements.Cores)
+}
+
 // Env decompresses and decodes job.EnvC (the output of CompressEnv(), which are
 // the environment variables the Job's Cmd should run/ran under). Note that EnvC
 // is only populated if you got the Job from GetByCmd(_, _, true) or Reserve().
@@ -383,6 +558,19 @@ func (j *Job) envCurrentOverrides() ([]string, error) {
 	return nil, nil
 }
 
+// copy returns a deep copy of this Job's exported properties.
+func (j *Job) copy() (*Job, error) {
+	ch := new(codec.BincHandle)
+	var encoded []byte
+	err := codec.NewEncoderBytes(&encoded, ch).Encode(j)
+	if err != nil {
+		return nil, err
+	}
+	job := &Job{}
+	err = codec.NewDecoderBytes(encoded, ch).Decode(job)
+	return job, err
+}
+
 // EnvAddOverride adds additional overrides to the jobs existing overrides (if
 // any). These will then get used to determine the final value of Env(). NB:
 // This does not do any updates to a job on the server if called from a client,
@@ -455,6 +643,184 @@ func (j *Job) TriggerBehaviours(success bool) error {
 	return j.Behaviours.Trigger(success, j)
 }
 
+// InputPaths returns the absolute paths of the Job's Inputs, treating relative
+// paths as relative to Cwd.
+func (j *Job) InputPaths() []string {
+	return absPaths(j.Inputs, j.Cwd)
+}
+
+// OutputPaths returns the absolute paths of the Job's Outputs, treating relative
+// paths as relative to ActualCwd, or Cwd if ActualCwd hasn't been set.
+func (j *Job) OutputPaths() []string {
+	cwd := j.ActualCwd
+	if cwd == "" {
+		cwd = j.Cwd
+	}
+	return absPaths(j.Outputs, cwd)
+}
+
+// MissingOutputs returns those OutputPaths() that do not exist.
+func (j *Job) MissingOutputs() []string {
+	var missing []string
+	for _, path := range j.OutputPaths() {
+		if _, err := os.Stat(path); err != nil {
+			missing = append(missing, path)
+		}
+	}
+	return missing
+}
+
+// outputsUpToDate tells you if the Job has declared Outputs that all exist and
+// are newer than all of its declared Inputs (which must also exist), in which
+// case, like make, we can consider there to be no need to run the Cmd. Because
+// the actual working directory of a job is only created when it runs, relative
+// Outputs are only considered when CwdMatters.
+func (j *Job) outputsUpToDate() bool {
+	if len(j.Outputs) == 0 {
+		return false
+	}
+
+	var newestInput time.Time
+	for _, path := range j.InputPaths() {
+		info, err := os.Stat(path)
+		if err != nil {
+			return false
+		}
+		if info.ModTime().After(newestInput) {
+			newestInput = info.ModTime()
+		}
+	}
+
+	for i, path := range absPaths(j.Outputs, j.Cwd) {
+		if !j.CwdMatters && !filepath.IsAbs(internal.TildaToHome(j.Outputs[i])) {
+			return false
+		}
+		info, err := os.Stat(path)
+		if err != nil || !info.ModTime().After(newestInput) {
+			return false
+		}
+	}
+	return true
+}
+
+// calculateCacheKey sets our CacheKey based on our Cmd, the given environment
+// variables (as returned by Env()), and the MD5 checksums of our Inputs.
+// CacheKey is left blank if Cache is false or any of the Inputs could not be
+// read.
+func (j *Job) calculateCacheKey(env []string, logger log15.Logger) {
+	j.CacheKey = ""
+	if !j.Cache {
+		return
+	}
+
+	sorted := make([]string, len(env))
+	copy(sorted, env)
+	sort.Strings(sorted)
+
+	var buf bytes.Buffer
+	buf.WriteString(j.Cmd)
+	buf.WriteString(dbDelimiter)
+	buf.WriteString(strings.Join(sorted, dbDelimiter))
+	for _, path := range j.InputPaths() {
+		md5, err := internal.FileMD5(path, logger)
+		if err != nil {
+			return
+		}
+		buf.WriteString(dbDelimiter)
+		buf.WriteString(md5)
+	}
+
+	j.CacheKey = byteKey(buf.Bytes())
+}
+
+// reuseCachedOutputs copies the outputs recorded in the given cachedResult to
+// our own Outputs (where those are different files). Returns an error if they
+// aren't all still there.
+func (j *Job) reuseCachedOutputs(cr *cachedResult) error {
+	if len(j.Outputs) != len(cr.Outputs) {
+		return fmt.Errorf("job has %d outputs, but the cached result has %d", len(j.Outputs), len(cr.Outputs))
+	}
+
+	for _, path := range cr.Outputs {
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("cached output %s is no longer available", path)
+		}
+	}
+
+	for i, dest := range j.OutputPaths() {
+		source := cr.Outputs[i]
+		if dest == source {
+			continue
+		}
+		err := os.MkdirAll(filepath.Dir(dest), os.ModePerm)
+		if err != nil {
+			return err
+		}
+		err = copyFile(source, dest)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// outputsInActualCwd returns the Job's Outputs that are inside its ActualCwd, as
+// paths relative to ActualCwd.
+func (j *Job) outputsInActualCwd() []string {
+	if j.ActualCwd == "" {
+		return nil
+	}
+	var rels []string
+	for _, path := range j.OutputPaths() {
+		rel, err := filepath.Rel(j.ActualCwd, path)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		rels = append(rels, rel)
+	}
+	return rels
+}
+
+// mountLocations returns the directory that relative mount points are relative
+// to, the default mount point and the default CacheBase, as described for
+// Mount().
+func (j *Job) mountLocations(onCwd ...bool) (cwd, defaultMount, defaultCacheBase string) {
+	cwd = j.Cwd
+	defaultMount = filepath.Join(j.Cwd, "mnt")
+	defaultCacheBase = cwd
+	if j.ActualCwd != "" {
+		cwd = j.ActualCwd
+		defaultMount = cwd
+		defaultCacheBase = filepath.Dir(cwd)
+	} else if len(onCwd) == 1 && onCwd[0] {
+		defaultMount = j.Cwd
+		defaultCacheBase = filepath.Dir(j.Cwd)
+	}
+	return cwd, defaultMount, defaultCacheBase
+}
+
+// mountPoint returns the directory the given MountConfig will be mounted on.
+func mountPoint(mc MountConfig, cwd, defaultMount string) string {
+	if mc.Mount == "" {
+		return defaultMount
+	}
+	if !filepath.IsAbs(mc.Mount) {
+		return filepath.Join(cwd, mc.Mount)
+	}
+	return mc.Mount
+}
+
+// mountPoints returns the directories the Job's MountConfigs will be mounted
+// on by Mount() (given the same onCwd argument).
+func (j *Job) mountPoints(onCwd ...bool) []string {
+	cwd, defaultMount, _ := j.mountLocations(onCwd...)
+	mounts := make([]string, 0, len(j.MountConfigs))
+	for _, mc := range j.MountConfigs {
+		mounts = append(mounts, mountPoint(mc, cwd, defaultMount))
+	}
+	return mounts
+}
+
 // Mount uses the Job's MountConfigs to mount the remote file systems at the
 // desired mount points. If a mount point is unspecified, mounts in the sub
 // folder Cwd/mnt if CwdMatters (and unspecified CacheBase becomes Cwd),
@@ -471,17 +837,7 @@ func (j *Job) TriggerBehaviours(success bool) error {
 // job's actual cwd if anything was mounted there, for the purpose of knowing
 // what directories to check and not check for disk usage.
 func (j *Job) Mount(onCwd ...bool) ([]string, []string, error) {
-	cwd := j.Cwd
-	defaultMount := filepath.Join(j.Cwd, "mnt")
-	defaultCacheBase := cwd
-	if j.ActualCwd != "" {
-		cwd = j.ActualCwd
-		defaultMount = cwd
-		defaultCacheBase = filepath.Dir(cwd)
-	} else if len(onCwd) == 1 && onCwd[0] {
-		defaultMount = j.Cwd
-		defaultCacheBase = filepath.Dir(j.Cwd)
-	}
+	cwd, defaultMount, defaultCacheBase := j.mountLocations(onCwd...)
 
 	var uniqueCacheDirs []string
 	var uniqueMountedDirs []string
@@ -537,14 +893,8 @@ func (j *Job) Mount(onCwd ...bool) ([]string, []string, error) {
 			retries = mc.Retries
 		}
 
-		mount := mc.Mount
-		if mount != "" {
-			if !filepath.IsAbs(mount) {
-				mount = filepath.Join(cwd, mount)
-				uniqueMountedDirs = append(uniqueMountedDirs, mount)
-			}
-		} else {
-			mount = defaultMount
+		mount := mountPoint(mc, cwd, defaultMount)
+		if !filepath.IsAbs(mc.Mount) {
 			uniqueMountedDirs = append(uniqueMountedDirs, mount)
 		}
 		cacheBase := mc.CacheBase
@@ -698,6 +1048,12 @@ func (j *Job) updateAfterExit(jes *JobEndState, lim *limiter.Limiter) {
 	j.PeakRAM = jes.PeakRAM
 	j.PeakDisk = jes.PeakDisk
 	j.CPUtime = jes.CPUtime
+	j.BytesRead = jes.BytesRead
+	j.BytesWritten = jes.BytesWritten
+	j.CtxSwitches = jes.CtxSwitches
+	j.ResourceProfile = jes.ResourceProfile
+	j.CacheKey = jes.CacheKey
+	j.CachedFrom = jes.CachedFrom
 	j.EndTime = jes.EndTime
 	if jes.Cwd != "" {
 		j.ActualCwd = jes.Cwd
@@ -711,6 +1067,65 @@ func (j *Job) updateAfterExit(jes *JobEndState, lim *limiter.Limiter) {
 	j.Unlock()
 }
 
+// noteFailure records that the job failed for the given reason, adjusting
+// UntilBuried or FailReasonCounts as per our RetryPolicy, and tells you if
+// the job should now be buried. ran should be true if the job's Cmd was
+// actually run; if not, the failure doesn't count against the job's retries.
+// You must hold the job's lock before calling this.
+func (j *Job) noteFailure(reason string, ran bool) bool {
+	if ran {
+		if j.FailReasonCounts == nil {
+			j.FailReasonCounts = make(map[string]int)
+		}
+		j.FailReasonCounts[reason]++
+	}
+
+	if retries, ruled := j.RetryPolicy.retriesFor(reason); ruled {
+		return j.FailReasonCounts[reason] > retries
+	}
+
+	if ran {
+		j.UntilBuried--
+	}
+	return j.UntilBuried <= 0
+}
+
+// addEvent records the given event in the job's History. You must hold the
+// job's lock before calling this.
+func (j *Job) addEvent(event, detail string) {
+	j.History = append(j.History, &JobEvent{Time: time.Now(), Event: event, Detail: detail})
+}
+
+// resetRetries restores the job's full complement of retries, as appropriate
+// after it was added or kicked. You must hold the job's lock before calling
+// this.
+func (j *Job) resetRetries() {
+	j.UntilBuried = j.Retries + 1
+	j.FailReasonCounts = nil
+}
+
+// notBefore returns the earliest time, from the given time on, that the job is
+// allowed to start running, given its BeginAt and Window.
+func (j *Job) notBefore(t time.Time) time.Time {
+	j.RLock()
+	defer j.RUnlock()
+	if j.BeginAt.After(t) {
+		t = j.BeginAt
+	}
+	return j.Window.Next(t)
+}
+
+// retryDelay returns how long the job should be delayed before being retried,
+// as per our RetryPolicy and the number of times it has failed since it was
+// added or kicked. You must hold the job's lock before calling this.
+func (j *Job) retryDelay() time.Duration {
+	var failures int
+	for _, count := range j.FailReasonCounts {
+		failures += count
+	}
+	return j.RetryPolicy.delay(failures)
+}
+
 // Key calculates a unique key to describe the job.
 func (j *Job) Key() string {
 	if j.CwdMatters {
@@ -778,6 +1193,16 @@ func (j *Job) ToStatus() JStatus {
 	state := j.State
 	if state == JobStateRunning && j.Lost {
 		state = JobStateLost
+	} else if state == JobStateRunning && j.Suspended {
+		state = JobStateSuspended
+	}
+	var beginAt string
+	if !j.BeginAt.IsZero() {
+		beginAt = j.BeginAt.Format(time.RFC3339)
+	}
+	var deadline string
+	if !j.Deadline.IsZero() {
+		deadline = j.Deadline.Format(time.RFC3339)
 	}
 	var ot []string
 	for key, val := range j.Requirements.Other {
@@ -786,6 +1211,12 @@ func (j *Job) ToStatus() JStatus {
 	return JStatus{
 		Key:           j.Key(),
 		RepGroup:      j.RepGroup,
+		ArrayName:     j.ArrayName,
+		ArrayIndex:    j.ArrayIndex,
+		Owner:         j.Owner,
+		Priority:      j.Priority,
+		EffPriority:   j.EffectivePriority,
+		PendingReason: j.PendingReason,
 		LimitGroups:   j.LimitGroups,
 		DepGroups:     j.DepGroups,
 		Dependencies:  j.Dependencies.Stringify(),
@@ -797,6 +1228,16 @@ func (j *Job) ToStatus() JStatus {
 		Behaviours:    j.Behaviours.String(),
 		Mounts:        j.MountConfigs.String(),
 		MonitorDocker: j.MonitorDocker,
+		Container:     j.Container.String(),
+		RetryPolicy:   j.RetryPolicy.String(),
+		TimeLimit:     j.TimeLimit.String(),
+		BeginAt:       beginAt,
+		Window:        j.Window.String(),
+		Deadline:      deadline,
+		Inputs:        j.Inputs,
+		Outputs:       j.Outputs,
+		LogDir:        j.LogDir,
+		LogToManager:  j.LogToManager,
 		ExpectedRAM:   j.Requirements.RAM,
 		ExpectedTime:  j.Requirements.Time.Seconds(),
 		RequestedDisk: j.Requirements.Disk,
@@ -813,10 +1254,19 @@ func (j *Job) ToStatus() JStatus {
 		HostIP:        j.HostIP,
 		Walltime:      j.WallTime().Seconds(),
 		CPUtime:       j.CPUtime.Seconds(),
+		CPUEfficiency: j.CPUEfficiency(),
+		BytesRead:     j.BytesRead,
+		BytesWritten:  j.BytesWritten,
+		CtxSwitches:   j.CtxSwitches,
+		Profile:       j.ResourceProfile,
 		Started:       j.StartTime.Unix(),
 		Ended:         j.EndTime.Unix(),
 		Attempts:      j.Attempts,
+		History:       j.History,
 		Similar:       j.Similar,
+		Skipped:       j.Skipped,
+		CachedFrom:    j.CachedFrom,
+		CopiedFiles:   j.CopiedFiles,
 		StdErr:        stderr,
 		StdOut:        stdout,
 		Env:           env,
@@ -888,6 +1338,16 @@ type JobModifier struct {
 	PrioritySet      bool
 	Retries          uint8
 	RetriesSet       bool
+	RetryPolicy      *RetryPolicy
+	RetryPolicySet   bool
+	TimeLimit        *TimeLimit
+	TimeLimitSet     bool
+	BeginAt          time.Time
+	BeginAtSet       bool
+	Window           *Window
+	WindowSet        bool
+	Deadline         time.Time
+	DeadlineSet      bool
 	EnvOverride      []byte
 	EnvOverrideSet   bool
 	LimitGroups      []string
@@ -904,6 +1364,8 @@ type JobModifier struct {
 	BsubModeSet      bool
 	MonitorDocker    string
 	MonitorDockerSet bool
+	Container        *Container
+	ContainerSet     bool
 }
 
 // NewJobModifer is a convenience for making a new JobModifer, that you can call
@@ -970,6 +1432,41 @@ func (j *JobModifier) SetRetries(new uint8) {
 	j.RetriesSet = true
 }
 
+// SetRetryPolicy notes that you want to modify the RetryPolicy of Jobs. Supply
+// nil to remove any existing RetryPolicy.
+func (j *JobModifier) SetRetryPolicy(new *RetryPolicy) {
+	j.RetryPolicy = new
+	j.RetryPolicySet = true
+}
+
+// SetTimeLimit notes that you want to modify the TimeLimit of Jobs. Supply nil
+// to remove any existing TimeLimit.
+func (j *JobModifier) SetTimeLimit(new *TimeLimit) {
+	j.TimeLimit = new
+	j.TimeLimitSet = true
+}
+
+// SetBeginAt notes that you want to modify the BeginAt of Jobs. Supply the
+// zero time to remove any existing BeginAt.
+func (j *JobModifier) SetBeginAt(new time.Time) {
+	j.BeginAt = new
+	j.BeginAtSet = true
+}
+
+// SetWindow notes that you want to modify the Window of Jobs. Supply nil to
+// remove any existing Window.
+func (j *JobModifier) SetWindow(new *Window) {
+	j.Window = new
+	j.WindowSet = true
+}
+
+// SetDeadline notes that you want to modify the Deadline of Jobs. Supply the
+// zero time to remove any existing Deadline.
+func (j *JobModifier) SetDeadline(new time.Time) {
+	j.Deadline = new
+	j.DeadlineSet = true
+}
+
 // SetEnvOverride notes that you want to modify the EnvOverride of Jobs. The
 // supplied string should be a comma separated list of key=value pairs. This can
 // generate an error if compression of the data fails.
@@ -1029,6 +1526,13 @@ func (j *JobModifier) SetMonitorDocker(new string) {
 	j.MonitorDockerSet = true
 }
 
+// SetContainer notes that you want to modify the Container of Jobs. Supply nil
+// to have them run directly on the host.
+func (j *JobModifier) SetContainer(new *Container) {
+	j.Container = new
+	j.ContainerSet = true
+}
+
 // Modify takes existing jobs and modifies them all by setting the new values
 // that you have previously set using the Set*() methods. Other values are left
 // alone. Note that this could result in a Job's Key() changing.
@@ -1087,6 +1591,21 @@ func (j *JobModifier) Modify(jobs []*Job) map[string]string {
 		if j.RetriesSet {
 			job.Retries = j.Retries
 		}
+		if j.RetryPolicySet {
+			job.RetryPolicy = j.RetryPolicy
+		}
+		if j.TimeLimitSet {
+			job.TimeLimit = j.TimeLimit
+		}
+		if j.BeginAtSet {
+			job.BeginAt = j.BeginAt
+		}
+		if j.WindowSet {
+			job.Window = j.Window
+		}
+		if j.DeadlineSet {
+			job.Deadline = j.Deadline
+		}
 		if j.EnvOverrideSet {
 			job.EnvOverride = j.EnvOverride
 		}
@@ -1125,6 +1644,9 @@ func (j *JobModifier) Modify(jobs []*Job) map[string]string {
 		if j.MonitorDockerSet {
 			job.MonitorDocker = j.MonitorDocker
 		}
+		if j.ContainerSet {
+			job.Container = j.Container
+		}
 		keys[job.Key()] = before
 		job.Unlock()
 	}
@@ -29,6 +29,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -1248,6 +1249,89 @@ func TestJobqueueMedium(t *testing.T) {
 				jq.Disconnect()
 			})
 
+			Convey("Users issued their own tokens can only alter their own jobs", func() {
+				admin, err := jq.WhoAmI()
+				So(err, ShouldBeNil)
+				So(admin.Admin, ShouldBeTrue)
+
+				userToken, err := jq.AddUser("user1", false)
+				So(err, ShouldBeNil)
+				So(len(userToken), ShouldEqual, tokenLength)
+				_, err = jq.AddUser("user1", true)
+				So(err, ShouldNotBeNil)
+				_, err = jq.AddUser("bad name", false)
+				So(err, ShouldNotBeNil)
+
+				ujq, err := Connect(addr, config.ManagerCAFile, config.ManagerCertDomain, userToken, clientConnectTime)
+				So(err, ShouldBeNil)
+				defer ujq.Disconnect()
+
+				user, err := ujq.WhoAmI()
+				So(err, ShouldBeNil)
+				So(user.Name, ShouldEqual, "user1")
+				So(user.Admin, ShouldBeFalse)
+
+				_, err = ujq.GetUsers()
+				So(err, ShouldNotBeNil)
+				jqerr, ok := err.(Error)
+				So(ok, ShouldBeTrue)
+				So(jqerr.Err, ShouldEqual, ErrNotAdmin)
+				_, err = ujq.AddUser("user2", true)
+				So(err, ShouldNotBeNil)
+
+				userCmd := "echo user1"
+				inserts, _, err := ujq.Add([]*Job{{Cmd: userCmd, Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "user1"}}, envVars, true)
+				So(err, ShouldBeNil)
+				So(inserts, ShouldEqual, 1)
+
+				job, err := jq.GetByEssence(&JobEssence{Cmd: userCmd}, false, false)
+				So(err, ShouldBeNil)
+				So(job.Owner, ShouldEqual, "user1")
+				job, err = jq.GetByEssence(&JobEssence{Cmd: jobs[0].Cmd}, false, false)
+				So(err, ShouldBeNil)
+				So(job.Owner, ShouldEqual, admin.Name)
+
+				_, err = ujq.Reserve(50 * time.Millisecond)
+				So(err, ShouldNotBeNil)
+				jqerr, ok = err.(Error)
+				So(ok, ShouldBeTrue)
+				So(jqerr.Err, ShouldEqual, ErrNotAdmin)
+
+				job, err = jq.Reserve(50 * time.Millisecond)
+				So(err, ShouldBeNil)
+				So(job.Cmd, ShouldEqual, jobs[0].Cmd)
+				err = ujq.Bury(job, nil, "")
+				So(err, ShouldNotBeNil)
+				jqerr, ok = err.(Error)
+				So(ok, ShouldBeTrue)
+				So(jqerr.Err, ShouldEqual, ErrNotAdmin)
+				job, err = jq.GetByEssence(&JobEssence{Cmd: jobs[0].Cmd}, false, false)
+				So(err, ShouldBeNil)
+				So(job.State, ShouldEqual, JobStateReserved)
+
+				deleted, err := ujq.Delete([]*JobEssence{{Cmd: jobs[0].Cmd}, {Cmd: userCmd}})
+				So(err, ShouldBeNil)
+				So(deleted, ShouldEqual, 1)
+				job, err = jq.GetByEssence(&JobEssence{Cmd: jobs[0].Cmd}, false, false)
+				So(err, ShouldBeNil)
+				So(job, ShouldNotBeNil)
+
+				users, err := jq.GetUsers()
+				So(err, ShouldBeNil)
+				So(len(users), ShouldEqual, 2)
+				So(users[0].Name, ShouldEqual, admin.Name)
+				So(users[1].Name, ShouldEqual, "user1")
+
+				removed, err := jq.RemoveUser("user1")
+				So(err, ShouldBeNil)
+				So(removed, ShouldBeTrue)
+				removed, err = jq.RemoveUser("user1")
+				So(err, ShouldBeNil)
+				So(removed, ShouldBeFalse)
+				_, err = ujq.WhoAmI()
+				So(err, ShouldNotBeNil)
+			})
+
 			Convey("Once reserved you can execute jobs, and other clients see the correct state on gets", func() {
 				// job that succeeds, no std out
 				job, err := jq.Reserve(50 * time.Millisecond)
@@ -2024,426 +2108,857 @@ func TestJobqueueMedium(t *testing.T) {
 					So(entries[0].Name(), ShouldEqual, "jobqueue_cwd")
 				})
 
-				Convey("Jobs that take longer than the ttr can execute successfully, even if clienttouchinterval is > ttr", func() {
+				Convey("Jobs with LogDir or LogToManager set have their complete output captured", func() {
+					logDir, err := ioutil.TempDir("", "wr_jobqueue_test_logs_")
+					So(err, ShouldBeNil)
+					defer os.RemoveAll(logDir)
+
 					jobs = nil
-					cmd := "perl -e 'for (1..3) { sleep(1) }'"
-					jobs = append(jobs, &Job{Cmd: cmd, Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "should_pass"})
+					cmd := "seq 1 5000; seq 1 3000 1>&2"
+					jobs = append(jobs, &Job{Cmd: cmd, Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "logs", LogDir: logDir, LogToManager: true})
+					jobs = append(jobs, &Job{Cmd: "echo nolog", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "logs"})
 					inserts, _, err := jq.Add(jobs, envVars, true)
 					So(err, ShouldBeNil)
-					So(inserts, ShouldEqual, 1)
+					So(inserts, ShouldEqual, 2)
 
 					job, err := jq.Reserve(50 * time.Millisecond)
 					So(err, ShouldBeNil)
 					So(job.Cmd, ShouldEqual, cmd)
-					So(job.State, ShouldEqual, JobStateReserved)
+					err = jq.Execute(job, config.RunnerExecShell)
+					So(err, ShouldBeNil)
+
+					expectedOut, err := exec.Command("bash", "-c", "seq 1 5000").Output()
+					So(err, ShouldBeNil)
+					expectedErr, err := exec.Command("bash", "-c", "seq 1 3000").Output()
+					So(err, ShouldBeNil)
+
+					job, err = jq.GetByEssence(&JobEssence{Cmd: cmd}, true, false)
+					So(err, ShouldBeNil)
+					So(job.LogDir, ShouldEqual, logDir)
+					So(job.LogToManager, ShouldBeTrue)
+					stdout, err := job.StdOut()
+					So(err, ShouldBeNil)
+					So(len(stdout), ShouldBeLessThan, len(expectedOut))
+
+					content, err := ioutil.ReadFile(filepath.Join(logDir, job.Key(), LogFileName(1, LogStdout)))
+					So(err, ShouldBeNil)
+					So(string(content), ShouldEqual, string(expectedOut))
 
+					content, err = jq.GetLog(job, 0, LogStdout)
+					So(err, ShouldBeNil)
+					So(string(content), ShouldEqual, string(expectedOut))
+					content, err = jq.GetLog(job, 1, LogStderr)
+					So(err, ShouldBeNil)
+					So(string(content), ShouldEqual, string(expectedErr))
+					_, err = jq.GetLog(job, 2, LogStderr)
+					So(err, ShouldNotBeNil)
+
+					job, err = jq.Reserve(50 * time.Millisecond)
+					So(err, ShouldBeNil)
+					So(job.Cmd, ShouldEqual, "echo nolog")
 					err = jq.Execute(job, config.RunnerExecShell)
 					So(err, ShouldBeNil)
-					So(job.State, ShouldEqual, JobStateComplete)
-					So(job.Exited, ShouldBeTrue)
-					So(job.Exitcode, ShouldEqual, 0)
+					_, err = jq.GetLog(job, 0, LogStdout)
+					So(err, ShouldNotBeNil)
 
-					job2, err := jq2.GetByEssence(&JobEssence{Cmd: cmd}, true, false)
+					So(validLogFileName("1.stdout"), ShouldBeTrue)
+					So(validLogFileName("../1.stdout"), ShouldBeFalse)
+					So(validLogFileName("01.stderr"), ShouldBeFalse)
+				})
+
+				Convey("Jobs with a TimeLimit are signalled and then killed", func() {
+					jobs = nil
+					trapCmd := "trap 'echo caught; exit 3' TERM; sleep 10 & wait"
+					ignoreCmd := "trap '' USR1; sleep 10"
+					tl, err := NewTimeLimit(3*time.Second, 2*time.Second, "")
 					So(err, ShouldBeNil)
-					So(job2, ShouldNotBeNil)
-					So(job2.State, ShouldEqual, JobStateComplete)
+					tlUSR1, err := NewTimeLimit(2*time.Second, 1*time.Second, "usr1")
+					So(err, ShouldBeNil)
+					So(tlUSR1.Signal, ShouldEqual, "SIGUSR1")
+					jobs = append(jobs, &Job{Cmd: trapCmd, Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(0), RepGroup: "timelimit", TimeLimit: tl})
+					jobs = append(jobs, &Job{Cmd: ignoreCmd, Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(0), RepGroup: "timelimit", TimeLimit: tlUSR1})
+					inserts, _, err := jq.Add(jobs, envVars, true)
+					So(err, ShouldBeNil)
+					So(inserts, ShouldEqual, 2)
 
-					// same again, but we'll alter the clienttouchinterval to be > ttr
-					ClientTouchInterval = 500 * time.Millisecond
-					inserts, _, err = jq.Add(jobs, envVars, false)
+					for _, cmd := range []string{trapCmd, ignoreCmd} {
+						job, err := jq.Reserve(50 * time.Millisecond)
+						So(err, ShouldBeNil)
+						So(job.Cmd, ShouldEqual, cmd)
+						So(job.TimeLimit, ShouldNotBeNil)
+
+						t := time.Now()
+						err = jq.Execute(job, config.RunnerExecShell)
+						So(err, ShouldNotBeNil)
+						jqerr, ok := err.(Error)
+						So(ok, ShouldBeTrue)
+						So(jqerr.Err, ShouldEqual, FailReasonTime)
+						So(time.Since(t), ShouldBeLessThan, 5*time.Second)
+
+						job, err = jq.GetByEssence(&JobEssence{Cmd: cmd}, true, false)
+						So(err, ShouldBeNil)
+						So(job.State, ShouldEqual, JobStateBuried)
+						So(job.FailReason, ShouldEqual, FailReasonTime)
+						if cmd == trapCmd {
+							So(job.Exitcode, ShouldEqual, 3)
+							stdout, err := job.StdOut()
+							So(err, ShouldBeNil)
+							So(stdout, ShouldEqual, "caught")
+						} else {
+							So(job.Exitcode, ShouldEqual, -1)
+						}
+					}
+
+					_, err = NewTimeLimit(0, 0, "")
+					So(err, ShouldNotBeNil)
+					_, err = ParseTimeLimit("1h", "", "SIGFOO")
+					So(err, ShouldNotBeNil)
+					tl, err = ParseTimeLimit("", "1m", "")
+					So(err, ShouldBeNil)
+					So(tl, ShouldBeNil)
+
+					req := reqForScheduler(standardReqs, tlUSR1)
+					So(req.Time, ShouldEqual, 2*time.Second+TimeLimitSchedulerLeeway)
+					So(standardReqs.Time, ShouldNotEqual, req.Time)
+				})
+
+				Convey("Running jobs can be suspended and resumed", func() {
+					jobs = nil
+					// (a single sleep would end at its original time regardless
+					// of how long it was stopped for)
+					cmd := "for i in 1 2 3 4 5 6 7 8 9 10 11 12 13 14 15 16 17 18 19 20; do sleep 0.1; done"
+					jobs = append(jobs, &Job{Cmd: cmd, Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(0), RepGroup: "suspend"})
+					inserts, _, err := jq.Add(jobs, envVars, true)
 					So(err, ShouldBeNil)
 					So(inserts, ShouldEqual, 1)
 
-					job, err = jq.Reserve(50 * time.Millisecond)
+					job, err := jq.Reserve(50 * time.Millisecond)
 					So(err, ShouldBeNil)
 					So(job.Cmd, ShouldEqual, cmd)
-					So(job.State, ShouldEqual, JobStateReserved)
 
-					lostCh := make(chan bool)
+					suspended, err := jq.SuspendJobs([]*JobEssence{job.ToEssense()})
+					So(err, ShouldBeNil)
+					So(suspended, ShouldEqual, 0)
+
+					t := time.Now()
+					ech := make(chan error, 1)
 					go func() {
-						<-time.After(300 * time.Millisecond)
-						// after ttr but before first touch, it becomes lost
-						job2f, errf := jq2.GetByEssence(&JobEssence{Cmd: cmd}, true, false)
-						if errf != nil || job2f == nil || job2f.State != JobStateLost || job2f.FailReason != FailReasonLost || job2f.Exited {
-							lostCh <- false
-						}
+						ech <- jq.Execute(job, config.RunnerExecShell)
+					}()
 
-						<-time.After(250 * time.Millisecond)
-						// after the first touch, it becomes running again
-						job2f, errf = jq2.GetByEssence(&JobEssence{Cmd: cmd}, true, false)
-						if errf != nil || job2f == nil || job2f.State != JobStateRunning || job2f.FailReason != FailReasonLost || job2f.Exited {
-							lostCh <- false
-						}
-						lostCh <- true
+					<-time.After(500 * time.Millisecond)
+					suspended, err = jq.SuspendJobs([]*JobEssence{job.ToEssense()})
+					So(err, ShouldBeNil)
+					So(suspended, ShouldEqual, 1)
+
+					<-time.After(500 * time.Millisecond)
+					got, err := jq.GetByEssence(&JobEssence{Cmd: cmd}, false, false)
+					So(err, ShouldBeNil)
+					So(got.State, ShouldEqual, JobStateSuspended)
+					So(got.Suspended, ShouldBeTrue)
+
+					jobs, err = jq.GetByRepGroup("suspend", false, 0, JobStateSuspended, false, false)
+					So(err, ShouldBeNil)
+					So(len(jobs), ShouldEqual, 1)
+
+					<-time.After(2 * time.Second)
+					got, err = jq.GetByEssence(&JobEssence{Cmd: cmd}, false, false)
+					So(err, ShouldBeNil)
+					So(got.State, ShouldEqual, JobStateSuspended)
+
+					resumed, err := jq.ResumeJobs([]*JobEssence{job.ToEssense()})
+					So(err, ShouldBeNil)
+					So(resumed, ShouldEqual, 1)
+
+					<-time.After(500 * time.Millisecond)
+					got, err = jq.GetByEssence(&JobEssence{Cmd: cmd}, false, false)
+					So(err, ShouldBeNil)
+					So(got.State, ShouldEqual, JobStateRunning)
+					So(got.Suspended, ShouldBeFalse)
+
+					err = <-ech
+					So(err, ShouldBeNil)
+					So(time.Since(t), ShouldBeGreaterThan, 3*time.Second)
+
+					got, err = jq.GetByEssence(&JobEssence{Cmd: cmd}, false, false)
+					So(err, ShouldBeNil)
+					So(got.State, ShouldEqual, JobStateComplete)
+				})
+
+				Convey("The output of running jobs can be followed", func() {
+					jobs = nil
+					cmd := "echo first; echo err 1>&2; sleep 3; echo second"
+					jobs = append(jobs, &Job{Cmd: cmd, Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "follow"})
+					inserts, _, err := jq.Add(jobs, envVars, true)
+					So(err, ShouldBeNil)
+					So(inserts, ShouldEqual, 1)
+
+					job, err := jq.Reserve(50 * time.Millisecond)
+					So(err, ShouldBeNil)
+					So(job.Cmd, ShouldEqual, cmd)
+
+					out, err := jq.GetOutput(job, 0, 0)
+					So(err, ShouldBeNil)
+					So(out.Running, ShouldBeFalse)
+					So(len(out.Stdout), ShouldEqual, 0)
+
+					executed := make(chan error)
+					go func() {
+						executed <- jq.Execute(job, config.RunnerExecShell)
 					}()
 
-					err = jq.Execute(job, config.RunnerExecShell)
+					<-time.After(2 * time.Second)
+					out, err = jq.GetOutput(job, 0, 0)
 					So(err, ShouldBeNil)
+					So(out.Running, ShouldBeTrue)
+					So(string(out.Stdout), ShouldEqual, "first\n")
+					So(string(out.Stderr), ShouldEqual, "err\n")
+					So(out.StdoutOffset, ShouldEqual, 6)
 
-					job2, err = jq2.GetByEssence(&JobEssence{Cmd: cmd}, true, false)
+					err = <-executed
 					So(err, ShouldBeNil)
-					So(job2, ShouldNotBeNil)
 
-					So(job2.State, ShouldEqual, JobStateComplete)
-					So(job2.Exited, ShouldBeTrue)
-					So(<-lostCh, ShouldBeTrue)
+					out, err = jq.GetOutput(job, out.StdoutOffset, out.StderrOffset)
+					So(err, ShouldBeNil)
+					So(out.Running, ShouldBeFalse)
+					So(string(out.Stdout), ShouldEqual, "second\n")
+					So(len(out.Stderr), ShouldEqual, 0)
 				})
-			})
-		})
 
-		Convey("After connecting and adding some jobs under one RepGroup", func() {
-			jq, err := Connect(addr, config.ManagerCAFile, config.ManagerCertDomain, token, clientConnectTime)
-			So(err, ShouldBeNil)
-			defer jq.Disconnect()
+				Convey("Live output only keeps the most recent LiveOutputMax bytes", func() {
+					origMax := LiveOutputMax
+					defer func() {
+						LiveOutputMax = origMax
+					}()
+					LiveOutputMax = 4
+					ls := &liveStream{}
+					ls.append([]byte("abc"))
+					ls.append([]byte("def"))
+					data, end := ls.since(0)
+					So(string(data), ShouldEqual, "cdef")
+					So(end, ShouldEqual, 6)
+					data, end = ls.since(5)
+					So(string(data), ShouldEqual, "f")
+					So(end, ShouldEqual, 6)
+					data, _ = ls.since(6)
+					So(data, ShouldBeNil)
+				})
 
-			var jobs []*Job
-			for i := 0; i < 3; i++ {
-				jobs = append(jobs, &Job{Cmd: fmt.Sprintf("echo rgduptest %d", i), Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "rp1"})
-			}
-			inserts, already, err := jq.Add(jobs, envVars, true)
-			So(err, ShouldBeNil)
-			So(inserts, ShouldEqual, 3)
-			So(already, ShouldEqual, 0)
+				Convey("Jobs with a RetryPolicy obey its backoff and per-reason retries", func() {
+					rp, err := NewRetryPolicy(RetryBackoffExponential, 1*time.Second, 3*time.Second, map[string]int{"exit": 1})
+					So(err, ShouldBeNil)
+					So(rp.Reasons, ShouldResemble, map[string]int{FailReasonExit: 1})
+					So(rp.delay(1), ShouldEqual, 1*time.Second)
+					So(rp.delay(2), ShouldEqual, 2*time.Second)
+					So(rp.delay(3), ShouldEqual, 3*time.Second)
+					rp.Backoff = RetryBackoffLinear
+					So(rp.delay(2), ShouldEqual, 2*time.Second)
+					So(rp.delay(10), ShouldEqual, 3*time.Second)
+					_, err = NewRetryPolicy("sometimes", 0, 0, nil)
+					So(err, ShouldNotBeNil)
+					_, err = NewRetryPolicy("", 0, 0, map[string]int{"foo": 1})
+					So(err, ShouldNotBeNil)
+
+					jobs = nil
+					rp, err = NewRetryPolicy(RetryBackoffLinear, 100*time.Millisecond, 0, map[string]int{"exit": 1, "cfound": 1})
+					So(err, ShouldBeNil)
+					jobs = append(jobs, &Job{Cmd: "exit 1", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: 0, RetryPolicy: rp, RepGroup: "retry_policy"})
+					jobs = append(jobs, &Job{Cmd: "wr_retry_policy_test_missing_cmd", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: 3, RetryPolicy: rp, RepGroup: "retry_policy"})
+					inserts, _, err := jq.Add(jobs, envVars, true)
+					So(err, ShouldBeNil)
+					So(inserts, ShouldEqual, 2)
 
-			Convey("You can reserve and execute those", func() {
-				for i := 0; i < 3; i++ {
 					job, err := jq.Reserve(50 * time.Millisecond)
 					So(err, ShouldBeNil)
+					So(job.Cmd, ShouldEqual, "exit 1")
+					So(job.RetryPolicy, ShouldNotBeNil)
 					err = jq.Execute(job, config.RunnerExecShell)
+					So(err, ShouldNotBeNil)
+					So(job.State, ShouldEqual, JobStateDelayed)
+					So(job.FailReason, ShouldEqual, FailReasonExit)
+
+					job2, err := jq.Reserve(50 * time.Millisecond)
 					So(err, ShouldBeNil)
-				}
+					So(job2.Cmd, ShouldEqual, "wr_retry_policy_test_missing_cmd")
+					err = jq.Execute(job2, config.RunnerExecShell)
+					So(err, ShouldNotBeNil)
+					So(job2.State, ShouldEqual, JobStateDelayed)
+					So(job2.FailReason, ShouldEqual, FailReasonCFound)
 
-				Convey("Then you can add dups and a new one under a new RepGroup and reserve/execute all of them", func() {
-					jobs = nil
-					for i := 0; i < 4; i++ {
-						jobs = append(jobs, &Job{Cmd: fmt.Sprintf("echo rgduptest %d", i), Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "rp2"})
-					}
-					inserts, already, err := jq.Add(jobs, envVars, false)
+					job, err = jq.GetByEssence(&JobEssence{Cmd: "exit 1"}, false, false)
 					So(err, ShouldBeNil)
-					So(inserts, ShouldEqual, 4)
-					So(already, ShouldEqual, 0)
+					So(job.State, ShouldEqual, JobStateDelayed)
+					So(job.FailReasonCounts[FailReasonExit], ShouldEqual, 1)
 
-					for i := 0; i < 4; i++ {
-						job, err := jq.Reserve(50 * time.Millisecond)
-						So(err, ShouldBeNil)
-						err = jq.Execute(job, config.RunnerExecShell)
+					<-time.After(150 * time.Millisecond)
+					job, err = jq.Reserve(50 * time.Millisecond)
+					So(err, ShouldBeNil)
+					So(job, ShouldNotBeNil)
+					if job.Cmd != "exit 1" {
+						job, err = jq.Reserve(50 * time.Millisecond)
 						So(err, ShouldBeNil)
+						So(job, ShouldNotBeNil)
 					}
+					So(job.Cmd, ShouldEqual, "exit 1")
+					err = jq.Execute(job, config.RunnerExecShell)
+					So(err, ShouldNotBeNil)
+					So(job.State, ShouldEqual, JobStateBuried)
 
-					Convey("The jobs can be retrieved by either RepGroup and will have the expected RepGroup", func() {
-						jobsg, err := jq.GetByRepGroup("rp1", false, 0, JobStateComplete, false, false)
+					job, err = jq.GetByEssence(&JobEssence{Cmd: "exit 1"}, false, false)
+					So(err, ShouldBeNil)
+					So(job.State, ShouldEqual, JobStateBuried)
+					So(job.FailReason, ShouldEqual, FailReasonExit)
+
+					Convey("Kicking resets the per-reason retries, and wr mod can change the policy", func() {
+						kicked, err := jq.Kick([]*JobEssence{{Cmd: "exit 1"}})
 						So(err, ShouldBeNil)
-						So(len(jobsg), ShouldEqual, 3)
-						So(jobsg[0].RepGroup, ShouldEqual, "rp1")
+						So(kicked, ShouldEqual, 1)
+						job, err = jq.GetByEssence(&JobEssence{Cmd: "exit 1"}, false, false)
+						So(err, ShouldBeNil)
+						So(job.FailReasonCounts, ShouldBeNil)
 
-						jobsg, err = jq.GetByRepGroup("rp2", false, 0, JobStateComplete, false, false)
+						jm := NewJobModifer()
+						jm.SetRetryPolicy(nil)
+						modified, err := jq.Modify([]*JobEssence{{Cmd: "exit 1"}}, jm)
 						So(err, ShouldBeNil)
-						So(len(jobsg), ShouldEqual, 4)
-						So(jobsg[0].RepGroup, ShouldEqual, "rp2")
+						So(len(modified), ShouldEqual, 1)
+						job, err = jq.GetByEssence(&JobEssence{Cmd: "exit 1"}, false, false)
+						So(err, ShouldBeNil)
+						So(job.RetryPolicy, ShouldBeNil)
 					})
 				})
 
-				Convey("Previously complete jobs are rejected when adding with ignoreComplete", func() {
-					inserts, already, err := jq.Add(jobs, envVars, true)
+				Convey("Jobs with afternotok and afterany dependencies start once their dependencies are buried", func() {
+					jobs = nil
+					jobs = append(jobs, &Job{Cmd: "exit 1", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Priority: 2, Retries: 0, RepGroup: "cond_deps", DepGroups: []string{"cond_fail"}})
+					jobs = append(jobs, &Job{Cmd: "echo ok", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Priority: 1, RepGroup: "cond_deps", DepGroups: []string{"cond_ok"}})
+					jobs = append(jobs, &Job{Cmd: "echo afternotok_fail", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "cond_deps", Dependencies: Dependencies{NewDepGroupDependency("afternotok:cond_fail")}})
+					jobs = append(jobs, &Job{Cmd: "echo afterany_fail", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "cond_deps", Dependencies: Dependencies{NewDepGroupDependency("afterany:cond_fail")}})
+					jobs = append(jobs, &Job{Cmd: "echo afterok_fail", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "cond_deps", Dependencies: Dependencies{NewDepGroupDependency("cond_fail")}})
+					jobs = append(jobs, &Job{Cmd: "echo afternotok_ok", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "cond_deps", Dependencies: Dependencies{NewDepGroupDependency("afternotok:cond_ok")}})
+					inserts, _, err := jq.Add(jobs, envVars, true)
 					So(err, ShouldBeNil)
-					So(inserts, ShouldEqual, 0)
-					So(already, ShouldEqual, 3)
-				})
-			})
+					So(inserts, ShouldEqual, 6)
 
-			Convey("You can add dups and a new one under a new RepGroup", func() {
-				jobs = nil
-				for i := 0; i < 4; i++ {
-					jobs = append(jobs, &Job{Cmd: fmt.Sprintf("echo rgduptest %d", i), Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "rp2"})
-				}
-				inserts, already, err := jq.Add(jobs, envVars, false)
-				So(err, ShouldBeNil)
-				So(inserts, ShouldEqual, 1)
-				So(already, ShouldEqual, 3)
+					job, err := jq.GetByEssence(&JobEssence{Cmd: "echo afternotok_fail"}, false, false)
+					So(err, ShouldBeNil)
+					So(job.State, ShouldEqual, JobStateDependent)
+					So(job.Dependencies.Stringify(), ShouldResemble, []string{"afternotok:cond_fail"})
 
-				Convey("You can then reserve and execute the only 4 jobs", func() {
-					for i := 0; i < 4; i++ {
-						job, err := jq.Reserve(50 * time.Millisecond)
-						So(err, ShouldBeNil)
-						err = jq.Execute(job, config.RunnerExecShell)
-						So(err, ShouldBeNil)
-					}
-					job, err := jq.Reserve(10 * time.Millisecond)
+					job, err = jq.Reserve(50 * time.Millisecond)
 					So(err, ShouldBeNil)
-					So(job, ShouldBeNil)
+					So(job.Cmd, ShouldEqual, "exit 1")
+					err = jq.Execute(job, config.RunnerExecShell)
+					So(err, ShouldNotBeNil)
+					So(job.State, ShouldEqual, JobStateBuried)
 
-					Convey("The jobs can be retrieved by either RepGroup and will have the expected RepGroup", func() {
-						jobs, err := jq.GetByRepGroup("rp1", false, 0, JobStateComplete, false, false)
+					job, err = jq.Reserve(50 * time.Millisecond)
+					So(err, ShouldBeNil)
+					So(job.Cmd, ShouldEqual, "echo ok")
+					err = jq.Execute(job, config.RunnerExecShell)
+					So(err, ShouldBeNil)
+					So(job.State, ShouldEqual, JobStateComplete)
+
+					job, err = jq.GetByEssence(&JobEssence{Cmd: "echo afternotok_fail"}, false, false)
+					So(err, ShouldBeNil)
+					So(job.State, ShouldEqual, JobStateReady)
+					job, err = jq.GetByEssence(&JobEssence{Cmd: "echo afterany_fail"}, false, false)
+					So(err, ShouldBeNil)
+					So(job.State, ShouldEqual, JobStateReady)
+					job, err = jq.GetByEssence(&JobEssence{Cmd: "echo afterok_fail"}, false, false)
+					So(err, ShouldBeNil)
+					So(job.State, ShouldEqual, JobStateDependent)
+					job, err = jq.GetByEssence(&JobEssence{Cmd: "echo afternotok_ok"}, false, false)
+					So(err, ShouldBeNil)
+					So(job.State, ShouldEqual, JobStateBuried)
+					So(job.FailReason, ShouldEqual, FailReasonDeps)
+
+					Convey("Adding an afternotok dependency on a completed job buries it immediately", func() {
+						dep := NewEssenceDependency("echo ok", "")
+						dep.Type = DepAfterNotOK
+						jobs = nil
+						jobs = append(jobs, &Job{Cmd: "echo late_afternotok", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "cond_deps", Dependencies: Dependencies{dep}})
+						inserts, _, err = jq.Add(jobs, envVars, true)
 						So(err, ShouldBeNil)
-						So(len(jobs), ShouldEqual, 3)
-						So(jobs[0].RepGroup, ShouldEqual, "rp1")
+						So(inserts, ShouldEqual, 1)
 
-						jobs, err = jq.GetByRepGroup("rp2", false, 0, JobStateComplete, false, false)
+						job, err = jq.GetByEssence(&JobEssence{Cmd: "echo late_afternotok"}, false, false)
 						So(err, ShouldBeNil)
-						So(len(jobs), ShouldEqual, 4)
-						So(jobs[0].RepGroup, ShouldEqual, "rp2")
+						So(job.State, ShouldEqual, JobStateBuried)
+						So(job.FailReason, ShouldEqual, FailReasonDeps)
 					})
-				})
-			})
-		})
 
-		Convey("After connecting and adding some jobs under some RepGroups", func() {
-			jq, err := Connect(addr, config.ManagerCAFile, config.ManagerCertDomain, token, clientConnectTime)
-			So(err, ShouldBeNil)
-			defer jq.Disconnect()
-
-			var jobs []*Job
-			jobs = append(jobs, &Job{Cmd: "echo deptest1", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "dep1"})
-			jobs = append(jobs, &Job{Cmd: "echo deptest2", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "dep2"})
-			jobs = append(jobs, &Job{Cmd: "echo deptest3", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "dep3"})
-			inserts, already, err := jq.Add(jobs, envVars, true)
-			So(err, ShouldBeNil)
-			So(inserts, ShouldEqual, 3)
-			So(already, ShouldEqual, 0)
+					Convey("Invalid dependency types are rejected", func() {
+						jvj := &JobViaJSON{Cmd: "echo bad_type", CmdDeps: Dependencies{{Essence: &JobEssence{Cmd: "echo ok"}, Type: "afterwards"}}}
+						_, err = jvj.Convert(&JobDefaults{})
+						So(err, ShouldNotBeNil)
+					})
+				})
 
-			Convey("You can search for the jobs using a common substring of their repgroups", func() {
-				gottenJobs, err := jq.GetByRepGroup("dep", true, 0, "", false, false)
-				So(err, ShouldBeNil)
-				So(len(gottenJobs), ShouldEqual, 3)
-				gottenJobs, err = jq.GetByRepGroup("2", true, 0, "", false, false)
-				So(err, ShouldBeNil)
-				So(len(gottenJobs), ShouldEqual, 1)
-			})
+				Convey("Jobs that exit 0 without creating their declared outputs are buried", func() {
+					jobs = nil
+					cwd, err := ioutil.TempDir("", "wr_jobqueue_test_runner_dir_")
+					So(err, ShouldBeNil)
+					defer os.RemoveAll(cwd)
+					bs := Behaviours{&Behaviour{When: OnExit, Do: Cleanup}}
+					jobs = append(jobs, &Job{Cmd: "touch foo bar", Cwd: cwd, ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "outputs", Outputs: []string{"foo"}, Behaviours: bs})
+					jobs = append(jobs, &Job{Cmd: "touch foo", Cwd: cwd, ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "outputs", Outputs: []string{"foo", "bar"}, Behaviours: bs})
+					inserts, _, err := jq.Add(jobs, envVars, true)
+					So(err, ShouldBeNil)
+					So(inserts, ShouldEqual, 2)
 
-			Convey("You can reserve and execute one of them", func() {
-				j1, err := jq.Reserve(50 * time.Millisecond)
-				So(err, ShouldBeNil)
-				So(j1.RepGroup, ShouldEqual, "dep1")
-				err = jq.Execute(j1, config.RunnerExecShell)
-				So(err, ShouldBeNil)
+					job, err := jq.Reserve(50 * time.Millisecond)
+					So(err, ShouldBeNil)
+					So(job.Cmd, ShouldEqual, "touch foo bar")
+					err = jq.Execute(job, config.RunnerExecShell)
+					So(err, ShouldBeNil)
+					So(job.State, ShouldEqual, JobStateComplete)
+					_, err = os.Stat(filepath.Join(job.ActualCwd, "foo"))
+					So(err, ShouldBeNil)
+					_, err = os.Stat(filepath.Join(job.ActualCwd, "bar"))
+					So(err, ShouldNotBeNil)
 
-				<-time.After(6 * time.Millisecond)
+					job, err = jq.Reserve(50 * time.Millisecond)
+					So(err, ShouldBeNil)
+					So(job.Cmd, ShouldEqual, "touch foo")
+					err = jq.Execute(job, config.RunnerExecShell)
+					So(err, ShouldNotBeNil)
+					So(job.State, ShouldEqual, JobStateBuried)
+					So(job.Exitcode, ShouldEqual, 0)
+					So(job.FailReason, ShouldEqual, FailReasonOutput)
 
-				gottenJobs, err := jq.GetByRepGroup("dep1", false, 0, "", false, false)
-				So(err, ShouldBeNil)
-				So(len(gottenJobs), ShouldEqual, 1)
-				So(gottenJobs[0].State, ShouldEqual, JobStateComplete)
+					job, err = jq.GetByEssence(&JobEssence{Cmd: "touch foo"}, false, false)
+					So(err, ShouldBeNil)
+					So(job.State, ShouldEqual, JobStateBuried)
+					So(job.FailReason, ShouldEqual, FailReasonOutput)
+					So(job.Outputs, ShouldResemble, []string{"foo", "bar"})
+				})
 
-				Convey("You can then add jobs dependent on the initial jobs and themselves", func() {
-					// https://i-msdn.sec.s-msft.com/dynimg/IC332764.gif
+				Convey("Jobs whose outputs are newer than their inputs are skipped, but still satisfy dependencies", func() {
 					jobs = nil
-					d1 := NewEssenceDependency("echo deptest1", "")
-					d2 := NewEssenceDependency("echo deptest2", "")
-					d3 := NewEssenceDependency("echo deptest3", "")
-					jobs = append(jobs, &Job{Cmd: "echo deptest4", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "dep4", Dependencies: Dependencies{d1}})
-					d4 := NewEssenceDependency("echo deptest4", "")
-					jobs = append(jobs, &Job{Cmd: "echo deptest5", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "dep5", Dependencies: Dependencies{d1, d2, d3}})
-					d5 := NewEssenceDependency("echo deptest5", "")
-					jobs = append(jobs, &Job{Cmd: "echo deptest6", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "dep6", Dependencies: Dependencies{d3, d4}})
-					d6 := NewEssenceDependency("echo deptest6", "")
-					jobs = append(jobs, &Job{Cmd: "echo deptest7", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "dep7", Dependencies: Dependencies{d5, d6}})
-					jobs = append(jobs, &Job{Cmd: "echo deptest8", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "dep8", Dependencies: Dependencies{d5}})
+					cwd, err := ioutil.TempDir("", "wr_jobqueue_test_runner_dir_")
+					So(err, ShouldBeNil)
+					defer os.RemoveAll(cwd)
+					input := filepath.Join(cwd, "in")
+					output := filepath.Join(cwd, "out")
+					err = ioutil.WriteFile(input, []byte("in\n"), 0600)
+					So(err, ShouldBeNil)
+					err = ioutil.WriteFile(output, []byte("out\n"), 0600)
+					So(err, ShouldBeNil)
+					past := time.Now().Add(-1 * time.Hour)
+					err = os.Chtimes(input, past, past)
+					So(err, ShouldBeNil)
 
+					cmd1 := "cat in > out"
+					cmd2 := "cat out > out2"
+					cmd3 := "cat in > out3"
+					jobs = append(jobs, &Job{Cmd: cmd1, Cwd: cwd, CwdMatters: true, ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "make", DepGroups: []string{"make1"}, Inputs: []string{"in"}, Outputs: []string{"out"}})
+					jobs = append(jobs, &Job{Cmd: cmd2, Cwd: cwd, CwdMatters: true, ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "make", Inputs: []string{"out"}, Outputs: []string{"out2"}, Dependencies: Dependencies{NewDepGroupDependency("make1"), NewEssenceDependency(cmd1, cwd)}})
+					jobs = append(jobs, &Job{Cmd: cmd3, Cwd: cwd, ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "make", Inputs: []string{"in"}, Outputs: []string{"out"}})
 					inserts, already, err := jq.Add(jobs, envVars, true)
 					So(err, ShouldBeNil)
-					So(inserts, ShouldEqual, 5)
-					So(already, ShouldEqual, 0)
+					So(inserts, ShouldEqual, 2)
+					So(already, ShouldEqual, 1)
 
-					// dep4 was added with a dependency on dep1, but after dep1
-					// was already completed; it should start off in the ready
-					// queue, not the dependent queue
-					gottenJobs, err = jq.GetByRepGroup("dep4", false, 0, "", false, false)
+					job, err := jq.GetByEssence(&JobEssence{Cmd: cmd1, Cwd: cwd}, false, false)
 					So(err, ShouldBeNil)
-					So(len(gottenJobs), ShouldEqual, 1)
-					So(gottenJobs[0].State, ShouldEqual, JobStateReady)
+					So(job, ShouldNotBeNil)
+					So(job.State, ShouldEqual, JobStateComplete)
+					So(job.Skipped, ShouldBeTrue)
 
-					Convey("They are then only reservable according to the dependency chain", func() {
-						j2, err := jq.Reserve(50 * time.Millisecond)
-						So(err, ShouldBeNil)
-						So(j2.RepGroup, ShouldEqual, "dep2")
-						j3, err := jq.Reserve(50 * time.Millisecond)
+					job, err = jq.GetByEssence(&JobEssence{Cmd: cmd2, Cwd: cwd}, false, false)
+					So(err, ShouldBeNil)
+					So(job, ShouldNotBeNil)
+					So(job.State, ShouldEqual, JobStateReady)
+					So(job.Skipped, ShouldBeFalse)
+
+					// relative outputs of jobs where cwd doesn't matter can't be
+					// checked before they run
+					job, err = jq.GetByEssence(&JobEssence{Cmd: cmd3}, false, false)
+					So(err, ShouldBeNil)
+					So(job, ShouldNotBeNil)
+					So(job.State, ShouldEqual, JobStateReady)
+
+					Convey("But not if you ask to rerun them", func() {
+						jobs = []*Job{{Cmd: "cat in > out && true", Cwd: cwd, CwdMatters: true, ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "make", Inputs: []string{"in"}, Outputs: []string{"out"}}}
+						inserts, already, err = jq.Add(jobs, envVars, false)
 						So(err, ShouldBeNil)
-						So(j3.RepGroup, ShouldEqual, "dep3")
-						j4, err := jq.Reserve(50 * time.Millisecond)
+						So(inserts, ShouldEqual, 1)
+						So(already, ShouldEqual, 0)
+					})
+				})
+
+				Convey("Jobs with up to date outputs are not skipped if they depend on jobs that will run", func() {
+					cwd, err := ioutil.TempDir("", "wr_jobqueue_test_runner_dir_")
+					So(err, ShouldBeNil)
+					defer os.RemoveAll(cwd)
+					for i, name := range []string{"mid", "final", "last", "in"} {
+						path := filepath.Join(cwd, name)
+						err = ioutil.WriteFile(path, []byte(name+"\n"), 0600)
 						So(err, ShouldBeNil)
-						So(j4.RepGroup, ShouldEqual, "dep4")
-						jNil, err := jq.Reserve(50 * time.Millisecond)
+						mtime := time.Now().Add(time.Duration(i-3) * time.Hour)
+						err = os.Chtimes(path, mtime, mtime)
 						So(err, ShouldBeNil)
-						So(jNil, ShouldBeNil)
+					}
 
-						// since things can take a while, we keep our reserved
-						// jobs touched
-						touchJ2 := true
-						touchJ3 := true
-						var touchLock sync.Mutex
-						go func() {
-							ticker := time.NewTicker(50 * time.Millisecond)
-							for range ticker.C {
-								touchLock.Lock()
-								if touchJ2 {
-									jq.Touch(j2)
-								}
-								if touchJ3 {
-									jq.Touch(j3)
-								}
-								if !touchJ2 && !touchJ3 {
-									ticker.Stop()
-									touchLock.Unlock()
-									return
-								}
-								touchLock.Unlock()
-								continue
-							}
-						}()
+					cmd1 := "cat in > mid"
+					cmd2 := "cat mid > final"
+					cmd3 := "cat final > last"
+					jobs = []*Job{
+						{Cmd: cmd1, Cwd: cwd, CwdMatters: true, ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "stale", Inputs: []string{"in"}, Outputs: []string{"mid"}},
+						{Cmd: cmd3, Cwd: cwd, CwdMatters: true, ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "stale", Inputs: []string{"final"}, Outputs: []string{"last"}, Dependencies: Dependencies{NewEssenceDependency(cmd2, cwd)}},
+						{Cmd: cmd2, Cwd: cwd, CwdMatters: true, ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "stale", Inputs: []string{"mid"}, Outputs: []string{"final"}, Dependencies: Dependencies{NewEssenceDependency(cmd1, cwd)}},
+					}
+					inserts, already, err := jq.Add(jobs, envVars, true)
+					So(err, ShouldBeNil)
+					So(inserts, ShouldEqual, 3)
+					So(already, ShouldEqual, 0)
 
-						gottenJobs, err = jq.GetByRepGroup("dep6", false, 0, "", false, false)
+					for _, cmd := range []string{cmd2, cmd3} {
+						job, err := jq.GetByEssence(&JobEssence{Cmd: cmd, Cwd: cwd}, false, false)
 						So(err, ShouldBeNil)
-						So(len(gottenJobs), ShouldEqual, 1)
-						So(gottenJobs[0].State, ShouldEqual, JobStateDependent)
+						So(job, ShouldNotBeNil)
+						So(job.State, ShouldEqual, JobStateDependent)
+						So(job.Skipped, ShouldBeFalse)
+					}
+				})
 
-						err = jq.Execute(j4, config.RunnerExecShell)
+				Convey("Jobs with Cache turned on reuse the results of identical completed jobs", func() {
+					cwd1, err := ioutil.TempDir("", "wr_jobqueue_test_runner_dir_")
+					So(err, ShouldBeNil)
+					defer os.RemoveAll(cwd1)
+					cwd2, err := ioutil.TempDir("", "wr_jobqueue_test_runner_dir_")
+					So(err, ShouldBeNil)
+					defer os.RemoveAll(cwd2)
+					for _, dir := range []string{cwd1, cwd2} {
+						err = ioutil.WriteFile(filepath.Join(dir, "in"), []byte("in\n"), 0600)
 						So(err, ShouldBeNil)
+					}
+					ranFile := filepath.Join(cwd1, "ran")
 
-						gottenJobs, err = jq.GetByRepGroup("dep6", false, 0, "", false, false)
-						So(err, ShouldBeNil)
-						So(len(gottenJobs), ShouldEqual, 1)
-						So(gottenJobs[0].State, ShouldEqual, JobStateDependent)
+					cmd := "cat in > out && echo ran >> " + ranFile
+					jobs = []*Job{{Cmd: cmd, Cwd: cwd1, CwdMatters: true, ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "cache", Inputs: []string{"in"}, Outputs: []string{"out"}, Cache: true}}
+					inserts, already, err := jq.Add(jobs, envVars, true)
+					So(err, ShouldBeNil)
+					So(inserts, ShouldEqual, 1)
+					So(already, ShouldEqual, 0)
 
-						touchLock.Lock()
-						touchJ3 = false
-						touchLock.Unlock()
-						err = jq.Execute(j3, config.RunnerExecShell)
-						So(err, ShouldBeNil)
+					job, err := jq.Reserve(50 * time.Millisecond)
+					So(err, ShouldBeNil)
+					So(job.Cmd, ShouldEqual, cmd)
+					err = jq.Execute(job, config.RunnerExecShell)
+					So(err, ShouldBeNil)
+					So(job.State, ShouldEqual, JobStateComplete)
+					So(job.CacheKey, ShouldNotBeBlank)
+					So(job.CachedFrom, ShouldBeBlank)
+					cachedKey := job.Key()
 
-						gottenJobs, err = jq.GetByRepGroup("dep6", false, 0, "", false, false)
-						So(err, ShouldBeNil)
-						So(len(gottenJobs), ShouldEqual, 1)
-						So(gottenJobs[0].State, ShouldEqual, JobStateReady)
+					jobs = []*Job{{Cmd: cmd, Cwd: cwd2, CwdMatters: true, ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "cache", Inputs: []string{"in"}, Outputs: []string{"out"}, Cache: true}}
+					inserts, already, err = jq.Add(jobs, envVars, true)
+					So(err, ShouldBeNil)
+					So(inserts, ShouldEqual, 1)
+					So(already, ShouldEqual, 0)
 
-						gottenJobs, err = jq.GetByRepGroup("dep5", false, 0, "", false, false)
-						So(err, ShouldBeNil)
-						So(len(gottenJobs), ShouldEqual, 1)
-						So(gottenJobs[0].State, ShouldEqual, JobStateDependent)
+					job, err = jq.Reserve(50 * time.Millisecond)
+					So(err, ShouldBeNil)
+					So(job.Cwd, ShouldEqual, cwd2)
+					err = jq.Execute(job, config.RunnerExecShell)
+					So(err, ShouldBeNil)
+					So(job.State, ShouldEqual, JobStateComplete)
 
-						touchLock.Lock()
-						touchJ2 = false
-						touchLock.Unlock()
-						err = jq.Execute(j2, config.RunnerExecShell)
+					job, err = jq.GetByEssence(&JobEssence{Cmd: cmd, Cwd: cwd2}, false, false)
+					So(err, ShouldBeNil)
+					So(job, ShouldNotBeNil)
+					So(job.State, ShouldEqual, JobStateComplete)
+					So(job.CachedFrom, ShouldEqual, cachedKey)
+					content, err := ioutil.ReadFile(filepath.Join(cwd2, "out"))
+					So(err, ShouldBeNil)
+					So(string(content), ShouldEqual, "in\n")
+					content, err = ioutil.ReadFile(ranFile)
+					So(err, ShouldBeNil)
+					So(string(content), ShouldEqual, "ran\n")
+
+					Convey("But not if the inputs differ", func() {
+						cwd3, err := ioutil.TempDir("", "wr_jobqueue_test_runner_dir_")
+						So(err, ShouldBeNil)
+						defer os.RemoveAll(cwd3)
+						err = ioutil.WriteFile(filepath.Join(cwd3, "in"), []byte("different\n"), 0600)
 						So(err, ShouldBeNil)
 
-						gottenJobs, err = jq.GetByRepGroup("dep5", false, 0, "", false, false)
+						jobs = []*Job{{Cmd: cmd, Cwd: cwd3, CwdMatters: true, ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "cache", Inputs: []string{"in"}, Outputs: []string{"out"}, Cache: true}}
+						inserts, already, err = jq.Add(jobs, envVars, true)
 						So(err, ShouldBeNil)
-						So(len(gottenJobs), ShouldEqual, 1)
-						So(gottenJobs[0].State, ShouldEqual, JobStateReady)
+						So(inserts, ShouldEqual, 1)
+						So(already, ShouldEqual, 0)
 
-						j5, err := jq.Reserve(50 * time.Millisecond)
+						job, err = jq.Reserve(50 * time.Millisecond)
 						So(err, ShouldBeNil)
-						So(j5.RepGroup, ShouldEqual, "dep5")
-						j6, err := jq.Reserve(50 * time.Millisecond)
+						err = jq.Execute(job, config.RunnerExecShell)
 						So(err, ShouldBeNil)
-						So(j6.RepGroup, ShouldEqual, "dep6")
-						jNil, err = jq.Reserve(50 * time.Millisecond)
+						So(job.State, ShouldEqual, JobStateComplete)
+						So(job.CachedFrom, ShouldBeBlank)
+						content, err = ioutil.ReadFile(filepath.Join(cwd3, "out"))
 						So(err, ShouldBeNil)
-						So(jNil, ShouldBeNil)
+						So(string(content), ShouldEqual, "different\n")
+						content, err = ioutil.ReadFile(ranFile)
+						So(err, ShouldBeNil)
+						So(string(content), ShouldEqual, "ran\nran\n")
+					})
+				})
 
-						touchJ6 := true
-						go func() {
-							ticker := time.NewTicker(50 * time.Millisecond)
-							for range ticker.C {
-								touchLock.Lock()
-								if touchJ6 {
-									jq.Touch(j6)
-								} else {
-									ticker.Stop()
-									touchLock.Unlock()
-									return
-								}
-								touchLock.Unlock()
-								continue
-							}
-						}()
+				Convey("CopyToManager behaviours copy files to the manager", func() {
+					jobs = nil
+					cwd, err := ioutil.TempDir("", "wr_jobqueue_test_runner_dir_")
+					So(err, ShouldBeNil)
+					defer os.RemoveAll(cwd)
+					b1 := &Behaviour{When: OnSuccess, Do: CopyToManager, Arg: []string{"bar", "sub/baz"}}
+					b2 := &Behaviour{When: OnExit, Do: CleanupAll}
+					bs := Behaviours{b1, b2}
+					cmd := "echo bar > bar && mkdir sub && echo baz > sub/baz"
+					jobs = append(jobs, &Job{Cmd: cmd, Cwd: cwd, ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "copy", Behaviours: bs})
+					inserts, _, err := jq.Add(jobs, envVars, true)
+					So(err, ShouldBeNil)
+					So(inserts, ShouldEqual, 1)
 
-						gottenJobs, err = jq.GetByRepGroup("dep8", false, 0, "", false, false)
-						So(err, ShouldBeNil)
-						So(len(gottenJobs), ShouldEqual, 1)
-						So(gottenJobs[0].State, ShouldEqual, JobStateDependent)
+					job, err := jq.Reserve(50 * time.Millisecond)
+					So(err, ShouldBeNil)
+					So(job.Cmd, ShouldEqual, cmd)
+					err = jq.Execute(job, config.RunnerExecShell)
+					So(err, ShouldBeNil)
+					So(job.State, ShouldEqual, JobStateComplete)
+					_, err = os.Stat(job.ActualCwd)
+					So(err, ShouldNotBeNil)
 
-						err = jq.Execute(j5, config.RunnerExecShell)
-						So(err, ShouldBeNil)
+					job, err = jq.GetByEssence(&JobEssence{Cmd: cmd}, false, false)
+					So(err, ShouldBeNil)
+					So(job, ShouldNotBeNil)
+					So(len(job.CopiedFiles), ShouldEqual, 2)
+					copyDir := filepath.Join(server.copyDir, job.Key())
+					defer os.RemoveAll(copyDir)
+					So(job.CopiedFiles[0], ShouldEqual, filepath.Join(copyDir, "bar"))
+					So(job.CopiedFiles[1], ShouldEqual, filepath.Join(copyDir, "sub", "baz"))
+					content, err := ioutil.ReadFile(job.CopiedFiles[1])
+					So(err, ShouldBeNil)
+					So(string(content), ShouldEqual, "baz\n")
 
-						gottenJobs, err = jq.GetByRepGroup("dep8", false, 0, "", false, false)
+					status := job.ToStatus()
+					So(status.CopiedFiles, ShouldResemble, job.CopiedFiles)
+
+					Convey("Copied files are deleted when a job is removed", func() {
+						cmd = "false"
+						jobs = []*Job{{Cmd: cmd, Cwd: cwd, ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "copy", Behaviours: Behaviours{{When: OnFailure, Do: CopyToManager, Arg: []string{"/etc/hosts"}}}}}
+						inserts, _, err = jq.Add(jobs, envVars, true)
 						So(err, ShouldBeNil)
-						So(len(gottenJobs), ShouldEqual, 1)
-						So(gottenJobs[0].State, ShouldEqual, JobStateReady)
+						So(inserts, ShouldEqual, 1)
 
-						gottenJobs, err = jq.GetByRepGroup("dep7", false, 0, "", false, false)
+						job, err = jq.Reserve(50 * time.Millisecond)
 						So(err, ShouldBeNil)
-						So(len(gottenJobs), ShouldEqual, 1)
-						So(gottenJobs[0].State, ShouldEqual, JobStateDependent)
+						So(job.Cmd, ShouldEqual, cmd)
+						err = jq.Execute(job, config.RunnerExecShell)
+						So(err, ShouldNotBeNil)
 
-						touchLock.Lock()
-						touchJ6 = false
-						touchLock.Unlock()
-						err = jq.Execute(j6, config.RunnerExecShell)
+						job, err = jq.GetByEssence(&JobEssence{Cmd: cmd}, false, false)
+						So(err, ShouldBeNil)
+						So(job.State, ShouldEqual, JobStateBuried)
+						copyDir = filepath.Join(server.copyDir, job.Key())
+						So(job.CopiedFiles, ShouldResemble, []string{filepath.Join(copyDir, "hosts")})
+						_, err = os.Stat(job.CopiedFiles[0])
 						So(err, ShouldBeNil)
 
-						gottenJobs, err = jq.GetByRepGroup("dep7", false, 0, "", false, false)
+						deleted, err := jq.Delete([]*JobEssence{{Cmd: cmd}})
 						So(err, ShouldBeNil)
-						So(len(gottenJobs), ShouldEqual, 1)
-						So(gottenJobs[0].State, ShouldEqual, JobStateReady)
+						So(deleted, ShouldEqual, 1)
+						_, err = os.Stat(copyDir)
+						So(err, ShouldNotBeNil)
 					})
 				})
 
-				Convey("You can add jobs with non-existent dependencies", func() {
-					// first get rid of the jobs added earlier
-					for i := 0; i < 2; i++ {
+				Convey("Jobs that take longer than the ttr can execute successfully, even if clienttouchinterval is > ttr", func() {
+					jobs = nil
+					cmd := "perl -e 'for (1..3) { sleep(1) }'"
+					jobs = append(jobs, &Job{Cmd: cmd, Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "should_pass"})
+					inserts, _, err := jq.Add(jobs, envVars, true)
+					So(err, ShouldBeNil)
+					So(inserts, ShouldEqual, 1)
+
+					job, err := jq.Reserve(50 * time.Millisecond)
+					So(err, ShouldBeNil)
+					So(job.Cmd, ShouldEqual, cmd)
+					So(job.State, ShouldEqual, JobStateReserved)
+
+					err = jq.Execute(job, config.RunnerExecShell)
+					So(err, ShouldBeNil)
+					So(job.State, ShouldEqual, JobStateComplete)
+					So(job.Exited, ShouldBeTrue)
+					So(job.Exitcode, ShouldEqual, 0)
+
+					job2, err := jq2.GetByEssence(&JobEssence{Cmd: cmd}, true, false)
+					So(err, ShouldBeNil)
+					So(job2, ShouldNotBeNil)
+					So(job2.State, ShouldEqual, JobStateComplete)
+
+					// same again, but we'll alter the clienttouchinterval to be > ttr
+					ClientTouchInterval = 500 * time.Millisecond
+					inserts, _, err = jq.Add(jobs, envVars, false)
+					So(err, ShouldBeNil)
+					So(inserts, ShouldEqual, 1)
+
+					job, err = jq.Reserve(50 * time.Millisecond)
+					So(err, ShouldBeNil)
+					So(job.Cmd, ShouldEqual, cmd)
+					So(job.State, ShouldEqual, JobStateReserved)
+
+					lostCh := make(chan bool)
+					go func() {
+						<-time.After(300 * time.Millisecond)
+						// after ttr but before first touch, it becomes lost
+						job2f, errf := jq2.GetByEssence(&JobEssence{Cmd: cmd}, true, false)
+						if errf != nil || job2f == nil || job2f.State != JobStateLost || job2f.FailReason != FailReasonLost || job2f.Exited {
+							lostCh <- false
+						}
+
+						<-time.After(250 * time.Millisecond)
+						// after the first touch, it becomes running again
+						job2f, errf = jq2.GetByEssence(&JobEssence{Cmd: cmd}, true, false)
+						if errf != nil || job2f == nil || job2f.State != JobStateRunning || job2f.FailReason != FailReasonLost || job2f.Exited {
+							lostCh <- false
+						}
+						lostCh <- true
+					}()
+
+					err = jq.Execute(job, config.RunnerExecShell)
+					So(err, ShouldBeNil)
+
+					job2, err = jq2.GetByEssence(&JobEssence{Cmd: cmd}, true, false)
+					So(err, ShouldBeNil)
+					So(job2, ShouldNotBeNil)
+
+					So(job2.State, ShouldEqual, JobStateComplete)
+					So(job2.Exited, ShouldBeTrue)
+					So(<-lostCh, ShouldBeTrue)
+				})
+			})
+		})
+
+		Convey("After connecting and adding some jobs under one RepGroup", func() {
+			jq, err := Connect(addr, config.ManagerCAFile, config.ManagerCertDomain, token, clientConnectTime)
+			So(err, ShouldBeNil)
+			defer jq.Disconnect()
+
+			var jobs []*Job
+			for i := 0; i < 3; i++ {
+				jobs = append(jobs, &Job{Cmd: fmt.Sprintf("echo rgduptest %d", i), Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "rp1"})
+			}
+			inserts, already, err := jq.Add(jobs, envVars, true)
+			So(err, ShouldBeNil)
+			So(inserts, ShouldEqual, 3)
+			So(already, ShouldEqual, 0)
+
+			Convey("You can reserve and execute those", func() {
+				for i := 0; i < 3; i++ {
+					job, err := jq.Reserve(50 * time.Millisecond)
+					So(err, ShouldBeNil)
+					err = jq.Execute(job, config.RunnerExecShell)
+					So(err, ShouldBeNil)
+				}
+
+				Convey("Then you can add dups and a new one under a new RepGroup and reserve/execute all of them", func() {
+					jobs = nil
+					for i := 0; i < 4; i++ {
+						jobs = append(jobs, &Job{Cmd: fmt.Sprintf("echo rgduptest %d", i), Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "rp2"})
+					}
+					inserts, already, err := jq.Add(jobs, envVars, false)
+					So(err, ShouldBeNil)
+					So(inserts, ShouldEqual, 4)
+					So(already, ShouldEqual, 0)
+
+					for i := 0; i < 4; i++ {
 						job, err := jq.Reserve(50 * time.Millisecond)
 						So(err, ShouldBeNil)
 						err = jq.Execute(job, config.RunnerExecShell)
 						So(err, ShouldBeNil)
 					}
 
-					jobs = nil
-					d5 := NewEssenceDependency("echo deptest5", "")
-					jobs = append(jobs, &Job{Cmd: "echo deptest4", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "dep4", Dependencies: Dependencies{d5}})
-					jobs = append(jobs, &Job{Cmd: "echo deptest5", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "dep5"})
+					Convey("The jobs can be retrieved by either RepGroup and will have the expected RepGroup", func() {
+						jobsg, err := jq.GetByRepGroup("rp1", false, 0, JobStateComplete, false, false)
+						So(err, ShouldBeNil)
+						So(len(jobsg), ShouldEqual, 3)
+						So(jobsg[0].RepGroup, ShouldEqual, "rp1")
 
-					inserts, already, err := jq.Add(jobs, envVars, true)
-					So(err, ShouldBeNil)
-					So(inserts, ShouldEqual, 2)
-					So(already, ShouldEqual, 0)
+						jobsg, err = jq.GetByRepGroup("rp2", false, 0, JobStateComplete, false, false)
+						So(err, ShouldBeNil)
+						So(len(jobsg), ShouldEqual, 4)
+						So(jobsg[0].RepGroup, ShouldEqual, "rp2")
+					})
+				})
 
-					j5, err := jq.Reserve(50 * time.Millisecond)
-					So(err, ShouldBeNil)
-					So(j5.RepGroup, ShouldEqual, "dep5")
-					jNil, err := jq.Reserve(50 * time.Millisecond)
+				Convey("Previously complete jobs are rejected when adding with ignoreComplete", func() {
+					inserts, already, err := jq.Add(jobs, envVars, true)
 					So(err, ShouldBeNil)
-					So(jNil, ShouldBeNil)
+					So(inserts, ShouldEqual, 0)
+					So(already, ShouldEqual, 3)
+				})
+			})
 
-					err = jq.Execute(j5, config.RunnerExecShell)
-					So(err, ShouldBeNil)
+			Convey("You can add dups and a new one under a new RepGroup", func() {
+				jobs = nil
+				for i := 0; i < 4; i++ {
+					jobs = append(jobs, &Job{Cmd: fmt.Sprintf("echo rgduptest %d", i), Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "rp2"})
+				}
+				inserts, already, err := jq.Add(jobs, envVars, false)
+				So(err, ShouldBeNil)
+				So(inserts, ShouldEqual, 1)
+				So(already, ShouldEqual, 3)
 
-					j4, err := jq.Reserve(50 * time.Millisecond)
+				Convey("You can then reserve and execute the only 4 jobs", func() {
+					for i := 0; i < 4; i++ {
+						job, err := jq.Reserve(50 * time.Millisecond)
+						So(err, ShouldBeNil)
+						err = jq.Execute(job, config.RunnerExecShell)
+						So(err, ShouldBeNil)
+					}
+					job, err := jq.Reserve(10 * time.Millisecond)
 					So(err, ShouldBeNil)
-					So(j4.RepGroup, ShouldEqual, "dep4")
+					So(job, ShouldBeNil)
 
-					jq.Release(j4, nil, "")
+					Convey("The jobs can be retrieved by either RepGroup and will have the expected RepGroup", func() {
+						jobs, err := jq.GetByRepGroup("rp1", false, 0, JobStateComplete, false, false)
+						So(err, ShouldBeNil)
+						So(len(jobs), ShouldEqual, 3)
+						So(jobs[0].RepGroup, ShouldEqual, "rp1")
 
-					// *** we should implement rejection of dependency cycles
-					// and test for that
+						jobs, err = jq.GetByRepGroup("rp2", false, 0, JobStateComplete, false, false)
+						So(err, ShouldBeNil)
+						So(len(jobs), ShouldEqual, 4)
+						So(jobs[0].RepGroup, ShouldEqual, "rp2")
+					})
 				})
 			})
 		})
 
-		Convey("After connecting you can add some jobs with DepGroups", func() {
+		Convey("After connecting and adding some jobs under some RepGroups", func() {
 			jq, err := Connect(addr, config.ManagerCAFile, config.ManagerCertDomain, token, clientConnectTime)
 			So(err, ShouldBeNil)
 			defer jq.Disconnect()
 
 			var jobs []*Job
-			jobs = append(jobs, &Job{Cmd: "echo deptest1", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "dep1", DepGroups: []string{"dep1", "dep1+2+3"}})
-			jobs = append(jobs, &Job{Cmd: "echo deptest2", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "dep2", DepGroups: []string{"dep2", "dep1+2+3"}})
-			jobs = append(jobs, &Job{Cmd: "echo deptest3", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "dep3", DepGroups: []string{"dep3", "dep1+2+3"}})
+			jobs = append(jobs, &Job{Cmd: "echo deptest1", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "dep1"})
+			jobs = append(jobs, &Job{Cmd: "echo deptest2", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "dep2"})
+			jobs = append(jobs, &Job{Cmd: "echo deptest3", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "dep3"})
 			inserts, already, err := jq.Add(jobs, envVars, true)
 			So(err, ShouldBeNil)
 			So(inserts, ShouldEqual, 3)
 			So(already, ShouldEqual, 0)
 
+			Convey("You can search for the jobs using a common substring of their repgroups", func() {
+				gottenJobs, err := jq.GetByRepGroup("dep", true, 0, "", false, false)
+				So(err, ShouldBeNil)
+				So(len(gottenJobs), ShouldEqual, 3)
+				gottenJobs, err = jq.GetByRepGroup("2", true, 0, "", false, false)
+				So(err, ShouldBeNil)
+				So(len(gottenJobs), ShouldEqual, 1)
+			})
+
 			Convey("You can reserve and execute one of them", func() {
 				j1, err := jq.Reserve(50 * time.Millisecond)
 				So(err, ShouldBeNil)
@@ -2461,16 +2976,250 @@ func TestJobqueueMedium(t *testing.T) {
 				Convey("You can then add jobs dependent on the initial jobs and themselves", func() {
 					// https://i-msdn.sec.s-msft.com/dynimg/IC332764.gif
 					jobs = nil
-					d1 := NewDepGroupDependency("dep1")
-					d123 := NewDepGroupDependency("dep1+2+3")
-					d3 := NewDepGroupDependency("dep3")
-					jobs = append(jobs, &Job{Cmd: "echo deptest4", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "dep4", DepGroups: []string{"dep4"}, Dependencies: Dependencies{d1}})
-					d4 := NewDepGroupDependency("dep4")
-					jobs = append(jobs, &Job{Cmd: "echo deptest5", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "dep5", DepGroups: []string{"dep5"}, Dependencies: Dependencies{d123}})
-					d5 := NewDepGroupDependency("dep5")
-					jobs = append(jobs, &Job{Cmd: "echo deptest6", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "dep6", DepGroups: []string{"dep6"}, Dependencies: Dependencies{d3, d4}})
-					d6 := NewDepGroupDependency("dep6")
-					jobs = append(jobs, &Job{Cmd: "echo deptest7", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "dep7", DepGroups: []string{"final"}, Dependencies: Dependencies{d5, d6}})
+					d1 := NewEssenceDependency("echo deptest1", "")
+					d2 := NewEssenceDependency("echo deptest2", "")
+					d3 := NewEssenceDependency("echo deptest3", "")
+					jobs = append(jobs, &Job{Cmd: "echo deptest4", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "dep4", Dependencies: Dependencies{d1}})
+					d4 := NewEssenceDependency("echo deptest4", "")
+					jobs = append(jobs, &Job{Cmd: "echo deptest5", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "dep5", Dependencies: Dependencies{d1, d2, d3}})
+					d5 := NewEssenceDependency("echo deptest5", "")
+					jobs = append(jobs, &Job{Cmd: "echo deptest6", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "dep6", Dependencies: Dependencies{d3, d4}})
+					d6 := NewEssenceDependency("echo deptest6", "")
+					jobs = append(jobs, &Job{Cmd: "echo deptest7", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "dep7", Dependencies: Dependencies{d5, d6}})
+					jobs = append(jobs, &Job{Cmd: "echo deptest8", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "dep8", Dependencies: Dependencies{d5}})
+
+					inserts, already, err := jq.Add(jobs, envVars, true)
+					So(err, ShouldBeNil)
+					So(inserts, ShouldEqual, 5)
+					So(already, ShouldEqual, 0)
+
+					// dep4 was added with a dependency on dep1, but after dep1
+					// was already completed; it should start off in the ready
+					// queue, not the dependent queue
+					gottenJobs, err = jq.GetByRepGroup("dep4", false, 0, "", false, false)
+					So(err, ShouldBeNil)
+					So(len(gottenJobs), ShouldEqual, 1)
+					So(gottenJobs[0].State, ShouldEqual, JobStateReady)
+
+					Convey("They are then only reservable according to the dependency chain", func() {
+						j2, err := jq.Reserve(50 * time.Millisecond)
+						So(err, ShouldBeNil)
+						So(j2.RepGroup, ShouldEqual, "dep2")
+						j3, err := jq.Reserve(50 * time.Millisecond)
+						So(err, ShouldBeNil)
+						So(j3.RepGroup, ShouldEqual, "dep3")
+						j4, err := jq.Reserve(50 * time.Millisecond)
+						So(err, ShouldBeNil)
+						So(j4.RepGroup, ShouldEqual, "dep4")
+						jNil, err := jq.Reserve(50 * time.Millisecond)
+						So(err, ShouldBeNil)
+						So(jNil, ShouldBeNil)
+
+						// since things can take a while, we keep our reserved
+						// jobs touched
+						touchJ2 := true
+						touchJ3 := true
+						var touchLock sync.Mutex
+						go func() {
+							ticker := time.NewTicker(50 * time.Millisecond)
+							for range ticker.C {
+								touchLock.Lock()
+								if touchJ2 {
+									jq.Touch(j2)
+								}
+								if touchJ3 {
+									jq.Touch(j3)
+								}
+								if !touchJ2 && !touchJ3 {
+									ticker.Stop()
+									touchLock.Unlock()
+									return
+								}
+								touchLock.Unlock()
+								continue
+							}
+						}()
+
+						gottenJobs, err = jq.GetByRepGroup("dep6", false, 0, "", false, false)
+						So(err, ShouldBeNil)
+						So(len(gottenJobs), ShouldEqual, 1)
+						So(gottenJobs[0].State, ShouldEqual, JobStateDependent)
+
+						err = jq.Execute(j4, config.RunnerExecShell)
+						So(err, ShouldBeNil)
+
+						gottenJobs, err = jq.GetByRepGroup("dep6", false, 0, "", false, false)
+						So(err, ShouldBeNil)
+						So(len(gottenJobs), ShouldEqual, 1)
+						So(gottenJobs[0].State, ShouldEqual, JobStateDependent)
+
+						touchLock.Lock()
+						touchJ3 = false
+						touchLock.Unlock()
+						err = jq.Execute(j3, config.RunnerExecShell)
+						So(err, ShouldBeNil)
+
+						gottenJobs, err = jq.GetByRepGroup("dep6", false, 0, "", false, false)
+						So(err, ShouldBeNil)
+						So(len(gottenJobs), ShouldEqual, 1)
+						So(gottenJobs[0].State, ShouldEqual, JobStateReady)
+
+						gottenJobs, err = jq.GetByRepGroup("dep5", false, 0, "", false, false)
+						So(err, ShouldBeNil)
+						So(len(gottenJobs), ShouldEqual, 1)
+						So(gottenJobs[0].State, ShouldEqual, JobStateDependent)
+
+						touchLock.Lock()
+						touchJ2 = false
+						touchLock.Unlock()
+						err = jq.Execute(j2, config.RunnerExecShell)
+						So(err, ShouldBeNil)
+
+						gottenJobs, err = jq.GetByRepGroup("dep5", false, 0, "", false, false)
+						So(err, ShouldBeNil)
+						So(len(gottenJobs), ShouldEqual, 1)
+						So(gottenJobs[0].State, ShouldEqual, JobStateReady)
+
+						j5, err := jq.Reserve(50 * time.Millisecond)
+						So(err, ShouldBeNil)
+						So(j5.RepGroup, ShouldEqual, "dep5")
+						j6, err := jq.Reserve(50 * time.Millisecond)
+						So(err, ShouldBeNil)
+						So(j6.RepGroup, ShouldEqual, "dep6")
+						jNil, err = jq.Reserve(50 * time.Millisecond)
+						So(err, ShouldBeNil)
+						So(jNil, ShouldBeNil)
+
+						touchJ6 := true
+						go func() {
+							ticker := time.NewTicker(50 * time.Millisecond)
+							for range ticker.C {
+								touchLock.Lock()
+								if touchJ6 {
+									jq.Touch(j6)
+								} else {
+									ticker.Stop()
+									touchLock.Unlock()
+									return
+								}
+								touchLock.Unlock()
+								continue
+							}
+						}()
+
+						gottenJobs, err = jq.GetByRepGroup("dep8", false, 0, "", false, false)
+						So(err, ShouldBeNil)
+						So(len(gottenJobs), ShouldEqual, 1)
+						So(gottenJobs[0].State, ShouldEqual, JobStateDependent)
+
+						err = jq.Execute(j5, config.RunnerExecShell)
+						So(err, ShouldBeNil)
+
+						gottenJobs, err = jq.GetByRepGroup("dep8", false, 0, "", false, false)
+						So(err, ShouldBeNil)
+						So(len(gottenJobs), ShouldEqual, 1)
+						So(gottenJobs[0].State, ShouldEqual, JobStateReady)
+
+						gottenJobs, err = jq.GetByRepGroup("dep7", false, 0, "", false, false)
+						So(err, ShouldBeNil)
+						So(len(gottenJobs), ShouldEqual, 1)
+						So(gottenJobs[0].State, ShouldEqual, JobStateDependent)
+
+						touchLock.Lock()
+						touchJ6 = false
+						touchLock.Unlock()
+						err = jq.Execute(j6, config.RunnerExecShell)
+						So(err, ShouldBeNil)
+
+						gottenJobs, err = jq.GetByRepGroup("dep7", false, 0, "", false, false)
+						So(err, ShouldBeNil)
+						So(len(gottenJobs), ShouldEqual, 1)
+						So(gottenJobs[0].State, ShouldEqual, JobStateReady)
+					})
+				})
+
+				Convey("You can add jobs with non-existent dependencies", func() {
+					// first get rid of the jobs added earlier
+					for i := 0; i < 2; i++ {
+						job, err := jq.Reserve(50 * time.Millisecond)
+						So(err, ShouldBeNil)
+						err = jq.Execute(job, config.RunnerExecShell)
+						So(err, ShouldBeNil)
+					}
+
+					jobs = nil
+					d5 := NewEssenceDependency("echo deptest5", "")
+					jobs = append(jobs, &Job{Cmd: "echo deptest4", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "dep4", Dependencies: Dependencies{d5}})
+					jobs = append(jobs, &Job{Cmd: "echo deptest5", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "dep5"})
+
+					inserts, already, err := jq.Add(jobs, envVars, true)
+					So(err, ShouldBeNil)
+					So(inserts, ShouldEqual, 2)
+					So(already, ShouldEqual, 0)
+
+					j5, err := jq.Reserve(50 * time.Millisecond)
+					So(err, ShouldBeNil)
+					So(j5.RepGroup, ShouldEqual, "dep5")
+					jNil, err := jq.Reserve(50 * time.Millisecond)
+					So(err, ShouldBeNil)
+					So(jNil, ShouldBeNil)
+
+					err = jq.Execute(j5, config.RunnerExecShell)
+					So(err, ShouldBeNil)
+
+					j4, err := jq.Reserve(50 * time.Millisecond)
+					So(err, ShouldBeNil)
+					So(j4.RepGroup, ShouldEqual, "dep4")
+
+					jq.Release(j4, nil, "")
+
+					// *** we should implement rejection of dependency cycles
+					// and test for that
+				})
+			})
+		})
+
+		Convey("After connecting you can add some jobs with DepGroups", func() {
+			jq, err := Connect(addr, config.ManagerCAFile, config.ManagerCertDomain, token, clientConnectTime)
+			So(err, ShouldBeNil)
+			defer jq.Disconnect()
+
+			var jobs []*Job
+			jobs = append(jobs, &Job{Cmd: "echo deptest1", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "dep1", DepGroups: []string{"dep1", "dep1+2+3"}})
+			jobs = append(jobs, &Job{Cmd: "echo deptest2", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "dep2", DepGroups: []string{"dep2", "dep1+2+3"}})
+			jobs = append(jobs, &Job{Cmd: "echo deptest3", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "dep3", DepGroups: []string{"dep3", "dep1+2+3"}})
+			inserts, already, err := jq.Add(jobs, envVars, true)
+			So(err, ShouldBeNil)
+			So(inserts, ShouldEqual, 3)
+			So(already, ShouldEqual, 0)
+
+			Convey("You can reserve and execute one of them", func() {
+				j1, err := jq.Reserve(50 * time.Millisecond)
+				So(err, ShouldBeNil)
+				So(j1.RepGroup, ShouldEqual, "dep1")
+				err = jq.Execute(j1, config.RunnerExecShell)
+				So(err, ShouldBeNil)
+
+				<-time.After(6 * time.Millisecond)
+
+				gottenJobs, err := jq.GetByRepGroup("dep1", false, 0, "", false, false)
+				So(err, ShouldBeNil)
+				So(len(gottenJobs), ShouldEqual, 1)
+				So(gottenJobs[0].State, ShouldEqual, JobStateComplete)
+
+				Convey("You can then add jobs dependent on the initial jobs and themselves", func() {
+					// https://i-msdn.sec.s-msft.com/dynimg/IC332764.gif
+					jobs = nil
+					d1 := NewDepGroupDependency("dep1")
+					d123 := NewDepGroupDependency("dep1+2+3")
+					d3 := NewDepGroupDependency("dep3")
+					jobs = append(jobs, &Job{Cmd: "echo deptest4", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "dep4", DepGroups: []string{"dep4"}, Dependencies: Dependencies{d1}})
+					d4 := NewDepGroupDependency("dep4")
+					jobs = append(jobs, &Job{Cmd: "echo deptest5", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "dep5", DepGroups: []string{"dep5"}, Dependencies: Dependencies{d123}})
+					d5 := NewDepGroupDependency("dep5")
+					jobs = append(jobs, &Job{Cmd: "echo deptest6", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "dep6", DepGroups: []string{"dep6"}, Dependencies: Dependencies{d3, d4}})
+					d6 := NewDepGroupDependency("dep6")
+					jobs = append(jobs, &Job{Cmd: "echo deptest7", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "dep7", DepGroups: []string{"final"}, Dependencies: Dependencies{d5, d6}})
 					jobs = append(jobs, &Job{Cmd: "echo deptest8", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "dep8", DepGroups: []string{"final"}, Dependencies: Dependencies{d5}})
 
 					inserts, already, err := jq.Add(jobs, envVars, true)
@@ -2653,166 +3402,1799 @@ func TestJobqueueMedium(t *testing.T) {
 							err = jq.Execute(j8, config.RunnerExecShell)
 							So(err, ShouldBeNil)
 
-							gottenJobs, err = jq.GetByRepGroup("afterfinal", false, 0, "", false, false)
-							So(err, ShouldBeNil)
-							So(len(gottenJobs), ShouldEqual, 1)
-							So(gottenJobs[0].State, ShouldEqual, JobStateReady)
+							gottenJobs, err = jq.GetByRepGroup("afterfinal", false, 0, "", false, false)
+							So(err, ShouldBeNil)
+							So(len(gottenJobs), ShouldEqual, 1)
+							So(gottenJobs[0].State, ShouldEqual, JobStateReady)
+
+							jobs = nil
+							jobs = append(jobs, &Job{Cmd: "echo deptest9", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "dep9", DepGroups: []string{"final"}})
+							inserts, already, err = jq.Add(jobs, envVars, true)
+							So(err, ShouldBeNil)
+							So(inserts, ShouldEqual, 1)
+							So(already, ShouldEqual, 0)
+
+							gottenJobs, err = jq.GetByRepGroup("afterfinal", false, 0, "", false, false)
+							So(err, ShouldBeNil)
+							So(len(gottenJobs), ShouldEqual, 1)
+							So(gottenJobs[0].State, ShouldEqual, JobStateDependent)
+
+							j9, err := jq.Reserve(50 * time.Millisecond)
+							So(err, ShouldBeNil)
+							So(j9.RepGroup, ShouldEqual, "dep9")
+							err = jq.Execute(j9, config.RunnerExecShell)
+							So(err, ShouldBeNil)
+
+							gottenJobs, err = jq.GetByRepGroup("afterfinal", false, 0, "", false, false)
+							So(err, ShouldBeNil)
+							So(len(gottenJobs), ShouldEqual, 1)
+							So(gottenJobs[0].State, ShouldEqual, JobStateReady)
+
+							faf, err := jq.Reserve(50 * time.Millisecond)
+							So(err, ShouldBeNil)
+							So(faf.RepGroup, ShouldEqual, "afterfinal")
+							err = jq.Execute(faf, config.RunnerExecShell)
+							So(err, ShouldBeNil)
+
+							gottenJobs, err = jq.GetByRepGroup("afterfinal", false, 0, "", false, false)
+							So(err, ShouldBeNil)
+							So(len(gottenJobs), ShouldEqual, 1)
+							So(gottenJobs[0].State, ShouldEqual, JobStateComplete)
+
+							gottenJobs, err = jq.GetByRepGroup("after-afterfinal", false, 0, "", false, false)
+							So(err, ShouldBeNil)
+							So(len(gottenJobs), ShouldEqual, 1)
+							So(gottenJobs[0].State, ShouldEqual, JobStateReady)
+
+							inserts, already, err = jq.Add(jobs, envVars, false)
+							So(err, ShouldBeNil)
+							So(inserts, ShouldEqual, 2) // the job I added, and the resurrected afterfinal job
+							So(already, ShouldEqual, 0)
+
+							gottenJobs, err = jq.GetByRepGroup("afterfinal", false, 0, "", false, false)
+							So(err, ShouldBeNil)
+							So(len(gottenJobs), ShouldEqual, 1)
+							So(gottenJobs[0].State, ShouldEqual, JobStateDependent)
+
+							gottenJobs, err = jq.GetByRepGroup("after-afterfinal", false, 0, "", false, false)
+							So(err, ShouldBeNil)
+							So(len(gottenJobs), ShouldEqual, 1)
+							So(gottenJobs[0].State, ShouldEqual, JobStateDependent)
+
+							j9, err = jq.Reserve(50 * time.Millisecond)
+							So(err, ShouldBeNil)
+							So(j9.RepGroup, ShouldEqual, "dep9")
+							err = jq.Execute(j9, config.RunnerExecShell)
+							So(err, ShouldBeNil)
+
+							gottenJobs, err = jq.GetByRepGroup("afterfinal", false, 0, "", false, false)
+							So(err, ShouldBeNil)
+							So(len(gottenJobs), ShouldEqual, 1)
+							So(gottenJobs[0].State, ShouldEqual, JobStateReady)
+
+							faf, err = jq.Reserve(50 * time.Millisecond)
+							So(err, ShouldBeNil)
+							So(faf.RepGroup, ShouldEqual, "afterfinal")
+							err = jq.Execute(faf, config.RunnerExecShell)
+							So(err, ShouldBeNil)
+
+							gottenJobs, err = jq.GetByRepGroup("afterfinal", false, 0, "", false, false)
+							So(err, ShouldBeNil)
+							So(len(gottenJobs), ShouldEqual, 1)
+							So(gottenJobs[0].State, ShouldEqual, JobStateComplete)
+
+							gottenJobs, err = jq.GetByRepGroup("after-afterfinal", false, 0, "", false, false)
+							So(err, ShouldBeNil)
+							So(len(gottenJobs), ShouldEqual, 1)
+							So(gottenJobs[0].State, ShouldEqual, JobStateReady)
+
+							faaf, err := jq.Reserve(50 * time.Millisecond)
+							So(err, ShouldBeNil)
+							So(faaf.RepGroup, ShouldEqual, "after-afterfinal")
+							err = jq.Execute(faaf, config.RunnerExecShell)
+							So(err, ShouldBeNil)
+
+							gottenJobs, err = jq.GetByRepGroup("after-afterfinal", false, 0, "", false, false)
+							So(err, ShouldBeNil)
+							So(len(gottenJobs), ShouldEqual, 1)
+							So(gottenJobs[0].State, ShouldEqual, JobStateComplete)
+
+							jobs = nil
+							jobs = append(jobs, &Job{Cmd: "echo deptest10", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "dep10", DepGroups: []string{"final"}})
+							inserts, already, err = jq.Add(jobs, envVars, true)
+							So(err, ShouldBeNil)
+							So(inserts, ShouldEqual, 3)
+							So(already, ShouldEqual, 0)
+
+							gottenJobs, err = jq.GetByRepGroup("afterfinal", false, 0, "", false, false)
+							So(err, ShouldBeNil)
+							So(len(gottenJobs), ShouldEqual, 1)
+							So(gottenJobs[0].State, ShouldEqual, JobStateDependent)
+
+							gottenJobs, err = jq.GetByRepGroup("after-afterfinal", false, 0, "", false, false)
+							So(err, ShouldBeNil)
+							So(len(gottenJobs), ShouldEqual, 1)
+							So(gottenJobs[0].State, ShouldEqual, JobStateDependent)
+						})
+					})
+				})
+
+				Convey("You can add jobs with non-existent depgroup dependencies", func() {
+					// first get rid of the jobs added earlier
+					for i := 0; i < 2; i++ {
+						job, err := jq.Reserve(50 * time.Millisecond)
+						So(err, ShouldBeNil)
+						err = jq.Execute(job, config.RunnerExecShell)
+						So(err, ShouldBeNil)
+					}
+
+					jobs = nil
+					d5 := NewDepGroupDependency("dep5")
+					jobs = append(jobs, &Job{Cmd: "echo deptest4", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "dep4", Dependencies: Dependencies{d5}})
+					jobs = append(jobs, &Job{Cmd: "echo deptest5", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "dep5", DepGroups: []string{"dep5"}})
+
+					inserts, already, err := jq.Add(jobs, envVars, true)
+					So(err, ShouldBeNil)
+					So(inserts, ShouldEqual, 2)
+					So(already, ShouldEqual, 0)
+
+					j5, err := jq.Reserve(50 * time.Millisecond)
+					So(err, ShouldBeNil)
+					So(j5.RepGroup, ShouldEqual, "dep5")
+					jNil, err := jq.Reserve(50 * time.Millisecond)
+					So(err, ShouldBeNil)
+					So(jNil, ShouldBeNil)
+
+					err = jq.Execute(j5, config.RunnerExecShell)
+					So(err, ShouldBeNil)
+
+					j4, err := jq.Reserve(50 * time.Millisecond)
+					So(err, ShouldBeNil)
+					So(j4.RepGroup, ShouldEqual, "dep4")
+
+					jq.Release(j4, nil, "")
+
+					// *** we should implement rejection of dependency cycles
+					// and test for that
+				})
+			})
+		})
+
+		Reset(func() {
+			server.Stop(true)
+		})
+	})
+}
+
+func TestJobqueueFairShare(t *testing.T) {
+	if runnermode || servermode {
+		return
+	}
+	config, serverConfig, addr, standardReqs, clientConnectTime := jobqueueTestInit(true)
+	serverConfig.FairShare = FairShareRepGroup
+	serverConfig.FairShareWeights = map[string]float64{"b": 2}
+
+	defer os.RemoveAll(filepath.Join(os.TempDir(), AppName+"_cwd"))
+
+	Convey("Once a new jobqueue server with fair-share scheduling is up", t, func() {
+		ServerItemTTR = 5 * time.Second
+		ClientTouchInterval = 2500 * time.Millisecond
+		server, _, token, errs := serve(serverConfig)
+		So(errs, ShouldBeNil)
+		defer func() {
+			server.Stop(true)
+		}()
+
+		jq, err := Connect(addr, config.ManagerCAFile, config.ManagerCertDomain, token, clientConnectTime)
+		So(err, ShouldBeNil)
+		defer jq.Disconnect()
+
+		shares, err := jq.GetFairShares()
+		So(err, ShouldBeNil)
+		So(len(shares), ShouldEqual, 0)
+
+		Convey("Jobs of report groups that have used less are reserved first", func() {
+			var jobs []*Job
+			for _, rg := range []string{"a", "a", "b", "b"} {
+				jobs = append(jobs, &Job{Cmd: fmt.Sprintf("sleep 0.1 && echo %d", len(jobs)), Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: rg})
+			}
+			inserts, _, err := jq.Add(jobs, envVars, true)
+			So(err, ShouldBeNil)
+			So(inserts, ShouldEqual, 4)
+
+			job, err := jq.Reserve(50 * time.Millisecond)
+			So(err, ShouldBeNil)
+			So(job.RepGroup, ShouldEqual, "a")
+			err = jq.Execute(job, config.RunnerExecShell)
+			So(err, ShouldBeNil)
+
+			job, err = jq.Reserve(50 * time.Millisecond)
+			So(err, ShouldBeNil)
+			So(job.RepGroup, ShouldEqual, "b")
+
+			shares, err = jq.GetFairShares()
+			So(err, ShouldBeNil)
+			So(len(shares), ShouldEqual, 2)
+			So(shares[0].Party, ShouldEqual, "a")
+			So(shares[0].Usage, ShouldBeGreaterThan, 0)
+			So(shares[0].Share, ShouldEqual, 1)
+			So(shares[0].Weight, ShouldEqual, 1)
+			So(shares[0].Running, ShouldEqual, 0)
+			So(shares[1].Party, ShouldEqual, "b")
+			So(shares[1].Usage, ShouldEqual, 0)
+			So(shares[1].Weight, ShouldEqual, 2)
+			So(shares[1].Target, ShouldAlmostEqual, 2.0/3.0)
+			So(shares[1].Running, ShouldEqual, 1)
+
+			job, err = jq.Reserve(50 * time.Millisecond)
+			So(err, ShouldBeNil)
+			So(job.RepGroup, ShouldEqual, "b")
+		})
+	})
+}
+
+func TestJobqueuePriorityAging(t *testing.T) {
+	if runnermode || servermode {
+		return
+	}
+	config, serverConfig, addr, standardReqs, clientConnectTime := jobqueueTestInit(true)
+	serverConfig.PriorityAging = 100 * time.Millisecond
+	serverConfig.PriorityAgingMax = 5
+
+	defer os.RemoveAll(filepath.Join(os.TempDir(), AppName+"_cwd"))
+
+	Convey("Once a new jobqueue server with priority aging is up", t, func() {
+		ServerItemTTR = 5 * time.Second
+		ClientTouchInterval = 2500 * time.Millisecond
+		server, _, token, errs := serve(serverConfig)
+		So(errs, ShouldBeNil)
+		defer func() {
+			server.Stop(true)
+		}()
+
+		jq, err := Connect(addr, config.ManagerCAFile, config.ManagerCertDomain, token, clientConnectTime)
+		So(err, ShouldBeNil)
+		defer jq.Disconnect()
+
+		Convey("Jobs that have waited a long time are reserved ahead of newer higher priority jobs", func() {
+			inserts, _, err := jq.Add([]*Job{{Cmd: "echo low", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "aging"}}, envVars, true)
+			So(err, ShouldBeNil)
+			So(inserts, ShouldEqual, 1)
+			<-time.After(350 * time.Millisecond)
+
+			job, err := jq.GetByEssence(&JobEssence{Cmd: "echo low"}, false, false)
+			So(err, ShouldBeNil)
+			So(job.Priority, ShouldEqual, 0)
+			So(job.EffectivePriority, ShouldBeGreaterThanOrEqualTo, 3)
+			So(job.EffectivePriority, ShouldBeLessThanOrEqualTo, 5)
+			So(job.ToStatus().EffPriority, ShouldEqual, job.EffectivePriority)
+
+			inserts, _, err = jq.Add([]*Job{{Cmd: "echo high", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "aging", Priority: 2}}, envVars, true)
+			So(err, ShouldBeNil)
+			So(inserts, ShouldEqual, 1)
+
+			job, err = jq.Reserve(50 * time.Millisecond)
+			So(err, ShouldBeNil)
+			So(job.Cmd, ShouldEqual, "echo low")
+			So(job.EffectivePriority, ShouldEqual, 0)
+
+			job, err = jq.Reserve(50 * time.Millisecond)
+			So(err, ShouldBeNil)
+			So(job.Cmd, ShouldEqual, "echo high")
+		})
+	})
+}
+
+func TestJobqueueHold(t *testing.T) {
+	if runnermode || servermode {
+		return
+	}
+	config, serverConfig, addr, standardReqs, clientConnectTime := jobqueueTestInit(true)
+
+	defer os.RemoveAll(filepath.Join(os.TempDir(), AppName+"_cwd"))
+
+	Convey("Once a new jobqueue server is up and some jobs have been added, some held", t, func() {
+		ServerItemTTR = 5 * time.Second
+		ClientTouchInterval = 2500 * time.Millisecond
+		server, _, token, errs := serve(serverConfig)
+		So(errs, ShouldBeNil)
+		defer func() {
+			server.Stop(true)
+		}()
+
+		jq, err := Connect(addr, config.ManagerCAFile, config.ManagerCertDomain, token, clientConnectTime)
+		So(err, ShouldBeNil)
+		defer func() {
+			jq.Disconnect()
+		}()
+
+		jobs := []*Job{
+			{Cmd: "echo 1", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "hold"},
+			{Cmd: "echo 2", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "hold", Held: true},
+			{Cmd: "echo 3", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "hold", Dependencies: Dependencies{NewEssenceDependency("echo 1", "")}},
+		}
+		inserts, _, err := jq.Add(jobs, envVars, true)
+		So(err, ShouldBeNil)
+		So(inserts, ShouldEqual, 3)
+
+		getState := func(cmd string) JobState {
+			job, errg := jq.GetByEssence(&JobEssence{Cmd: cmd}, false, false)
+			So(errg, ShouldBeNil)
+			So(job, ShouldNotBeNil)
+			return job.State
+		}
+
+		So(getState("echo 1"), ShouldEqual, JobStateReady)
+		So(getState("echo 2"), ShouldEqual, JobStateHeld)
+		So(getState("echo 3"), ShouldEqual, JobStateDependent)
+
+		Convey("Held jobs are never reserved", func() {
+			job, err := jq.Reserve(50 * time.Millisecond)
+			So(err, ShouldBeNil)
+			So(job, ShouldNotBeNil)
+			So(job.Cmd, ShouldEqual, "echo 1")
+
+			job, err = jq.Reserve(50 * time.Millisecond)
+			So(err, ShouldBeNil)
+			So(job, ShouldBeNil)
+
+			held, err := jq.GetByRepGroup("hold", false, 0, JobStateHeld, false, false)
+			So(err, ShouldBeNil)
+			So(len(held), ShouldEqual, 1)
+			So(held[0].Held, ShouldBeTrue)
+		})
+
+		Convey("You can hold dependent jobs, which stay held once their dependencies complete", func() {
+			all, err := jq.GetByRepGroup("hold", false, 0, "", false, false)
+			So(err, ShouldBeNil)
+			So(len(all), ShouldEqual, 3)
+
+			held, err := jq.HoldJobs(jobsToJobEssenses(all))
+			So(err, ShouldBeNil)
+			So(held, ShouldEqual, 2)
+			So(getState("echo 1"), ShouldEqual, JobStateHeld)
+			So(getState("echo 3"), ShouldEqual, JobStateHeld)
+
+			job, err := jq.Reserve(50 * time.Millisecond)
+			So(err, ShouldBeNil)
+			So(job, ShouldBeNil)
+
+			released, err := jq.UnholdJobs([]*JobEssence{{Cmd: "echo 1"}})
+			So(err, ShouldBeNil)
+			So(released, ShouldEqual, 1)
+			So(getState("echo 1"), ShouldEqual, JobStateReady)
+
+			job, err = jq.Reserve(50 * time.Millisecond)
+			So(err, ShouldBeNil)
+			So(job, ShouldNotBeNil)
+			So(job.Cmd, ShouldEqual, "echo 1")
+			err = jq.Execute(job, config.RunnerExecShell)
+			So(err, ShouldBeNil)
+			So(getState("echo 3"), ShouldEqual, JobStateHeld)
+
+			job, err = jq.Reserve(50 * time.Millisecond)
+			So(err, ShouldBeNil)
+			So(job, ShouldBeNil)
+
+			Convey("Releasing them lets them run", func() {
+				released, err := jq.UnholdJobs(jobsToJobEssenses(all))
+				So(err, ShouldBeNil)
+				So(released, ShouldEqual, 2)
+				So(getState("echo 2"), ShouldEqual, JobStateReady)
+				So(getState("echo 3"), ShouldEqual, JobStateReady)
+
+				for i := 0; i < 2; i++ {
+					job, err = jq.Reserve(50 * time.Millisecond)
+					So(err, ShouldBeNil)
+					So(job, ShouldNotBeNil)
+					So(job.Held, ShouldBeFalse)
+				}
+			})
+		})
+
+		Convey("Held jobs stay held after a restart", func() {
+			ok := jq.ShutdownServer()
+			So(ok, ShouldBeTrue)
+			jq.Disconnect()
+
+			wipeDevDBOnInit = false
+			server, _, token, errs = serve(serverConfig)
+			wipeDevDBOnInit = true
+			So(errs, ShouldBeNil)
+			jq, err = Connect(addr, config.ManagerCAFile, config.ManagerCertDomain, token, clientConnectTime)
+			So(err, ShouldBeNil)
+
+			So(getState("echo 2"), ShouldEqual, JobStateHeld)
+
+			job, err := jq.Reserve(50 * time.Millisecond)
+			So(err, ShouldBeNil)
+			So(job, ShouldNotBeNil)
+			So(job.Cmd, ShouldEqual, "echo 1")
+
+			job, err = jq.Reserve(50 * time.Millisecond)
+			So(err, ShouldBeNil)
+			So(job, ShouldBeNil)
+		})
+	})
+}
+
+func TestJobqueueWindows(t *testing.T) {
+	if runnermode || servermode {
+		return
+	}
+
+	Convey("You can parse windows", t, func() {
+		w, err := ParseWindow("Mon-Fri 19:00-07:00,sat-sun")
+		So(err, ShouldBeNil)
+		So(w, ShouldNotBeNil)
+		So(len(w.Periods), ShouldEqual, 2)
+		So(w.String(), ShouldEqual, "Mon-Fri 19:00-07:00,Sat-Sun")
+
+		w, err = ParseWindow("Fri-Mon,09:00-17:00")
+		So(err, ShouldBeNil)
+		So(w.String(), ShouldEqual, "Fri-Mon,09:00-17:00")
+
+		w, err = ParseWindow("")
+		So(err, ShouldBeNil)
+		So(w, ShouldBeNil)
+
+		for _, bad := range []string{"Mon-Foo", "25:00-07:00", "19:00", "Mon Tue", "Mon 19:00-07:00 extra", "Mon,,Tue"} {
+			_, err = ParseWindow(bad)
+			So(err, ShouldNotBeNil)
+		}
+
+		Convey("Which tell you when they next open", func() {
+			w, err = ParseWindow("Mon-Fri 19:00-07:00,Sat-Sun")
+			So(err, ShouldBeNil)
+
+			// 2026-10-12 is a Monday
+			at := func(day, hour, min int) time.Time {
+				return time.Date(2026, 10, day, hour, min, 0, 0, time.Local)
+			}
+			So(w.Next(at(12, 12, 0)), ShouldEqual, at(12, 19, 0))
+			So(w.Next(at(12, 20, 0)), ShouldEqual, at(12, 20, 0))
+			So(w.Next(at(13, 6, 59)), ShouldEqual, at(13, 6, 59))
+			So(w.Next(at(13, 7, 0)), ShouldEqual, at(13, 19, 0))
+			So(w.Next(at(17, 12, 0)), ShouldEqual, at(17, 12, 0))
+			So(w.Next(at(12, 0, 30)), ShouldEqual, at(12, 19, 0))
+
+			var nilWindow *Window
+			So(nilWindow.Next(at(12, 12, 0)), ShouldEqual, at(12, 12, 0))
+		})
+	})
+
+	Convey("You can parse begin times", t, func() {
+		begin, err := ParseBeginAt("2h")
+		So(err, ShouldBeNil)
+		So(begin, ShouldHappenWithin, 5*time.Second, time.Now().Add(2*time.Hour))
+
+		begin, err = ParseBeginAt("2030-01-02 15:04")
+		So(err, ShouldBeNil)
+		So(begin, ShouldEqual, time.Date(2030, 1, 2, 15, 4, 0, 0, time.Local))
+
+		begin, err = ParseBeginAt("")
+		So(err, ShouldBeNil)
+		So(begin.IsZero(), ShouldBeTrue)
+
+		_, err = ParseBeginAt("tomorrow")
+		So(err, ShouldNotBeNil)
+	})
+
+	config, serverConfig, addr, standardReqs, clientConnectTime := jobqueueTestInit(true)
+
+	defer os.RemoveAll(filepath.Join(os.TempDir(), AppName+"_cwd"))
+
+	Convey("Once a new jobqueue server is up and some jobs with begin times have been added", t, func() {
+		ServerItemTTR = 5 * time.Second
+		ClientTouchInterval = 2500 * time.Millisecond
+		server, _, token, errs := serve(serverConfig)
+		So(errs, ShouldBeNil)
+		defer func() {
+			server.Stop(true)
+		}()
+
+		jq, err := Connect(addr, config.ManagerCAFile, config.ManagerCertDomain, token, clientConnectTime)
+		So(err, ShouldBeNil)
+		defer func() {
+			jq.Disconnect()
+		}()
+
+		soon := time.Now().Add(2 * time.Second)
+		jobs := []*Job{
+			{Cmd: "echo 1", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "window"},
+			{Cmd: "echo 2", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "window", BeginAt: soon},
+			{Cmd: "echo 3", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "window", BeginAt: time.Now().Add(1 * time.Hour)},
+		}
+		inserts, _, err := jq.Add(jobs, envVars, true)
+		So(err, ShouldBeNil)
+		So(inserts, ShouldEqual, 3)
+
+		getJob := func(cmd string) *Job {
+			job, errg := jq.GetByEssence(&JobEssence{Cmd: cmd}, false, false)
+			So(errg, ShouldBeNil)
+			So(job, ShouldNotBeNil)
+			return job
+		}
+
+		So(getJob("echo 1").State, ShouldEqual, JobStateReady)
+		job2 := getJob("echo 2")
+		So(job2.State, ShouldEqual, JobStateDelayed)
+		So(job2.BeginAt.Equal(soon), ShouldBeTrue)
+		So(getJob("echo 3").State, ShouldEqual, JobStateDelayed)
+
+		Convey("They can't be reserved until their begin time", func() {
+			job, err := jq.Reserve(50 * time.Millisecond)
+			So(err, ShouldBeNil)
+			So(job, ShouldNotBeNil)
+			So(job.Cmd, ShouldEqual, "echo 1")
+
+			job, err = jq.Reserve(50 * time.Millisecond)
+			So(err, ShouldBeNil)
+			So(job, ShouldBeNil)
+
+			exps, err := jq.Explain(jobsToJobEssenses([]*Job{job2}))
+			So(err, ShouldBeNil)
+			So(len(exps), ShouldEqual, 1)
+			So(exps[0].Reasons[0], ShouldContainSubstring, "isn't allowed to start until")
+
+			<-time.After(time.Until(soon) + 500*time.Millisecond)
+			So(getJob("echo 2").State, ShouldEqual, JobStateReady)
+			job, err = jq.Reserve(50 * time.Millisecond)
+			So(err, ShouldBeNil)
+			So(job, ShouldNotBeNil)
+			So(job.Cmd, ShouldEqual, "echo 2")
+
+			job, err = jq.Reserve(50 * time.Millisecond)
+			So(err, ShouldBeNil)
+			So(job, ShouldBeNil)
+		})
+
+		Convey("You can modify their begin times and windows", func() {
+			jm := NewJobModifer()
+			jm.SetBeginAt(time.Time{})
+			modified, err := jq.Modify(jobsToJobEssenses([]*Job{getJob("echo 3")}), jm)
+			So(err, ShouldBeNil)
+			So(len(modified), ShouldEqual, 1)
+
+			<-time.After(100 * time.Millisecond)
+			job3 := getJob("echo 3")
+			So(job3.State, ShouldEqual, JobStateReady)
+			So(job3.BeginAt.IsZero(), ShouldBeTrue)
+
+			now := time.Now()
+			closed := &Window{Periods: []*WindowPeriod{{Start: 0, End: minutesPerDay}}}
+			closed.Periods[0].Days[(now.Weekday()+2)%7] = true
+			jm = NewJobModifer()
+			jm.SetWindow(closed)
+			modified, err = jq.Modify(jobsToJobEssenses([]*Job{getJob("echo 1"), job3}), jm)
+			So(err, ShouldBeNil)
+			So(len(modified), ShouldEqual, 2)
+
+			job1 := getJob("echo 1")
+			So(job1.State, ShouldEqual, JobStateDelayed)
+			So(job1.Window, ShouldNotBeNil)
+			So(job1.Window.String(), ShouldEqual, closed.String())
+			So(getJob("echo 3").State, ShouldEqual, JobStateDelayed)
+
+			job, err := jq.Reserve(50 * time.Millisecond)
+			So(err, ShouldBeNil)
+			So(job, ShouldBeNil)
+
+			jm = NewJobModifer()
+			jm.SetWindow(nil)
+			_, err = jq.Modify(jobsToJobEssenses([]*Job{job1}), jm)
+			So(err, ShouldBeNil)
+
+			<-time.After(100 * time.Millisecond)
+			So(getJob("echo 1").State, ShouldEqual, JobStateReady)
+			job, err = jq.Reserve(50 * time.Millisecond)
+			So(err, ShouldBeNil)
+			So(job, ShouldNotBeNil)
+			So(job.Cmd, ShouldEqual, "echo 1")
+		})
+	})
+}
+
+func TestJobqueueCron(t *testing.T) {
+	if runnermode || servermode {
+		return
+	}
+
+	Convey("You can parse cron schedules", t, func() {
+		// 2026-10-12 is a Monday
+		at := func(month time.Month, day, hour, min int) time.Time {
+			return time.Date(2026, month, day, hour, min, 0, 0, time.Local)
+		}
+
+		cs, err := ParseCronSchedule("30 2 * * Mon-Fri")
+		So(err, ShouldBeNil)
+		So(cs.Next(at(10, 12, 1, 0)), ShouldEqual, at(10, 12, 2, 30))
+		So(cs.Next(at(10, 12, 2, 30)), ShouldEqual, at(10, 13, 2, 30))
+		So(cs.Next(at(10, 16, 3, 0)), ShouldEqual, at(10, 19, 2, 30))
+
+		cs, err = ParseCronSchedule("*/15 * * * *")
+		So(err, ShouldBeNil)
+		So(cs.Next(at(10, 12, 1, 7)), ShouldEqual, at(10, 12, 1, 15))
+		So(cs.Next(at(10, 12, 1, 45)), ShouldEqual, at(10, 12, 2, 0))
+
+		cs, err = ParseCronSchedule("@monthly")
+		So(err, ShouldBeNil)
+		So(cs.Next(at(10, 12, 1, 0)), ShouldEqual, at(11, 1, 0, 0))
+
+		cs, err = ParseCronSchedule("0 9 1,15 * sun")
+		So(err, ShouldBeNil)
+		So(cs.Next(at(10, 12, 1, 0)), ShouldEqual, at(10, 15, 9, 0))
+		So(cs.Next(at(10, 15, 10, 0)), ShouldEqual, at(10, 18, 9, 0))
+
+		cs, err = ParseCronSchedule("0 0 * * 7")
+		So(err, ShouldBeNil)
+		So(cs.Next(at(10, 12, 1, 0)), ShouldEqual, at(10, 18, 0, 0))
+
+		cs, err = ParseCronSchedule("0 0 30 Feb *")
+		So(err, ShouldBeNil)
+		So(cs.Next(at(10, 12, 1, 0)).IsZero(), ShouldBeTrue)
+
+		for _, bad := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * Foo *", "*/0 * * * *", "5-1 * * * *", "@often"} {
+			_, err = ParseCronSchedule(bad)
+			So(err, ShouldNotBeNil)
+		}
+	})
+
+	config, serverConfig, addr, standardReqs, clientConnectTime := jobqueueTestInit(true)
+
+	defer os.RemoveAll(filepath.Join(os.TempDir(), AppName+"_cwd"))
+
+	Convey("Once a new jobqueue server is up and a cron has been added", t, func() {
+		ServerItemTTR = 5 * time.Second
+		ClientTouchInterval = 2500 * time.Millisecond
+		server, _, token, errs := serve(serverConfig)
+		So(errs, ShouldBeNil)
+		defer func() {
+			server.Stop(true)
+		}()
+
+		jq, err := Connect(addr, config.ManagerCAFile, config.ManagerCertDomain, token, clientConnectTime)
+		So(err, ShouldBeNil)
+		defer func() {
+			jq.Disconnect()
+		}()
+
+		err = jq.AddCron(&Cron{Name: "bad name", Schedule: "@daily", Job: &Job{Cmd: "echo cron"}}, envVars)
+		So(err, ShouldNotBeNil)
+		err = jq.AddCron(&Cron{Name: "nightly", Schedule: "@daily", Overlap: "sometimes", Job: &Job{Cmd: "echo cron"}}, envVars)
+		So(err, ShouldNotBeNil)
+
+		template := &Job{Cmd: "echo cron", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs}
+		err = jq.AddCron(&Cron{Name: "nightly", Schedule: "0 2 * * *", Job: template}, envVars)
+		So(err, ShouldBeNil)
+
+		crons, err := jq.GetCrons()
+		So(err, ShouldBeNil)
+		So(len(crons), ShouldEqual, 1)
+		So(crons[0].Overlap, ShouldEqual, CronOverlapSkip)
+		So(crons[0].Job.RepGroup, ShouldEqual, "nightly")
+		So(crons[0].Last.IsZero(), ShouldBeTrue)
+		next := crons[0].Next
+		So(next.Hour(), ShouldEqual, 2)
+		So(next.After(time.Now()), ShouldBeTrue)
+
+		getCronJobs := func() []*Job {
+			jobs, errg := jq.GetByRepGroup("nightly", false, 0, "", false, true)
+			So(errg, ShouldBeNil)
+			return jobs
+		}
+
+		Convey("Nothing is added before it is due", func() {
+			server.fireCrons(next.Add(-1 * time.Minute))
+			So(len(getCronJobs()), ShouldEqual, 0)
+		})
+
+		Convey("When it is due a job is added, with the time in its environment", func() {
+			server.fireCrons(next)
+			jobs := getCronJobs()
+			So(len(jobs), ShouldEqual, 1)
+			So(jobs[0].Cmd, ShouldEqual, "echo cron")
+			So(jobs[0].State, ShouldEqual, JobStateReady)
+			So(jobs[0].Getenv("WR_CRON_NAME"), ShouldEqual, "nightly")
+			So(jobs[0].Getenv("WR_CRON_TIME"), ShouldEqual, next.Format(time.RFC3339))
+
+			crons, err = jq.GetCrons()
+			So(err, ShouldBeNil)
+			So(crons[0].Last, ShouldEqual, next)
+			So(crons[0].Next, ShouldEqual, next.Add(24*time.Hour))
+
+			Convey("By default it is skipped while the previous job is incomplete", func() {
+				server.fireCrons(next.Add(24 * time.Hour))
+				So(len(getCronJobs()), ShouldEqual, 1)
+				crons, err = jq.GetCrons()
+				So(err, ShouldBeNil)
+				So(crons[0].Last, ShouldEqual, next)
+				So(crons[0].Pending, ShouldBeFalse)
+			})
+
+			Convey("Once the previous job completes, it is added again", func() {
+				job, err := jq.Reserve(50 * time.Millisecond)
+				So(err, ShouldBeNil)
+				So(job, ShouldNotBeNil)
+				err = jq.Execute(job, config.RunnerExecShell)
+				So(err, ShouldBeNil)
+
+				server.fireCrons(next.Add(24 * time.Hour))
+				jobs := getCronJobs()
+				So(len(jobs), ShouldEqual, 1)
+				So(jobs[0].State, ShouldEqual, JobStateReady)
+				So(jobs[0].Getenv("WR_CRON_TIME"), ShouldEqual, next.Add(24*time.Hour).Format(time.RFC3339))
+			})
+
+			Convey("If the previous job failed, it is held up until that job is dealt with", func() {
+				job, err := jq.Reserve(50 * time.Millisecond)
+				So(err, ShouldBeNil)
+				So(job, ShouldNotBeNil)
+				err = jq.Bury(job, nil, FailReasonExit)
+				So(err, ShouldBeNil)
+
+				server.fireCrons(next.Add(24 * time.Hour))
+				jobs := getCronJobs()
+				So(len(jobs), ShouldEqual, 1)
+				So(jobs[0].State, ShouldEqual, JobStateBuried)
+				crons, err = jq.GetCrons()
+				So(err, ShouldBeNil)
+				So(crons[0].Last, ShouldEqual, next)
+
+				deleted, err := jq.Delete([]*JobEssence{{Cmd: "echo cron"}})
+				So(err, ShouldBeNil)
+				So(deleted, ShouldEqual, 1)
+
+				server.fireCrons(next.Add(48 * time.Hour))
+				jobs = getCronJobs()
+				So(len(jobs), ShouldEqual, 1)
+				So(jobs[0].State, ShouldEqual, JobStateReady)
+				So(jobs[0].Getenv("WR_CRON_TIME"), ShouldEqual, next.Add(48*time.Hour).Format(time.RFC3339))
+				crons, err = jq.GetCrons()
+				So(err, ShouldBeNil)
+				So(crons[0].Last, ShouldEqual, next.Add(48*time.Hour))
+			})
+
+			Convey("With the queue overlap policy, it is added once the previous job completes", func() {
+				err = jq.AddCron(&Cron{Name: "nightly", Schedule: "0 2 * * *", Overlap: CronOverlapQueue, Job: template}, envVars)
+				So(err, ShouldBeNil)
+				crons, err = jq.GetCrons()
+				So(err, ShouldBeNil)
+				So(crons[0].Next, ShouldEqual, next)
+
+				server.fireCrons(next)
+				server.fireCrons(next.Add(24 * time.Hour))
+				So(len(getCronJobs()), ShouldEqual, 1)
+				crons, err = jq.GetCrons()
+				So(err, ShouldBeNil)
+				So(crons[0].Pending, ShouldBeTrue)
+
+				job, err := jq.Reserve(50 * time.Millisecond)
+				So(err, ShouldBeNil)
+				So(job, ShouldNotBeNil)
+				err = jq.Execute(job, config.RunnerExecShell)
+				So(err, ShouldBeNil)
+
+				server.fireCrons(next.Add(24*time.Hour + time.Minute))
+				jobs := getCronJobs()
+				So(len(jobs), ShouldEqual, 1)
+				So(jobs[0].State, ShouldEqual, JobStateReady)
+				So(jobs[0].Getenv("WR_CRON_TIME"), ShouldEqual, next.Format(time.RFC3339))
+				crons, err = jq.GetCrons()
+				So(err, ShouldBeNil)
+				So(crons[0].Pending, ShouldBeFalse)
+			})
+
+			Convey("With the allow overlap policy, it is added alongside the previous job", func() {
+				err = jq.AddCron(&Cron{Name: "nightly", Schedule: "0 2 * * *", Overlap: CronOverlapAllow, Job: template}, envVars)
+				So(err, ShouldBeNil)
+
+				server.fireCrons(next)
+				jobs := getCronJobs()
+				So(len(jobs), ShouldEqual, 2)
+				var cmds []string
+				for _, job := range jobs {
+					cmds = append(cmds, job.Cmd)
+				}
+				So(cmds, ShouldContain, "echo cron")
+				So(cmds, ShouldContain, "echo cron # wr cron "+next.Format(time.RFC3339))
+			})
+		})
+
+		Convey("Crons survive a restart", func() {
+			ok := jq.ShutdownServer()
+			So(ok, ShouldBeTrue)
+			jq.Disconnect()
+
+			wipeDevDBOnInit = false
+			server, _, token, errs = serve(serverConfig)
+			wipeDevDBOnInit = true
+			So(errs, ShouldBeNil)
+			jq, err = Connect(addr, config.ManagerCAFile, config.ManagerCertDomain, token, clientConnectTime)
+			So(err, ShouldBeNil)
+
+			crons, err = jq.GetCrons()
+			So(err, ShouldBeNil)
+			So(len(crons), ShouldEqual, 1)
+			So(crons[0].Next, ShouldEqual, next)
+
+			server.fireCrons(next)
+			So(len(getCronJobs()), ShouldEqual, 1)
+		})
+
+		Convey("You can remove crons", func() {
+			removed, err := jq.RemoveCron("nightly")
+			So(err, ShouldBeNil)
+			So(removed, ShouldBeTrue)
+
+			removed, err = jq.RemoveCron("nightly")
+			So(err, ShouldBeNil)
+			So(removed, ShouldBeFalse)
+
+			crons, err = jq.GetCrons()
+			So(err, ShouldBeNil)
+			So(len(crons), ShouldEqual, 0)
+
+			server.fireCrons(next)
+			So(len(getCronJobs()), ShouldEqual, 0)
+		})
+	})
+}
+
+func TestJobqueueDeadlines(t *testing.T) {
+	if runnermode || servermode {
+		return
+	}
+
+	config, serverConfig, addr, standardReqs, clientConnectTime := jobqueueTestInit(true)
+
+	defer os.RemoveAll(filepath.Join(os.TempDir(), AppName+"_cwd"))
+
+	Convey("Once a new jobqueue server is up and some jobs with deadlines have been added", t, func() {
+		ServerItemTTR = 5 * time.Second
+		ClientTouchInterval = 2500 * time.Millisecond
+		server, _, token, errs := serve(serverConfig)
+		So(errs, ShouldBeNil)
+		defer func() {
+			server.Stop(true)
+		}()
+
+		jq, err := Connect(addr, config.ManagerCAFile, config.ManagerCertDomain, token, clientConnectTime)
+		So(err, ShouldBeNil)
+		defer func() {
+			jq.Disconnect()
+		}()
+
+		// standardReqs have a time of 10s, so with no learned run times, jobs
+		// with less than 20s to go are at risk, and those with less than 10s
+		// will miss their deadline
+		now := time.Now()
+		jobs := []*Job{
+			{Cmd: "echo 1", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "none", Priority: 5},
+			{Cmd: "echo 2", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "ontime", Deadline: now.Add(15 * time.Second)},
+			{Cmd: "echo 3", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "late", Deadline: now.Add(5 * time.Second)},
+			{Cmd: "echo 4", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "ontime", Deadline: now.Add(1 * time.Hour)},
+		}
+		inserts, _, err := jq.Add(jobs, envVars, true)
+		So(err, ShouldBeNil)
+		So(inserts, ShouldEqual, 4)
+
+		getJob := func(cmd string) *Job {
+			job, errg := jq.GetByEssence(&JobEssence{Cmd: cmd}, false, false)
+			So(errg, ShouldBeNil)
+			So(job, ShouldNotBeNil)
+			return job
+		}
+
+		So(getJob("echo 2").Deadline.Equal(jobs[1].Deadline), ShouldBeTrue)
+
+		server.deadlineCheck(time.Now())
+
+		issueExists := func(repGroup string) bool {
+			server.simutex.RLock()
+			defer server.simutex.RUnlock()
+			for msg := range server.schedIssues {
+				if strings.HasPrefix(msg, "RepGroup "+repGroup+" is predicted to miss its deadline") {
+					return true
+				}
+			}
+			return false
+		}
+
+		Convey("Those at risk of missing their deadline are reserved first", func() {
+			So(getJob("echo 2").EffectivePriority, ShouldEqual, deadlineBoost)
+			So(getJob("echo 2").Priority, ShouldEqual, 0)
+			So(getJob("echo 4").EffectivePriority, ShouldEqual, 0)
+
+			exps, err := jq.Explain(jobsToJobEssenses([]*Job{jobs[2]}))
+			So(err, ShouldBeNil)
+			So(len(exps), ShouldEqual, 1)
+			So(exps[0].Reasons[0], ShouldContainSubstring, "at risk of missing its deadline")
+
+			for _, cmd := range []string{"echo 2", "echo 3", "echo 1", "echo 4"} {
+				job, err := jq.Reserve(50 * time.Millisecond)
+				So(err, ShouldBeNil)
+				So(job, ShouldNotBeNil)
+				So(job.Cmd, ShouldEqual, cmd)
+			}
+		})
+
+		Convey("RepGroups predicted to miss their deadline are warned about", func() {
+			So(issueExists("late"), ShouldBeTrue)
+			So(issueExists("ontime"), ShouldBeFalse)
+			So(issueExists("none"), ShouldBeFalse)
+		})
+
+		Convey("Removing a deadline removes the boost", func() {
+			jm := NewJobModifer()
+			jm.SetDeadline(time.Time{})
+			modified, err := jq.Modify(jobsToJobEssenses([]*Job{jobs[1]}), jm)
+			So(err, ShouldBeNil)
+			So(len(modified), ShouldEqual, 1)
+			So(getJob("echo 2").Deadline.IsZero(), ShouldBeTrue)
+
+			server.deadlineCheck(time.Now())
+			So(getJob("echo 2").EffectivePriority, ShouldEqual, 0)
+
+			job, err := jq.Reserve(50 * time.Millisecond)
+			So(err, ShouldBeNil)
+			So(job, ShouldNotBeNil)
+			So(job.Cmd, ShouldEqual, "echo 3")
+		})
+	})
+}
+
+func TestJobqueueArrays(t *testing.T) {
+	if runnermode || servermode {
+		return
+	}
+
+	Convey("You can parse array indices", t, func() {
+		indices, err := ParseArrayIndices("1-3,10-20:5,2")
+		So(err, ShouldBeNil)
+		So(indices, ShouldResemble, []int{1, 2, 3, 10, 15, 20})
+
+		indices, err = ParseArrayIndices("-2-0")
+		So(err, ShouldBeNil)
+		So(indices, ShouldResemble, []int{-2, -1, 0})
+
+		for _, bad := range []string{"", "1,,2", "3-1", "a", "1-10:0", ":2", "1-"} {
+			_, err = ParseArrayIndices(bad)
+			So(err, ShouldNotBeNil)
+		}
+	})
+
+	Convey("You can make job arrays", t, func() {
+		a, err := NewJobArray("", nil, "", "")
+		So(err, ShouldBeNil)
+		So(a, ShouldBeNil)
+
+		a, err = NewJobArray("1-3", nil, "", "name")
+		So(err, ShouldBeNil)
+		So(a.Name, ShouldEqual, "name")
+		So(a.size(), ShouldEqual, 3)
+
+		a, err = NewJobArray("", []string{"x", "y"}, "", "")
+		So(err, ShouldBeNil)
+		So(a.Params, ShouldResemble, []string{ArrayListParam})
+		So(a.size(), ShouldEqual, 2)
+
+		_, err = NewJobArray("1-3", []string{"x"}, "", "")
+		So(err, ShouldNotBeNil)
+
+		tsv := filepath.Join(os.TempDir(), "wr_array_test.tsv")
+		defer os.Remove(tsv)
+		err = ioutil.WriteFile(tsv, []byte("sample\tk\ns1\t21\ns2\t31\ns3\n"), 0600)
+		So(err, ShouldBeNil)
+		a, err = NewJobArray("", nil, tsv, "")
+		So(err, ShouldBeNil)
+		So(a.Params, ShouldResemble, []string{"sample", "k"})
+		So(a.size(), ShouldEqual, 6)
+		index, values := a.member(1)
+		So(index, ShouldEqual, 2)
+		So(values, ShouldResemble, []string{"s1", "31"})
+
+		err = ioutil.WriteFile(tsv, []byte("index\tk\n1\t2\n"), 0600)
+		So(err, ShouldBeNil)
+		_, err = NewJobArray("", nil, tsv, "")
+		So(err, ShouldNotBeNil)
+	})
+
+	config, serverConfig, addr, standardReqs, clientConnectTime := jobqueueTestInit(true)
+
+	defer os.RemoveAll(filepath.Join(os.TempDir(), AppName+"_cwd"))
+
+	Convey("Once a new jobqueue server is up and some job arrays have been added", t, func() {
+		ServerItemTTR = 5 * time.Second
+		ClientTouchInterval = 2500 * time.Millisecond
+		server, _, token, errs := serve(serverConfig)
+		So(errs, ShouldBeNil)
+		defer func() {
+			server.Stop(true)
+		}()
+
+		jq, err := Connect(addr, config.ManagerCAFile, config.ManagerCertDomain, token, clientConnectTime)
+		So(err, ShouldBeNil)
+		defer func() {
+			jq.Disconnect()
+		}()
+
+		jobs := []*Job{
+			{Cmd: "echo {{index}}", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "arr.{{index}}", Array: &JobArray{Name: "numbers", Indices: []int{1, 2, 3}}},
+			{Cmd: "echo {{a}} {{b}}", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "sweep", Array: &JobArray{Params: []string{"a", "b"}, Values: [][]string{{"x", "y"}, {"1", "2"}}}},
+			{Cmd: "echo same", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "same", Array: &JobArray{Indices: []int{1, 2}}},
+		}
+		inserts, _, err := jq.Add(jobs, envVars, true)
+		So(err, ShouldBeNil)
+		So(inserts, ShouldEqual, 9)
+
+		Convey("They are expanded in to their members", func() {
+			members, err := jq.GetByArray("numbers", "", 0, "", false, true)
+			So(err, ShouldBeNil)
+			So(len(members), ShouldEqual, 3)
+			sort.Slice(members, func(i, j int) bool { return members[i].ArrayIndex < members[j].ArrayIndex })
+			for i, job := range members {
+				index := strconv.Itoa(i + 1)
+				So(job.ArrayName, ShouldEqual, "numbers")
+				So(job.ArrayIndex, ShouldEqual, i+1)
+				So(job.Cmd, ShouldEqual, "echo "+index)
+				So(job.RepGroup, ShouldEqual, "arr."+index)
+				So(job.Getenv("WR_ARRAY_NAME"), ShouldEqual, "numbers")
+				So(job.Getenv("WR_ARRAY_INDEX"), ShouldEqual, index)
+			}
+
+			members, err = jq.GetByArray("sweep", "", 0, "", false, true)
+			So(err, ShouldBeNil)
+			So(len(members), ShouldEqual, 4)
+			cmds := make(map[string]int)
+			for _, job := range members {
+				cmds[job.Cmd] = job.ArrayIndex
+				So(job.Getenv("WR_ARRAY_A")+" "+job.Getenv("WR_ARRAY_B"), ShouldEqual, strings.TrimPrefix(job.Cmd, "echo "))
+			}
+			So(cmds, ShouldResemble, map[string]int{"echo x 1": 1, "echo x 2": 2, "echo y 1": 3, "echo y 2": 4})
+
+			members, err = jq.GetByArray("same", "", 0, "", false, false)
+			So(err, ShouldBeNil)
+			So(len(members), ShouldEqual, 2)
+			So(members[0].Cmd, ShouldStartWith, "echo same # wr array same[")
+
+			members, err = jq.GetByRepGroup("numbers", false, 0, "", false, false)
+			So(err, ShouldBeNil)
+			So(len(members), ShouldEqual, 3)
+		})
+
+		Convey("You can address a slice of an array", func() {
+			members, err := jq.GetByArray("numbers", "2-3", 0, "", false, false)
+			So(err, ShouldBeNil)
+			So(len(members), ShouldEqual, 2)
+
+			_, err = jq.GetByArray("numbers", "3-2", 0, "", false, false)
+			So(err, ShouldNotBeNil)
+
+			deleted, err := jq.Delete(jobsToJobEssenses(members))
+			So(err, ShouldBeNil)
+			So(deleted, ShouldEqual, 2)
+
+			members, err = jq.GetByArray("numbers", "", 0, "", false, false)
+			So(err, ShouldBeNil)
+			So(len(members), ShouldEqual, 1)
+			So(members[0].ArrayIndex, ShouldEqual, 1)
+
+			Convey("Including completed members", func() {
+				job, err := jq.Reserve(50 * time.Millisecond)
+				So(err, ShouldBeNil)
+				So(job, ShouldNotBeNil)
+				for job.ArrayName != "numbers" {
+					job, err = jq.Reserve(50 * time.Millisecond)
+					So(err, ShouldBeNil)
+					So(job, ShouldNotBeNil)
+				}
+				err = jq.Execute(job, config.RunnerExecShell)
+				So(err, ShouldBeNil)
+
+				members, err = jq.GetByArray("numbers", "1", 0, JobStateComplete, false, false)
+				So(err, ShouldBeNil)
+				So(len(members), ShouldEqual, 1)
+				So(members[0].Cmd, ShouldEqual, "echo 1")
+				So(members[0].ArrayIndex, ShouldEqual, 1)
+			})
+		})
+
+		Convey("Bad arrays are rejected", func() {
+			_, _, err := jq.Add([]*Job{{Cmd: "echo bad", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "bad", Array: &JobArray{}}}, envVars, true)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, ErrBadArray)
+		})
+	})
+}
+
+func TestJobqueueContainers(t *testing.T) {
+	if runnermode || servermode {
+		return
+	}
+
+	// we use fake container runtimes that just run the cmd after noting the
+	// args they were given
+	fakeDir, err := ioutil.TempDir("", "wr_fake_runtimes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(fakeDir)
+	argsFile := filepath.Join(fakeDir, "args")
+	fake := "#!/bin/sh\necho \"$@\" > " + argsFile + "\nshift 6\nexec \"$@\"\n"
+	for _, runtime := range []string{ContainerRuntimeDocker, ContainerRuntimeSingularity} {
+		err = ioutil.WriteFile(filepath.Join(fakeDir, runtime), []byte(fake), 0700) // #nosec
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	origPath := os.Getenv("PATH")
+	defer func() {
+		err = os.Setenv("PATH", origPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+	err = os.Setenv("PATH", fakeDir+":"+origPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	Convey("You can make containers", t, func() {
+		c, err := NewContainer("", "")
+		So(err, ShouldBeNil)
+		So(c, ShouldBeNil)
+
+		c, err = NewContainer("ubuntu", "")
+		So(err, ShouldBeNil)
+		So(c.String(), ShouldEqual, "ubuntu (docker)")
+		So(c.usesDocker(), ShouldBeTrue)
+
+		c, err = NewContainer("my.sif", "Apptainer")
+		So(err, ShouldBeNil)
+		So(c.Runtime, ShouldEqual, ContainerRuntimeApptainer)
+		So(c.usesDocker(), ShouldBeFalse)
+
+		_, err = NewContainer("ubuntu", "podman")
+		So(err, ShouldNotBeNil)
+
+		_, err = NewContainer("", "docker")
+		So(err, ShouldNotBeNil)
+
+		Convey("docker containers get our user, dirs, env and resource limits", func() {
+			c, err = NewContainer("ubuntu", "")
+			So(err, ShouldBeNil)
+			cmd := exec.Command("bash", "-c", "echo hi")
+			cmd.Env = []string{"PATH=/bin", "FOO=bar"}
+			name, err := c.wrap(cmd, "bash", "echo hi", "/work", []string{"/work", "/tmp/x", ""}, &jqs.Requirements{RAM: 100, Cores: 1.5}, "key")
+			So(err, ShouldBeNil)
+			So(name, ShouldStartWith, "wr_key_")
+			So(cmd.Path, ShouldEqual, filepath.Join(fakeDir, ContainerRuntimeDocker))
+
+			args := strings.Join(cmd.Args, " ")
+			So(args, ShouldStartWith, "docker run --name "+name+" --user "+strconv.Itoa(os.Getuid())+":"+strconv.Itoa(os.Getgid()))
+			So(args, ShouldContainSubstring, " --workdir /work --volume /work:/work --volume /tmp/x:/tmp/x --env FOO --memory 100m --memory-swap 100m --cpus 1.5 ")
+			So(args, ShouldNotContainSubstring, "PATH")
+			So(args, ShouldEndWith, " ubuntu bash -c echo hi")
+		})
+
+		Convey("docker is asked if containers were killed for using too much memory", func() {
+			dockerPath := filepath.Join(fakeDir, ContainerRuntimeDocker)
+			inspector := "#!/bin/sh\nif [ \"$1\" = inspect ]; then case \"$4\" in *oom*) echo true;; *) echo false;; esac; fi\n"
+			err = ioutil.WriteFile(dockerPath, []byte(inspector), 0700) // #nosec
+			So(err, ShouldBeNil)
+			defer func() {
+				err = ioutil.WriteFile(dockerPath, []byte(fake), 0700) // #nosec
+				So(err, ShouldBeNil)
+			}()
+
+			oom, err := dockerOOMKilled("wr_oom_1")
+			So(err, ShouldBeNil)
+			So(oom, ShouldBeTrue)
+
+			oom, err = dockerOOMKilled("wr_ok_1")
+			So(err, ShouldBeNil)
+			So(oom, ShouldBeFalse)
+
+			err = removeDockerContainer("wr_ok_1")
+			So(err, ShouldBeNil)
+		})
+	})
+
+	config, serverConfig, addr, standardReqs, clientConnectTime := jobqueueTestInit(true)
+
+	defer os.RemoveAll(filepath.Join(os.TempDir(), AppName+"_cwd"))
+
+	Convey("Once a new jobqueue server is up and a job with a container has been added", t, func() {
+		ServerItemTTR = 5 * time.Second
+		ClientTouchInterval = 2500 * time.Millisecond
+		server, _, token, errs := serve(serverConfig)
+		So(errs, ShouldBeNil)
+		defer func() {
+			server.Stop(true)
+		}()
+
+		jq, err := Connect(addr, config.ManagerCAFile, config.ManagerCertDomain, token, clientConnectTime)
+		So(err, ShouldBeNil)
+		defer func() {
+			jq.Disconnect()
+		}()
+
+		outFile := filepath.Join(fakeDir, "out")
+		containerCmd := "echo container > " + outFile
+		jobs := []*Job{{Cmd: containerCmd, Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "container", Container: &Container{Image: "my.sif", Runtime: ContainerRuntimeSingularity}}}
+		inserts, _, err := jq.Add(jobs, envVars, true)
+		So(err, ShouldBeNil)
+		So(inserts, ShouldEqual, 1)
+
+		Convey("It runs inside the container", func() {
+			job, err := jq.Reserve(50 * time.Millisecond)
+			So(err, ShouldBeNil)
+			So(job, ShouldNotBeNil)
+			So(job.Container, ShouldResemble, &Container{Image: "my.sif", Runtime: ContainerRuntimeSingularity})
+
+			err = jq.Execute(job, config.RunnerExecShell)
+			So(err, ShouldBeNil)
+
+			job, err = jq.GetByEssence(&JobEssence{Cmd: containerCmd}, true, false)
+			So(err, ShouldBeNil)
+			So(job.State, ShouldEqual, JobStateComplete)
+			out, err := ioutil.ReadFile(outFile)
+			So(err, ShouldBeNil)
+			So(string(out), ShouldEqual, "container\n")
+
+			args, err := ioutil.ReadFile(argsFile)
+			So(err, ShouldBeNil)
+			So(string(args), ShouldStartWith, "exec --pwd "+job.ActualCwd+" --bind "+job.ActualCwd+",")
+			So(string(args), ShouldEndWith, " my.sif "+config.RunnerExecShell+" -c "+containerCmd+"\n")
+		})
+
+		Convey("It is buried if the container runtime isn't installed", func() {
+			if _, errl := exec.LookPath(ContainerRuntimeApptainer); errl == nil {
+				return
+			}
+			modifier := NewJobModifer()
+			modifier.SetContainer(&Container{Image: "my.sif", Runtime: ContainerRuntimeApptainer})
+			modified, err := jq.Modify(jobsToJobEssenses(jobs), modifier)
+			So(err, ShouldBeNil)
+			So(len(modified), ShouldEqual, 1)
+
+			job, err := jq.Reserve(50 * time.Millisecond)
+			So(err, ShouldBeNil)
+			So(job, ShouldNotBeNil)
+			err = jq.Execute(job, config.RunnerExecShell)
+			So(err, ShouldNotBeNil)
+
+			job, err = jq.GetByEssence(&JobEssence{Cmd: containerCmd}, false, false)
+			So(err, ShouldBeNil)
+			So(job.State, ShouldEqual, JobStateBuried)
+			So(job.FailReason, ShouldEqual, FailReasonContainer)
+		})
+	})
+}
+
+func TestJobqueueCgroups(t *testing.T) {
+	if runnermode || servermode {
+		return
+	}
+
+	Convey("Without a usable cgroup v2 hierarchy, cmds don't get cgroups", t, func() {
+		fakeRoot, err := ioutil.TempDir("", "wr_fake_cgroups")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(fakeRoot)
+		origRoot, origProc := cgroupRoot, procSelfCgroup
+		defer func() {
+			cgroupRoot, procSelfCgroup = origRoot, origProc
+		}()
+		cgroupRoot = fakeRoot
+		procSelfCgroup = filepath.Join(fakeRoot, "self")
+
+		parent, _ := findCgroupParent()
+		So(parent, ShouldEqual, "")
+
+		own := filepath.Join(fakeRoot, "job")
+		err = os.Mkdir(own, 0755)
+		So(err, ShouldBeNil)
+		err = ioutil.WriteFile(filepath.Join(fakeRoot, "cgroup.controllers"), []byte("cpu memory\n"), 0644)
+		So(err, ShouldBeNil)
+		err = ioutil.WriteFile(procSelfCgroup, []byte("0::/job\n"), 0644)
+		So(err, ShouldBeNil)
+		err = ioutil.WriteFile(filepath.Join(own, "cgroup.controllers"), []byte("cpu\n"), 0644)
+		So(err, ShouldBeNil)
+
+		parent, _ = findCgroupParent()
+		So(parent, ShouldEqual, "")
+
+		err = ioutil.WriteFile(filepath.Join(own, "cgroup.controllers"), []byte("cpu memory\n"), 0644)
+		So(err, ShouldBeNil)
+		stale := filepath.Join(own, cgroupRunnerPrefix+"1")
+		err = os.Mkdir(stale, 0755)
+		So(err, ShouldBeNil)
+
+		// (we can't move ourselves in to a fake cgroup)
+		parent, _ = findCgroupParent()
+		So(parent, ShouldEqual, "")
+		entries, err := ioutil.ReadDir(own)
+		So(err, ShouldBeNil)
+		So(len(entries), ShouldEqual, 1)
+		So(entries[0].Name(), ShouldEqual, "cgroup.controllers")
+
+		// (nor create a real one)
+		cg, err := createJobCgroup(own, true, "wr_test", &jqs.Requirements{RAM: 100, Cores: 1})
+		So(err, ShouldBeNil)
+		So(cg, ShouldBeNil)
+		_, err = os.Stat(filepath.Join(own, "wr_test"))
+		So(os.IsNotExist(err), ShouldBeTrue)
+
+		Convey("Cgroup memory limits have headroom, or can be turned off", func() {
+			origHeadroom := ClientPercentMemoryHeadroom
+			defer func() {
+				ClientPercentMemoryHeadroom = origHeadroom
+			}()
+			So(cgroupMemoryLimit(0), ShouldEqual, 0)
+			ClientPercentMemoryHeadroom = 25
+			So(cgroupMemoryLimit(100), ShouldEqual, 125*1024*1024)
+			ClientPercentMemoryHeadroom = 0
+			So(cgroupMemoryLimit(100), ShouldEqual, 100*1024*1024)
+			ClientPercentMemoryHeadroom = -1
+			So(cgroupMemoryLimit(100), ShouldEqual, 0)
+		})
+
+		Convey("A nil cgroup does nothing", func() {
+			cg.apply(exec.Command("true"))
+			mem, err := cg.anonMemory()
+			So(err, ShouldBeNil)
+			So(mem, ShouldEqual, 0)
+			cpu, err := cg.cpuTime()
+			So(err, ShouldBeNil)
+			So(cpu, ShouldEqual, 0)
+			So(cg.oomKilled(), ShouldBeFalse)
+			So(cg.kill(), ShouldBeNil)
+			So(cg.remove(), ShouldBeNil)
+		})
+
+		Convey("Usage is read from a cgroup's interface files", func() {
+			cg = &jobCgroup{dir: own}
+			_, err = cg.anonMemory()
+			So(err, ShouldNotBeNil)
+			So(cg.oomKilled(), ShouldBeFalse)
+
+			err = ioutil.WriteFile(cg.file("memory.stat"), []byte("anon 104857600\nfile 1073741824\nkernel 4096\n"), 0644)
+			So(err, ShouldBeNil)
+			err = ioutil.WriteFile(cg.file("cpu.stat"), []byte("usage_usec 2500000\nuser_usec 2000000\nsystem_usec 500000\n"), 0644)
+			So(err, ShouldBeNil)
+			err = ioutil.WriteFile(cg.file("memory.events"), []byte("low 0\nhigh 0\nmax 3\noom 1\noom_kill 1\n"), 0644)
+			So(err, ShouldBeNil)
+
+			mem, err := cg.anonMemory()
+			So(err, ShouldBeNil)
+			So(mem, ShouldEqual, 100)
+			cpu, err := cg.cpuTime()
+			So(err, ShouldBeNil)
+			So(cpu, ShouldEqual, 2500*time.Millisecond)
+			So(cg.oomKilled(), ShouldBeTrue)
+		})
+	})
+}
+
+func TestJobqueueUsageStats(t *testing.T) {
+	if runnermode || servermode {
+		return
+	}
+
+	// (I/O to tmpfs isn't counted, so we don't use a temp dir in /tmp)
+	ioDir, err := ioutil.TempDir(".", "wr_io_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ioDir, err = filepath.Abs(ioDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(ioDir)
+
+	Convey("CPU efficiency is relative to wall time and requested cores", t, func() {
+		job := &Job{Requirements: &jqs.Requirements{Cores: 2}}
+		So(job.CPUEfficiency(), ShouldEqual, 0)
+
+		job.StartTime = time.Now().Add(-10 * time.Second)
+		job.EndTime = job.StartTime.Add(10 * time.Second)
+		job.CPUtime = 5 * time.Second
+		So(job.CPUEfficiency(), ShouldEqual, 0.25)
+
+		job.Requirements.Cores = 0.5
+		So(job.CPUEfficiency(), ShouldEqual, 1)
+
+		job.Requirements.Cores = 0
+		So(job.CPUEfficiency(), ShouldEqual, 0)
+	})
+
+	Convey("currentIO() includes the I/O of child processes", t, func() {
+		_, _, err := currentIO(-1)
+		So(err, ShouldNotBeNil)
+
+		cmd := exec.Command("sh", "-c", "(head -c 1000000 /dev/zero > child; sleep 5) & wait")
+		cmd.Dir = ioDir
+		err = cmd.Start()
+		So(err, ShouldBeNil)
+		defer func() {
+			errk := cmd.Process.Kill()
+			So(errk, ShouldBeNil)
+			errw := cmd.Wait()
+			So(errw, ShouldNotBeNil)
+		}()
+
+		var written int64
+		for i := 0; i < 50; i++ {
+			_, written, err = currentIO(cmd.Process.Pid)
+			So(err, ShouldBeNil)
+			if written >= 1000000 {
+				break
+			}
+			<-time.After(100 * time.Millisecond)
+		}
+		So(written, ShouldBeGreaterThanOrEqualTo, 1000000)
+	})
+
+	config, serverConfig, addr, standardReqs, clientConnectTime := jobqueueTestInit(true)
+
+	defer os.RemoveAll(filepath.Join(os.TempDir(), AppName+"_cwd"))
+
+	Convey("Once a new jobqueue server is up and a job has been added", t, func() {
+		ServerItemTTR = 5 * time.Second
+		ClientTouchInterval = 2500 * time.Millisecond
+		server, _, token, errs := serve(serverConfig)
+		So(errs, ShouldBeNil)
+		defer func() {
+			server.Stop(true)
+		}()
+
+		jq, err := Connect(addr, config.ManagerCAFile, config.ManagerCertDomain, token, clientConnectTime)
+		So(err, ShouldBeNil)
+		defer func() {
+			jq.Disconnect()
+		}()
+
+		ioCmd := "head -c 2000000 /dev/zero > out && sleep 0.1"
+		jobs := []*Job{{Cmd: ioCmd, Cwd: ioDir, CwdMatters: true, ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "io"}}
+		inserts, _, err := jq.Add(jobs, envVars, true)
+		So(err, ShouldBeNil)
+		So(inserts, ShouldEqual, 1)
+
+		Convey("Executing it records its I/O, context switches and CPU efficiency", func() {
+			job, err := jq.Reserve(50 * time.Millisecond)
+			So(err, ShouldBeNil)
+			So(job, ShouldNotBeNil)
+
+			err = jq.Execute(job, config.RunnerExecShell)
+			So(err, ShouldBeNil)
+			So(job.BytesWritten, ShouldBeGreaterThanOrEqualTo, 2000000)
+			So(job.CtxSwitches, ShouldBeGreaterThan, 0)
+
+			got, err := jq.GetByEssence(&JobEssence{Cmd: ioCmd, Cwd: ioDir}, false, false)
+			So(err, ShouldBeNil)
+			So(got.State, ShouldEqual, JobStateComplete)
+			So(got.BytesRead, ShouldEqual, job.BytesRead)
+			So(got.BytesWritten, ShouldEqual, job.BytesWritten)
+			So(got.CtxSwitches, ShouldEqual, job.CtxSwitches)
+			So(got.CPUEfficiency(), ShouldBeGreaterThan, 0)
+
+			status := got.ToStatus()
+			So(status.BytesWritten, ShouldEqual, job.BytesWritten)
+			So(status.CPUEfficiency, ShouldEqual, got.CPUEfficiency())
+		})
+	})
+}
+
+func TestJobqueueResourceProfiles(t *testing.T) {
+	if runnermode || servermode {
+		return
+	}
+
+	Convey("Resource profiles are downsampled without losing peaks", t, func() {
+		p := newResourceProfiler(1 * time.Second)
+		for i := 0; i < 3; i++ {
+			p.add(&ResourceSample{Time: p.start.Add(time.Duration(i)*time.Second + 500*time.Millisecond), RAM: 10})
+		}
+		So(len(p.samples()), ShouldEqual, 3)
+
+		for i := 3; i < 300; i++ {
+			ram := 10
+			if i == 151 {
+				ram = 1000
+			}
+			p.add(&ResourceSample{Time: p.start.Add(time.Duration(i)*time.Second + 500*time.Millisecond), RAM: ram, CPU: time.Duration(i) * time.Second})
+		}
+		So(p.interval, ShouldEqual, 4*time.Second)
+		samples := p.samples()
+		So(len(samples), ShouldEqual, 75)
+		peaks := 0
+		for i, sample := range samples {
+			if sample.RAM == 1000 {
+				peaks++
+			}
+			if i > 0 {
+				So(sample.Time, ShouldHappenAfter, samples[i-1].Time)
+			}
+		}
+		So(peaks, ShouldEqual, 1)
+		So(samples[74].CPU, ShouldEqual, 299*time.Second)
+
+		samples[0].RAM = 5
+		So(p.samples()[0].RAM, ShouldEqual, 10)
+	})
+
+	Convey("currentCPU() reports CPU time used", t, func() {
+		_, err := currentCPU(-1)
+		So(err, ShouldNotBeNil)
+
+		for start := time.Now(); time.Since(start) < 100*time.Millisecond; {
+			// use some CPU time
+		}
+		cpu, err := currentCPU(os.Getpid())
+		So(err, ShouldBeNil)
+		So(cpu, ShouldBeGreaterThan, 0)
+	})
+
+	config, serverConfig, addr, standardReqs, clientConnectTime := jobqueueTestInit(true)
+
+	defer os.RemoveAll(filepath.Join(os.TempDir(), AppName+"_cwd"))
+
+	Convey("Once a new jobqueue server is up and a job has been added", t, func() {
+		ServerItemTTR = 5 * time.Second
+		ClientTouchInterval = 1 * time.Second
+		server, _, token, errs := serve(serverConfig)
+		So(errs, ShouldBeNil)
+		defer func() {
+			server.Stop(true)
+		}()
+
+		jq, err := Connect(addr, config.ManagerCAFile, config.ManagerCertDomain, token, clientConnectTime)
+		So(err, ShouldBeNil)
+		defer func() {
+			jq.Disconnect()
+		}()
+
+		profileCmd := "sleep 3.5"
+		jobs := []*Job{{Cmd: profileCmd, Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "profile"}}
+		inserts, _, err := jq.Add(jobs, envVars, true)
+		So(err, ShouldBeNil)
+		So(inserts, ShouldEqual, 1)
+
+		Convey("Its resource profile is sent while it runs and stored when it ends", func() {
+			job, err := jq.Reserve(50 * time.Millisecond)
+			So(err, ShouldBeNil)
+			So(job, ShouldNotBeNil)
+
+			errCh := make(chan error, 1)
+			go func() {
+				errCh <- jq.Execute(job, config.RunnerExecShell)
+			}()
 
-							jobs = nil
-							jobs = append(jobs, &Job{Cmd: "echo deptest9", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "dep9", DepGroups: []string{"final"}})
-							inserts, already, err = jq.Add(jobs, envVars, true)
-							So(err, ShouldBeNil)
-							So(inserts, ShouldEqual, 1)
-							So(already, ShouldEqual, 0)
+			var running *Job
+			for i := 0; i < 30; i++ {
+				<-time.After(100 * time.Millisecond)
+				running, err = jq.GetByEssence(&JobEssence{Cmd: profileCmd}, false, false)
+				So(err, ShouldBeNil)
+				if len(running.ResourceProfile) > 0 {
+					break
+				}
+			}
+			So(running.State, ShouldEqual, JobStateRunning)
+			So(len(running.ResourceProfile), ShouldBeGreaterThan, 0)
 
-							gottenJobs, err = jq.GetByRepGroup("afterfinal", false, 0, "", false, false)
-							So(err, ShouldBeNil)
-							So(len(gottenJobs), ShouldEqual, 1)
-							So(gottenJobs[0].State, ShouldEqual, JobStateDependent)
+			So(<-errCh, ShouldBeNil)
 
-							j9, err := jq.Reserve(50 * time.Millisecond)
-							So(err, ShouldBeNil)
-							So(j9.RepGroup, ShouldEqual, "dep9")
-							err = jq.Execute(j9, config.RunnerExecShell)
-							So(err, ShouldBeNil)
+			got, err := jq.GetByEssence(&JobEssence{Cmd: profileCmd}, false, false)
+			So(err, ShouldBeNil)
+			So(got.State, ShouldEqual, JobStateComplete)
+			So(len(got.ResourceProfile), ShouldBeGreaterThan, len(running.ResourceProfile))
+			for _, sample := range got.ResourceProfile {
+				So(sample.Time, ShouldHappenOnOrBetween, got.StartTime, got.EndTime)
+			}
+			So(got.ToStatus().Profile, ShouldResemble, got.ResourceProfile)
+		})
+	})
+}
 
-							gottenJobs, err = jq.GetByRepGroup("afterfinal", false, 0, "", false, false)
-							So(err, ShouldBeNil)
-							So(len(gottenJobs), ShouldEqual, 1)
-							So(gottenJobs[0].State, ShouldEqual, JobStateReady)
+func TestJobqueueQuotas(t *testing.T) {
+	if runnermode || servermode {
+		return
+	}
+	config, serverConfig, addr, standardReqs, clientConnectTime := jobqueueTestInit(true)
 
-							faf, err := jq.Reserve(50 * time.Millisecond)
-							So(err, ShouldBeNil)
-							So(faf.RepGroup, ShouldEqual, "afterfinal")
-							err = jq.Execute(faf, config.RunnerExecShell)
-							So(err, ShouldBeNil)
+	defer os.RemoveAll(filepath.Join(os.TempDir(), AppName+"_cwd"))
 
-							gottenJobs, err = jq.GetByRepGroup("afterfinal", false, 0, "", false, false)
-							So(err, ShouldBeNil)
-							So(len(gottenJobs), ShouldEqual, 1)
-							So(gottenJobs[0].State, ShouldEqual, JobStateComplete)
+	Convey("Once a new jobqueue server is up", t, func() {
+		ServerItemTTR = 5 * time.Second
+		ClientTouchInterval = 2500 * time.Millisecond
+		server, _, token, errs := serve(serverConfig)
+		So(errs, ShouldBeNil)
+		defer func() {
+			server.Stop(true)
+		}()
 
-							gottenJobs, err = jq.GetByRepGroup("after-afterfinal", false, 0, "", false, false)
-							So(err, ShouldBeNil)
-							So(len(gottenJobs), ShouldEqual, 1)
-							So(gottenJobs[0].State, ShouldEqual, JobStateReady)
+		jq, err := Connect(addr, config.ManagerCAFile, config.ManagerCertDomain, token, clientConnectTime)
+		So(err, ShouldBeNil)
+		defer jq.Disconnect()
 
-							inserts, already, err = jq.Add(jobs, envVars, false)
-							So(err, ShouldBeNil)
-							So(inserts, ShouldEqual, 2) // the job I added, and the resurrected afterfinal job
-							So(already, ShouldEqual, 0)
+		quotas, err := jq.GetQuotas()
+		So(err, ShouldBeNil)
+		So(len(quotas), ShouldEqual, 0)
 
-							gottenJobs, err = jq.GetByRepGroup("afterfinal", false, 0, "", false, false)
-							So(err, ShouldBeNil)
-							So(len(gottenJobs), ShouldEqual, 1)
-							So(gottenJobs[0].State, ShouldEqual, JobStateDependent)
+		Convey("You can't set bad quotas", func() {
+			err = jq.SetQuota(&Quota{Kind: "foo", Name: "quota", Cores: 2})
+			So(err, ShouldNotBeNil)
+			err = jq.SetQuota(&Quota{Kind: QuotaRepGroup, Name: "quota", Cores: -2})
+			So(err, ShouldNotBeNil)
+			err = jq.SetQuota(&Quota{Kind: QuotaOwner, Cores: 2})
+			So(err, ShouldNotBeNil)
+		})
 
-							gottenJobs, err = jq.GetByRepGroup("after-afterfinal", false, 0, "", false, false)
-							So(err, ShouldBeNil)
-							So(len(gottenJobs), ShouldEqual, 1)
-							So(gottenJobs[0].State, ShouldEqual, JobStateDependent)
+		Convey("Jobs over their RepGroup's quota are held back until others finish", func() {
+			err = jq.SetQuota(&Quota{Kind: QuotaRepGroup, Name: "quota", Cores: 2})
+			So(err, ShouldBeNil)
 
-							j9, err = jq.Reserve(50 * time.Millisecond)
-							So(err, ShouldBeNil)
-							So(j9.RepGroup, ShouldEqual, "dep9")
-							err = jq.Execute(j9, config.RunnerExecShell)
-							So(err, ShouldBeNil)
+			var jobs []*Job
+			for i := 1; i <= 3; i++ {
+				jobs = append(jobs, &Job{Cmd: fmt.Sprintf("echo quota %d", i), Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "quota", Priority: uint8(4 - i)})
+			}
+			jobs = append(jobs, &Job{Cmd: "echo other", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "other"})
+			inserts, _, err := jq.Add(jobs, envVars, true)
+			So(err, ShouldBeNil)
+			So(inserts, ShouldEqual, 4)
 
-							gottenJobs, err = jq.GetByRepGroup("afterfinal", false, 0, "", false, false)
-							So(err, ShouldBeNil)
-							So(len(gottenJobs), ShouldEqual, 1)
-							So(gottenJobs[0].State, ShouldEqual, JobStateReady)
+			job1, err := jq.Reserve(50 * time.Millisecond)
+			So(err, ShouldBeNil)
+			So(job1.Cmd, ShouldEqual, "echo quota 1")
+			job, err := jq.Reserve(50 * time.Millisecond)
+			So(err, ShouldBeNil)
+			So(job.Cmd, ShouldEqual, "echo quota 2")
+			job, err = jq.Reserve(50 * time.Millisecond)
+			So(err, ShouldBeNil)
+			So(job.Cmd, ShouldEqual, "echo other")
+			job, err = jq.Reserve(50 * time.Millisecond)
+			So(err, ShouldBeNil)
+			So(job, ShouldBeNil)
 
-							faf, err = jq.Reserve(50 * time.Millisecond)
-							So(err, ShouldBeNil)
-							So(faf.RepGroup, ShouldEqual, "afterfinal")
-							err = jq.Execute(faf, config.RunnerExecShell)
-							So(err, ShouldBeNil)
+			job, err = jq.GetByEssence(&JobEssence{Cmd: "echo quota 3"}, false, false)
+			So(err, ShouldBeNil)
+			So(job.State, ShouldEqual, JobStateReady)
+			So(job.PendingReason, ShouldEqual, "repgroup quota is at its quota of 2 cores")
 
-							gottenJobs, err = jq.GetByRepGroup("afterfinal", false, 0, "", false, false)
-							So(err, ShouldBeNil)
-							So(len(gottenJobs), ShouldEqual, 1)
-							So(gottenJobs[0].State, ShouldEqual, JobStateComplete)
+			quotas, err = jq.GetQuotas()
+			So(err, ShouldBeNil)
+			So(len(quotas), ShouldEqual, 1)
+			So(quotas[0].Kind, ShouldEqual, QuotaRepGroup)
+			So(quotas[0].Name, ShouldEqual, "quota")
+			So(quotas[0].Cores, ShouldEqual, 2)
+			So(quotas[0].UsedCores, ShouldEqual, 2)
+			So(quotas[0].UsedCount, ShouldEqual, 2)
+
+			err = jq.Execute(job1, config.RunnerExecShell)
+			So(err, ShouldBeNil)
+			<-time.After(100 * time.Millisecond)
 
-							gottenJobs, err = jq.GetByRepGroup("after-afterfinal", false, 0, "", false, false)
-							So(err, ShouldBeNil)
-							So(len(gottenJobs), ShouldEqual, 1)
-							So(gottenJobs[0].State, ShouldEqual, JobStateReady)
+			job, err = jq.Reserve(50 * time.Millisecond)
+			So(err, ShouldBeNil)
+			So(job, ShouldNotBeNil)
+			So(job.Cmd, ShouldEqual, "echo quota 3")
+			So(job.PendingReason, ShouldBeEmpty)
 
-							faaf, err := jq.Reserve(50 * time.Millisecond)
-							So(err, ShouldBeNil)
-							So(faaf.RepGroup, ShouldEqual, "after-afterfinal")
-							err = jq.Execute(faaf, config.RunnerExecShell)
-							So(err, ShouldBeNil)
+			Convey("And you can remove the quota", func() {
+				inserts, _, err = jq.Add([]*Job{{Cmd: "echo quota 4", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "quota"}}, envVars, true)
+				So(err, ShouldBeNil)
+				So(inserts, ShouldEqual, 1)
+				job, err = jq.Reserve(50 * time.Millisecond)
+				So(err, ShouldBeNil)
+				So(job, ShouldBeNil)
 
-							gottenJobs, err = jq.GetByRepGroup("after-afterfinal", false, 0, "", false, false)
-							So(err, ShouldBeNil)
-							So(len(gottenJobs), ShouldEqual, 1)
-							So(gottenJobs[0].State, ShouldEqual, JobStateComplete)
+				err = jq.SetQuota(&Quota{Kind: QuotaRepGroup, Name: "quota"})
+				So(err, ShouldBeNil)
+				quotas, err = jq.GetQuotas()
+				So(err, ShouldBeNil)
+				So(len(quotas), ShouldEqual, 0)
 
-							jobs = nil
-							jobs = append(jobs, &Job{Cmd: "echo deptest10", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "dep10", DepGroups: []string{"final"}})
-							inserts, already, err = jq.Add(jobs, envVars, true)
-							So(err, ShouldBeNil)
-							So(inserts, ShouldEqual, 3)
-							So(already, ShouldEqual, 0)
+				job, err = jq.Reserve(50 * time.Millisecond)
+				So(err, ShouldBeNil)
+				So(job, ShouldNotBeNil)
+				So(job.Cmd, ShouldEqual, "echo quota 4")
+			})
+		})
+	})
+}
 
-							gottenJobs, err = jq.GetByRepGroup("afterfinal", false, 0, "", false, false)
-							So(err, ShouldBeNil)
-							So(len(gottenJobs), ShouldEqual, 1)
-							So(gottenJobs[0].State, ShouldEqual, JobStateDependent)
+func TestJobqueuePreemption(t *testing.T) {
+	if runnermode || servermode {
+		return
+	}
+	config, serverConfig, addr, standardReqs, clientConnectTime := jobqueueTestInit(true)
 
-							gottenJobs, err = jq.GetByRepGroup("after-afterfinal", false, 0, "", false, false)
-							So(err, ShouldBeNil)
-							So(len(gottenJobs), ShouldEqual, 1)
-							So(gottenJobs[0].State, ShouldEqual, JobStateDependent)
-						})
-					})
-				})
+	defer os.RemoveAll(filepath.Join(os.TempDir(), AppName+"_cwd"))
 
-				Convey("You can add jobs with non-existent depgroup dependencies", func() {
-					// first get rid of the jobs added earlier
-					for i := 0; i < 2; i++ {
-						job, err := jq.Reserve(50 * time.Millisecond)
-						So(err, ShouldBeNil)
-						err = jq.Execute(job, config.RunnerExecShell)
-						So(err, ShouldBeNil)
-					}
+	Convey("Once a new jobqueue server is up with preemption enabled", t, func() {
+		ServerItemTTR = 5 * time.Second
+		ClientTouchInterval = 2500 * time.Millisecond
+		ServerPreemptInterval = 100 * time.Millisecond
+		ServerPreemptWait = 200 * time.Millisecond
+
+		// our "runners" just use up the only core, while we act as the real
+		// runner ourselves
+		serverConfig.SchedulerConfig = &jqs.ConfigLocal{Shell: config.RunnerExecShell, MaxCores: 1}
+		serverConfig.RunnerCmd = "sleep 5 # '%s' %s '%s' %s %d %d"
+		serverConfig.PreemptPriority = 100
+		server, _, token, errs := serve(serverConfig)
+		So(errs, ShouldBeNil)
+		defer func() {
+			server.Stop(true)
+		}()
 
-					jobs = nil
-					d5 := NewDepGroupDependency("dep5")
-					jobs = append(jobs, &Job{Cmd: "echo deptest4", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "dep4", Dependencies: Dependencies{d5}})
-					jobs = append(jobs, &Job{Cmd: "echo deptest5", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, Retries: uint8(3), RepGroup: "dep5", DepGroups: []string{"dep5"}})
+		jq, err := Connect(addr, config.ManagerCAFile, config.ManagerCertDomain, token, clientConnectTime)
+		So(err, ShouldBeNil)
+		defer jq.Disconnect()
 
-					inserts, already, err := jq.Add(jobs, envVars, true)
-					So(err, ShouldBeNil)
-					So(inserts, ShouldEqual, 2)
-					So(already, ShouldEqual, 0)
+		inserts, _, err := jq.Add([]*Job{{Cmd: "sleep 20", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "low", Retries: 1}}, envVars, true)
+		So(err, ShouldBeNil)
+		So(inserts, ShouldEqual, 1)
 
-					j5, err := jq.Reserve(50 * time.Millisecond)
-					So(err, ShouldBeNil)
-					So(j5.RepGroup, ShouldEqual, "dep5")
-					jNil, err := jq.Reserve(50 * time.Millisecond)
-					So(err, ShouldBeNil)
-					So(jNil, ShouldBeNil)
+		schedGrp := "110:0:1:0"
+		low, err := jq.ReserveScheduled(50*time.Millisecond, schedGrp)
+		So(err, ShouldBeNil)
+		So(low, ShouldNotBeNil)
+		So(low.Cmd, ShouldEqual, "sleep 20")
+		err = jq.Started(low, os.Getpid())
+		So(err, ShouldBeNil)
+		untilBuried := low.UntilBuried
 
-					err = jq.Execute(j5, config.RunnerExecShell)
-					So(err, ShouldBeNil)
+		Convey("A job at the preempt priority that can't run gets a lower priority one killed and requeued", func() {
+			inserts, _, err = jq.Add([]*Job{{Cmd: "echo urgent", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "urgent", Priority: 200}}, envVars, true)
+			So(err, ShouldBeNil)
+			So(inserts, ShouldEqual, 1)
 
-					j4, err := jq.Reserve(50 * time.Millisecond)
-					So(err, ShouldBeNil)
-					So(j4.RepGroup, ShouldEqual, "dep4")
+			var killCalled bool
+			for i := 0; i < 50; i++ {
+				killCalled, err = jq.Touch(low)
+				So(err, ShouldBeNil)
+				if killCalled {
+					break
+				}
+				<-time.After(50 * time.Millisecond)
+			}
+			So(killCalled, ShouldBeTrue)
 
-					jq.Release(j4, nil, "")
+			job, err := jq.GetByEssence(&JobEssence{Cmd: "sleep 20"}, false, false)
+			So(err, ShouldBeNil)
+			So(len(job.History), ShouldEqual, 1)
+			So(job.History[0].Event, ShouldEqual, JobEventPreempted)
+			So(job.History[0].Detail, ShouldContainSubstring, "killed to make room for job")
 
-					// *** we should implement rejection of dependency cycles
-					// and test for that
-				})
-			})
+			err = jq.Bury(low, &JobEndState{Exitcode: -1, Exited: true, EndTime: time.Now()}, FailReasonKilled)
+			So(err, ShouldBeNil)
+
+			job, err = jq.GetByEssence(&JobEssence{Cmd: "sleep 20"}, false, false)
+			So(err, ShouldBeNil)
+			So(job.State, ShouldEqual, JobStateReady)
+			So(job.FailReason, ShouldEqual, FailReasonPreempt)
+			So(job.UntilBuried, ShouldEqual, untilBuried)
+
+			job, err = jq.ReserveScheduled(50*time.Millisecond, schedGrp)
+			So(err, ShouldBeNil)
+			So(job, ShouldBeNil)
 		})
 
-		Reset(func() {
-			server.Stop(true)
+		Convey("A job below the preempt priority doesn't cause preemption", func() {
+			inserts, _, err = jq.Add([]*Job{{Cmd: "echo normal", Cwd: "/tmp", ReqGroup: "fake_group", Requirements: standardReqs, RepGroup: "normal", Priority: 99}}, envVars, true)
+			So(err, ShouldBeNil)
+			So(inserts, ShouldEqual, 1)
+
+			<-time.After(500 * time.Millisecond)
+			killCalled, err := jq.Touch(low)
+			So(err, ShouldBeNil)
+			So(killCalled, ShouldBeFalse)
+
+			job, err := jq.GetByEssence(&JobEssence{Cmd: "sleep 20"}, false, false)
+			So(err, ShouldBeNil)
+			So(len(job.History), ShouldEqual, 0)
 		})
 	})
 }
@@ -2936,6 +5318,48 @@ func TestJobqueueLimitGroups(t *testing.T) {
 				So(ok, ShouldBeTrue)
 				So(serr.Err, ShouldEqual, ErrBadLimitGroup)
 			})
+
+			Convey("You can find out why Jobs aren't running using Explain()", func() {
+				jobs := reserveJobs()
+				So(len(jobs), ShouldEqual, 2)
+
+				jobs = []*Job{{Cmd: "echo dep", Cwd: "/tmp", ReqGroup: "rgroup", Requirements: standardReqs, Override: uint8(2), Retries: uint8(0), RepGroup: "dep", Dependencies: Dependencies{NewEssenceDependency("echo 1", "")}}}
+				inserts, _, err := jq.Add(jobs, envVars, true)
+				So(err, ShouldBeNil)
+				So(inserts, ShouldEqual, 1)
+
+				ready, err := jq.GetByRepGroup("ab", false, 0, JobStateReady, false, false)
+				So(err, ShouldBeNil)
+				So(len(ready), ShouldEqual, 3)
+				dependent, err := jq.GetByRepGroup("dep", false, 0, "", false, false)
+				So(err, ShouldBeNil)
+				So(len(dependent), ShouldEqual, 1)
+
+				exps, err := jq.Explain(jobsToJobEssenses([]*Job{ready[0], dependent[0]}))
+				So(err, ShouldBeNil)
+				So(len(exps), ShouldEqual, 2)
+				for _, exp := range exps {
+					switch exp.Key {
+					case ready[0].Key():
+						So(exp.State, ShouldEqual, JobStateReady)
+						So(exp.Reasons, ShouldContain, "its limit group b is full (2 running, limit 2)")
+					case dependent[0].Key():
+						So(exp.State, ShouldEqual, JobStateDependent)
+						So(len(exp.Reasons), ShouldEqual, 1)
+						So(exp.Reasons[0], ShouldStartWith, "it is waiting for [echo 1]")
+						So(exp.Reasons[0], ShouldEndWith, "to complete successfully; it is currently "+string(JobStateReserved))
+					}
+				}
+
+				_, _, err = jq.PauseServer()
+				So(err, ShouldBeNil)
+				exps, err = jq.Explain(jobsToJobEssenses(ready[:1]))
+				So(err, ShouldBeNil)
+				So(len(exps), ShouldEqual, 1)
+				So(exps[0].Reasons, ShouldContain, "the manager has been paused; use \"wr manager resume\" to let jobs start again")
+				err = jq.ResumeServer()
+				So(err, ShouldBeNil)
+			})
 		})
 
 		Reset(func() {
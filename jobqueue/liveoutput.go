@@ -0,0 +1,139 @@
+// Copyright © 2026 Genome Research Limited
+// Author: Sendu Bala <sb10@sanger.ac.uk>.
+//
+//  This file is part of wr.
+//
+//  wr is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Lesser General Public License as published by
+//  the Free Software Foundation, either version 3 of the License, or
+//  (at your option) any later version.
+//
+//  wr is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Lesser General Public License for more details.
+//
+//  You should have received a copy of the GNU Lesser General Public License
+//  along with wr. If not, see <http://www.gnu.org/licenses/>.
+
+package jobqueue
+
+// This file contains the code for streaming the STDOUT and STDERR of running
+// Jobs from runners, via the server, to anyone following that output.
+
+import (
+	"sync"
+	"time"
+)
+
+// these global variables are primarily exported for testing purposes; you
+// probably shouldn't change them
+var (
+	// LiveOutputMax is the maximum number of bytes of each of STDOUT and STDERR
+	// that will be held for a running Job, on both the runner (between sends
+	// to the server) and the server. Older output is discarded.
+	LiveOutputMax = 1024 * 1024
+
+	// LiveOutputLinger is how long the server keeps the output of a Job after
+	// it stops running, so that followers can receive its final output.
+	LiveOutputLinger = 1 * time.Minute
+)
+
+// JobOutput holds some of the STDOUT and STDERR of a running Job's Cmd, as
+// returned by Client.GetOutput().
+type JobOutput struct {
+	// Key is the key of the Job this output came from.
+	Key    string
+	Stdout []byte
+	Stderr []byte
+
+	// StdoutOffset and StderrOffset are the positions in the complete streams
+	// just after Stdout and Stderr, to supply to your next GetOutput() call.
+	StdoutOffset int64
+	StderrOffset int64
+
+	// Running is false once the Cmd has stopped running (or was not running
+	// in the first place), meaning no more output will arrive.
+	Running bool
+}
+
+// liveStream holds the most recent part of a stream of output.
+type liveStream struct {
+	data []byte
+	// the position of data[0] in the complete stream
+	start int64
+}
+
+// append adds to the stream, discarding older data to stay within
+// LiveOutputMax.
+func (ls *liveStream) append(p []byte) {
+	ls.data = append(ls.data, p...)
+	if excess := len(ls.data) - LiveOutputMax; excess > 0 {
+		ls.data = append([]byte(nil), ls.data[excess:]...)
+		ls.start += int64(excess)
+	}
+}
+
+// since returns the data we hold after the given position in the complete
+// stream, and the position just after the end of that data.
+func (ls *liveStream) since(offset int64) ([]byte, int64) {
+	end := ls.start + int64(len(ls.data))
+	if offset < ls.start {
+		offset = ls.start
+	}
+	if offset >= end {
+		return nil, end
+	}
+	return append([]byte(nil), ls.data[offset-ls.start:]...), end
+}
+
+// liveOutput is what the server stores for each Job it has received output
+// for.
+type liveOutput struct {
+	sync.RWMutex
+	stdout liveStream
+	stderr liveStream
+}
+
+// append adds the given output to our streams.
+func (lo *liveOutput) append(stdout, stderr []byte) {
+	lo.Lock()
+	defer lo.Unlock()
+	lo.stdout.append(stdout)
+	lo.stderr.append(stderr)
+}
+
+// since returns a JobOutput for the given Job key containing our output after
+// the given positions in the complete streams.
+func (lo *liveOutput) since(key string, stdoutOffset, stderrOffset int64) *JobOutput {
+	lo.RLock()
+	defer lo.RUnlock()
+	out := &JobOutput{Key: key}
+	out.Stdout, out.StdoutOffset = lo.stdout.since(stdoutOffset)
+	out.Stderr, out.StderrOffset = lo.stderr.since(stderrOffset)
+	return out
+}
+
+// outputBuffer is used by runners to collect a stream of output from a running
+// Cmd between sends to the server.
+type outputBuffer struct {
+	sync.Mutex
+	stream liveStream
+}
+
+// Write implements io.Writer.
+func (ob *outputBuffer) Write(p []byte) (int, error) {
+	ob.Lock()
+	defer ob.Unlock()
+	ob.stream.append(p)
+	return len(p), nil
+}
+
+// take returns and removes everything written so far.
+func (ob *outputBuffer) take() []byte {
+	ob.Lock()
+	defer ob.Unlock()
+	data := ob.stream.data
+	ob.stream = liveStream{}
+	return data
+}
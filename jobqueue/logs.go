@@ -0,0 +1,166 @@
+// Copyright © 2026 Genome Research Limited
+// Author: Sendu Bala <sb10@sanger.ac.uk>.
+//
+//  This file is part of wr.
+//
+//  wr is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Lesser General Public License as published by
+//  the Free Software Foundation, either version 3 of the License, or
+//  (at your option) any later version.
+//
+//  wr is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Lesser General Public License for more details.
+//
+//  You should have received a copy of the GNU Lesser General Public License
+//  along with wr. If not, see <http://www.gnu.org/licenses/>.
+
+package jobqueue
+
+// This file contains the code for capturing the complete STDOUT and STDERR of
+// each attempt at running a Job's Cmd to log files.
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/VertebrateResequencing/wr/internal"
+)
+
+// Log* constants are the names of the streams that can be captured to log
+// files when a Job has LogDir or LogToManager set.
+const (
+	LogStdout = "stdout"
+	LogStderr = "stderr"
+)
+
+// LogFileName returns the name of the log file that the given stream (one of
+// the Log* constants) of the given attempt at running a Job's Cmd is written
+// to, within the Job's log directory.
+func LogFileName(attempt uint32, stream string) string {
+	return fmt.Sprintf("%d.%s", attempt, stream)
+}
+
+// validLogFileName checks that the given name is something LogFileName() could
+// have returned, so that it is safe to use as part of a path.
+func validLogFileName(name string) bool {
+	parts := strings.SplitN(name, ".", 2)
+	if len(parts) != 2 || (parts[1] != LogStdout && parts[1] != LogStderr) {
+		return false
+	}
+	attempt, err := strconv.ParseUint(parts[0], 10, 32)
+	return err == nil && attempt > 0 && parts[0] == strconv.FormatUint(attempt, 10)
+}
+
+// attemptLogs writes the complete STDOUT and STDERR of a single attempt at
+// running a Job's Cmd to log files.
+type attemptLogs struct {
+	dir     string
+	tmp     bool
+	attempt uint32
+	files   map[string]*os.File
+}
+
+// newAttemptLogs prepares to capture the output of the next attempt at running
+// the given Job's Cmd, if it has LogDir or LogToManager set. Otherwise returns
+// nil, which is safe to call methods on.
+func newAttemptLogs(job *Job) (*attemptLogs, error) {
+	if job.LogDir == "" && !job.LogToManager {
+		return nil, nil
+	}
+
+	al := &attemptLogs{attempt: job.Attempts + 1, files: make(map[string]*os.File)}
+	if job.LogDir != "" {
+		al.dir = filepath.Join(internal.TildaToHome(job.LogDir), job.Key())
+		err := os.MkdirAll(al.dir, os.ModePerm)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		dir, err := ioutil.TempDir("", "wr_logs_")
+		if err != nil {
+			return nil, err
+		}
+		al.dir = dir
+		al.tmp = true
+	}
+	return al, nil
+}
+
+// tee returns a reader that also writes everything read from the given one to
+// the log file for the given stream (one of the Log* constants).
+func (al *attemptLogs) tee(r io.Reader, stream string) (io.Reader, error) {
+	if al == nil {
+		return r, nil
+	}
+	f, err := os.OpenFile(filepath.Join(al.dir, LogFileName(al.attempt, stream)), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return r, err
+	}
+	al.files[stream] = f
+	return io.TeeReader(r, f), nil
+}
+
+// finish closes our log files, uploading them to the server if the Job has
+// LogToManager set, then does a cleanup().
+func (al *attemptLogs) finish(c *Client, job *Job) error {
+	if al == nil {
+		return nil
+	}
+	var errs []string
+	for stream, f := range al.files {
+		delete(al.files, stream)
+		err := f.Close()
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if job.LogToManager {
+			err = c.uploadLog(job, f.Name(), LogFileName(al.attempt, stream))
+			if err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+	}
+	if err := al.cleanup(); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to store log files: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// cleanup closes any of our log files that are still open, and deletes them if
+// they were only temporary. It is safe to call more than once.
+func (al *attemptLogs) cleanup() error {
+	if al == nil {
+		return nil
+	}
+	var errs []string
+	for stream, f := range al.files {
+		delete(al.files, stream)
+		err := f.Close()
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if al.tmp {
+		al.tmp = false
+		err := os.RemoveAll(al.dir)
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
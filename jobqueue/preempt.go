@@ -0,0 +1,407 @@
+// Copyright © 2026 Genome Research Limited
+// Author: Sendu Bala <sb10@sanger.ac.uk>.
+//
+//  This file is part of wr.
+//
+//  wr is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Lesser General Public License as published by
+//  the Free Software Foundation, either version 3 of the License, or
+//  (at your option) any later version.
+//
+//  wr is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Lesser General Public License for more details.
+//
+//  You should have received a copy of the GNU Lesser General Public License
+//  along with wr. If not, see <http://www.gnu.org/licenses/>.
+
+package jobqueue
+
+// This file contains the implementation of preemption, where running Jobs are
+// killed or suspended to make room for higher priority Jobs that would
+// otherwise have to wait for them to finish.
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/VertebrateResequencing/wr/internal"
+	"github.com/VertebrateResequencing/wr/queue"
+	"github.com/gofrs/uuid"
+)
+
+// Preempt* constants are the ways ServerConfig.PreemptMode can make room for
+// urgent Jobs.
+const (
+	PreemptKill    = "kill"
+	PreemptSuspend = "suspend"
+)
+
+// these global variables are primarily exported for testing purposes; you
+// probably shouldn't change them
+var (
+	// ServerPreemptInterval is how often we look for urgent Jobs that other
+	// Jobs should be preempted for.
+	ServerPreemptInterval = 10 * time.Second
+
+	// ServerPreemptWait is how long an urgent Job must have been unable to
+	// start running before we preempt other Jobs for it.
+	ServerPreemptWait = 30 * time.Second
+)
+
+// preempter tracks the Jobs we have preempted and the urgent Jobs we did it
+// for.
+type preempter struct {
+	sync.Mutex
+	priority uint8
+	mode     string
+	waiting  map[string]time.Time // urgent job key => when we first saw it unable to run
+	victims  map[string][]string  // urgent job key => keys of jobs preempted for it
+	runners  map[uuid.UUID]bool   // runners of killed jobs that should now exit
+}
+
+// newPreempter creates a preempter that makes room for Jobs with a Priority
+// greater than the given priority, using one of the Preempt* constants as the
+// mode (defaulting to PreemptKill).
+func newPreempter(priority uint8, mode string) (*preempter, error) {
+	if mode == "" {
+		mode = PreemptKill
+	}
+	if mode != PreemptKill && mode != PreemptSuspend {
+		return nil, fmt.Errorf("preempt mode must be one of '%s' or '%s', not '%s'", PreemptKill, PreemptSuspend, mode)
+	}
+	return &preempter{
+		priority: priority,
+		mode:     mode,
+		waiting:  make(map[string]time.Time),
+		victims:  make(map[string][]string),
+		runners:  make(map[uuid.UUID]bool),
+	}, nil
+}
+
+// stuck returns those of the given urgent ready jobs that have been ready for
+// at least ServerPreemptWait without anything being preempted for them. You
+// must hold the preempter's lock before calling this.
+func (p *preempter) stuck(urgent []*Job) []*Job {
+	now := time.Now()
+	seen := make(map[string]bool)
+	var stuck []*Job
+	for _, job := range urgent {
+		key := job.Key()
+		seen[key] = true
+		if _, preempting := p.victims[key]; preempting {
+			continue
+		}
+		first, waited := p.waiting[key]
+		if !waited {
+			p.waiting[key] = now
+			continue
+		}
+		if now.Sub(first) >= ServerPreemptWait {
+			stuck = append(stuck, job)
+		}
+	}
+
+	for key := range p.waiting {
+		if !seen[key] {
+			delete(p.waiting, key)
+		}
+	}
+	return stuck
+}
+
+// preemptee is a running job that could be preempted.
+type preemptee struct {
+	job      *Job
+	priority uint8
+	started  time.Time
+	cores    float64
+	ram      int
+}
+
+// pickVictims picks the fewest running jobs on a single host that between them
+// use at least the given cores and RAM. Only jobs with a Priority no greater
+// than our threshold and less than the given priority, that haven't already
+// been preempted or otherwise stopped, are considered. On each host, lower
+// priority and then more recently started jobs are picked first, so that the
+// least work is lost. Returns nil if no host has enough suitable jobs. You must
+// hold the preempter's lock before calling this.
+func (p *preempter) pickVictims(priority uint8, cores float64, ram int, running map[string]*Job) []*Job {
+	byHost := make(map[string][]*preemptee)
+	for _, job := range running {
+		job.RLock()
+		if !job.Lost && !job.Suspended && !job.killCalled && !job.suspendCalled && job.preemptedBy == "" && !job.StartTime.IsZero() && job.Priority <= p.priority && job.Priority < priority {
+			byHost[job.Host] = append(byHost[job.Host], &preemptee{
+				job:      job,
+				priority: job.Priority,
+				started:  job.StartTime,
+				cores:    job.Requirements.Cores,
+				ram:      job.Requirements.RAM,
+			})
+		}
+		job.RUnlock()
+	}
+
+	var victims []*Job
+	for _, candidates := range byHost {
+		sort.Slice(candidates, func(i, j int) bool {
+			if candidates[i].priority != candidates[j].priority {
+				return candidates[i].priority < candidates[j].priority
+			}
+			return candidates[i].started.After(candidates[j].started)
+		})
+
+		var picked []*Job
+		var freedCores float64
+		var freedRAM int
+		for _, c := range candidates {
+			picked = append(picked, c.job)
+			freedCores = internal.FloatAdd(freedCores, c.cores)
+			freedRAM += c.ram
+			if !internal.FloatLessThan(freedCores, cores) && freedRAM >= ram {
+				break
+			}
+		}
+		if internal.FloatLessThan(freedCores, cores) || freedRAM < ram {
+			continue
+		}
+
+		if victims == nil || len(picked) < len(victims) {
+			victims = picked
+		}
+	}
+	return victims
+}
+
+// startPreempting begins periodically looking for urgent Jobs that other Jobs
+// should be preempted for, if preemption has been turned on.
+func (s *Server) startPreempting() {
+	if s.preempt == nil || s.rc == "" {
+		return
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer internal.LogPanic(s.Logger, "jobqueue preemption", true)
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(ServerPreemptInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.preemptCheck()
+			case <-s.stopClientHandling:
+				return
+			}
+		}
+	}()
+}
+
+// preemptCheck deals with the aftermath of previous preemptions, then
+// preempts running Jobs for any urgent ready Jobs that the job scheduler has
+// been unable to find room for.
+func (s *Server) preemptCheck() {
+	var urgent []*Job
+	priorities := make(map[*Job]uint8)
+	ready := make(map[string]bool)
+	running := make(map[string]*Job)
+	for _, item := range s.q.AllItems() {
+		job := item.Data.(*Job)
+		switch item.Stats().State {
+		case queue.ItemStateReady:
+			job.RLock()
+			priority := job.Priority
+			job.RUnlock()
+			if priority > s.preempt.priority {
+				urgent = append(urgent, job)
+				priorities[job] = priority
+				ready[item.Key] = true
+			}
+		case queue.ItemStateRun:
+			running[item.Key] = job
+		}
+	}
+	sort.SliceStable(urgent, func(i, j int) bool {
+		return priorities[urgent[i]] > priorities[urgent[j]]
+	})
+
+	s.preempt.Lock()
+	defer s.preempt.Unlock()
+
+	for urgentKey, victimKeys := range s.preempt.victims {
+		if s.preempt.mode == PreemptSuspend {
+			// victims stay suspended until the urgent job stops running
+			if _, urgentRunning := running[urgentKey]; urgentRunning || ready[urgentKey] {
+				continue
+			}
+			for _, key := range victimKeys {
+				if victim, stillRunning := running[key]; stillRunning {
+					s.resumePreempted(victim, urgentKey)
+				}
+			}
+			delete(s.preempt.victims, urgentKey)
+			continue
+		}
+
+		// killed victims are done with once they've all stopped running; if
+		// the urgent job is still waiting after that, it will have to wait
+		// a while again before we preempt anything else for it
+		done := true
+		for _, key := range victimKeys {
+			if _, stillRunning := running[key]; stillRunning {
+				done = false
+				break
+			}
+		}
+		if done {
+			delete(s.preempt.victims, urgentKey)
+			if ready[urgentKey] {
+				s.preempt.waiting[urgentKey] = time.Now()
+			}
+		}
+	}
+
+	for _, job := range s.preempt.stuck(urgent) {
+		s.preemptFor(job, running)
+	}
+}
+
+// preemptFor preempts enough of the given running jobs to make room for the
+// given urgent job, if the job scheduler says there isn't room for it. You must
+// hold the preempter's lock before calling this.
+func (s *Server) preemptFor(job *Job, running map[string]*Job) {
+	job.RLock()
+	key := job.Key()
+	group := job.schedulerGroup
+	priority := job.Priority
+	cores := job.Requirements.Cores
+	ram := job.Requirements.RAM
+	job.RUnlock()
+
+	s.sgcmutex.Lock()
+	req, scheduled := s.sgtr[group]
+	s.sgcmutex.Unlock()
+	if !scheduled {
+		return
+	}
+
+	cmd := fmt.Sprintf(s.rc, group, s.ServerInfo.Deployment, s.ServerInfo.Addr, s.ServerInfo.Host, s.scheduler.ReserveTimeout(req), int(s.scheduler.MaxQueueTime(req).Minutes()))
+	if !s.scheduler.Starved(cmd, req) {
+		return
+	}
+
+	victims := s.preempt.pickVictims(priority, cores, ram, running)
+	if len(victims) == 0 {
+		s.Debug("nothing to preempt for urgent job", "cmd", job.Cmd)
+		return
+	}
+
+	// make sure the room we make goes to the urgent job's runner, and not to
+	// some other waiting runner
+	s.scheduler.Prioritise(cmd)
+
+	keys := make([]string, len(victims))
+	for i, victim := range victims {
+		keys[i] = victim.Key()
+		s.preemptJob(victim, key, priority)
+	}
+	s.preempt.victims[key] = keys
+	delete(s.preempt.waiting, key)
+}
+
+// preemptJob kills or suspends the given running job to make room for the job
+// with the given key and priority, recording this in the job's History. You
+// must hold the preempter's lock before calling this.
+func (s *Server) preemptJob(job *Job, urgentKey string, urgentPriority uint8) {
+	job.Lock()
+	job.preemptedBy = urgentKey
+	if s.preempt.mode == PreemptSuspend {
+		job.suspendCalled = true
+		job.addEvent(JobEventPreempted, fmt.Sprintf("suspended to make room for job %s (priority %d)", urgentKey, urgentPriority))
+	} else {
+		// the runner of a killed job has to exit, else it would just use the
+		// room we made to run something else
+		job.killCalled = true
+		s.preempt.runners[job.ReservedBy] = true
+		job.addEvent(JobEventPreempted, fmt.Sprintf("killed to make room for job %s (priority %d)", urgentKey, urgentPriority))
+	}
+	job.Unlock()
+
+	s.db.updateJobAfterChange(job)
+	s.Debug("preempted job", "cmd", job.Cmd, "mode", s.preempt.mode, "for", urgentKey)
+}
+
+// resumePreempted continues a job we suspended to make room for the job with
+// the given key, now that that job has stopped running. You must hold the
+// preempter's lock before calling this.
+func (s *Server) resumePreempted(job *Job, urgentKey string) {
+	job.Lock()
+	if job.preemptedBy != urgentKey {
+		job.Unlock()
+		return
+	}
+	job.preemptedBy = ""
+	job.suspendCalled = false
+	job.addEvent(JobEventResumed, fmt.Sprintf("job %s that it was suspended for stopped running", urgentKey))
+	job.Unlock()
+
+	s.db.updateJobAfterChange(job)
+	s.Debug("resumed preempted job", "cmd", job.Cmd)
+}
+
+// killedByPreemption tells you if the given job, which its runner says was
+// killed for the given reason, was killed because we preempted it.
+func (s *Server) killedByPreemption(job *Job, failReason string) bool {
+	if s.preempt == nil || failReason != FailReasonKilled {
+		return false
+	}
+	job.RLock()
+	defer job.RUnlock()
+	return job.preemptedBy != ""
+}
+
+// requeuePreempted moves a job that was killed because we preempted it from the
+// run queue back to the ready queue, without it counting against its retries.
+func (s *Server) requeuePreempted(job *Job, endState *JobEndState) error {
+	job.updateAfterExit(endState, s.limiter)
+	job.Lock()
+	key := job.Key()
+	job.FailReason = FailReasonPreempt
+	job.preemptedBy = ""
+	sgroup := job.schedulerGroup
+	errq := s.q.SetDelay(key, 0*time.Second)
+	if errq == nil {
+		errq = s.q.Release(key)
+	}
+	job.State = JobStateReady
+	job.Unlock()
+	if errq != nil {
+		return errq
+	}
+
+	s.decrementGroupCount(job.getSchedulerGroup())
+	s.db.updateJobAfterExit(job, endState.Stdout, endState.Stderr, false)
+	s.noteUsage(job)
+	s.Debug("requeued preempted job", "cmd", job.Cmd, "schedGrp", sgroup)
+	return nil
+}
+
+// preemptedRunner tells you if the runner with the given id was running a job
+// that we killed because we preempted it, in which case it should not reserve
+// any more jobs. It only tells you this once.
+func (s *Server) preemptedRunner(clientID uuid.UUID) bool {
+	if s.preempt == nil {
+		return false
+	}
+	s.preempt.Lock()
+	defer s.preempt.Unlock()
+	if s.preempt.runners[clientID] {
+		delete(s.preempt.runners, clientID)
+		return true
+	}
+	return false
+}
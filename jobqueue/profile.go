@@ -0,0 +1,118 @@
+// Copyright © 2026 Genome Research Limited
+// Author: Sendu Bala <sb10@sanger.ac.uk>.
+//
+//  This file is part of wr.
+//
+//  wr is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Lesser General Public License as published by
+//  the Free Software Foundation, either version 3 of the License, or
+//  (at your option) any later version.
+//
+//  wr is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Lesser General Public License for more details.
+//
+//  You should have received a copy of the GNU Lesser General Public License
+//  along with wr. If not, see <http://www.gnu.org/licenses/>.
+
+package jobqueue
+
+// This file contains the implementation of recording how the resource usage of
+// a Job's Cmd changes over time while it runs.
+
+import (
+	"time"
+)
+
+// profileMaxSamples is the most ResourceSamples we keep for a Job's
+// ResourceProfile; this keeps the profiles of long running Jobs small enough
+// to send with every touch and store in the database.
+const profileMaxSamples = 120
+
+// ResourceSample describes the resources a Job's Cmd was using at a point in
+// time. When samples have been merged to keep a ResourceProfile small, RAM and
+// Disk are the highest of the merged values, so that peaks are never lost.
+type ResourceSample struct {
+	// Time is when the sample was taken.
+	Time time.Time
+
+	// RAM is the memory in use, in MB.
+	RAM int
+
+	// Disk is the disk space used, in MB.
+	Disk int64
+
+	// CPU is the total CPU time used since the Cmd started.
+	CPU time.Duration
+}
+
+// resourceProfiler accumulates ResourceSamples taken at regular intervals,
+// downsampling them so that there are never more than profileMaxSamples.
+type resourceProfiler struct {
+	start    time.Time
+	interval time.Duration
+	series   []*ResourceSample
+}
+
+// newResourceProfiler creates a resourceProfiler for samples that will be
+// taken every interval, starting now.
+func newResourceProfiler(interval time.Duration) *resourceProfiler {
+	return &resourceProfiler{start: time.Now(), interval: interval}
+}
+
+// add adds a sample to the profile. If that makes the profile too big, the
+// interval between samples is doubled and existing samples within each new
+// interval are merged.
+func (p *resourceProfiler) add(sample *ResourceSample) {
+	p.merge(sample)
+	for len(p.series) > profileMaxSamples {
+		p.interval *= 2
+		old := p.series
+		p.series = nil
+		for _, s := range old {
+			p.merge(s)
+		}
+	}
+}
+
+// merge adds the sample to the end of our series, or if it is in the same
+// interval as the last sample, merges it in to that one.
+func (p *resourceProfiler) merge(sample *ResourceSample) {
+	if n := len(p.series); n > 0 {
+		last := p.series[n-1]
+		if p.bucket(last.Time) == p.bucket(sample.Time) {
+			last.Time = sample.Time
+			if sample.RAM > last.RAM {
+				last.RAM = sample.RAM
+			}
+			if sample.Disk > last.Disk {
+				last.Disk = sample.Disk
+			}
+			if sample.CPU > last.CPU {
+				last.CPU = sample.CPU
+			}
+			return
+		}
+	}
+	s := *sample
+	p.series = append(p.series, &s)
+}
+
+// bucket returns which of our intervals the given time falls in.
+func (p *resourceProfiler) bucket(t time.Time) int64 {
+	return int64(t.Sub(p.start) / p.interval)
+}
+
+// samples returns a copy of the samples in the profile, oldest first.
+func (p *resourceProfiler) samples() []*ResourceSample {
+	if len(p.series) == 0 {
+		return nil
+	}
+	samples := make([]*ResourceSample, len(p.series))
+	for i, s := range p.series {
+		c := *s
+		samples[i] = &c
+	}
+	return samples
+}
@@ -0,0 +1,419 @@
+// Copyright © 2026 Genome Research Limited
+// Author: Sendu Bala <sb10@sanger.ac.uk>.
+//
+//  This file is part of wr.
+//
+//  wr is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Lesser General Public License as published by
+//  the Free Software Foundation, either version 3 of the License, or
+//  (at your option) any later version.
+//
+//  wr is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Lesser General Public License for more details.
+//
+//  You should have received a copy of the GNU Lesser General Public License
+//  along with wr. If not, see <http://www.gnu.org/licenses/>.
+
+package jobqueue
+
+// This file contains the implementation of quotas on the resources that the
+// running Jobs of an owner or RepGroup can use at once.
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Quota* constants are the kinds of things a Quota can apply to.
+const (
+	QuotaOwner    = "owner"
+	QuotaRepGroup = "repgroup"
+)
+
+// Quota limits the resources that the Jobs of a particular owner or RepGroup
+// can use while running. Jobs that would take their owner or RepGroup over
+// quota stay in the ready state, with their PendingReason saying why, until
+// enough of the others finish running.
+type Quota struct {
+	// Kind is one of the Quota* constants.
+	Kind string
+
+	// Name is the name of the owner or RepGroup.
+	Name string
+
+	// Cores is the maximum total number of cores that running Jobs can have
+	// reserved. 0 means there is no quota on cores.
+	Cores float64
+
+	// RAM is the maximum total amount of memory, in GB, that running Jobs can
+	// have reserved. 0 means there is no quota on memory.
+	RAM float64
+
+	// Count is the maximum number of Jobs that can be running. 0 means there
+	// is no quota on the number of Jobs.
+	Count int
+
+	// The remaining properties are set by the server when you call
+	// Client.GetQuotas(); it is meaningless to set these yourself.
+
+	// the total cores reserved by currently running jobs.
+	UsedCores float64
+	// the total memory (GB) reserved by currently running jobs.
+	UsedRAM float64
+	// the number of currently running jobs.
+	UsedCount int
+	// the number of ready jobs being held back by this quota.
+	Pending int
+}
+
+// validate checks that the Quota is for a known kind of thing and has sensible
+// values.
+func (q *Quota) validate() error {
+	if q.Kind != QuotaOwner && q.Kind != QuotaRepGroup {
+		return fmt.Errorf("quota kind must be one of '%s' or '%s', not '%s'", QuotaOwner, QuotaRepGroup, q.Kind)
+	}
+	if q.Name == "" {
+		return fmt.Errorf("quota needs the name of an owner or RepGroup")
+	}
+	if q.Cores < 0 || q.RAM < 0 || q.Count < 0 {
+		return fmt.Errorf("quota values can't be negative")
+	}
+	return nil
+}
+
+// unlimited tells you if this Quota doesn't actually limit anything.
+func (q *Quota) unlimited() bool {
+	return q.Cores == 0 && q.RAM == 0 && q.Count == 0
+}
+
+// String returns a short human readable description of the quota limits.
+func (q *Quota) String() string {
+	var limits []string
+	if q.Cores > 0 {
+		limits = append(limits, fmt.Sprintf("%s cores", strconv.FormatFloat(q.Cores, 'f', -1, 64)))
+	}
+	if q.RAM > 0 {
+		limits = append(limits, fmt.Sprintf("%sGB RAM", strconv.FormatFloat(q.RAM, 'f', -1, 64)))
+	}
+	if q.Count > 0 {
+		limits = append(limits, fmt.Sprintf("%d jobs", q.Count))
+	}
+	if len(limits) == 0 {
+		return "unlimited"
+	}
+	return strings.Join(limits, ", ")
+}
+
+// quotaUsage is an amount of resources reserved by running jobs.
+type quotaUsage struct {
+	cores float64
+	ram   float64
+	count int
+}
+
+// add adds the other usage to this one.
+func (u *quotaUsage) add(other quotaUsage) {
+	u.cores += other.cores
+	u.ram += other.ram
+	u.count += other.count
+}
+
+// subtract takes the other usage away from this one.
+func (u *quotaUsage) subtract(other quotaUsage) {
+	u.cores -= other.cores
+	u.ram -= other.ram
+	u.count -= other.count
+}
+
+// exceeds returns a description of the first of the given Quota's limits that
+// would be exceeded by adding the demand to this usage, or "" if it fits.
+func (u *quotaUsage) exceeds(q *Quota, demand quotaUsage) string {
+	switch {
+	case q.Cores > 0 && u.cores+demand.cores > q.Cores:
+		return fmt.Sprintf("%s %s is at its quota of %s cores", q.Kind, q.Name, strconv.FormatFloat(q.Cores, 'f', -1, 64))
+	case q.RAM > 0 && u.ram+demand.ram > q.RAM:
+		return fmt.Sprintf("%s %s is at its quota of %sGB RAM", q.Kind, q.Name, strconv.FormatFloat(q.RAM, 'f', -1, 64))
+	case q.Count > 0 && u.count+demand.count > q.Count:
+		return fmt.Sprintf("%s %s is at its quota of %d running jobs", q.Kind, q.Name, q.Count)
+	}
+	return ""
+}
+
+// quotaKey returns the key we store Quotas and usage under.
+func quotaKey(kind, name string) string {
+	return kind + dbDelimiter + name
+}
+
+// quotaKeys returns the keys of the quotas that could apply to the given job.
+// The job's Owner and RepGroup never change, so this doesn't lock the job.
+func quotaKeys(job *Job) []string {
+	keys := []string{quotaKey(QuotaRepGroup, job.RepGroup)}
+	if job.Owner != "" {
+		keys = append(keys, quotaKey(QuotaOwner, job.Owner))
+	}
+	return keys
+}
+
+// quotaDemand returns the resources the given job would reserve if it ran.
+func quotaDemand(job *Job) quotaUsage {
+	job.RLock()
+	defer job.RUnlock()
+	return quotaUsage{cores: job.Requirements.Cores, ram: float64(job.Requirements.RAM) / 1024, count: 1}
+}
+
+// quotas tracks the quotas that have been set, and the resources reserved by
+// the running jobs of every owner and RepGroup.
+type quotas struct {
+	sync.Mutex
+	limits  map[string]*Quota
+	used    map[string]*quotaUsage
+	claims  map[string][]quotaUsage
+	pending map[string]int
+	db      *db
+}
+
+// newQuotas creates a quotas, loading previously set Quotas from the database.
+func newQuotas(db *db) (*quotas, error) {
+	stored, err := db.retrieveQuotas()
+	if err != nil {
+		return nil, err
+	}
+	limits := make(map[string]*Quota, len(stored))
+	for _, q := range stored {
+		limits[quotaKey(q.Kind, q.Name)] = q
+	}
+	return &quotas{
+		limits:  limits,
+		used:    make(map[string]*quotaUsage),
+		claims:  make(map[string][]quotaUsage),
+		pending: make(map[string]int),
+		db:      db,
+	}, nil
+}
+
+// set stores the given Quota, replacing any previous one for the same owner or
+// RepGroup. A Quota that doesn't limit anything is removed instead.
+func (qs *quotas) set(q *Quota) error {
+	err := q.validate()
+	if err != nil {
+		return err
+	}
+	stored := &Quota{Kind: q.Kind, Name: q.Name, Cores: q.Cores, RAM: q.RAM, Count: q.Count}
+	key := quotaKey(q.Kind, q.Name)
+
+	qs.Lock()
+	defer qs.Unlock()
+	if stored.unlimited() {
+		err = qs.db.removeQuota(key)
+		if err == nil {
+			delete(qs.limits, key)
+		}
+		return err
+	}
+	err = qs.db.storeQuota(key, stored)
+	if err == nil {
+		qs.limits[key] = stored
+	}
+	return err
+}
+
+// claim is our queue.ReserveFilterCallback. It returns true if the given ready
+// job can run without going over any quota, in which case its resources are
+// counted as used until release() is called. Otherwise it sets the job's
+// PendingReason and returns false.
+//
+// This is called while the queue is locked, but that's fine because ready jobs
+// are never locked while their locker waits on the queue.
+func (qs *quotas) claim(data interface{}) bool {
+	job := data.(*Job)
+	keys, demand := quotaKeys(job), quotaDemand(job)
+
+	qs.Lock()
+	_, reason := qs.exceeded(keys, demand, qs.used)
+	if reason == "" {
+		qs.note(job.Key(), keys, demand)
+	}
+	qs.Unlock()
+
+	job.Lock()
+	job.PendingReason = reason
+	job.Unlock()
+	return reason == ""
+}
+
+// exceeded returns the key and a description of the first quota with one of the
+// given keys that would be exceeded by adding the demand to the given usage, or
+// empty strings if nothing would be. You must hold the lock before calling
+// this.
+func (qs *quotas) exceeded(keys []string, demand quotaUsage, used map[string]*quotaUsage) (string, string) {
+	for _, key := range keys {
+		limit, exists := qs.limits[key]
+		if !exists {
+			continue
+		}
+		u := used[key]
+		if u == nil {
+			u = &quotaUsage{}
+		}
+		if reason := u.exceeds(limit, demand); reason != "" {
+			return key, reason
+		}
+	}
+	return "", ""
+}
+
+// add counts the resources of the given running job as used, regardless of any
+// quota. It is for jobs that started running without a claim(), eg. those
+// recovered from the database after a restart.
+func (qs *quotas) add(job *Job) {
+	keys, demand := quotaKeys(job), quotaDemand(job)
+	qs.Lock()
+	defer qs.Unlock()
+	qs.note(job.Key(), keys, demand)
+}
+
+// note records that the job with the given key is using the given resources
+// of the given quota keys. You must hold the lock before calling this.
+func (qs *quotas) note(jobKey string, keys []string, demand quotaUsage) {
+	for _, key := range keys {
+		used, exists := qs.used[key]
+		if !exists {
+			used = &quotaUsage{}
+			qs.used[key] = used
+		}
+		used.add(demand)
+	}
+	qs.claims[jobKey] = append(qs.claims[jobKey], demand)
+}
+
+// release stops counting the resources of the given job, which has stopped
+// running, as used. Because we learn about jobs stopping asynchronously, a job
+// can be claim()ed again before we release its previous run; we release the
+// oldest claim, so its usage is only over-counted in the meantime.
+func (qs *quotas) release(job *Job) {
+	jobKey := job.Key()
+	qs.Lock()
+	defer qs.Unlock()
+	claims := qs.claims[jobKey]
+	if len(claims) == 0 {
+		return
+	}
+	demand := claims[0]
+	if len(claims) == 1 {
+		delete(qs.claims, jobKey)
+	} else {
+		qs.claims[jobKey] = claims[1:]
+	}
+
+	for _, key := range quotaKeys(job) {
+		if used, exists := qs.used[key]; exists {
+			used.subtract(demand)
+			if used.count <= 0 {
+				delete(qs.used, key)
+			}
+		}
+	}
+}
+
+// holdBack works out which of the given ready jobs can't all run at once
+// without going over quota, considering the highest priority ones first. It
+// sets the PendingReason of those that can't (clearing it on the others), and
+// returns them keyed on job key.
+func (qs *quotas) holdBack(readyJobs []*Job) map[string]bool {
+	jobs := make([]*Job, len(readyJobs))
+	copy(jobs, readyJobs)
+	priorities := make(map[*Job]uint8, len(jobs))
+	for _, job := range jobs {
+		job.RLock()
+		priorities[job] = job.Priority
+		job.RUnlock()
+	}
+	sort.SliceStable(jobs, func(i, j int) bool {
+		return priorities[jobs[i]] > priorities[jobs[j]]
+	})
+	demands := make([]quotaUsage, len(jobs))
+	for i, job := range jobs {
+		demands[i] = quotaDemand(job)
+	}
+
+	reasons := make([]string, len(jobs))
+	qs.Lock()
+	pending := make(map[string]int)
+	planned := make(map[string]*quotaUsage)
+	for key, used := range qs.used {
+		if _, exists := qs.limits[key]; exists {
+			plan := *used
+			planned[key] = &plan
+		}
+	}
+	for i, job := range jobs {
+		keys := quotaKeys(job)
+		var heldBy string
+		heldBy, reasons[i] = qs.exceeded(keys, demands[i], planned)
+		if heldBy != "" {
+			pending[heldBy]++
+			continue
+		}
+		for _, key := range keys {
+			if _, exists := qs.limits[key]; !exists {
+				continue
+			}
+			plan, exists := planned[key]
+			if !exists {
+				plan = &quotaUsage{}
+				planned[key] = plan
+			}
+			plan.add(demands[i])
+		}
+	}
+	qs.pending = pending
+	qs.Unlock()
+
+	held := make(map[string]bool)
+	for i, job := range jobs {
+		job.Lock()
+		job.PendingReason = reasons[i]
+		job.Unlock()
+		if reasons[i] != "" {
+			held[job.Key()] = true
+		}
+	}
+	return held
+}
+
+// anyPending tells you if the last holdBack() held back any jobs.
+func (qs *quotas) anyPending() bool {
+	qs.Lock()
+	defer qs.Unlock()
+	return len(qs.pending) > 0
+}
+
+// get returns all the Quotas that have been set, along with their current
+// usage, sorted by kind and name.
+func (qs *quotas) get() []*Quota {
+	qs.Lock()
+	defer qs.Unlock()
+	got := make([]*Quota, 0, len(qs.limits))
+	for key, limit := range qs.limits {
+		q := *limit
+		if used, exists := qs.used[key]; exists {
+			q.UsedCores = used.cores
+			q.UsedRAM = used.ram
+			q.UsedCount = used.count
+		}
+		q.Pending = qs.pending[key]
+		got = append(got, &q)
+	}
+	sort.Slice(got, func(i, j int) bool {
+		if got[i].Kind == got[j].Kind {
+			return got[i].Name < got[j].Name
+		}
+		return got[i].Kind < got[j].Kind
+	})
+	return got
+}
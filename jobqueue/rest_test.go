@@ -36,6 +36,7 @@ import (
 	"github.com/VertebrateResequencing/wr/cloud"
 	"github.com/VertebrateResequencing/wr/internal"
 	jqs "github.com/VertebrateResequencing/wr/jobqueue/scheduler"
+	"github.com/gorilla/websocket"
 	"github.com/inconshreveable/log15"
 	. "github.com/smartystreets/goconvey/convey"
 )
@@ -79,6 +80,10 @@ func TestREST(t *testing.T) {
 	uploadEndPoint := baseURL + "/rest/v1/upload"
 	warningsEndPoint := baseURL + "/rest/v1/warnings/"
 	serversEndPoint := baseURL + "/rest/v1/servers/"
+	explainEndPoint := baseURL + "/rest/v1/explain/"
+	cronsEndPoint := baseURL + "/rest/v1/crons/"
+	profileEndPoint := baseURL + "/rest/v1/profile/"
+	statusWSEndPoint := "wss://" + config.ManagerCertDomain + ":" + config.ManagerWeb + "/status_ws"
 
 	setDomainIP(config.ManagerCertDomain)
 
@@ -233,6 +238,51 @@ func TestREST(t *testing.T) {
 				So(jstati2[1].Key, ShouldEqual, "db1e7d99becace3306c1c2470331c78e")
 			})
 
+			Convey("You can GET explanations of why particular jobs aren't running", func() {
+				req, err := http.NewRequest(http.MethodGet, explainEndPoint+"de6d167c58701e55f5b9f9e1e91d7807", nil)
+				So(err, ShouldBeNil)
+				req.Header.Add("Authorization", bearer)
+				response, err = client.Do(req)
+				So(err, ShouldBeNil)
+				So(response.StatusCode, ShouldEqual, http.StatusOK)
+				responseData, err = ioutil.ReadAll(response.Body)
+				So(err, ShouldBeNil)
+
+				var exps []*JobExplanation
+				err = json.Unmarshal(responseData, &exps)
+				So(err, ShouldBeNil)
+				So(len(exps), ShouldEqual, 1)
+				So(exps[0].Key, ShouldEqual, "de6d167c58701e55f5b9f9e1e91d7807")
+				So(exps[0].State, ShouldEqual, JobStateReady)
+				So(len(exps[0].Reasons), ShouldBeGreaterThan, 0)
+			})
+
+			Convey("You can GET the resource profiles of particular jobs", func() {
+				req, err := http.NewRequest(http.MethodGet, profileEndPoint, nil)
+				So(err, ShouldBeNil)
+				req.Header.Add("Authorization", bearer)
+				response, err = client.Do(req)
+				So(err, ShouldBeNil)
+				So(response.StatusCode, ShouldEqual, http.StatusBadRequest)
+
+				req, err = http.NewRequest(http.MethodGet, profileEndPoint+"de6d167c58701e55f5b9f9e1e91d7807", nil)
+				So(err, ShouldBeNil)
+				req.Header.Add("Authorization", bearer)
+				response, err = client.Do(req)
+				So(err, ShouldBeNil)
+				So(response.StatusCode, ShouldEqual, http.StatusOK)
+				responseData, err = ioutil.ReadAll(response.Body)
+				So(err, ShouldBeNil)
+
+				var profiles []*JobResourceProfile
+				err = json.Unmarshal(responseData, &profiles)
+				So(err, ShouldBeNil)
+				So(len(profiles), ShouldEqual, 1)
+				So(profiles[0].Key, ShouldEqual, "de6d167c58701e55f5b9f9e1e91d7807")
+				So(profiles[0].Samples, ShouldNotBeNil)
+				So(len(profiles[0].Samples), ShouldEqual, 0)
+			})
+
 			Convey("You can GET the status of jobs by RepGroup", func() {
 				req, err := http.NewRequest(http.MethodGet, jobsEndPoint+"/rp1", nil)
 				So(err, ShouldBeNil)
@@ -720,6 +770,131 @@ func TestREST(t *testing.T) {
 			})
 		})
 
+		Convey("You can POST job arrays", func() {
+			inputJobs := []*JobViaJSON{{Cmd: "echo {{value}}", RepGrp: "rparr", ArrayList: []string{"a", "b"}, ArrayName: "letters"}}
+			jsonValue, err := json.Marshal(inputJobs)
+			So(err, ShouldBeNil)
+			req, err := http.NewRequest(http.MethodPost, jobsEndPoint+"/", bytes.NewBuffer(jsonValue))
+			So(err, ShouldBeNil)
+			req.Header.Add("Authorization", bearer)
+			req.Header.Add("Content-Type", "application/json")
+			response, err := client.Do(req)
+			So(err, ShouldBeNil)
+			So(response.StatusCode, ShouldEqual, http.StatusCreated)
+			responseData, err := ioutil.ReadAll(response.Body)
+			So(err, ShouldBeNil)
+			var jstati []JStatus
+			err = json.Unmarshal(responseData, &jstati)
+			So(err, ShouldBeNil)
+			So(len(jstati), ShouldEqual, 2)
+			So(jstati[0].Cmd, ShouldEqual, "echo a")
+			So(jstati[0].ArrayName, ShouldEqual, "letters")
+			So(jstati[0].ArrayIndex, ShouldEqual, 1)
+			So(jstati[1].Cmd, ShouldEqual, "echo b")
+			So(jstati[1].ArrayIndex, ShouldEqual, 2)
+
+			inputJobs = []*JobViaJSON{{Cmd: "echo {{index}}", Array: "3-1"}}
+			jsonValue, err = json.Marshal(inputJobs)
+			So(err, ShouldBeNil)
+			req, err = http.NewRequest(http.MethodPost, jobsEndPoint+"/", bytes.NewBuffer(jsonValue))
+			So(err, ShouldBeNil)
+			req.Header.Add("Authorization", bearer)
+			response, err = client.Do(req)
+			So(err, ShouldBeNil)
+			So(response.StatusCode, ShouldEqual, http.StatusBadRequest)
+		})
+
+		Convey("You can POST jobs that run in containers", func() {
+			inputJobs := []*JobViaJSON{{Cmd: "echo contained", RepGrp: "rpcontainer", ContainerImage: "ubuntu:22.04", ContainerRuntime: "Singularity"}}
+			jsonValue, err := json.Marshal(inputJobs)
+			So(err, ShouldBeNil)
+			req, err := http.NewRequest(http.MethodPost, jobsEndPoint+"/", bytes.NewBuffer(jsonValue))
+			So(err, ShouldBeNil)
+			req.Header.Add("Authorization", bearer)
+			req.Header.Add("Content-Type", "application/json")
+			response, err := client.Do(req)
+			So(err, ShouldBeNil)
+			So(response.StatusCode, ShouldEqual, http.StatusCreated)
+			responseData, err := ioutil.ReadAll(response.Body)
+			So(err, ShouldBeNil)
+			var jstati []JStatus
+			err = json.Unmarshal(responseData, &jstati)
+			So(err, ShouldBeNil)
+			So(len(jstati), ShouldEqual, 1)
+			So(jstati[0].Container, ShouldEqual, "ubuntu:22.04 (singularity)")
+
+			inputJobs = []*JobViaJSON{{Cmd: "echo contained", ContainerImage: "ubuntu:22.04", ContainerRuntime: "podman"}}
+			jsonValue, err = json.Marshal(inputJobs)
+			So(err, ShouldBeNil)
+			req, err = http.NewRequest(http.MethodPost, jobsEndPoint+"/", bytes.NewBuffer(jsonValue))
+			So(err, ShouldBeNil)
+			req.Header.Add("Authorization", bearer)
+			response, err = client.Do(req)
+			So(err, ShouldBeNil)
+			So(response.StatusCode, ShouldEqual, http.StatusBadRequest)
+		})
+
+		Convey("You can POST, GET and DELETE crons", func() {
+			cvj := &CronViaJSON{Name: "nightly", Schedule: "30 2 * * *", Job: &JobViaJSON{Cmd: "echo cron"}}
+			jsonValue, err := json.Marshal(cvj)
+			So(err, ShouldBeNil)
+			req, err := http.NewRequest(http.MethodPost, cronsEndPoint, bytes.NewBuffer(jsonValue))
+			So(err, ShouldBeNil)
+			req.Header.Add("Authorization", bearer)
+			req.Header.Add("Content-Type", "application/json")
+			response, err := client.Do(req)
+			So(err, ShouldBeNil)
+			So(response.StatusCode, ShouldEqual, http.StatusCreated)
+			responseData, err := ioutil.ReadAll(response.Body)
+			So(err, ShouldBeNil)
+			var cstati []CronStatus
+			err = json.Unmarshal(responseData, &cstati)
+			So(err, ShouldBeNil)
+			So(len(cstati), ShouldEqual, 1)
+			So(cstati[0].Name, ShouldEqual, "nightly")
+			So(cstati[0].Overlap, ShouldEqual, CronOverlapSkip)
+			So(cstati[0].RepGroup, ShouldEqual, "nightly")
+			So(cstati[0].Cmd, ShouldEqual, "echo cron")
+			So(cstati[0].Next, ShouldNotBeEmpty)
+			So(cstati[0].Last, ShouldBeEmpty)
+
+			cvj.Schedule = "61 * * * *"
+			jsonValue, err = json.Marshal(cvj)
+			So(err, ShouldBeNil)
+			req, err = http.NewRequest(http.MethodPost, cronsEndPoint, bytes.NewBuffer(jsonValue))
+			So(err, ShouldBeNil)
+			req.Header.Add("Authorization", bearer)
+			response, err = client.Do(req)
+			So(err, ShouldBeNil)
+			So(response.StatusCode, ShouldEqual, http.StatusBadRequest)
+
+			req, err = http.NewRequest(http.MethodGet, cronsEndPoint, nil)
+			So(err, ShouldBeNil)
+			req.Header.Add("Authorization", bearer)
+			response, err = client.Do(req)
+			So(err, ShouldBeNil)
+			responseData, err = ioutil.ReadAll(response.Body)
+			So(err, ShouldBeNil)
+			err = json.Unmarshal(responseData, &cstati)
+			So(err, ShouldBeNil)
+			So(len(cstati), ShouldEqual, 1)
+			So(cstati[0].Schedule, ShouldEqual, "30 2 * * *")
+
+			req, err = http.NewRequest(http.MethodDelete, cronsEndPoint+"nightly", nil)
+			So(err, ShouldBeNil)
+			req.Header.Add("Authorization", bearer)
+			response, err = client.Do(req)
+			So(err, ShouldBeNil)
+			So(response.StatusCode, ShouldEqual, http.StatusOK)
+
+			req, err = http.NewRequest(http.MethodDelete, cronsEndPoint+"nightly", nil)
+			So(err, ShouldBeNil)
+			req.Header.Add("Authorization", bearer)
+			response, err = client.Do(req)
+			So(err, ShouldBeNil)
+			So(response.StatusCode, ShouldEqual, http.StatusNotFound)
+		})
+
 		Convey("Initial GET queries on the warnings and servers endpoints return nothing", func() {
 			req, err := http.NewRequest(http.MethodGet, serversEndPoint, nil)
 			So(err, ShouldBeNil)
@@ -780,6 +955,94 @@ func TestREST(t *testing.T) {
 			})
 		})
 
+		Convey("Only admins can dismiss warnings and destroy bad servers", func() {
+			userToken, err := server.addUser("user1", false)
+			So(err, ShouldBeNil)
+
+			server.simutex.Lock()
+			server.schedIssues["msg1"] = &schedulerIssue{
+				Msg:       "msg1",
+				FirstDate: time.Now().Unix(),
+				LastDate:  time.Now().Unix(),
+				Count:     1,
+			}
+			server.simutex.Unlock()
+			cloudServer := &cloud.Server{
+				ID:   "serverid1",
+				Name: "name",
+				IP:   "192.168.0.1",
+			}
+			cloudServer.GoneBad()
+			server.bsmutex.Lock()
+			server.badServers["serverid1"] = cloudServer
+			server.bsmutex.Unlock()
+
+			numIssuesAndBadServers := func() (int, int) {
+				server.simutex.RLock()
+				defer server.simutex.RUnlock()
+				server.bsmutex.RLock()
+				defer server.bsmutex.RUnlock()
+				return len(server.schedIssues), len(server.badServers)
+			}
+
+			req, err := http.NewRequest(http.MethodGet, warningsEndPoint, nil)
+			So(err, ShouldBeNil)
+			req.Header.Add("Authorization", "Bearer "+string(userToken))
+			response, err := client.Do(req)
+			So(err, ShouldBeNil)
+			responseData, err := ioutil.ReadAll(response.Body)
+			So(err, ShouldBeNil)
+			var sis []*schedulerIssue
+			err = json.Unmarshal(responseData, &sis)
+			So(err, ShouldBeNil)
+			So(len(sis), ShouldEqual, 1)
+
+			req, err = http.NewRequest(http.MethodDelete, serversEndPoint+"?id=serverid1", nil)
+			So(err, ShouldBeNil)
+			req.Header.Add("Authorization", "Bearer "+string(userToken))
+			response, err = client.Do(req)
+			So(err, ShouldBeNil)
+			So(response.StatusCode, ShouldEqual, http.StatusForbidden)
+
+			numIssues, numBadServers := numIssuesAndBadServers()
+			So(numIssues, ShouldEqual, 1)
+			So(numBadServers, ShouldEqual, 1)
+
+			// requests over a websocket are handled in order, so once we get
+			// the reply to a tail request, the prior requests have been dealt
+			// with
+			dialer := &websocket.Dialer{TLSClientConfig: tlsConfig}
+			sendWebRequests := func(token []byte) {
+				conn, _, errd := dialer.Dial(statusWSEndPoint+"?token="+string(token), nil)
+				So(errd, ShouldBeNil)
+				defer conn.Close()
+				reqs := []*jstatusReq{
+					{Request: "confirmBadServer", ServerID: "serverid1"},
+					{Request: "dismissMsg", Msg: "msg1"},
+					{Request: "dismissMsgs"},
+					{Request: "tail", Key: "sync"},
+				}
+				for _, req := range reqs {
+					errw := conn.WriteJSON(req)
+					So(errw, ShouldBeNil)
+				}
+				out := &jstatusOutput{}
+				errr := conn.ReadJSON(out)
+				So(errr, ShouldBeNil)
+				So(out.OutputKey, ShouldEqual, "sync")
+			}
+
+			sendWebRequests(userToken)
+			numIssues, numBadServers = numIssuesAndBadServers()
+			So(numIssues, ShouldEqual, 1)
+			So(numBadServers, ShouldEqual, 1)
+
+			sendWebRequests(token)
+			numIssues, numBadServers = numIssuesAndBadServers()
+			So(numIssues, ShouldEqual, 0)
+			So(numBadServers, ShouldEqual, 0)
+		})
+
 		Reset(func() {
 			server.Stop(true)
 		})
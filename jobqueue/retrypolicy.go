@@ -0,0 +1,246 @@
+// Copyright © 2026 Genome Research Limited
+// Author: Sendu Bala <sb10@sanger.ac.uk>.
+//
+//  This file is part of wr.
+//
+//  wr is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Lesser General Public License as published by
+//  the Free Software Foundation, either version 3 of the License, or
+//  (at your option) any later version.
+//
+//  wr is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Lesser General Public License for more details.
+//
+//  You should have received a copy of the GNU Lesser General Public License
+//  along with wr. If not, see <http://www.gnu.org/licenses/>.
+
+package jobqueue
+
+// This file contains the implementation of Job retry policies.
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RetryBackoff* constants are the possible values of RetryPolicy.Backoff.
+const (
+	RetryBackoffFixed       = "fixed"
+	RetryBackoffLinear      = "linear"
+	RetryBackoffExponential = "exponential"
+)
+
+// RetryMaxDelay is the longest a failed Job will ever be delayed before it
+// is retried, used when a RetryPolicy has no MaxDelay of its own.
+var RetryMaxDelay = 24 * time.Hour
+
+// failReasonShortNames lets users refer to our FailReason* constants by short
+// names in RetryPolicy.Reasons.
+var failReasonShortNames = map[string]string{
+	"env":       FailReasonEnv,
+	"cwd":       FailReasonCwd,
+	"start":     FailReasonStart,
+	"cperm":     FailReasonCPerm,
+	"cfound":    FailReasonCFound,
+	"cexit":     FailReasonCExit,
+	"exit":      FailReasonExit,
+	"ram":       FailReasonRAM,
+	"disk":      FailReasonDisk,
+	"time":      FailReasonTime,
+	"docker":    FailReasonDocker,
+	"container": FailReasonContainer,
+	"abnormal":  FailReasonAbnormal,
+	"lost":      FailReasonLost,
+	"signal":    FailReasonSignal,
+	"resource":  FailReasonResource,
+	"mount":     FailReasonMount,
+	"upload":    FailReasonUpload,
+	"output":    FailReasonOutput,
+}
+
+// RetryPolicy describes how a Job should be retried after it fails. It
+// controls how long the Job is delayed before becoming ready to run again, and
+// lets you have different numbers of retries depending on why the Job failed.
+type RetryPolicy struct {
+	// Backoff is one of the RetryBackoff* constants. With RetryBackoffFixed
+	// (or the empty string), every retry is delayed by Delay. With
+	// RetryBackoffLinear, the nth retry is delayed by n*Delay. With
+	// RetryBackoffExponential, the nth retry is delayed by Delay*2^(n-1).
+	Backoff string
+
+	// Delay is the base delay before a failed Job is retried. 0 means use
+	// ClientReleaseDelay.
+	Delay time.Duration
+
+	// MaxDelay caps the delay that Backoff can grow to. 0 means use
+	// RetryMaxDelay.
+	MaxDelay time.Duration
+
+	// Reasons has our FailReason* constants as keys and the number of times a
+	// Job that failed for that reason should be retried as values. When a Job
+	// fails for a reason present here, this value is used instead of the Job's
+	// Retries, and failures for this reason do not use up those Retries. A
+	// value of 0 means never retry.
+	Reasons map[string]int
+}
+
+// NewRetryPolicy creates a RetryPolicy, checking that the given backoff is
+// valid. The keys of reasons can be our FailReason* constants, or the short
+// names "env", "cwd", "start", "cperm", "cfound", "cexit", "exit", "ram",
+// "disk", "time", "docker", "container", "abnormal", "lost", "signal",
+// "resource", "mount", "upload" and "output".
+func NewRetryPolicy(backoff string, delay, maxDelay time.Duration, reasons map[string]int) (*RetryPolicy, error) {
+	switch backoff {
+	case "", RetryBackoffFixed, RetryBackoffLinear, RetryBackoffExponential:
+	default:
+		return nil, fmt.Errorf("retry backoff '%s' is not one of %s, %s or %s", backoff, RetryBackoffFixed, RetryBackoffLinear, RetryBackoffExponential)
+	}
+
+	if delay < 0 || maxDelay < 0 {
+		return nil, fmt.Errorf("retry delays can't be negative")
+	}
+
+	rp := &RetryPolicy{Backoff: backoff, Delay: delay, MaxDelay: maxDelay}
+	if len(reasons) > 0 {
+		rp.Reasons = make(map[string]int, len(reasons))
+		for reason, retries := range reasons {
+			if long, exists := failReasonShortNames[reason]; exists {
+				reason = long
+			} else if !knownFailReason(reason) {
+				return nil, fmt.Errorf("retry reason '%s' is not a known fail reason", reason)
+			}
+			if retries < 0 || retries > 255 {
+				return nil, fmt.Errorf("retries value (%d) for reason '%s' is not in the range 0..255", retries, reason)
+			}
+			rp.Reasons[reason] = retries
+		}
+	}
+
+	return rp, nil
+}
+
+// knownFailReason tells you if the given string is one of our FailReason*
+// constants that can be used in a RetryPolicy.
+func knownFailReason(reason string) bool {
+	for _, long := range failReasonShortNames {
+		if long == reason {
+			return true
+		}
+	}
+	return false
+}
+
+// retriesFor returns the number of retries the policy allows for the given
+// fail reason, and true if the policy has a rule for that reason. It is safe
+// to call on a nil RetryPolicy.
+func (rp *RetryPolicy) retriesFor(reason string) (int, bool) {
+	if rp == nil {
+		return 0, false
+	}
+	retries, exists := rp.Reasons[reason]
+	return retries, exists
+}
+
+// delay returns how long a Job that has failed the given number of times
+// should wait before it is retried. It is safe to call on a nil RetryPolicy,
+// which gives ClientReleaseDelay.
+func (rp *RetryPolicy) delay(failures int) time.Duration {
+	if rp == nil {
+		return ClientReleaseDelay
+	}
+
+	base := rp.Delay
+	if base == 0 {
+		base = ClientReleaseDelay
+	}
+	max := rp.MaxDelay
+	if max == 0 {
+		max = RetryMaxDelay
+	}
+	if failures < 1 {
+		failures = 1
+	}
+
+	d := base
+	switch rp.Backoff {
+	case RetryBackoffLinear:
+		d = base * time.Duration(failures)
+		if d/time.Duration(failures) != base {
+			// overflowed
+			d = max
+		}
+	case RetryBackoffExponential:
+		for i := 1; i < failures && d < max; i++ {
+			d *= 2
+		}
+	}
+
+	if d > max {
+		d = max
+	}
+	return d
+}
+
+// String returns a short human readable description of the policy, suitable
+// for display in status output.
+func (rp *RetryPolicy) String() string {
+	if rp == nil {
+		return ""
+	}
+
+	backoff := rp.Backoff
+	if backoff == "" {
+		backoff = RetryBackoffFixed
+	}
+	base := rp.Delay
+	if base == 0 {
+		base = ClientReleaseDelay
+	}
+	desc := fmt.Sprintf("%s backoff from %s", backoff, base)
+	if rp.MaxDelay > 0 {
+		desc += fmt.Sprintf(" up to %s", rp.MaxDelay)
+	}
+
+	if len(rp.Reasons) > 0 {
+		reasons := make([]string, 0, len(rp.Reasons))
+		for reason, retries := range rp.Reasons {
+			reasons = append(reasons, fmt.Sprintf("%s: %d", reason, retries))
+		}
+		sort.Strings(reasons)
+		desc += "; retries for " + strings.Join(reasons, ", ")
+	}
+	return desc
+}
+
+// RetryPolicyViaJSON describes a RetryPolicy in a way that is convenient to
+// specify in JSON: the delays are durations with unit suffixes (eg. "30s") and
+// the keys of Reasons can be the short names accepted by NewRetryPolicy().
+type RetryPolicyViaJSON struct {
+	Backoff  string         `json:"backoff"`
+	Delay    string         `json:"delay"`
+	MaxDelay string         `json:"max_delay"`
+	Reasons  map[string]int `json:"reasons"`
+}
+
+// RetryPolicy converts a RetryPolicyViaJSON to a real RetryPolicy.
+func (rpj *RetryPolicyViaJSON) RetryPolicy() (*RetryPolicy, error) {
+	var delay, maxDelay time.Duration
+	var err error
+	if rpj.Delay != "" {
+		delay, err = time.ParseDuration(rpj.Delay)
+		if err != nil {
+			return nil, fmt.Errorf("retry delay was not specified correctly: %s", err)
+		}
+	}
+	if rpj.MaxDelay != "" {
+		maxDelay, err = time.ParseDuration(rpj.MaxDelay)
+		if err != nil {
+			return nil, fmt.Errorf("retry max_delay was not specified correctly: %s", err)
+		}
+	}
+	return NewRetryPolicy(rpj.Backoff, delay, maxDelay, rpj.Reasons)
+}
@@ -329,6 +329,23 @@ func (s *k8s) reqCheck(req *Requirements) error {
 	return resp.Error
 }
 
+// suspended does nothing, since the resources of the pod the suspended cmd is
+// running in remain reserved by kubernetes.
+func (s *k8s) suspended(req *Requirements) {}
+
+// resumed does nothing, for the same reason as suspended().
+func (s *k8s) resumed(req *Requirements) {}
+
+// starved always returns false, since kubernetes decides if there's room for
+// our pods.
+func (s *k8s) starved(cmd string, req *Requirements) bool {
+	return false
+}
+
+// prioritise does nothing, since kubernetes decides which of our pods run
+// first.
+func (s *k8s) prioritise(cmd string) {}
+
 // setMessageCallBack sets the given callback function.
 func (s *k8s) setMessageCallBack(cb MessageCallBack) {
 	s.Debug("setMessageCallBack called")
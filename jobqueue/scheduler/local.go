@@ -43,7 +43,8 @@ import (
 const (
 	localPlace          = "localhost"
 	localReserveTimeout = 1
-	priorityScaler      = float64(255) / float64(100)
+	urgentPriority      = 255
+	priorityScaler      = float64(urgentPriority-1) / float64(100)
 	maxZeroCoreJobs     = 1000000 // the maxmimum number of jobs to run when cpu request is 0
 )
 
@@ -105,6 +106,7 @@ type local struct {
 	resourceMutex     sync.RWMutex
 	queue             *queue.Queue
 	running           map[string]int
+	urgent            map[string]bool
 	cleaned           bool
 	reqCheckFunc      reqChecker
 	maxMemFunc        maxResourceGetter
@@ -183,6 +185,7 @@ func (s *local) initialize(config interface{}, logger log15.Logger) error {
 	// make our queue
 	s.queue = queue.New(localPlace)
 	s.running = make(map[string]int)
+	s.urgent = make(map[string]bool)
 
 	// set our functions for use in schedule() and processQueue()
 	s.reqCheckFunc = s.reqCheck
@@ -246,8 +249,9 @@ func (s *local) schedule(cmd string, req *Requirements, count int) error {
 	// priority of this cmd will be based on how "large" it is, which is the max
 	// of the percentage of available memory it needs and percentage of cpus it
 	// needs. A cmd that needs 100% of memory or cpu will be our highest
-	// priority command, which is expressed as priority 255, while one that
-	// needs 0% of resources will be expressed as priority 0.
+	// priority command, which is expressed as priority 254, while one that
+	// needs 0% of resources will be expressed as priority 0. (255 is reserved
+	// for cmds that have been prioritise()d.)
 	maxMem := s.maxMemFunc()
 	maxCPU := s.maxCPUFunc()
 	percentMemNeeded := (float64(req.RAM) / float64(maxMem)) * float64(100)
@@ -266,6 +270,9 @@ func (s *local) schedule(cmd string, req *Requirements, count int) error {
 		count: count,
 	}
 	s.mutex.Lock()
+	if s.urgent[key] {
+		priority = urgentPriority
+	}
 
 	item, err := s.queue.Add(key, "", data, priority, 0*time.Second, 30*time.Second, "") // the ttr just has to be long enough for processQueue() to process a job, not actually run the cmds
 	if err != nil {
@@ -392,6 +399,7 @@ func (s *local) removeKey(key string) {
 	if s.cleaned {
 		return
 	}
+	delete(s.urgent, key)
 	err := s.queue.Remove(key)
 	if err != nil {
 		// warn unless we've already removed this key
@@ -717,6 +725,83 @@ func (s *local) hostToID(host string) string {
 	return ""
 }
 
+// suspended stops counting the given resources as being in use, and sees if
+// something else can now run.
+func (s *local) suspended(req *Requirements) {
+	s.resourceMutex.Lock()
+	s.ram -= req.RAM
+	s.cores = internal.FloatSubtract(s.cores, req.Cores)
+	s.resourceMutex.Unlock()
+
+	go func() {
+		defer internal.LogPanic(s.Logger, "suspended processQueue", true)
+		err := s.processQueue()
+		if err != nil {
+			s.Error("processQueue after suspension failed", "err", err)
+		}
+	}()
+}
+
+// resumed counts the given resources as being in use again. This can result in
+// us using more than our maximum resources until cmds that started while the
+// resumed cmd was suspended exit.
+func (s *local) resumed(req *Requirements) {
+	s.resourceMutex.Lock()
+	s.ram += req.RAM
+	s.cores += req.Cores
+	s.resourceMutex.Unlock()
+}
+
+// starved returns true if the cmd's job in our queue wants more running than
+// are running, and there isn't room to run another.
+func (s *local) starved(cmd string, req *Requirements) bool {
+	key := jobName(cmd, "n/a", false)
+	s.mutex.Lock()
+	item, err := s.queue.Get(key)
+	running := s.running[key]
+	s.mutex.Unlock()
+	if err != nil {
+		return false
+	}
+
+	j := item.Data.(*job)
+	j.RLock()
+	count := j.count
+	j.RUnlock()
+	if count <= running {
+		return false
+	}
+	return s.canCountFunc(req, "") <= 0
+}
+
+// prioritise makes the cmd's job in our queue our highest priority, so that
+// processQueue() considers it before all others, and sees if it can now run.
+func (s *local) prioritise(cmd string) {
+	key := jobName(cmd, "n/a", false)
+	s.mutex.Lock()
+	if s.cleaned {
+		s.mutex.Unlock()
+		return
+	}
+	s.urgent[key] = true
+	item, err := s.queue.Get(key)
+	if err == nil {
+		err = s.queue.Update(key, "", item.Data, urgentPriority, 0*time.Second, 30*time.Second)
+		if err != nil {
+			s.Warn("prioritise item update failed", "err", err)
+		}
+	}
+	s.mutex.Unlock()
+
+	go func() {
+		defer internal.LogPanic(s.Logger, "prioritise processQueue", true)
+		errp := s.processQueue()
+		if errp != nil {
+			s.Error("processQueue after prioritisation failed", "err", errp)
+		}
+	}()
+}
+
 // setMessageCallBack does nothing at the moment, since we don't generate any
 // messages for the user.
 func (s *local) setMessageCallBack(cb MessageCallBack) {}
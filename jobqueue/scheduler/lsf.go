@@ -674,6 +674,20 @@ func (s *lsf) hostToID(host string) string {
 // messages for the user.
 func (s *lsf) setMessageCallBack(cb MessageCallBack) {}
 
+// suspended does nothing, since LSF tracks resource usage itself.
+func (s *lsf) suspended(req *Requirements) {}
+
+// resumed does nothing, since LSF tracks resource usage itself.
+func (s *lsf) resumed(req *Requirements) {}
+
+// starved always returns false, since LSF tracks resource usage itself.
+func (s *lsf) starved(cmd string, req *Requirements) bool {
+	return false
+}
+
+// prioritise does nothing, since LSF decides which of our jobs run first.
+func (s *lsf) prioritise(cmd string) {}
+
 // setBadServerCallBack does nothing, since we're not a cloud-based scheduler.
 func (s *lsf) setBadServerCallBack(cb BadServerCallBack) {}
 
@@ -631,6 +631,7 @@ func (s *opst) initialize(config interface{}, logger log15.Logger) error {
 	// initialize our job queue and other trackers
 	s.queue = queue.New(localPlace)
 	s.running = make(map[string]int)
+	s.urgent = make(map[string]bool)
 
 	// initialise our servers with details of ourself
 	s.servers = make(map[string]*cloud.Server)
@@ -1718,6 +1719,14 @@ func (s *opst) hostToID(host string) string {
 	return server.ID
 }
 
+// suspended does nothing, since our servers' resources are allocated to
+// runners for as long as they run, and we don't know which server the
+// suspended cmd is on.
+func (s *opst) suspended(req *Requirements) {}
+
+// resumed does nothing, for the same reason as suspended().
+func (s *opst) resumed(req *Requirements) {}
+
 // setMessageCallBack sets the given callback.
 func (s *opst) setMessageCallBack(cb MessageCallBack) {
 	s.cbmutex.Lock()
@@ -180,6 +180,10 @@ type scheduleri interface {
 	reserveTimeout(req *Requirements) int                                    // achieve the aims of ReserveTimeout()
 	maxQueueTime(req *Requirements) time.Duration                            // achieve the aims of MaxQueueTime()
 	hostToID(host string) string                                             // achieve the aims of HostToID()
+	suspended(req *Requirements)                                             // achieve the aims of Suspended()
+	resumed(req *Requirements)                                               // achieve the aims of Resumed()
+	starved(cmd string, req *Requirements) bool                              // achieve the aims of Starved()
+	prioritise(cmd string)                                                   // achieve the aims of Prioritise()
 	setMessageCallBack(MessageCallBack)                                      // achieve the aims of SetMessageCallBack()
 	setBadServerCallBack(BadServerCallBack)                                  // achieve the aims of SetBadServerCallBack()
 	cleanup()                                                                // do any clean up once you've finished using the job scheduler
@@ -354,6 +358,37 @@ func (s *Scheduler) HostToID(host string) string {
 	return s.impl.hostToID(host)
 }
 
+// Suspended tells the scheduler that a cmd it is running with the given
+// Requirements has been stopped, so that (for schedulers that track resource
+// usage themselves, ie. the local scheduler) its resources can be used to run
+// other cmds until Resumed() is called with the same Requirements.
+func (s *Scheduler) Suspended(req *Requirements) {
+	s.impl.suspended(req)
+}
+
+// Resumed tells the scheduler that a cmd previously noted as Suspended() is
+// running again and using its resources once more.
+func (s *Scheduler) Resumed(req *Requirements) {
+	s.impl.resumed(req)
+}
+
+// Starved tells you if a cmd you previously Schedule()d has fewer instances
+// running than you asked for because there are currently no free resources to
+// run any more of them. Schedulers that don't track resource usage themselves
+// (ie. all except local and openstack) always return false.
+func (s *Scheduler) Starved(cmd string, req *Requirements) bool {
+	return s.impl.starved(cmd, req)
+}
+
+// Prioritise tells the scheduler that a cmd you previously Schedule()d should
+// be run ahead of all other cmds, regardless of their size, so that resources
+// that become free are used for it first. This lasts until its count drops to
+// 0. Schedulers that don't decide the order cmds run in themselves (ie. all
+// except local and openstack) ignore this.
+func (s *Scheduler) Prioritise(cmd string) {
+	s.impl.prioritise(cmd)
+}
+
 // Cleanup means you've finished using a scheduler and it can delete any
 // remaining jobs in its system and clean up any other used resources.
 func (s *Scheduler) Cleanup() {
@@ -352,6 +352,48 @@ func TestLocal(t *testing.T) {
 			So(first, ShouldHappenBefore, second)
 			So(first, ShouldHappenBefore, second.Add(-400*time.Millisecond))
 		})
+
+		Convey("You can find out if a cmd is starved of resources, and prioritise it", t, func() {
+			s, err := New("local", &ConfigLocal{"bash", 1 * time.Second, 1, 0}, testLogger)
+			So(err, ShouldBeNil)
+			So(s, ShouldNotBeNil)
+
+			tmpDir, err := ioutil.TempDir("", "wr_schedulers_local_test_prioritise_dir_")
+			if err != nil {
+				log.Fatal(err)
+			}
+			defer os.RemoveAll(tmpDir)
+			outFile := filepath.Join(tmpDir, "order")
+
+			req := &Requirements{1, 1 * time.Second, 1, true, 0, true, otherReqs, true}
+			blocker := "sleep 1"
+			firstCmd := fmt.Sprintf("echo first >> %s", outFile)
+			secondCmd := fmt.Sprintf("echo second >> %s", outFile)
+			So(s.Starved(firstCmd, req), ShouldBeFalse)
+
+			err = s.Schedule(blocker, req, 1)
+			So(err, ShouldBeNil)
+			err = s.Schedule(firstCmd, req, 1)
+			So(err, ShouldBeNil)
+			err = s.Schedule(secondCmd, req, 1)
+			So(err, ShouldBeNil)
+			So(s.Starved(blocker, req), ShouldBeFalse)
+			So(s.Starved(firstCmd, req), ShouldBeTrue)
+			So(s.Starved(secondCmd, req), ShouldBeTrue)
+
+			s.Prioritise(secondCmd)
+
+			for {
+				if !s.Busy() {
+					break
+				}
+				<-time.After(1 * time.Millisecond)
+			}
+
+			order, err := ioutil.ReadFile(outFile)
+			So(err, ShouldBeNil)
+			So(string(order), ShouldEqual, "second\nfirst\n")
+		})
 	}
 }
 
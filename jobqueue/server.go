@@ -75,6 +75,12 @@ const (
 	ErrMustReserve      = "you must Reserve() a Job before passing it to other methods"
 	ErrDBError          = "failed to use database"
 	ErrPermissionDenied = "bad token: permission denied"
+	ErrNotAdmin         = "permission denied: only admin users can do that"
+	ErrBadUser          = "bad user (invalid name or already exists)"
+	ErrNoFairShare      = "fair-share scheduling is not enabled"
+	ErrBadQuota         = "bad quota"
+	ErrBadCron          = "bad cron"
+	ErrBadArray         = "bad job array"
 	ErrBeingDrained     = "server is being drained"
 	ErrStopReserving    = "recovered on a new server; you should stop reserving"
 	ErrBadLimitGroup    = "colons in limit group names must be followed by integers"
@@ -123,19 +129,29 @@ type itemErr struct {
 // serverResponse is the struct that the server sends to clients over the
 // network in response to their clientRequest.
 type serverResponse struct {
-	Err        string // string instead of error so we can decode on the client side
-	Added      int
-	Existed    int
-	Modified   map[string]string
-	KillCalled bool
-	Job        *Job
-	Jobs       []*Job
-	Limit      int
-	SInfo      *ServerInfo
-	SStats     *ServerStats
-	DB         []byte
-	Path       string
-	BadServers []*BadServer
+	Err           string // string instead of error so we can decode on the client side
+	Added         int
+	Existed       int
+	Modified      map[string]string
+	KillCalled    bool
+	SuspendCalled bool
+	Job           *Job
+	Jobs          []*Job
+	Limit         int
+	SInfo         *ServerInfo
+	SStats        *ServerStats
+	DB            []byte
+	Path          string
+	File          []byte // compressed bytes of file content
+	Output        *JobOutput
+	BadServers    []*BadServer
+	Token         []byte
+	Users         []*User
+	Shares        []*FairShare
+	Quotas        []*Quota
+	Crons         []*Cron
+	Explanations  []*JobExplanation
+	CachedResult  *cachedResult
 }
 
 // ServerInfo holds basic addressing info about the server.
@@ -207,6 +223,8 @@ type Server struct {
 	ServerVersions     *ServerVersions
 	token              []byte
 	uploadDir          string
+	copyDir            string
+	logDir             string
 	sock               mangos.Socket
 	ch                 codec.Handle
 	db                 *db
@@ -232,6 +250,9 @@ type Server struct {
 	statusCaster    *bcast.Group
 	badServerCaster *bcast.Group
 	schedCaster     *bcast.Group
+	outputCaster    *bcast.Group
+	liveOutputs     map[string]*liveOutput
+	lomutex         sync.RWMutex
 	racCheckTimer   *time.Timer
 	racChecking     bool
 	racCheckReady   int
@@ -245,6 +266,17 @@ type Server struct {
 	killRunners     bool
 	timings         map[string]*timingAvg
 	tmutex          sync.Mutex
+	owner           *User
+	users           map[string]*User // keyed on tokenHash()
+	umutex          sync.RWMutex
+	fairShare       *fairShare
+	quotas          *quotas
+	crons           *crons
+	preempt         *preempter
+	agingInterval   time.Duration
+	agingMax        uint8
+	deadlineBoosts  map[string]bool
+	dlmutex         sync.RWMutex
 	ssmutex         sync.RWMutex // "server state mutex" to protect up, drain, blocking and ServerInfo.Mode
 	log15.Logger
 }
@@ -344,6 +376,61 @@ type ServerConfig struct {
 	// uploaded. Defaults to /tmp.
 	UploadDir string
 
+	// CopyDir is the directory where files copied from runners by the
+	// CopyToManager Behaviour will be stored, in sub-directories named after
+	// the keys of the Jobs they came from. Defaults to a directory named
+	// "copied" inside UploadDir.
+	CopyDir string
+
+	// LogDir is the directory where the STDOUT and STDERR log files of Jobs
+	// with LogToManager set will be stored, in sub-directories named after the
+	// keys of the Jobs. Defaults to a directory named "logs" inside UploadDir.
+	LogDir string
+
+	// FairShare, if set to FairShareOwner or FairShareRepGroup, turns on
+	// fair-share scheduling: amongst ready Jobs of the same Priority, those
+	// belonging to the owner (or RepGroup) that has recently used the fewest
+	// CPU-hours (Requirements.Cores multiplied by wall time) are reserved
+	// first. Defaults to off, where Jobs of the same Priority are reserved in
+	// the order they were added.
+	FairShare string
+
+	// FairShareHalfLife is how quickly past usage is forgotten when FairShare
+	// is set: usage this long ago counts half as much as usage now. Defaults
+	// to FairShareDefaultHalfLife.
+	FairShareHalfLife time.Duration
+
+	// FairShareWeights lets some parties get a bigger share than others when
+	// FairShare is set, keyed on owner or RepGroup. A party with a weight of 2
+	// is entitled to twice the usage of one with the default weight of 1.
+	FairShareWeights map[string]float64
+
+	// PriorityAging, if greater than 0, turns on priority aging: the effective
+	// priority of ready Jobs rises by 1 for every PriorityAging they spend
+	// waiting to be reserved, so that low Priority Jobs eventually run even
+	// while higher Priority Jobs keep being added. Defaults to off.
+	PriorityAging time.Duration
+
+	// PriorityAgingMax is the highest effective priority that PriorityAging
+	// can raise Jobs to. 0 means 255.
+	PriorityAgingMax uint8
+
+	// PreemptPriority, if greater than 0, turns on preemption: when a ready Job
+	// with a Priority greater than PreemptPriority has been unable to start
+	// running for ServerPreemptWait because the job scheduler has no room for
+	// it, enough running Jobs with a Priority of PreemptPriority or less (and
+	// all on the same host) are killed or suspended to make room for it. Only
+	// the local and openstack schedulers track their own resource usage, so
+	// preemption has no effect with other schedulers. Defaults to off.
+	PreemptPriority uint8
+
+	// PreemptMode is PreemptKill (the default) or PreemptSuspend. Killed Jobs
+	// go back to the ready queue without using up any of their Retries.
+	// Suspended Jobs continue once the Job they made room for stops running;
+	// only the local scheduler can make use of the resources of suspended
+	// Jobs, so use PreemptKill with openstack.
+	PreemptMode string
+
 	// Logger is a logger object that will be used to log uncaught errors and
 	// debug statements. "Uncought" errors are all errors generated during
 	// operation that either shouldn't affect the success of operations, and can
@@ -543,6 +630,56 @@ func Serve(config ServerConfig) (s *Server, msg string, token []byte, err error)
 	if uploadDir == "" {
 		uploadDir = "/tmp"
 	}
+	copyDir := config.CopyDir
+	if copyDir == "" {
+		copyDir = filepath.Join(uploadDir, "copied")
+	}
+	logDir := config.LogDir
+	if logDir == "" {
+		logDir = filepath.Join(uploadDir, "logs")
+	}
+
+	// the person running us is an admin user, and there may be other users
+	// that we issued their own tokens to
+	ownerName, err := internal.Username()
+	if err != nil {
+		ownerName = "admin"
+	}
+	users, err := db.retrieveUsers()
+	if err != nil {
+		return s, msg, token, err
+	}
+
+	agingMax := config.PriorityAgingMax
+	if agingMax == 0 {
+		agingMax = 255
+	}
+
+	var fs *fairShare
+	if config.FairShare != "" {
+		fs, err = newFairShare(config.FairShare, config.FairShareHalfLife, config.FairShareWeights, db)
+		if err != nil {
+			return s, msg, token, err
+		}
+	}
+
+	qs, err := newQuotas(db)
+	if err != nil {
+		return s, msg, token, err
+	}
+
+	cs, err := newCrons(db)
+	if err != nil {
+		return s, msg, token, err
+	}
+
+	var pre *preempter
+	if config.PreemptPriority > 0 {
+		pre, err = newPreempter(config.PreemptPriority, config.PreemptMode)
+		if err != nil {
+			return s, msg, token, err
+		}
+	}
 
 	// our limiter will use a callback that gets group limits from our database
 	lcb := func(name string) int {
@@ -555,6 +692,8 @@ func Serve(config ServerConfig) (s *Server, msg string, token []byte, err error)
 		ServerVersions:     &ServerVersions{Version: ServerVersion, API: restAPIVersion},
 		token:              token,
 		uploadDir:          uploadDir,
+		copyDir:            copyDir,
+		logDir:             logDir,
 		sock:               sock,
 		ch:                 new(codec.BincHandle),
 		rpl:                &rgToKeys{lookup: make(map[string]map[string]bool)},
@@ -576,7 +715,18 @@ func Serve(config ServerConfig) (s *Server, msg string, token []byte, err error)
 		badServers:         make(map[string]*cloud.Server),
 		schedCaster:        bcast.NewGroup(),
 		schedIssues:        make(map[string]*schedulerIssue),
+		outputCaster:       bcast.NewGroup(),
+		liveOutputs:        make(map[string]*liveOutput),
 		timings:            make(map[string]*timingAvg),
+		owner:              &User{Name: ownerName, Admin: true},
+		users:              users,
+		fairShare:          fs,
+		quotas:             qs,
+		crons:              cs,
+		preempt:            pre,
+		agingInterval:      config.PriorityAging,
+		agingMax:           agingMax,
+		deadlineBoosts:     make(map[string]bool),
 		Logger:             serverLogger,
 	}
 
@@ -599,15 +749,31 @@ func Serve(config ServerConfig) (s *Server, msg string, token []byte, err error)
 			ttd = cloudConfig.GetServerKeepTime()
 		}
 
+		priorBuried := make(map[string]bool)
+		for _, job := range priorJobs {
+			if job.State == JobStateBuried {
+				priorBuried[job.Key()] = true
+			}
+		}
+		buried := func(key string) bool {
+			return priorBuried[key]
+		}
+
 		var itemdefs []*queue.ItemDef
 		for _, job := range priorJobs {
 			var deps []string
-			deps, err = job.Dependencies.incompleteJobKeys(s.db)
+			var depTypes map[string]queue.DependencyType
+			var unsatisfiable bool
+			deps, depTypes, unsatisfiable, err = job.Dependencies.incompleteJobKeys(s.db, buried)
 			if err != nil {
 				return nil, msg, token, err
 			}
 
-			itemdef := &queue.ItemDef{Key: job.Key(), ReserveGroup: job.getSchedulerGroup(), Data: job, Priority: job.Priority, Delay: 0 * time.Second, TTR: ServerItemTTR, Dependencies: deps}
+			itemdef := &queue.ItemDef{Key: job.Key(), ReserveGroup: job.getSchedulerGroup(), Data: job, Priority: job.Priority, Delay: 0 * time.Second, TTR: ServerItemTTR, Dependencies: deps, DependencyTypes: depTypes, NotBefore: job.notBefore(time.Now())}
+			if unsatisfiable && job.State != JobStateRunning {
+				job.State = JobStateBuried
+				job.FailReason = FailReasonDeps
+			}
 
 			switch job.State {
 			case JobStateRunning:
@@ -621,13 +787,24 @@ func Serve(config ServerConfig) (s *Server, msg string, token []byte, err error)
 					}
 				}
 
-				req := reqForScheduler(job.Requirements)
+				req := reqForScheduler(job.Requirements, job.TimeLimit)
 				errr := s.scheduler.Recover(fmt.Sprintf(s.rc, req.Stringify(), s.ServerInfo.Deployment, s.ServerInfo.Addr, s.ServerInfo.Host, s.scheduler.ReserveTimeout(req), int(s.scheduler.MaxQueueTime(req).Minutes())), req, &scheduler.RecoveredHostDetails{Host: job.Host, UserName: loginUser, TTD: ttd})
 				if errr != nil {
 					s.Warn("recovery of an old cmd failed", "cmd", job.Cmd, "host", job.Host)
 				}
+
+				if job.Suspended {
+					// keep it suspended until the user says otherwise
+					job.suspendCalled = true
+					job.suspendedReq = req
+					s.scheduler.Suspended(req)
+				}
 			case JobStateBuried:
 				itemdef.StartQueue = queue.SubQueueBury
+			default:
+				if job.Held {
+					itemdef.StartQueue = queue.SubQueueHold
+				}
 			}
 
 			itemdefs = append(itemdefs, itemdef)
@@ -638,6 +815,16 @@ func Serve(config ServerConfig) (s *Server, msg string, token []byte, err error)
 		}
 	}
 
+	// make room for urgent jobs, if desired
+	s.startPreempting()
+
+	// don't leave jobs looking ready once their Window closes
+	s.startWindowChecking()
+
+	// add the jobs of crons as they become due
+	s.startCronFiring()
+	s.startDeadlineChecking()
+
 	// set up responding to command-line clients
 	ignoreClientMessages := true
 	wg.Add(1)
@@ -732,6 +919,9 @@ func Serve(config ServerConfig) (s *Server, msg string, token []byte, err error)
 		mux.HandleFunc(restBadServersEndpoint, restBadServers(s))
 		mux.HandleFunc(restFileUploadEndpoint, restFileUpload(s))
 		mux.HandleFunc(restInfoEndpoint, restInfo(s))
+		mux.HandleFunc(restExplainEndpoint, restExplain(s))
+		mux.HandleFunc(restCronsEndpoint, restCrons(s))
+		mux.HandleFunc(restProfileEndpoint, restProfile(s))
 		mux.HandleFunc(restVersionEndpoint, restVersion(s))
 		srv := &http.Server{Addr: httpAddr, Handler: mux}
 		wg.Add(1)
@@ -763,6 +953,12 @@ func Serve(config ServerConfig) (s *Server, msg string, token []byte, err error)
 			defer wg.Done()
 			s.schedCaster.Broadcasting(0)
 		}()
+		wg.Add(1)
+		go func() {
+			defer internal.LogPanic(s.Logger, "jobqueue web server output casting", true)
+			defer wg.Done()
+			s.outputCaster.Broadcasting(0)
+		}()
 
 		badServerCB := func(server *cloud.Server) {
 			s.bsmutex.Lock()
@@ -793,26 +989,7 @@ func Serve(config ServerConfig) (s *Server, msg string, token []byte, err error)
 		}
 		s.scheduler.SetBadServerCallBack(badServerCB)
 
-		messageCB := func(msg string) {
-			s.simutex.Lock()
-			var si *schedulerIssue
-			var existed bool
-			if si, existed = s.schedIssues[msg]; existed {
-				si.LastDate = time.Now().Unix()
-				si.Count = si.Count + 1
-			} else {
-				si = &schedulerIssue{
-					Msg:       msg,
-					FirstDate: time.Now().Unix(),
-					LastDate:  time.Now().Unix(),
-					Count:     1,
-				}
-				s.schedIssues[msg] = si
-			}
-			s.simutex.Unlock()
-			s.schedCaster.Send(si)
-		}
-		s.scheduler.SetMessageCallBack(messageCB)
+		s.scheduler.SetMessageCallBack(s.addSchedulerIssue)
 
 		// wait a while for ListenAndServe() to start listening
 		<-time.After(10 * time.Millisecond)
@@ -1007,7 +1184,7 @@ func (s *Server) uploadFile(source io.Reader, savePath string) (string, error) {
 			s.Error("uploadFile create directory error", "err", err)
 			return "", err
 		}
-		file, err = os.OpenFile(savePath, os.O_RDWR|os.O_CREATE, 0600)
+		file, err = os.OpenFile(savePath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
 		if err != nil {
 			s.Error("uploadFile create file error", "err", err)
 			return "", err
@@ -1066,12 +1243,153 @@ func (s *Server) uploadFile(source io.Reader, savePath string) (string, error) {
 	return savePath, nil
 }
 
+// storeCopiedFile stores the given file data, copied from the given running
+// job's actual cwd by a CopyToManager Behaviour, under the given relative name
+// in a sub-directory of our copyDir named after the job's key. The path it was
+// stored at is noted in the job's CopiedFiles, and returned.
+func (s *Server) storeCopiedFile(job *Job, source io.Reader, name string) (string, error) {
+	name = filepath.Clean(name)
+	if name == "." || filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid name for a copied file: %s", name)
+	}
+
+	savePath, err := s.uploadFile(source, filepath.Join(s.copyDir, job.Key(), name))
+	if err != nil {
+		return "", err
+	}
+
+	job.Lock()
+	defer job.Unlock()
+	for _, copied := range job.CopiedFiles {
+		if copied == savePath {
+			return savePath, nil
+		}
+	}
+	job.CopiedFiles = append(job.CopiedFiles, savePath)
+	return savePath, nil
+}
+
+// removeCopiedFiles deletes any files that were copied to us from the actual
+// cwd of the job with the given key by a CopyToManager Behaviour, along with
+// any of its log files that were uploaded because of LogToManager.
+func (s *Server) removeCopiedFiles(key string) {
+	err := os.RemoveAll(filepath.Join(s.copyDir, key))
+	if err != nil {
+		s.Warn("failed to remove copied files", "key", key, "err", err)
+	}
+	err = os.RemoveAll(filepath.Join(s.logDir, key))
+	if err != nil {
+		s.Warn("failed to remove log files", "key", key, "err", err)
+	}
+}
+
+// startLiveOutput forgets any output we were storing for the job with the
+// given key from a previous run, ready to store the output of its new run.
+func (s *Server) startLiveOutput(key string) {
+	s.lomutex.Lock()
+	defer s.lomutex.Unlock()
+	s.liveOutputs[key] = &liveOutput{}
+}
+
+// storeLiveOutput stores output sent by the runner of the running job with the
+// given key, and sends it to anyone following the job's output on the web
+// interface.
+func (s *Server) storeLiveOutput(key string, stdout, stderr []byte) {
+	s.lomutex.Lock()
+	lo, exists := s.liveOutputs[key]
+	if !exists {
+		lo = &liveOutput{}
+		s.liveOutputs[key] = lo
+	}
+	s.lomutex.Unlock()
+	lo.append(stdout, stderr)
+	s.outputCaster.Send(&JobOutput{Key: key, Stdout: stdout, Stderr: stderr, Running: true})
+}
+
+// endLiveOutput arranges for the output we stored for the job with the given
+// key to be forgotten after LiveOutputLinger, giving followers the chance to
+// get the final output of a job that just stopped running.
+func (s *Server) endLiveOutput(key string) {
+	s.lomutex.RLock()
+	lo, exists := s.liveOutputs[key]
+	s.lomutex.RUnlock()
+	if !exists {
+		return
+	}
+	time.AfterFunc(LiveOutputLinger, func() {
+		s.lomutex.Lock()
+		defer s.lomutex.Unlock()
+		if s.liveOutputs[key] == lo {
+			delete(s.liveOutputs, key)
+		}
+	})
+}
+
+// getLiveOutput returns the output we have stored for the job with the given
+// key after the given positions in its complete STDOUT and STDERR.
+func (s *Server) getLiveOutput(key string, stdoutOffset, stderrOffset int64) *JobOutput {
+	s.lomutex.RLock()
+	lo, exists := s.liveOutputs[key]
+	s.lomutex.RUnlock()
+	out := &JobOutput{Key: key, StdoutOffset: stdoutOffset, StderrOffset: stderrOffset}
+	if exists {
+		out = lo.since(key, stdoutOffset, stderrOffset)
+	}
+	item, err := s.q.Get(key)
+	if err == nil && item.Stats().State == queue.ItemStateRun {
+		// reserved jobs are also in the run sub-queue, but their Cmd hasn't
+		// started yet
+		job := item.Data.(*Job)
+		job.RLock()
+		out.Running = job.State == JobStateRunning
+		job.RUnlock()
+	}
+	return out
+}
+
+// storeLogFile stores the given STDOUT or STDERR log file data of the given
+// running job with the given name (as returned by LogFileName()), in a
+// sub-directory of our logDir named after the job's key.
+func (s *Server) storeLogFile(job *Job, source io.Reader, name string) error {
+	if !validLogFileName(name) {
+		return fmt.Errorf("invalid name for a log file: %s", name)
+	}
+	_, err := s.uploadFile(source, filepath.Join(s.logDir, job.Key(), name))
+	return err
+}
+
+// retrieveLogFile returns the contents of a log file previously stored with
+// storeLogFile().
+func (s *Server) retrieveLogFile(key, name string) ([]byte, error) {
+	if !validLogFileName(name) || key == "" || filepath.Base(key) != key {
+		return nil, fmt.Errorf("invalid job key or log file name: %s/%s", key, name)
+	}
+	return ioutil.ReadFile(filepath.Join(s.logDir, key, name))
+}
+
 // createQueue creates and stores a queue.Queue on the Server and sets up its
 // callbacks.
 func (s *Server) createQueue() {
 	q := queue.New("cmds")
 	s.q = q
 
+	// with fair-share scheduling, jobs of the same priority are reserved in
+	// order of the recent usage of their owner or RepGroup
+	if s.fairShare != nil {
+		q.SetFairShare(s.fairShare.party, s.fairShare.usage)
+	}
+
+	// with priority aging, jobs that have been waiting a long time get
+	// reserved ahead of newer jobs of higher priority
+	if s.agingInterval > 0 {
+		q.SetPriorityAging(s.agingInterval, s.agingMax)
+	}
+
+	// jobs that aren't allowed to start yet due to their BeginAt or Window, or
+	// that would take their owner or RepGroup over quota, are left in the ready
+	// queue
+	q.SetReserveFilter(s.reserveFilter)
+
 	// we set a callback for things entering this queue's ready sub-queue.
 	// This function will be called in a go routine and receives a slice of
 	// all the ready jobs. Based on the requirements, we add to each job a
@@ -1096,9 +1414,18 @@ func (s *Server) createQueue() {
 		groupsScheduledCounts := make(map[string]int)
 		groupsChangedCounts := make(map[string]int)
 		noRecGroups := make(map[string]bool)
+		readyJobs := make([]*Job, 0, len(allitemdata))
+		now := time.Now()
 		for _, inter := range allitemdata {
 			job := inter.(*Job)
 
+			// jobs outside their Window (or before their BeginAt) should wait
+			// in the delay queue instead
+			if s.delayIfNotAllowed(job, now) {
+				continue
+			}
+			readyJobs = append(readyJobs, job)
+
 			// depending on job.Override, get memory, disk and time
 			// recommendations, which are rounded to get fewer larger
 			// groups
@@ -1226,7 +1553,7 @@ func (s *Server) createQueue() {
 				noRec = true
 			}
 
-			req := reqForScheduler(job.Requirements)
+			req := reqForScheduler(job.Requirements, job.TimeLimit)
 
 			prevSchedGroup := job.getSchedulerGroup()
 			schedulerGroup := job.generateSchedulerGroup(req)
@@ -1250,13 +1577,6 @@ func (s *Server) createQueue() {
 			}
 
 			if s.rc != "" {
-				if job.getScheduledRunner() {
-					groupsScheduledCounts[schedulerGroup]++
-				} else {
-					job.setScheduledRunner(true)
-				}
-				groups[schedulerGroup]++
-
 				if noRec {
 					noRecGroups[schedulerGroup] = true
 				}
@@ -1269,7 +1589,31 @@ func (s *Server) createQueue() {
 			}
 		}
 
+		// jobs that would take their owner or RepGroup over quota can't run
+		// yet, so we don't want runners for them sitting idle, nor Reserve()
+		// to have to consider them every time it's called
+		held := s.quotas.holdBack(readyJobs)
+		q.HoldBack(held)
+
 		if s.rc != "" {
+			for _, job := range readyJobs {
+				schedulerGroup := job.getSchedulerGroup()
+				if held[job.Key()] {
+					if job.getScheduledRunner() {
+						job.setScheduledRunner(false)
+						groupsChangedCounts[schedulerGroup]++
+					}
+					continue
+				}
+
+				if job.getScheduledRunner() {
+					groupsScheduledCounts[schedulerGroup]++
+				} else {
+					job.setScheduledRunner(true)
+				}
+				groups[schedulerGroup]++
+			}
+
 			// clear out groups we no longer need
 			for group, count := range groupsChangedCounts {
 				s.decrementGroupCount(group, count)
@@ -1406,6 +1750,40 @@ func (s *Server) createQueue() {
 		}
 		from = subqueueToJobState[fromQ]
 
+		if fromQ == queue.SubQueueRun {
+			// followers of these jobs' output won't get any more, and any
+			// that were suspended no longer are. They also stop counting
+			// towards quotas, which might let held back jobs run
+			for _, inter := range data {
+				job := inter.(*Job)
+				s.endLiveOutput(job.Key())
+				s.setJobSuspended(job, false)
+				s.quotas.release(job)
+			}
+			if s.quotas.anyPending() {
+				q.TriggerReadyAddedCallback()
+			}
+		} else if fromQ == queue.SubQueueNew && toQ == queue.SubQueueRun {
+			// jobs recovered as running didn't get their quota claimed
+			for _, inter := range data {
+				s.quotas.add(inter.(*Job))
+			}
+		}
+
+		if (fromQ == queue.SubQueueDependent || fromQ == queue.SubQueueHold) && toQ == queue.SubQueueBury {
+			// a job that should only run if something failed can never run,
+			// because that thing completed successfully
+			for _, inter := range data {
+				job := inter.(*Job)
+				job.Lock()
+				job.State = JobStateBuried
+				job.FailReason = FailReasonDeps
+				job.Held = false
+				job.Unlock()
+				s.db.updateJobAfterChange(job)
+			}
+		}
+
 		// calculate counts per RepGroup
 		groups := make(map[string]int)
 		groupsLost := make(map[string]int)
@@ -1498,14 +1876,20 @@ func (s *Server) enqueueItems(itemdefs []*queue.ItemDef) (added, dups int, err e
 		return added, dups, err
 	}
 
-	// add to our lookup of job RepGroup to key
+	// add to our lookup of job RepGroup to key; job arrays can also be looked
+	// up by their name
 	s.rpl.Lock()
 	for _, itemdef := range itemdefs {
-		rp := itemdef.Data.(*Job).RepGroup
-		if _, exists := s.rpl.lookup[rp]; !exists {
-			s.rpl.lookup[rp] = make(map[string]bool)
+		job := itemdef.Data.(*Job)
+		for _, rp := range []string{job.RepGroup, job.ArrayName} {
+			if rp == "" {
+				continue
+			}
+			if _, exists := s.rpl.lookup[rp]; !exists {
+				s.rpl.lookup[rp] = make(map[string]bool)
+			}
+			s.rpl.lookup[rp][itemdef.Key] = true
 		}
-		s.rpl.lookup[rp][itemdef.Key] = true
 	}
 	s.rpl.Unlock()
 
@@ -1515,13 +1899,20 @@ func (s *Server) enqueueItems(itemdefs []*queue.ItemDef) (added, dups int, err e
 // createJobs creates new jobs, adding them to the database and the in-memory
 // queue. It returns 2 errors; the first is one of our Err constant strings,
 // the second is the actual error with more details.
-func (s *Server) createJobs(inputJobs []*Job, envkey string, ignoreComplete bool) (added, dups, alreadyComplete int, srerr string, qerr error) {
+func (s *Server) createJobs(inputJobs []*Job, envkey string, ignoreComplete bool, owner *User) (added, dups, alreadyComplete int, srerr string, qerr error) {
+	// expand any job arrays in to their member jobs
+	inputJobs, qerr = expandJobArrays(inputJobs)
+	if qerr != nil {
+		return added, dups, alreadyComplete, ErrBadArray, qerr
+	}
+
 	// create itemdefs for the jobs
 	limitGroups := make(map[string]int)
 	for _, job := range inputJobs {
 		job.Lock()
 		job.EnvKey = envkey
-		job.UntilBuried = job.Retries + 1
+		job.Owner = owner.Name
+		job.resetRetries()
 		if s.rc != "" {
 			job.schedulerGroup = job.Requirements.Stringify()
 		}
@@ -1555,6 +1946,14 @@ func (s *Server) createJobs(inputJobs []*Job, envkey string, ignoreComplete bool
 	// Remove the job that created the new jobs from the queue and when we
 	// recover, at worst the creating job will be run again - no jobs get lost.)
 	jobsToQueue, jobsToUpdate, alreadyComplete, err := s.db.storeNewJobs(inputJobs, ignoreComplete)
+	if err == nil && ignoreComplete {
+		// like make, we don't need to run jobs whose outputs are already up to
+		// date; these get treated as complete so that they satisfy the
+		// dependencies of other jobs
+		var skipped int
+		jobsToQueue, skipped, err = s.skipUpToDateJobs(jobsToQueue)
+		alreadyComplete += skipped
+	}
 	if err != nil {
 		srerr = ErrDBError
 		qerr = err
@@ -1567,13 +1966,28 @@ func (s *Server) createJobs(inputJobs []*Job, envkey string, ignoreComplete bool
 		// their DepGroup dependencies being in cr.Jobs
 		var itemdefs []*queue.ItemDef
 		for _, job := range jobsToQueue {
-			deps, err := job.Dependencies.incompleteJobKeys(s.db)
+			deps, depTypes, unsatisfiable, err := job.Dependencies.incompleteJobKeys(s.db, s.jobIsBuried)
 			if err != nil {
 				srerr = ErrDBError
 				qerr = err
 				break
 			}
-			itemdefs = append(itemdefs, &queue.ItemDef{Key: job.Key(), ReserveGroup: job.getSchedulerGroup(), Data: job, Priority: job.Priority, Delay: 0 * time.Second, TTR: ServerItemTTR, Dependencies: deps})
+			itemdef := &queue.ItemDef{Key: job.Key(), ReserveGroup: job.getSchedulerGroup(), Data: job, Priority: job.Priority, Delay: 0 * time.Second, TTR: ServerItemTTR, Dependencies: deps, DependencyTypes: depTypes, NotBefore: job.notBefore(time.Now())}
+			if job.Held {
+				itemdef.StartQueue = queue.SubQueueHold
+			}
+			if unsatisfiable {
+				// this job wanted to run only if something failed, but that
+				// thing already completed successfully
+				itemdef.StartQueue = queue.SubQueueBury
+				job.Lock()
+				job.State = JobStateBuried
+				job.FailReason = FailReasonDeps
+				job.Held = false
+				job.Unlock()
+				s.db.updateJobAfterChange(job)
+			}
+			itemdefs = append(itemdefs, itemdef)
 		}
 
 		srerr, qerr = s.updateJobDependencies(jobsToUpdate)
@@ -1591,6 +2005,78 @@ func (s *Server) createJobs(inputJobs []*Job, envkey string, ignoreComplete bool
 	return added, dups, alreadyComplete, srerr, qerr
 }
 
+// skipUpToDateJobs finds the jobs amongst the given (just stored) ones whose
+// Outputs are already up to date with their Inputs, marks them as complete
+// without running them, and moves them to the complete bucket of the database.
+// Like make, a job is not skipped if it depends on a job that will run (either
+// one already in the queue, or one of the given jobs that isn't being skipped),
+// since that could rewrite its Inputs. Returns the remaining jobs that still
+// need to be queued, and the number that were skipped.
+func (s *Server) skipUpToDateJobs(jobs []*Job) ([]*Job, int, error) {
+	// find the jobs that are up to date in their own right, along with the
+	// jobs they depend on that haven't completed
+	upToDate := make(map[string][]string)
+	for _, job := range jobs {
+		job.RLock()
+		fresh := job.outputsUpToDate()
+		job.RUnlock()
+		if !fresh {
+			continue
+		}
+		deps, _, _, err := job.Dependencies.incompleteJobKeys(s.db, s.jobIsBuried)
+		if err != nil {
+			return jobs, 0, err
+		}
+		upToDate[job.Key()] = deps
+	}
+
+	// staleness carries through to dependent jobs, so keep discounting jobs
+	// that depend on anything that isn't going to be skipped
+	for changed := true; changed; {
+		changed = false
+		for key, deps := range upToDate {
+			for _, dep := range deps {
+				if _, skipping := upToDate[dep]; !skipping {
+					delete(upToDate, key)
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	var toQueue, skipped []*Job
+	now := time.Now()
+	for _, job := range jobs {
+		if _, skip := upToDate[job.Key()]; !skip {
+			toQueue = append(toQueue, job)
+			continue
+		}
+		job.Lock()
+		job.State = JobStateComplete
+		job.Skipped = true
+		job.Exited = true
+		job.Exitcode = 0
+		job.StartTime = now
+		job.EndTime = now
+		job.Unlock()
+		skipped = append(skipped, job)
+	}
+
+	if len(skipped) == 0 {
+		return jobs, 0, nil
+	}
+
+	err := s.db.archiveSkippedJobs(skipped)
+	if err != nil {
+		return jobs, 0, err
+	}
+	for _, job := range skipped {
+		s.Debug("skipped job with up to date outputs", "cmd", job.Cmd)
+	}
+	return toQueue, len(skipped), nil
+}
+
 // handleUserSpecifiedJobLimitGroups takes limit groups on a job that may have
 // been specified like name:limit, and fixes them to remove the limit suffix,
 // dedup and sort the groups, and fill in your supplied limitGroups map with the
@@ -1640,14 +2126,11 @@ func (s *Server) storeLimitGroups(limitGroups map[string]int) error {
 // jobs.
 func (s *Server) updateJobDependencies(jobs []*Job) (srerr string, qerr error) {
 	for _, job := range jobs {
-		deps, err := job.Dependencies.incompleteJobKeys(s.db)
-		if err != nil {
-			srerr = ErrDBError
-			qerr = err
-			break
-		}
-		thisErr := s.q.Update(job.Key(), job.getSchedulerGroup(), job, job.Priority, 0*time.Second, ServerItemTTR, deps)
+		thisErr := s.updateQueuedJob(job)
 		if thisErr != nil {
+			if _, isQErr := thisErr.(queue.Error); !isQErr {
+				srerr = ErrDBError
+			}
 			qerr = thisErr
 			break
 		}
@@ -1655,27 +2138,55 @@ func (s *Server) updateJobDependencies(jobs []*Job) (srerr string, qerr error) {
 	return srerr, qerr
 }
 
+// updateQueuedJob updates the given job's item in the queue with the job's
+// current priority and dependencies. Should a DepAfterNotOK dependency have
+// become unsatisfiable, the job remains dependent on the completed job, so it
+// will only run if that job is re-run and fails.
+func (s *Server) updateQueuedJob(job *Job) error {
+	deps, depTypes, unsatisfiable, err := job.Dependencies.incompleteJobKeys(s.db, s.jobIsBuried)
+	if err != nil {
+		return err
+	}
+	if unsatisfiable {
+		s.Warn("job dependencies can never be satisfied", "cmd", job.Cmd)
+	}
+	err = s.q.Update(job.Key(), job.getSchedulerGroup(), job, job.Priority, 0*time.Second, ServerItemTTR)
+	if err != nil {
+		return err
+	}
+	return s.q.UpdateDependencies(job.Key(), deps, depTypes)
+}
+
+// jobIsBuried tells you if the job with the given key is currently in the
+// bury sub-queue of our queue.
+func (s *Server) jobIsBuried(key string) bool {
+	item, err := s.q.Get(key)
+	return err == nil && item.Stats().State == queue.ItemStateBury
+}
+
 // releaseJob either releases or buries a job as per its retries, and updates
 // our scheduling counts as appropriate.
 func (s *Server) releaseJob(job *Job, endState *JobEndState, failReason string, forceStorage bool) error {
 	job.updateAfterExit(endState, s.limiter)
 	job.Lock()
 	job.FailReason = failReason
-	if !job.StartTime.IsZero() {
-		// obey jobs's Retries count by adjusting UntilBuried if a
-		// client reserved this job and started to run the job's cmd
-		job.UntilBuried--
-	}
+
+	// obey jobs's Retries count and RetryPolicy, only counting this as a
+	// failure if a client reserved this job and started to run the job's cmd
+	bury := job.noteFailure(failReason, !job.StartTime.IsZero())
 
 	sgroup := job.schedulerGroup
 	var errq error
 	var msg string
-	if job.UntilBuried <= 0 {
+	if bury {
 		errq = s.q.Bury(job.Key())
 		job.State = JobStateBuried
 		msg = "buried job"
 	} else {
-		errq = s.q.Release(job.Key())
+		errq = s.q.SetDelay(job.Key(), job.retryDelay())
+		if errq == nil {
+			errq = s.q.Release(job.Key())
+		}
 		job.State = JobStateDelayed
 		msg = "released job"
 	}
@@ -1686,10 +2197,43 @@ func (s *Server) releaseJob(job *Job, endState *JobEndState, failReason string,
 
 	s.decrementGroupCount(job.getSchedulerGroup())
 	s.db.updateJobAfterExit(job, endState.Stdout, endState.Stderr, forceStorage)
+	s.noteUsage(job)
 	s.Debug(msg, "cmd", job.Cmd, "schedGrp", sgroup)
 	return nil
 }
 
+// noteUsage records the resources used by the given job's most recent run
+// towards its fair-share party, if fair-share scheduling is enabled.
+func (s *Server) noteUsage(job *Job) {
+	if s.fairShare == nil {
+		return
+	}
+	err := s.fairShare.noteUsage(job)
+	if err != nil {
+		s.Warn("failed to store fair-share usage", "err", err)
+	}
+}
+
+// getFairShares returns the current share of every fair-share party, or nil if
+// fair-share scheduling is not enabled.
+func (s *Server) getFairShares() []*FairShare {
+	if s.fairShare == nil {
+		return nil
+	}
+	return s.fairShare.shares(s.q.GetRunningData())
+}
+
+// setQuota stores the given Quota, then re-considers which ready jobs are held
+// back by quotas.
+func (s *Server) setQuota(q *Quota) error {
+	err := s.quotas.set(q)
+	if err != nil {
+		return err
+	}
+	s.q.TriggerReadyAddedCallback()
+	return nil
+}
+
 // killJob sets the killCalled property on a job, to change the subsequent
 // behaviour of touching, which should result in an executing job killing
 // itself.
@@ -1720,6 +2264,84 @@ func (s *Server) killJob(jobkey string) (bool, error) {
 	return true, err
 }
 
+// suspendJob sets (or with suspend false, unsets) the suspendCalled property on
+// a job, to change the subsequent behaviour of touching, which should result in
+// an executing job stopping (or continuing) its Cmd.
+//
+// If the job wasn't running, or we've lost contact with it, returned bool will
+// be false and nothing will have been done.
+func (s *Server) suspendJob(jobkey string, suspend bool) (bool, error) {
+	item, err := s.q.Get(jobkey)
+	if err != nil || item.Stats().State != queue.ItemStateRun {
+		return false, err
+	}
+
+	job := item.Data.(*Job)
+	job.Lock()
+	defer job.Unlock()
+	if job.Lost || job.StartTime.IsZero() {
+		return false, nil
+	}
+	job.suspendCalled = suspend
+	return true, nil
+}
+
+// setJobSuspended records that a job's Cmd has been stopped (or continued),
+// letting the scheduler use (or stop using) the job's resources for other
+// jobs. Returns true if this was a change.
+func (s *Server) setJobSuspended(job *Job, suspended bool) bool {
+	job.Lock()
+	defer job.Unlock()
+	if job.Suspended == suspended {
+		return false
+	}
+	job.Suspended = suspended
+	if suspended {
+		job.suspendedReq = reqForScheduler(job.Requirements, job.TimeLimit)
+		s.scheduler.Suspended(job.suspendedReq)
+	} else if job.suspendedReq != nil {
+		s.scheduler.Resumed(job.suspendedReq)
+		job.suspendedReq = nil
+	}
+	return true
+}
+
+// holdJob holds (or releases) the job with the given key, if it is waiting to
+// run (or held). Held jobs are never reserved or scheduled, but keep their place
+// in the queue. Returns true if the job was eligible.
+func (s *Server) holdJob(jobkey string, hold bool) (bool, error) {
+	item, err := s.q.Get(jobkey)
+	if err != nil {
+		// it must have completed or been deleted
+		return false, nil
+	}
+
+	if hold {
+		err = s.q.Hold(jobkey)
+	} else {
+		err = s.q.Unhold(jobkey)
+	}
+	if err != nil {
+		if qerr, ok := err.(queue.Error); ok && (qerr.Err == queue.ErrNotHoldable || qerr.Err == queue.ErrNotHeld) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	job := item.Data.(*Job)
+	job.Lock()
+	job.Held = hold
+	job.Unlock()
+	s.db.updateJobAfterChange(job)
+
+	if hold && job.getScheduledRunner() {
+		// we no longer need a runner for it
+		job.setScheduledRunner(false)
+		s.decrementGroupCount(job.getSchedulerGroup())
+	}
+	return true, nil
+}
+
 // deleteJobs deletes the jobs with the given keys from the
 // bury/delay/dependent/ready queue and the live bucket. Does not delete jobs
 // that have jobs dependant upon them, unless all those dependants were also
@@ -1731,7 +2353,7 @@ func (s *Server) deleteJobs(keys []string) []string {
 		var skippedDeps []string
 		var toDelete []string
 		schedGroups := make(map[string]int)
-		var repGroups []string
+		var repGroups, arrayNames []string
 		for _, jobkey := range keys {
 			item, err := s.q.Get(jobkey)
 			if err != nil || item == nil {
@@ -1762,6 +2384,8 @@ func (s *Server) deleteJobs(keys []string) []string {
 					schedGroups[job.getSchedulerGroup()]++
 				}
 				repGroups = append(repGroups, job.RepGroup)
+				arrayNames = append(arrayNames, job.ArrayName)
+				s.removeCopiedFiles(jobkey)
 				s.Debug("removed job", "cmd", job.Cmd)
 			}
 		}
@@ -1783,6 +2407,7 @@ func (s *Server) deleteJobs(keys []string) []string {
 			s.rpl.Lock()
 			for i, rg := range repGroups {
 				delete(s.rpl.lookup[rg], toDelete[i])
+				delete(s.rpl.lookup[arrayNames[i]], toDelete[i])
 			}
 			s.rpl.Unlock()
 
@@ -1902,6 +2527,55 @@ func (s *Server) getJobsByRepGroup(repgroup string, search bool, limit int, stat
 	return jobs, srerr, qerr
 }
 
+// getJobsByArray gets jobs that are members of the job array with the given
+// name, optionally only those with the given indices. The other args are as
+// for getJobsByRepGroup().
+func (s *Server) getJobsByArray(name string, indices string, limit int, state JobState, getStd bool, getEnv bool) (jobs []*Job, srerr string, qerr string) {
+	var wanted map[int]bool
+	if indices != "" {
+		idx, err := ParseArrayIndices(indices)
+		if err != nil {
+			return nil, ErrBadArray, err.Error()
+		}
+		wanted = make(map[int]bool, len(idx))
+		for _, i := range idx {
+			wanted[i] = true
+		}
+	}
+
+	member := func(job *Job) bool {
+		return job.ArrayName == name && (wanted == nil || wanted[job.ArrayIndex])
+	}
+
+	// array members can be looked up as if their array name were a RepGroup
+	s.rpl.RLock()
+	for key := range s.rpl.lookup[name] {
+		item, err := s.q.Get(key)
+		if err == nil && item != nil {
+			job := s.itemToJob(item, false, false)
+			if member(job) {
+				jobs = append(jobs, job)
+			}
+		}
+	}
+	s.rpl.RUnlock()
+
+	if state == "" || state == JobStateComplete {
+		var complete []*Job
+		complete, srerr, qerr = s.getCompleteJobsByRepGroup(name)
+		for _, job := range complete {
+			if member(job) {
+				jobs = append(jobs, job)
+			}
+		}
+	}
+
+	if limit > 0 || state != "" || getStd || getEnv {
+		jobs = s.limitJobs(jobs, limit, state, getStd, getEnv)
+	}
+	return jobs, srerr, qerr
+}
+
 // getCompleteJobsByRepGroup gets complete jobs in the given group.
 func (s *Server) getCompleteJobsByRepGroup(repgroup string) (jobs []*Job, srerr string, qerr string) {
 	jobs, err := s.db.retrieveCompleteJobsByRepGroup(repgroup)
@@ -1928,7 +2602,7 @@ func (s *Server) getJobsCurrent(limit int, state JobState, getStd bool, getEnv b
 
 // limitJobs handles the limiting of jobs for getJobsByRepGroup() and
 // getJobsCurrent(). States 'reserved' and 'running' are treated as the same
-// state.
+// state, which also includes 'suspended'.
 func (s *Server) limitJobs(jobs []*Job, limit int, state JobState, getStd bool, getEnv bool) []*Job {
 	groups := make(map[string][]*Job)
 	var limited []*Job
@@ -1955,7 +2629,7 @@ func (s *Server) limitJobs(jobs []*Job, limit int, state JobState, getStd bool,
 				if jState == JobStateRunning || jState == JobStateComplete {
 					continue
 				}
-			} else if jState != state {
+			} else if jState != state && !(state == JobStateReserved && jState == JobStateSuspended) {
 				continue
 			}
 		}
@@ -2162,6 +2836,28 @@ func (s *Server) clearSchedulerGroup(schedulerGroup string) {
 	}
 }
 
+// addSchedulerIssue records the given problem message, or notes that it has
+// happened again, and sends it to the status webpage.
+func (s *Server) addSchedulerIssue(msg string) {
+	s.simutex.Lock()
+	var si *schedulerIssue
+	var existed bool
+	if si, existed = s.schedIssues[msg]; existed {
+		si.LastDate = time.Now().Unix()
+		si.Count = si.Count + 1
+	} else {
+		si = &schedulerIssue{
+			Msg:       msg,
+			FirstDate: time.Now().Unix(),
+			LastDate:  time.Now().Unix(),
+			Count:     1,
+		}
+		s.schedIssues[msg] = si
+	}
+	s.simutex.Unlock()
+	s.schedCaster.Send(si)
+}
+
 // getBadServers converts the slice of cloud.Server objects we hold in to a
 // slice of badServer structs.
 func (s *Server) getBadServers() []*BadServer {
@@ -2311,6 +3007,7 @@ func (s *Server) shutdown(reason string, wait bool, stopSigHandling bool) {
 	s.statusCaster.Close()
 	s.badServerCaster.Close()
 	s.schedCaster.Close()
+	s.outputCaster.Close()
 	s.wsmutex.Lock()
 	for unique, conn := range s.wsconns {
 		errc := conn.Close()
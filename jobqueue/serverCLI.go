@@ -53,10 +53,15 @@ func (s *Server) handleRequest(m *mangos.Message) error {
 	drain := s.drain
 	s.ssmutex.RUnlock()
 
-	// check that the client making the request has the expected token
-	if (len(cr.Token) != tokenLength || !tokenMatches(cr.Token, s.token)) && cr.Method != "ping" {
+	// check that the client making the request has a token we issued, and
+	// work out who they are
+	user := s.authenticate(cr.Token)
+	if user == nil && cr.Method != "ping" {
 		srerr = ErrPermissionDenied
 		qerr = "Client presented the wrong token"
+	} else if (adminOnlyMethods[cr.Method] || runnerMethods[cr.Method]) && !user.Admin {
+		srerr = ErrNotAdmin
+		qerr = "Client user " + user.Name + " is not an admin"
 	} else if s.q == nil || (!up && !drain) {
 		// the server just got shutdown
 		srerr = ErrClosedStop
@@ -153,7 +158,7 @@ func (s *Server) handleRequest(m *mangos.Message) error {
 				} else {
 					if srerr == "" {
 						// create the jobs server-side
-						added, dups, alreadyComplete, thisSrerr, err := s.createJobs(cr.Jobs, envkey, cr.IgnoreComplete)
+						added, dups, alreadyComplete, thisSrerr, err := s.createJobs(cr.Jobs, envkey, cr.IgnoreComplete, user)
 						if err != nil {
 							srerr = thisSrerr
 							qerr = err.Error()
@@ -168,6 +173,11 @@ func (s *Server) handleRequest(m *mangos.Message) error {
 			// return the next ready job
 			if cr.ClientID.String() == "00000000-0000-0000-0000-000000000000" {
 				srerr = ErrBadRequest
+			} else if s.preemptedRunner(cr.ClientID) {
+				// this runner's job was killed to make room for a higher
+				// priority job, so it should exit to free up its resources;
+				// we act as if nothing were ready, as in drain mode
+				s.Debug("told preempted runner to stop reserving", "client", cr.ClientID)
 			} else if !drain {
 				// first just try to Reserve normally
 				var item *queue.Item
@@ -256,11 +266,19 @@ func (s *Server) handleRequest(m *mangos.Message) error {
 					sjob.EndTime = tnil
 					sjob.PeakRAM = 0
 					sjob.PeakDisk = 0
+					sjob.BytesRead = 0
+					sjob.BytesWritten = 0
+					sjob.CtxSwitches = 0
+					sjob.ResourceProfile = nil
 					sjob.Exitcode = -1
 					sgroup := sjob.schedulerGroup
+					delay := sjob.retryDelay()
 					sjob.Unlock()
 
-					errd := s.q.SetDelay(item.Key, ClientReleaseDelay)
+					// if the job's ttr expires before it is started, it will
+					// be moved to the delay queue, so we need to set the delay
+					// its RetryPolicy wants now
+					errd := s.q.SetDelay(item.Key, delay)
 					if errd != nil {
 						s.Warn("reserve queue SetDelay failed", "err", errd)
 					}
@@ -293,11 +311,16 @@ func (s *Server) handleRequest(m *mangos.Message) error {
 					job.EndTime = tend
 					job.Attempts++
 					job.killCalled = false
+					job.suspendCalled = false
+					job.preemptedBy = ""
+					job.Suspended = false
 					job.Lost = false
 					job.State = JobStateRunning
 
 					job.Unlock()
 
+					s.startLiveOutput(job.Key())
+
 					// we'll save-to-disk that we started running this job, so
 					// recovery is possible after a crash
 					s.db.updateJobAfterChange(job)
@@ -311,7 +334,9 @@ func (s *Server) handleRequest(m *mangos.Message) error {
 				// if kill has been called for this job, just return KillCalled
 				job.RLock()
 				killCalled := job.killCalled
+				suspendCalled := job.suspendCalled
 				lost := job.Lost
+				suspended := job.Suspended
 				job.RUnlock()
 
 				if !killCalled {
@@ -339,8 +364,91 @@ func (s *Server) handleRequest(m *mangos.Message) error {
 						s.statusCaster.Send(&jstateCount{"+all+", JobStateLost, JobStateRunning, 1})
 						s.statusCaster.Send(&jstateCount{job.RepGroup, JobStateLost, JobStateRunning, 1})
 					}
+
+					if len(cr.Job.ResourceProfile) > 0 {
+						job.Lock()
+						job.ResourceProfile = cr.Job.ResourceProfile
+						job.Unlock()
+					}
+
+					// the runner tells us if it has stopped or continued the
+					// cmd in response to us returning SuspendCalled
+					if cr.Job.Suspended != suspended && s.setJobSuspended(job, cr.Job.Suspended) {
+						s.db.updateJobAfterChange(job)
+					}
+				}
+				sr = &serverResponse{KillCalled: killCalled, SuspendCalled: suspendCalled}
+			}
+		case "jcopy":
+			// store a file copied from the actual cwd of a running job
+			var job *Job
+			_, job, srerr = s.getij(cr)
+			if srerr == "" {
+				if cr.File == nil || cr.Path == "" {
+					srerr = ErrBadRequest
+				} else {
+					data, err := decompress(cr.File)
+					if err != nil {
+						srerr = ErrInternalError
+						qerr = err.Error()
+					} else {
+						path, err := s.storeCopiedFile(job, bytes.NewReader(data), cr.Path)
+						if err != nil {
+							srerr = ErrInternalError
+							qerr = err.Error()
+						} else {
+							sr = &serverResponse{Path: path}
+						}
+					}
+				}
+			}
+		case "jlog":
+			// store a STDOUT or STDERR log file of a running job
+			var job *Job
+			_, job, srerr = s.getij(cr)
+			if srerr == "" {
+				if cr.File == nil || cr.Path == "" {
+					srerr = ErrBadRequest
+				} else {
+					data, err := decompress(cr.File)
+					if err != nil {
+						srerr = ErrInternalError
+						qerr = err.Error()
+					} else {
+						err = s.storeLogFile(job, bytes.NewReader(data), cr.Path)
+						if err != nil {
+							srerr = ErrInternalError
+							qerr = err.Error()
+						}
+					}
+				}
+			}
+		case "jout":
+			// store output of a running job for followers
+			_, _, srerr = s.getij(cr)
+			if srerr == "" {
+				if cr.Output == nil {
+					srerr = ErrBadRequest
+				} else {
+					s.storeLiveOutput(cr.Job.Key(), cr.Output.Stdout, cr.Output.Stderr)
+				}
+			}
+		case "jcached":
+			// find out where the results of a previously completed job with the
+			// same CacheKey as a reserved job can be found
+			_, _, srerr = s.getij(cr)
+			if srerr == "" {
+				if cr.Job.CacheKey == "" {
+					srerr = ErrBadRequest
+				} else {
+					result, err := s.db.retrieveCachedResult(cr.Job.CacheKey)
+					if err != nil {
+						srerr = ErrDBError
+						qerr = err.Error()
+					} else {
+						sr = &serverResponse{CachedResult: result}
+					}
 				}
-				sr = &serverResponse{KillCalled: killCalled}
 			}
 		case "jarchive":
 			// remove the job from the queue, rpl and live bucket and add to
@@ -367,6 +475,7 @@ func (s *Server) handleRequest(m *mangos.Message) error {
 					job.FailReason = ""
 					sgroup := job.schedulerGroup
 					rgroup := job.RepGroup
+					arrayName := job.ArrayName
 					job.Unlock()
 					err := s.db.archiveJob(key, job)
 					if err != nil {
@@ -379,10 +488,13 @@ func (s *Server) handleRequest(m *mangos.Message) error {
 							qerr = err.Error()
 						} else {
 							s.rpl.Lock()
-							if m, exists := s.rpl.lookup[rgroup]; exists {
-								delete(m, key)
+							for _, rg := range []string{rgroup, arrayName} {
+								if m, exists := s.rpl.lookup[rg]; exists {
+									delete(m, key)
+								}
 							}
 							s.rpl.Unlock()
+							s.noteUsage(job)
 							s.Debug("completed job", "cmd", job.Cmd, "schedGrp", sgroup)
 							go func(group string) {
 								defer internal.LogPanic(s.Logger, "jarchive", true)
@@ -411,7 +523,18 @@ func (s *Server) handleRequest(m *mangos.Message) error {
 			var item *queue.Item
 			var job *Job
 			item, job, srerr = s.getij(cr)
-			if srerr == "" {
+			if srerr == "" && s.killedByPreemption(job, cr.Job.FailReason) {
+				// it was killed to make room for a higher priority job, so
+				// it goes back to being ready to run without using up any of
+				// its retries
+				cr.JobEndState.Stdout = cr.Job.StdOutC
+				cr.JobEndState.Stderr = cr.Job.StdErrC
+				err := s.requeuePreempted(job, cr.JobEndState)
+				if err != nil {
+					srerr = ErrInternalError
+					qerr = err.Error()
+				}
+			} else if srerr == "" {
 				job.updateAfterExit(cr.JobEndState, s.limiter)
 				job.Lock()
 				job.FailReason = cr.Job.FailReason
@@ -425,18 +548,20 @@ func (s *Server) handleRequest(m *mangos.Message) error {
 				} else {
 					s.decrementGroupCount(job.getSchedulerGroup())
 					s.db.updateJobAfterExit(job, cr.Job.StdOutC, cr.Job.StdErrC, true)
+					s.noteUsage(job)
 					s.Debug("buried job", "cmd", job.Cmd, "schedGrp", sgroup)
 				}
 			}
 		case "jkick":
 			// move the jobs from the bury queue to the ready queue; unlike the
 			// other j* methods, client doesn't have to be the Reserve() owner
-			// of these jobs, and we don't want the "in run queue" test
+			// of these jobs, and we don't want the "in run queue" test. They
+			// do have to be the owner of the jobs, or an admin
 			if cr.Keys == nil {
 				srerr = ErrBadRequest
 			} else {
 				kicked := 0
-				for _, jobkey := range cr.Keys {
+				for _, jobkey := range s.ownedKeys(user, cr.Keys) {
 					item, err := s.q.Get(jobkey)
 					if err != nil || item.Stats().State != queue.ItemStateBury {
 						continue
@@ -445,7 +570,7 @@ func (s *Server) handleRequest(m *mangos.Message) error {
 					if err == nil {
 						job := item.Data.(*Job)
 						job.Lock()
-						job.UntilBuried = job.Retries + 1
+						job.resetRetries()
 						s.Debug("unburied job", "cmd", job.Cmd, "schedGrp", job.schedulerGroup)
 						job.State = JobStateReady
 						job.Unlock()
@@ -462,7 +587,7 @@ func (s *Server) handleRequest(m *mangos.Message) error {
 			if cr.Keys == nil {
 				srerr = ErrBadRequest
 			} else {
-				deleted := s.deleteJobs(cr.Keys)
+				deleted := s.deleteJobs(s.ownedKeys(user, cr.Keys))
 				s.Debug("deleted jobs", "count", len(deleted))
 				sr = &serverResponse{Existed: len(deleted)}
 			}
@@ -489,7 +614,7 @@ func (s *Server) handleRequest(m *mangos.Message) error {
 
 				if err == nil {
 					var toModify []*Job
-					for _, jobkey := range cr.Keys {
+					for _, jobkey := range s.ownedKeys(user, cr.Keys) {
 						item, err := s.q.Get(jobkey)
 						if err != nil || item == nil {
 							continue
@@ -557,16 +682,19 @@ func (s *Server) handleRequest(m *mangos.Message) error {
 							// reflected in the queue as well
 							if cr.Modifier.DependenciesSet || cr.Modifier.PrioritySet {
 								for _, job := range toModify {
-									deps, err := job.Dependencies.incompleteJobKeys(s.db)
-									if err != nil {
-										s.Error("failed to get job dependencies", "err", err)
-									}
-									err = s.q.Update(job.Key(), job.getSchedulerGroup(), job, job.Priority, 0*time.Second, ServerItemTTR, deps)
+									err := s.updateQueuedJob(job)
 									if err != nil {
 										s.Error("failed to modify a job in the queue", "err", err)
 									}
 								}
 							}
+
+							// or when they're allowed to start running
+							if cr.Modifier.BeginAtSet || cr.Modifier.WindowSet {
+								for _, job := range toModify {
+									s.rescheduleJob(job)
+								}
+							}
 						}
 					}
 
@@ -589,7 +717,7 @@ func (s *Server) handleRequest(m *mangos.Message) error {
 				srerr = ErrBadRequest
 			} else {
 				killable := 0
-				for _, jobkey := range cr.Keys {
+				for _, jobkey := range s.ownedKeys(user, cr.Keys) {
 					k, err := s.killJob(jobkey)
 					if err != nil {
 						continue
@@ -601,6 +729,138 @@ func (s *Server) handleRequest(m *mangos.Message) error {
 				s.Debug("killed jobs", "count", killable)
 				sr = &serverResponse{Existed: killable}
 			}
+		case "jsuspend", "jresume":
+			// set the suspendCalled property on the jobs, to change the
+			// subsequent behaviour of jtouch; as per jkill, client doesn't
+			// have to be the Reserve() owner of these jobs
+			if cr.Keys == nil {
+				srerr = ErrBadRequest
+			} else {
+				suspend := cr.Method == "jsuspend"
+				eligible := 0
+				for _, jobkey := range s.ownedKeys(user, cr.Keys) {
+					e, err := s.suspendJob(jobkey, suspend)
+					if err != nil {
+						continue
+					}
+					if e {
+						eligible++
+					}
+				}
+				s.Debug("suspended or resumed jobs", "suspend", suspend, "count", eligible)
+				sr = &serverResponse{Existed: eligible}
+			}
+		case "jhold", "junhold":
+			// move jobs waiting to run in to (or out of) the held state; client
+			// doesn't have to be the Reserve() owner of these jobs, since they
+			// can't be reserved
+			if cr.Keys == nil {
+				srerr = ErrBadRequest
+			} else {
+				hold := cr.Method == "jhold"
+				eligible := 0
+				for _, jobkey := range s.ownedKeys(user, cr.Keys) {
+					e, err := s.holdJob(jobkey, hold)
+					if err != nil {
+						continue
+					}
+					if e {
+						eligible++
+					}
+				}
+				s.Debug("held or released jobs", "hold", hold, "count", eligible)
+				sr = &serverResponse{Existed: eligible}
+			}
+		case "uadd":
+			// issue a new user with their own token
+			if cr.User == nil {
+				srerr = ErrBadRequest
+			} else {
+				token, err := s.addUser(cr.User.Name, cr.User.Admin)
+				if err != nil {
+					srerr = ErrBadUser
+					qerr = err.Error()
+				} else {
+					s.Debug("added user", "name", cr.User.Name, "admin", cr.User.Admin)
+					sr = &serverResponse{Token: token}
+				}
+			}
+		case "udel":
+			// revoke a user's token
+			if cr.User == nil {
+				srerr = ErrBadRequest
+			} else {
+				removed, err := s.removeUser(cr.User.Name)
+				if err != nil {
+					srerr = ErrDBError
+					qerr = err.Error()
+				} else {
+					existed := 0
+					if removed {
+						s.Debug("removed user", "name", cr.User.Name)
+						existed = 1
+					}
+					sr = &serverResponse{Existed: existed}
+				}
+			}
+		case "ulist":
+			sr = &serverResponse{Users: s.getUsers()}
+		case "whoami":
+			sr = &serverResponse{Users: []*User{user}}
+		case "fairshare":
+			shares := s.getFairShares()
+			if shares == nil {
+				srerr = ErrNoFairShare
+			} else {
+				sr = &serverResponse{Shares: shares}
+			}
+		case "quotaset":
+			if cr.Quota == nil {
+				srerr = ErrBadRequest
+			} else {
+				err := s.setQuota(cr.Quota)
+				if err != nil {
+					srerr = ErrBadQuota
+					qerr = err.Error()
+				} else {
+					s.Debug("set quota", "kind", cr.Quota.Kind, "name", cr.Quota.Name, "quota", cr.Quota.String())
+					sr = &serverResponse{}
+				}
+			}
+		case "quotas":
+			sr = &serverResponse{Quotas: s.quotas.get()}
+		case "cronadd":
+			if cr.Cron == nil || cr.Env == nil {
+				srerr = ErrBadRequest
+			} else {
+				err := s.addCron(cr.Cron, cr.Env, user)
+				if err != nil {
+					srerr = ErrBadCron
+					qerr = err.Error()
+				} else {
+					s.Debug("added cron", "name", cr.Cron.Name, "schedule", cr.Cron.Schedule, "owner", user.Name)
+					sr = &serverResponse{}
+				}
+			}
+		case "crons":
+			sr = &serverResponse{Crons: s.crons.get()}
+		case "crondel":
+			if cr.Cron == nil {
+				srerr = ErrBadRequest
+			} else {
+				removed, err := s.removeCron(cr.Cron.Name, user)
+				if err != nil {
+					srerr = ErrBadCron
+					qerr = err.Error()
+				} else {
+					var existed int
+					if removed {
+						s.Debug("removed cron", "name", cr.Cron.Name)
+						existed = 1
+					}
+					sr = &serverResponse{Existed: existed}
+				}
+			}
 		case "getbc":
 			// get jobs by their keys (which come from their Cmds & Cwds)
 			if cr.Keys == nil {
@@ -612,6 +872,17 @@ func (s *Server) handleRequest(m *mangos.Message) error {
 					sr = &serverResponse{Jobs: jobs}
 				}
 			}
+		case "explain":
+			// explain why jobs are (or aren't) running
+			if cr.Keys == nil {
+				srerr = ErrBadRequest
+			} else {
+				var exps []*JobExplanation
+				exps, srerr, qerr = s.explainJobs(cr.Keys)
+				if len(exps) > 0 {
+					sr = &serverResponse{Explanations: exps}
+				}
+			}
 		case "getbr":
 			// get jobs by their RepGroup
 			if cr.Job == nil || cr.Job.RepGroup == "" {
@@ -623,6 +894,47 @@ func (s *Server) handleRequest(m *mangos.Message) error {
 					sr = &serverResponse{Jobs: jobs}
 				}
 			}
+		case "getba":
+			// get jobs by the name of the job array they're a member of
+			if cr.Job == nil || cr.Job.ArrayName == "" {
+				srerr = ErrBadRequest
+			} else {
+				var jobs []*Job
+				jobs, srerr, qerr = s.getJobsByArray(cr.Job.ArrayName, cr.Indices, cr.Limit, cr.State, cr.GetStd, cr.GetEnv)
+				if len(jobs) > 0 {
+					sr = &serverResponse{Jobs: jobs}
+				}
+			}
+		case "getlog":
+			// get a log file previously stored by jlog
+			if len(cr.Keys) != 1 || cr.Path == "" {
+				srerr = ErrBadRequest
+			} else {
+				data, err := s.retrieveLogFile(cr.Keys[0], cr.Path)
+				if err != nil {
+					srerr = ErrInternalError
+					qerr = err.Error()
+				} else {
+					compressed, err := compress(data)
+					if err != nil {
+						srerr = ErrInternalError
+						qerr = err.Error()
+					} else {
+						sr = &serverResponse{File: compressed}
+					}
+				}
+			}
+		case "getout":
+			// get the output of a (running) job that was stored by jout
+			if len(cr.Keys) != 1 {
+				srerr = ErrBadRequest
+			} else {
+				var stdoutOffset, stderrOffset int64
+				if cr.Output != nil {
+					stdoutOffset, stderrOffset = cr.Output.StdoutOffset, cr.Output.StderrOffset
+				}
+				sr = &serverResponse{Output: s.getLiveOutput(cr.Keys[0], stdoutOffset, stderrOffset)}
+			}
 		case "getin":
 			// get all jobs in the jobqueue
 			jobs := s.getJobsCurrent(cr.Limit, cr.State, cr.GetStd, cr.GetEnv)
@@ -809,12 +1121,14 @@ func (s *Server) getij(cr *clientRequest) (*queue.Item, *Job, string) {
 	return item, job, ""
 }
 
-func (s *Server) itemStateToJobState(itemState queue.ItemState, lost bool) JobState {
+func (s *Server) itemStateToJobState(itemState queue.ItemState, lost bool, suspended bool) JobState {
 	state := itemsStateToJobState[itemState]
 	if state == "" {
 		state = JobStateUnknown
 	} else if state == JobStateReserved && lost {
 		state = JobStateLost
+	} else if state == JobStateReserved && suspended {
+		state = JobStateSuspended
 	}
 	return state
 }
@@ -827,7 +1141,7 @@ func (s *Server) itemToJob(item *queue.Item, getStd bool, getEnv bool) *Job {
 
 	stats := item.Stats()
 
-	state := s.itemStateToJobState(stats.State, sjob.Lost)
+	state := s.itemStateToJobState(stats.State, sjob.Lost, sjob.Suspended)
 
 	// we're going to fill in some properties of the Job and return
 	// it to client, but don't want those properties set here for
@@ -836,6 +1150,8 @@ func (s *Server) itemToJob(item *queue.Item, getStd bool, getEnv bool) *Job {
 	*req = *sjob.Requirements // copy reqs since server changes these, avoiding a race condition
 	job := &Job{
 		RepGroup:      sjob.RepGroup,
+		ArrayName:     sjob.ArrayName,
+		ArrayIndex:    sjob.ArrayIndex,
 		ReqGroup:      sjob.ReqGroup,
 		LimitGroups:   sjob.LimitGroups,
 		DepGroups:     sjob.DepGroups,
@@ -847,11 +1163,19 @@ func (s *Server) itemToJob(item *queue.Item, getStd bool, getEnv bool) *Job {
 		Requirements:  req,
 		Priority:      sjob.Priority,
 		Retries:       sjob.Retries,
+		RetryPolicy:   sjob.RetryPolicy,
+		TimeLimit:     sjob.TimeLimit,
+		BeginAt:       sjob.BeginAt,
+		Window:        sjob.Window,
+		Deadline:      sjob.Deadline,
 		PeakRAM:       sjob.PeakRAM,
 		PeakDisk:      sjob.PeakDisk,
 		Exited:        sjob.Exited,
 		Exitcode:      sjob.Exitcode,
 		FailReason:    sjob.FailReason,
+		Suspended:     sjob.Suspended,
+		History:       sjob.History,
+		Owner:         sjob.Owner,
 		StartTime:     sjob.StartTime,
 		EndTime:       sjob.EndTime,
 		Pid:           sjob.Pid,
@@ -859,6 +1183,10 @@ func (s *Server) itemToJob(item *queue.Item, getStd bool, getEnv bool) *Job {
 		HostID:        sjob.HostID,
 		HostIP:        sjob.HostIP,
 		CPUtime:       sjob.CPUtime,
+		BytesRead:     sjob.BytesRead,
+		BytesWritten:  sjob.BytesWritten,
+		CtxSwitches:   sjob.CtxSwitches,
+		CopiedFiles:   sjob.CopiedFiles,
 		State:         state,
 		Attempts:      sjob.Attempts,
 		UntilBuried:   sjob.UntilBuried,
@@ -869,9 +1197,27 @@ func (s *Server) itemToJob(item *queue.Item, getStd bool, getEnv bool) *Job {
 		Behaviours:    sjob.Behaviours,
 		MountConfigs:  sjob.MountConfigs,
 		MonitorDocker: sjob.MonitorDocker,
+		Container:     sjob.Container,
+		PendingReason: sjob.PendingReason,
+		Inputs:        sjob.Inputs,
+		Outputs:       sjob.Outputs,
+		Cache:         sjob.Cache,
+		CacheKey:      sjob.CacheKey,
+		LogDir:        sjob.LogDir,
+		LogToManager:  sjob.LogToManager,
+		Held:          sjob.Held,
 		BsubMode:      sjob.BsubMode,
 		BsubID:        sjob.BsubID,
 	}
+	job.EffectivePriority = stats.EffectivePriority
+	job.ResourceProfile = sjob.ResourceProfile
+
+	if len(sjob.FailReasonCounts) > 0 {
+		job.FailReasonCounts = make(map[string]int, len(sjob.FailReasonCounts))
+		for reason, count := range sjob.FailReasonCounts {
+			job.FailReasonCounts[reason] = count
+		}
+	}
 
 	if state == JobStateReserved && !sjob.StartTime.IsZero() {
 		job.State = JobStateRunning
@@ -46,6 +46,9 @@ const (
 	restBadServersEndpoint = "/rest/v" + restAPIVersion + "/servers/"
 	restFileUploadEndpoint = "/rest/v" + restAPIVersion + "/upload/"
 	restInfoEndpoint       = "/rest/v" + restAPIVersion + "/info/"
+	restExplainEndpoint    = "/rest/v" + restAPIVersion + "/explain/"
+	restCronsEndpoint      = "/rest/v" + restAPIVersion + "/crons/"
+	restProfileEndpoint    = "/rest/v" + restAPIVersion + "/profile/"
 	restFormTrue           = "true"
 	bearerSchema           = "Bearer "
 )
@@ -65,40 +68,90 @@ type JobViaJSON struct {
 	Time string   `json:"time"`
 	CPUs *float64 `json:"cpus"`
 	// Disk is the number of Gigabytes the cmd will use.
-	Disk             *int              `json:"disk"`
-	Override         *int              `json:"override"`
-	Priority         *int              `json:"priority"`
-	Retries          *int              `json:"retries"`
-	RepGrp           string            `json:"rep_grp"`
-	LimitGrps        []string          `json:"limit_grps"`
-	DepGrps          []string          `json:"dep_grps"`
-	Deps             []string          `json:"deps"`
-	CmdDeps          Dependencies      `json:"cmd_deps"`
-	OnFailure        BehavioursViaJSON `json:"on_failure"`
-	OnSuccess        BehavioursViaJSON `json:"on_success"`
-	OnExit           BehavioursViaJSON `json:"on_exit"`
-	Env              []string          `json:"env"`
-	MonitorDocker    string            `json:"monitor_docker"`
-	CloudOS          string            `json:"cloud_os"`
-	CloudUser        string            `json:"cloud_username"`
-	CloudScript      string            `json:"cloud_script"`
-	CloudConfigFiles string            `json:"cloud_config_files"`
-	CloudOSRam       *int              `json:"cloud_ram"`
-	CloudFlavor      string            `json:"cloud_flavor"`
-	CloudShared      bool              `json:"cloud_shared"`
-	BsubMode         string            `json:"bsub_mode"`
-	RTimeout         *int              `json:"reserve_timeout"`
+	Disk             *int                `json:"disk"`
+	Override         *int                `json:"override"`
+	Priority         *int                `json:"priority"`
+	Retries          *int                `json:"retries"`
+	RetryPolicy      *RetryPolicyViaJSON `json:"retry_policy"`
+	TimeLimit        string              `json:"time_limit"`
+	TimeLimitGrace   string              `json:"time_limit_grace"`
+	TimeLimitSignal  string              `json:"time_limit_signal"`
+	BeginAt          string              `json:"begin_at"`
+	Window           string              `json:"window"`
+	Deadline         string              `json:"deadline"`
+	RepGrp           string              `json:"rep_grp"`
+	Array            string              `json:"array"`
+	ArrayList        []string            `json:"array_list"`
+	ArrayTSV         string              `json:"array_tsv"`
+	ArrayName        string              `json:"array_name"`
+	LimitGrps        []string            `json:"limit_grps"`
+	DepGrps          []string            `json:"dep_grps"`
+	Deps             []string            `json:"deps"`
+	CmdDeps          Dependencies        `json:"cmd_deps"`
+	OnFailure        BehavioursViaJSON   `json:"on_failure"`
+	OnSuccess        BehavioursViaJSON   `json:"on_success"`
+	OnExit           BehavioursViaJSON   `json:"on_exit"`
+	Env              []string            `json:"env"`
+	MonitorDocker    string              `json:"monitor_docker"`
+	ContainerImage   string              `json:"container_image"`
+	ContainerRuntime string              `json:"container_runtime"`
+	Inputs           []string            `json:"inputs"`
+	Outputs          []string            `json:"outputs"`
+	Cache            bool                `json:"cache"`
+	LogDir           string              `json:"log_dir"`
+	LogToManager     bool                `json:"log_to_manager"`
+	Held             bool                `json:"held"`
+	CloudOS          string              `json:"cloud_os"`
+	CloudUser        string              `json:"cloud_username"`
+	CloudScript      string              `json:"cloud_script"`
+	CloudConfigFiles string              `json:"cloud_config_files"`
+	CloudOSRam       *int                `json:"cloud_ram"`
+	CloudFlavor      string              `json:"cloud_flavor"`
+	CloudShared      bool                `json:"cloud_shared"`
+	BsubMode         string              `json:"bsub_mode"`
+	RTimeout         *int                `json:"reserve_timeout"`
+}
+
+// CronViaJSON describes a Cron that a user wishes to add, convenient if they
+// are supplying JSON. The Job's RepGrp defaults to the Cron's Name.
+type CronViaJSON struct {
+	Name     string      `json:"name"`
+	Schedule string      `json:"schedule"`
+	Overlap  string      `json:"overlap"`
+	Job      *JobViaJSON `json:"job"`
+}
+
+// CronStatus is a summary of a Cron, as returned by the REST API.
+type CronStatus struct {
+	Name     string
+	Schedule string
+	Overlap  string
+	Owner    string
+	RepGroup string
+	Cmd      string
+	Cwd      string
+	Next     string
+	Last     string
+	Pending  bool
 }
 
 // JobDefaults is supplied to JobViaJSON.Convert() to provide default values for
 // the conversion.
 type JobDefaults struct {
 	RepGrp string
+	// Array, if set, makes each cmd a template for a job array.
+	Array *JobArray
 	// Cwd defaults to /tmp.
 	Cwd        string
 	CwdMatters bool
 	ChangeHome bool
+	Cache      bool
 	ReqGrp     string
+	// LogDir is a directory on the machines that run cmds to write complete
+	// STDOUT and STDERR log files to.
+	LogDir       string
+	LogToManager bool
+	Held         bool
 	// CPUs is the number of CPU cores each cmd will use.
 	CPUs float64
 	// Memory is the number of Megabytes each cmd will use. Defaults to 1000.
@@ -113,6 +166,11 @@ type JobDefaults struct {
 	Override    int
 	Priority    int
 	Retries     int
+	RetryPolicy *RetryPolicy
+	TimeLimit   *TimeLimit
+	BeginAt     time.Time
+	Window      *Window
+	Deadline    time.Time
 	LimitGroups []string
 	DepGroups   []string
 	Deps        Dependencies
@@ -123,6 +181,7 @@ type JobDefaults struct {
 	OnExit        Behaviours
 	MountConfigs  MountConfigs
 	MonitorDocker string
+	Container     *Container
 	CloudOS       string
 	CloudUser     string
 	CloudFlavor   string
@@ -209,6 +268,12 @@ func (jvj *JobViaJSON) Convert(jd *JobDefaults) (*Job, error) {
 	var behaviours Behaviours
 	var mounts MountConfigs
 	var bsubMode string
+	var retryPolicy *RetryPolicy
+	var timeLimit *TimeLimit
+	var container *Container
+	var beginAt time.Time
+	var window *Window
+	var deadline time.Time
 
 	if jvj.RepGrp == "" {
 		repg = jd.RepGrp
@@ -237,6 +302,39 @@ func (jvj *JobViaJSON) Convert(jd *JobDefaults) (*Job, error) {
 		changeHome = true
 	}
 
+	cache := jd.Cache
+	if jvj.Cache {
+		cache = true
+	}
+
+	logDir := jd.LogDir
+	if jvj.LogDir != "" {
+		logDir = jvj.LogDir
+	}
+
+	logToManager := jd.LogToManager
+	if jvj.LogToManager {
+		logToManager = true
+	}
+
+	held := jd.Held
+	if jvj.Held {
+		held = true
+	}
+
+	array := jd.Array
+	if jvj.Array != "" || len(jvj.ArrayList) > 0 || jvj.ArrayTSV != "" {
+		var err error
+		array, err = NewJobArray(jvj.Array, jvj.ArrayList, jvj.ArrayTSV, jvj.ArrayName)
+		if err != nil {
+			return nil, err
+		}
+	} else if array != nil && jvj.ArrayName != "" {
+		named := *array
+		named.Name = jvj.ArrayName
+		array = &named
+	}
+
 	if jvj.ReqGrp == "" {
 		if jd.ReqGrp != "" {
 			rg = jd.ReqGrp
@@ -309,6 +407,56 @@ func (jvj *JobViaJSON) Convert(jd *JobDefaults) (*Job, error) {
 		return nil, fmt.Errorf("retries value (%d) is not in the range 0..255", retries)
 	}
 
+	if jvj.RetryPolicy == nil {
+		retryPolicy = jd.RetryPolicy
+	} else {
+		var err error
+		retryPolicy, err = jvj.RetryPolicy.RetryPolicy()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if jvj.TimeLimit == "" {
+		timeLimit = jd.TimeLimit
+	} else {
+		var err error
+		timeLimit, err = ParseTimeLimit(jvj.TimeLimit, jvj.TimeLimitGrace, jvj.TimeLimitSignal)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if jvj.BeginAt == "" {
+		beginAt = jd.BeginAt
+	} else {
+		var err error
+		beginAt, err = ParseBeginAt(jvj.BeginAt)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if jvj.Deadline == "" {
+		deadline = jd.Deadline
+	} else {
+		var err error
+		deadline, err = ParseBeginAt(jvj.Deadline)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if jvj.Window == "" {
+		window = jd.Window
+	} else {
+		var err error
+		window, err = ParseWindow(jvj.Window)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if len(jvj.LimitGrps) == 0 {
 		limitGroups = jd.LimitGroups
 	} else {
@@ -333,6 +481,9 @@ func (jvj *JobViaJSON) Convert(jd *JobDefaults) (*Job, error) {
 			}
 		}
 	}
+	if err := deps.validate(); err != nil {
+		return nil, err
+	}
 
 	if len(jvj.Env) > 0 {
 		var err error
@@ -381,6 +532,16 @@ func (jvj *JobViaJSON) Convert(jd *JobDefaults) (*Job, error) {
 		monitorDocker = jvj.MonitorDocker
 	}
 
+	if jvj.ContainerImage == "" && jvj.ContainerRuntime == "" {
+		container = jd.Container
+	} else {
+		var err error
+		container, err = NewContainer(jvj.ContainerImage, jvj.ContainerRuntime)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// scheduler-specific options
 	other := make(map[string]string)
 	if jvj.CloudOS != "" {
@@ -450,6 +611,11 @@ func (jvj *JobViaJSON) Convert(jd *JobDefaults) (*Job, error) {
 		Override:      uint8(override),
 		Priority:      uint8(priority),
 		Retries:       uint8(retries),
+		RetryPolicy:   retryPolicy,
+		TimeLimit:     timeLimit,
+		BeginAt:       beginAt,
+		Window:        window,
+		Deadline:      deadline,
 		LimitGroups:   limitGroups,
 		DepGroups:     depGroups,
 		Dependencies:  deps,
@@ -457,18 +623,26 @@ func (jvj *JobViaJSON) Convert(jd *JobDefaults) (*Job, error) {
 		Behaviours:    behaviours,
 		MountConfigs:  mounts,
 		MonitorDocker: monitorDocker,
+		Container:     container,
+		Inputs:        jvj.Inputs,
+		Outputs:       jvj.Outputs,
+		Cache:         cache,
+		LogDir:        logDir,
+		LogToManager:  logToManager,
+		Held:          held,
 		BsubMode:      bsubMode,
+		Array:         array,
 	}, nil
 }
 
 // httpAuthorized checks for parameter 'token' and for Authorization header for
 // Bearer token; if not supplied, or the token is wrong, writes out an error to
-// w, otherwise returns true.
-func (s *Server) httpAuthorized(w http.ResponseWriter, r *http.Request) bool {
+// w, otherwise returns the User the token belongs to, and true.
+func (s *Server) httpAuthorized(w http.ResponseWriter, r *http.Request) (*User, bool) {
 	err := r.ParseForm()
 	if err != nil {
 		http.Error(w, fmt.Sprintf("form parsing error: %s", err), http.StatusBadRequest)
-		return false
+		return nil, false
 	}
 
 	// try token parameter
@@ -478,22 +652,23 @@ func (s *Server) httpAuthorized(w http.ResponseWriter, r *http.Request) bool {
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
 			http.Error(w, "Authorization header required", http.StatusUnauthorized)
-			return false
+			return nil, false
 		}
 
 		if !strings.HasPrefix(authHeader, bearerSchema) {
 			http.Error(w, "Authorization requires Bearer scheme", http.StatusUnauthorized)
-			return false
+			return nil, false
 		}
 
 		token = authHeader[len(bearerSchema):]
 	}
 
-	if !tokenMatches([]byte(token), s.token) {
+	user := s.authenticate([]byte(token))
+	if user == nil {
 		http.Error(w, "Invalid token", http.StatusUnauthorized)
-		return false
+		return nil, false
 	}
-	return true
+	return user, true
 }
 
 // restJobs lets you do CRUD on jobs in the queue.
@@ -501,7 +676,7 @@ func restJobs(s *Server) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		defer internal.LogPanic(s.Logger, "jobqueue web server restJobs", false)
 
-		ok := s.httpAuthorized(w, r)
+		user, ok := s.httpAuthorized(w, r)
 		if !ok {
 			return
 		}
@@ -514,11 +689,17 @@ func restJobs(s *Server) http.HandlerFunc {
 		case http.MethodGet:
 			jobs, status, err = restJobsStatus(r, s)
 		case http.MethodPost:
-			jobs, status, err = restJobsAdd(r, s)
+			jobs, status, err = restJobsAdd(r, s, user)
 		case http.MethodDelete:
-			jobs, status, err = restJobsCancel(r, s)
+			jobs, status, err = restJobsCancel(r, s, user)
+		case http.MethodPut:
+			if action := r.Form.Get("action"); action == "hold" || action == "release" {
+				jobs, status, err = restJobsHold(r, s, user)
+			} else {
+				jobs, status, err = restJobsSuspend(r, s, user)
+			}
 		default:
-			http.Error(w, "So far only GET, POST and DELETE are supported", http.StatusBadRequest)
+			http.Error(w, "So far only GET, POST, PUT and DELETE are supported", http.StatusBadRequest)
 			return
 		}
 
@@ -549,7 +730,7 @@ func restJobs(s *Server) http.HandlerFunc {
 // request url can be suffixed with comma separated job keys or RepGroups.
 // Possible query parameters are search, std, env (which can take a "true"
 // value), limit (a number) and state (one of
-// delayed|ready|reserved|running|lost|buried|dependent|complete|deletable),
+// delayed|ready|reserved|running|suspended|lost|buried|dependent|complete|deletable),
 // where deletable == !(running|complete). Returns the Jobs, a http.Status*
 // value and error.
 func restJobsStatus(r *http.Request, s *Server) ([]*Job, int, error) {
@@ -584,12 +765,16 @@ func restJobsStatus(r *http.Request, s *Server) ([]*Job, int, error) {
 			state = JobStateReserved
 		case "running":
 			state = JobStateRunning
+		case "suspended":
+			state = JobStateSuspended
 		case "lost":
 			state = JobStateLost
 		case "buried":
 			state = JobStateBuried
 		case "dependent":
 			state = JobStateDependent
+		case "held":
+			state = JobStateHeld
 		case "complete":
 			state = JobStateComplete
 		case "deletable":
@@ -633,11 +818,12 @@ func restJobsStatus(r *http.Request, s *Server) ([]*Job, int, error) {
 // It optionally takes parameters to use as defaults for the job properties,
 // which correspond to the json properties of a JobViaJSON (except for cmd and
 // cmd_deps). For dep_grps, deps and env, which normally take []string, provide
-// a comma-separated list. mounts, on_failure, on_success and on_exit values
-// should be supplied as url query escaped JSON strings.
+// a comma-separated list. mounts, on_failure, on_success, on_exit and
+// retry_policy values should be supplied as url query escaped JSON strings.
 //
-// The returned int is a http.Status* variable.
-func restJobsAdd(r *http.Request, s *Server) ([]*Job, int, error) {
+// The added jobs will be owned by the given user. The returned int is a
+// http.Status* variable.
+func restJobsAdd(r *http.Request, s *Server, user *User) ([]*Job, int, error) {
 	// handle possible ?query parameters
 	_, diskSet := r.Form["disk"]
 	jd := &JobDefaults{
@@ -645,6 +831,7 @@ func restJobsAdd(r *http.Request, s *Server) ([]*Job, int, error) {
 		RepGrp:        r.Form.Get("rep_grp"),
 		LimitGroups:   urlStringToSlice(r.Form.Get("limit_grps")),
 		ReqGrp:        r.Form.Get("req_grp"),
+		LogDir:        r.Form.Get("log_dir"),
 		CPUs:          urlStringToFloat(r.Form.Get("cpus")),
 		Disk:          urlStringToInt(r.Form.Get("disk")),
 		DiskSet:       diskSet,
@@ -670,6 +857,15 @@ func restJobsAdd(r *http.Request, s *Server) ([]*Job, int, error) {
 	if r.Form.Get("change_home") == restFormTrue {
 		jd.ChangeHome = true
 	}
+	if r.Form.Get("cache") == restFormTrue {
+		jd.Cache = true
+	}
+	if r.Form.Get("log_to_manager") == restFormTrue {
+		jd.LogToManager = true
+	}
+	if r.Form.Get("held") == restFormTrue {
+		jd.Held = true
+	}
 	if r.Form.Get("cloud_shared") == restFormTrue {
 		jd.CloudShared = true
 	}
@@ -727,6 +923,52 @@ func restJobsAdd(r *http.Request, s *Server) ([]*Job, int, error) {
 			jd.OnExit = bvj.Behaviours(OnExit)
 		}
 	}
+	if r.Form.Get("retry_policy") != "" {
+		var rpj RetryPolicyViaJSON
+		err := urlStringToStruct(r.Form.Get("retry_policy"), &rpj)
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+		jd.RetryPolicy, err = rpj.RetryPolicy()
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+	}
+	if r.Form.Get("time_limit") != "" {
+		var err error
+		jd.TimeLimit, err = ParseTimeLimit(r.Form.Get("time_limit"), r.Form.Get("time_limit_grace"), r.Form.Get("time_limit_signal"))
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+	}
+	if r.Form.Get("container_image") != "" || r.Form.Get("container_runtime") != "" {
+		var err error
+		jd.Container, err = NewContainer(r.Form.Get("container_image"), r.Form.Get("container_runtime"))
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+	}
+	if r.Form.Get("begin_at") != "" {
+		var err error
+		jd.BeginAt, err = ParseBeginAt(r.Form.Get("begin_at"))
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+	}
+	if r.Form.Get("deadline") != "" {
+		var err error
+		jd.Deadline, err = ParseBeginAt(r.Form.Get("deadline"))
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+	}
+	if r.Form.Get("window") != "" {
+		var err error
+		jd.Window, err = ParseWindow(r.Form.Get("window"))
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+	}
 	if r.Form.Get("mounts") != "" {
 		var mcs MountConfigs
 		err := urlStringToStruct(r.Form.Get("mounts"), &mcs)
@@ -755,12 +997,18 @@ func restJobsAdd(r *http.Request, s *Server) ([]*Job, int, error) {
 		inputJobs = append(inputJobs, job)
 	}
 
+	// expand job arrays now, so we can find their members in the queue below
+	inputJobs, err = expandJobArrays(inputJobs)
+	if err != nil {
+		return nil, http.StatusBadRequest, fmt.Errorf("There was a problem interpreting your job array: %s", err)
+	}
+
 	envkey, err := s.db.storeEnv([]byte{})
 	if err != nil {
 		return nil, http.StatusInternalServerError, err
 	}
 
-	_, _, _, _, err = s.createJobs(inputJobs, envkey, !rerun)
+	_, _, _, _, err = s.createJobs(inputJobs, envkey, !rerun, user)
 	if err != nil {
 		return nil, http.StatusInternalServerError, err
 	}
@@ -787,9 +1035,10 @@ func restJobsAdd(r *http.Request, s *Server) ([]*Job, int, error) {
 // restJobsCancel kills running jobs, confirms lost jobs as dead, or deletes
 // incomplete jobs. You identify the jobs to operate on in the same way as for
 // restJobsStatus(). However state must be specified, and only one of:
-// (running|lost|deletable) are allowed. Returns the affected Jobs, a
-// http.Status* value and error.
-func restJobsCancel(r *http.Request, s *Server) ([]*Job, int, error) {
+// (running|lost|deletable) are allowed. Only jobs owned by the given user are
+// affected, unless they are an admin. Returns the affected Jobs, a http.Status*
+// value and error.
+func restJobsCancel(r *http.Request, s *Server, user *User) ([]*Job, int, error) {
 	var state JobState
 	if r.Form.Get("state") != "" {
 		switch r.Form.Get("state") {
@@ -809,6 +1058,7 @@ func restJobsCancel(r *http.Request, s *Server) ([]*Job, int, error) {
 	if err != nil || status != http.StatusOK {
 		return nil, status, err
 	}
+	jobs = user.ownedJobs(jobs)
 
 	var handled []*Job
 	returnStatus := http.StatusAccepted
@@ -843,13 +1093,257 @@ func restJobsCancel(r *http.Request, s *Server) ([]*Job, int, error) {
 	return handled, returnStatus, nil
 }
 
-// restWarnings lets you read warnings from the scheduler, and auto-"dismisses"
-// (deletes) them.
+// restJobsSuspend suspends or resumes running jobs. You identify the jobs to
+// operate on in the same way as for restJobsStatus(). However action must be
+// specified, as one of suspend|resume. Only jobs owned by the given user are
+// affected, unless they are an admin. Returns the affected Jobs, a
+// http.Status* value and error.
+func restJobsSuspend(r *http.Request, s *Server, user *User) ([]*Job, int, error) {
+	var suspend bool
+	switch r.Form.Get("action") {
+	case "suspend":
+		suspend = true
+	case "resume":
+		suspend = false
+	default:
+		return nil, http.StatusBadRequest, fmt.Errorf("action must be supplied as one of suspend|resume|hold|release")
+	}
+
+	jobs, status, err := restJobsStatus(r, s)
+	if err != nil || status != http.StatusOK {
+		return nil, status, err
+	}
+	jobs = user.ownedJobs(jobs)
+
+	var handled []*Job
+	for _, job := range jobs {
+		k, err := s.suspendJob(job.Key(), suspend)
+		if err != nil {
+			return handled, http.StatusInternalServerError, err
+		}
+		if k {
+			handled = append(handled, job)
+		}
+	}
+	return handled, http.StatusAccepted, nil
+}
+
+// restJobsHold holds jobs that are waiting to run, or releases held jobs. You
+// identify the jobs to operate on in the same way as for restJobsStatus().
+// However action must be specified, as one of hold|release. Only jobs owned by
+// the given user are affected, unless they are an admin. Returns the affected
+// Jobs, a http.Status* value and error.
+func restJobsHold(r *http.Request, s *Server, user *User) ([]*Job, int, error) {
+	hold := r.Form.Get("action") == "hold"
+
+	jobs, status, err := restJobsStatus(r, s)
+	if err != nil || status != http.StatusOK {
+		return nil, status, err
+	}
+	jobs = user.ownedJobs(jobs)
+
+	var handled []*Job
+	for _, job := range jobs {
+		h, err := s.holdJob(job.Key(), hold)
+		if err != nil {
+			return handled, http.StatusInternalServerError, err
+		}
+		if h {
+			if item, errg := s.q.Get(job.Key()); errg == nil {
+				job.State = s.itemStateToJobState(item.Stats().State, false, false)
+			}
+			handled = append(handled, job)
+		}
+	}
+	return handled, http.StatusOK, nil
+}
+
+// restExplain lets you find out why jobs are (or aren't) running. The request
+// url must be suffixed with comma separated job keys. The only method supported
+// is GET.
+func restExplain(s *Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer internal.LogPanic(s.Logger, "jobqueue web server restExplain", false)
+
+		_, ok := s.httpAuthorized(w, r)
+		if !ok {
+			return
+		}
+
+		if r.Method != http.MethodGet {
+			http.Error(w, "Only GET is supported", http.StatusBadRequest)
+			return
+		}
+
+		if len(r.URL.Path) <= len(restExplainEndpoint) {
+			http.Error(w, "job keys are required", http.StatusBadRequest)
+			return
+		}
+		keys := strings.Split(r.URL.Path[len(restExplainEndpoint):], ",")
+
+		exps, srerr, qerr := s.explainJobs(keys)
+		if srerr != "" {
+			http.Error(w, qerr, http.StatusInternalServerError)
+			return
+		}
+		if exps == nil {
+			exps = []*JobExplanation{}
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.WriteHeader(http.StatusOK)
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+		erre := encoder.Encode(exps)
+		if erre != nil {
+			s.Warn("restExplain failed to encode explanations", "err", erre)
+		}
+	}
+}
+
+// JobResourceProfile is what restProfile returns for each requested Job: its
+// key and the samples of its ResourceProfile, oldest first.
+type JobResourceProfile struct {
+	Key     string
+	Samples []*ResourceSample
+}
+
+// restProfile lets you get the ResourceProfile of running and ran jobs, to see
+// how their resource usage changed over time. The request url must be suffixed
+// with comma separated job keys. The only method supported is GET.
+func restProfile(s *Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer internal.LogPanic(s.Logger, "jobqueue web server restProfile", false)
+
+		_, ok := s.httpAuthorized(w, r)
+		if !ok {
+			return
+		}
+
+		if r.Method != http.MethodGet {
+			http.Error(w, "Only GET is supported", http.StatusBadRequest)
+			return
+		}
+
+		if len(r.URL.Path) <= len(restProfileEndpoint) {
+			http.Error(w, "job keys are required", http.StatusBadRequest)
+			return
+		}
+		keys := strings.Split(r.URL.Path[len(restProfileEndpoint):], ",")
+
+		jobs, srerr, qerr := s.getJobsByKeys(keys, false, false)
+		if srerr != "" {
+			http.Error(w, qerr, http.StatusInternalServerError)
+			return
+		}
+
+		profiles := make([]*JobResourceProfile, len(jobs))
+		for i, job := range jobs {
+			samples := job.ResourceProfile
+			if samples == nil {
+				samples = []*ResourceSample{}
+			}
+			profiles[i] = &JobResourceProfile{Key: job.Key(), Samples: samples}
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.WriteHeader(http.StatusOK)
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+		erre := encoder.Encode(profiles)
+		if erre != nil {
+			s.Warn("restProfile failed to encode profiles", "err", erre)
+		}
+	}
+}
+
+// restCrons lets you list (GET), add (POST) and remove (DELETE) Crons. To add,
+// post a CronViaJSON. To remove, suffix the url with the name of the Cron.
+func restCrons(s *Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer internal.LogPanic(s.Logger, "jobqueue web server restCrons", false)
+
+		user, ok := s.httpAuthorized(w, r)
+		if !ok {
+			return
+		}
+
+		var crons []*Cron
+		status := http.StatusOK
+		switch r.Method {
+		case http.MethodGet:
+			crons = s.crons.get()
+		case http.MethodPost:
+			var cvj CronViaJSON
+			err := json.NewDecoder(r.Body).Decode(&cvj)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if cvj.Job == nil {
+				http.Error(w, "a job is required", http.StatusBadRequest)
+				return
+			}
+			job, err := cvj.Job.Convert(&JobDefaults{RepGrp: cvj.Name})
+			if err != nil {
+				http.Error(w, fmt.Sprintf("There was a problem interpreting your job: %s", err), http.StatusBadRequest)
+				return
+			}
+			c := &Cron{Name: cvj.Name, Schedule: cvj.Schedule, Overlap: cvj.Overlap, Job: job}
+			err = s.addCron(c, []byte{}, user)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			for _, added := range s.crons.get() {
+				if added.Name == c.Name {
+					crons = []*Cron{added}
+				}
+			}
+			status = http.StatusCreated
+		case http.MethodDelete:
+			if len(r.URL.Path) <= len(restCronsEndpoint) {
+				http.Error(w, "a cron name is required", http.StatusBadRequest)
+				return
+			}
+			name := r.URL.Path[len(restCronsEndpoint):]
+			removed, err := s.removeCron(name, user)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusForbidden)
+				return
+			}
+			if !removed {
+				http.Error(w, "there is no cron named "+name, http.StatusNotFound)
+				return
+			}
+		default:
+			http.Error(w, "Only GET, POST and DELETE are supported", http.StatusBadRequest)
+			return
+		}
+
+		cstati := make([]CronStatus, len(crons))
+		for i, c := range crons {
+			cstati[i] = c.ToStatus()
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.WriteHeader(status)
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+		erre := encoder.Encode(cstati)
+		if erre != nil {
+			s.Warn("restCrons failed to encode crons", "err", erre)
+		}
+	}
+}
+
+// restWarnings lets you read warnings from the scheduler. If you are an admin,
+// this also auto-"dismisses" (deletes) them.
 func restWarnings(s *Server) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		defer internal.LogPanic(s.Logger, "jobqueue web server restWarnings", false)
 
-		ok := s.httpAuthorized(w, r)
+		user, ok := s.httpAuthorized(w, r)
 		if !ok {
 			return
 		}
@@ -858,10 +1352,13 @@ func restWarnings(s *Server) http.HandlerFunc {
 		sis := []*schedulerIssue{}
 		switch r.Method {
 		case http.MethodGet:
+			// (only admins dismiss them, since they're for everyone)
 			s.simutex.Lock()
 			for key, si := range s.schedIssues {
 				sis = append(sis, si)
-				delete(s.schedIssues, key)
+				if user.Admin {
+					delete(s.schedIssues, key)
+				}
 			}
 			s.simutex.Unlock()
 		default:
@@ -882,13 +1379,14 @@ func restWarnings(s *Server) http.HandlerFunc {
 }
 
 // restBadServers lets you do CRUD on cloud servers that have gone bad. The
-// DELETE verb has a required 'id' parameter, being the ID of a server you wish
-// to confirm as bad and have terminated if it still exists.
+// DELETE verb, which only admins can use, has a required 'id' parameter, being
+// the ID of a server you wish to confirm as bad and have terminated if it still
+// exists.
 func restBadServers(s *Server) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		defer internal.LogPanic(s.Logger, "jobqueue web server restBadServers", false)
 
-		ok := s.httpAuthorized(w, r)
+		user, ok := s.httpAuthorized(w, r)
 		if !ok {
 			return
 		}
@@ -910,6 +1408,10 @@ func restBadServers(s *Server) http.HandlerFunc {
 			}
 			return
 		case http.MethodDelete:
+			if !user.Admin {
+				http.Error(w, ErrNotAdmin, http.StatusForbidden)
+				return
+			}
 			serverID := r.Form.Get("id")
 			if serverID == "" {
 				http.Error(w, "id parameter is required", http.StatusBadRequest)
@@ -945,7 +1447,7 @@ func restFileUpload(s *Server) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		defer internal.LogPanic(s.Logger, "jobqueue web server restFileUpload", false)
 
-		ok := s.httpAuthorized(w, r)
+		_, ok := s.httpAuthorized(w, r)
 		if !ok {
 			return
 		}
@@ -979,7 +1481,7 @@ func restInfo(s *Server) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		defer internal.LogPanic(s.Logger, "jobqueue server status", false)
 
-		ok := s.httpAuthorized(w, r)
+		_, ok := s.httpAuthorized(w, r)
 		if !ok {
 			return
 		}
@@ -40,9 +40,13 @@ type jstatusReq struct {
 	// retry = retry buried jobs.
 	// remove = remove non-running jobs.
 	// kill = kill running jobs or confirm lost jobs are dead.
-	// confirmBadServer = confirm that the server with ID ServerID is bad.
-	// dismissMsg = dismiss the given Msg.
-	// dismissMsgs = dismiss all scheduler messages.
+	// confirmBadServer = confirm that the server with ID ServerID is bad
+	//                    (admins only).
+	// dismissMsg = dismiss the given Msg (admins only).
+	// dismissMsgs = dismiss all scheduler messages (admins only).
+	// tail = send the output of the running job with the given Key so far,
+	//        and then its new output as it is produced.
+	// untail = stop sending the output of the job with the given Key.
 	Request string
 
 	// sending Key means "give me detailed info about this single job", and
@@ -61,11 +65,26 @@ type jstatusReq struct {
 	Msg        string // required argument for dismissMsg
 }
 
+// jstatusOutput is what we send to the status webpage when it is following the
+// output of a running job.
+type jstatusOutput struct {
+	OutputKey string
+	StdOut    string
+	StdErr    string
+	Running   bool
+}
+
 // JStatus is the job info we send to the status webpage (only real difference
 // to Job is that some of the values are converted to easy-to-display forms).
 type JStatus struct {
 	Key           string
 	RepGroup      string
+	ArrayName     string
+	ArrayIndex    int
+	Owner         string
+	Priority      uint8
+	EffPriority   uint8
+	PendingReason string
 	LimitGroups   []string
 	DepGroups     []string
 	Dependencies  []string
@@ -77,6 +96,16 @@ type JStatus struct {
 	Behaviours    string
 	Mounts        string
 	MonitorDocker string
+	Container     string
+	RetryPolicy   string
+	TimeLimit     string
+	BeginAt       string
+	Window        string
+	Deadline      string
+	Inputs        []string
+	Outputs       []string
+	LogDir        string
+	LogToManager  bool
 	// ExpectedRAM is in Megabytes.
 	ExpectedRAM int
 	// ExpectedTime is in seconds.
@@ -96,13 +125,22 @@ type JStatus struct {
 	HostIP        string
 	Walltime      float64
 	CPUtime       float64
+	CPUEfficiency float64
+	BytesRead     int64
+	BytesWritten  int64
+	CtxSwitches   int64
+	Profile       []*ResourceSample
 	Started       int64
 	Ended         int64
 	StdErr        string
 	StdOut        string
 	Env           []string
 	Attempts      uint32
+	History       []*JobEvent
 	Similar       int
+	Skipped       bool
+	CachedFrom    string
+	CopiedFiles   []string
 }
 
 // webInterfaceStatic is a http handler for our static documents in static.go
@@ -116,7 +154,7 @@ func webInterfaceStatic(s *Server) http.HandlerFunc {
 		if path == "/" || path == "/status" {
 			path = "/status.html"
 
-			ok := s.httpAuthorized(w, r)
+			_, ok := s.httpAuthorized(w, r)
 			if !ok {
 				return
 			}
@@ -178,7 +216,7 @@ func webSocket(w http.ResponseWriter, r *http.Request) (*websocket.Conn, bool) {
 // webpage
 func webInterfaceStatusWS(s *Server) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		ok := s.httpAuthorized(w, r)
+		user, ok := s.httpAuthorized(w, r)
 		if !ok {
 			return
 		}
@@ -191,6 +229,10 @@ func webInterfaceStatusWS(s *Server) http.HandlerFunc {
 
 		writeMutex := &sync.Mutex{}
 
+		// the keys of the jobs whose output the client is following
+		tailing := make(map[string]bool)
+		tailMutex := &sync.Mutex{}
+
 		// when the server shuts down it will close our conn, ending the main
 		// goroutine
 		storedName := s.storeWebSocketConnection(conn)
@@ -220,6 +262,11 @@ func webInterfaceStatusWS(s *Server) http.HandlerFunc {
 
 				switch {
 				case req.Request != "":
+					if adminOnlyWebRequests[req.Request] && !user.Admin {
+						s.Warn("web interface request denied", "request", req.Request, "user", user.Name, "err", ErrNotAdmin)
+						continue
+					}
+
 					switch req.Request {
 					case "current":
 						// get all current jobs
@@ -291,16 +338,16 @@ func webInterfaceStatusWS(s *Server) http.HandlerFunc {
 							}
 						}
 					case "retry":
-						jobs := s.reqToJobs(req, []queue.ItemState{queue.ItemStateBury})
+						jobs := user.ownedJobs(s.reqToJobs(req, []queue.ItemState{queue.ItemStateBury}))
 						for _, job := range jobs {
 							err := s.q.Kick(job.Key())
 							if err != nil {
 								continue
 							}
-							job.UntilBuried = job.Retries + 1
+							job.resetRetries()
 						}
 					case "remove":
-						jobs := s.reqToJobs(req, []queue.ItemState{queue.ItemStateBury, queue.ItemStateDelay, queue.ItemStateDependent, queue.ItemStateReady})
+						jobs := user.ownedJobs(s.reqToJobs(req, []queue.ItemState{queue.ItemStateBury, queue.ItemStateDelay, queue.ItemStateDependent, queue.ItemStateReady, queue.ItemStateHold}))
 						var toDelete []string
 						for _, job := range jobs {
 							key := job.Key()
@@ -320,6 +367,7 @@ func webInterfaceStatusWS(s *Server) http.HandlerFunc {
 								continue
 							}
 							s.db.deleteLiveJob(key)
+							s.removeCopiedFiles(key)
 							s.Debug("removed job", "cmd", job.Cmd)
 							toDelete = append(toDelete, key)
 							if job.State == JobStateReady {
@@ -332,13 +380,33 @@ func webInterfaceStatusWS(s *Server) http.HandlerFunc {
 						}
 						s.rpl.Unlock()
 					case "kill":
-						jobs := s.reqToJobs(req, []queue.ItemState{queue.ItemStateRun})
+						jobs := user.ownedJobs(s.reqToJobs(req, []queue.ItemState{queue.ItemStateRun}))
 						for _, job := range jobs {
 							_, err := s.killJob(job.Key())
 							if err != nil {
 								s.Warn("web interface kill job failed", "err", err)
 							}
 						}
+					case "suspend", "resume":
+						jobs := user.ownedJobs(s.reqToJobs(req, []queue.ItemState{queue.ItemStateRun}))
+						for _, job := range jobs {
+							_, err := s.suspendJob(job.Key(), req.Request == "suspend")
+							if err != nil {
+								s.Warn("web interface "+req.Request+" job failed", "err", err)
+							}
+						}
+					case "hold", "release":
+						states := []queue.ItemState{queue.ItemStateDelay, queue.ItemStateDependent, queue.ItemStateReady}
+						if req.Request == "release" {
+							states = []queue.ItemState{queue.ItemStateHold}
+						}
+						jobs := user.ownedJobs(s.reqToJobs(req, states))
+						for _, job := range jobs {
+							_, err := s.holdJob(job.Key(), req.Request == "hold")
+							if err != nil {
+								s.Warn("web interface "+req.Request+" job failed", "err", err)
+							}
+						}
 					case "confirmBadServer":
 						if req.ServerID != "" {
 							s.bsmutex.Lock()
@@ -362,6 +430,23 @@ func webInterfaceStatusWS(s *Server) http.HandlerFunc {
 						s.simutex.Lock()
 						s.schedIssues = make(map[string]*schedulerIssue)
 						s.simutex.Unlock()
+					case "tail":
+						if req.Key != "" {
+							tailMutex.Lock()
+							tailing[req.Key] = true
+							tailMutex.Unlock()
+							out := s.getLiveOutput(req.Key, 0, 0)
+							writeMutex.Lock()
+							err := conn.WriteJSON(&jstatusOutput{OutputKey: out.Key, StdOut: string(out.Stdout), StdErr: string(out.Stderr), Running: out.Running})
+							writeMutex.Unlock()
+							if err != nil {
+								break
+							}
+						}
+					case "untail":
+						tailMutex.Lock()
+						delete(tailing, req.Key)
+						tailMutex.Unlock()
 					default:
 						continue
 					}
@@ -426,6 +511,35 @@ func webInterfaceStatusWS(s *Server) http.HandlerFunc {
 			}
 		}(conn, stopper)
 
+		go func(conn *websocket.Conn, stop chan bool) {
+			defer internal.LogPanic(s.Logger, "jobqueue websocket output updating", true)
+
+			outputReceiver := s.outputCaster.Join()
+			defer outputReceiver.Close()
+
+			for {
+				select {
+				case <-stop:
+					return
+				case inter := <-outputReceiver.In:
+					out := inter.(*JobOutput)
+					tailMutex.Lock()
+					following := tailing[out.Key]
+					tailMutex.Unlock()
+					if !following {
+						continue
+					}
+					writeMutex.Lock()
+					err := conn.WriteJSON(&jstatusOutput{OutputKey: out.Key, StdOut: string(out.Stdout), StdErr: string(out.Stderr), Running: out.Running})
+					writeMutex.Unlock()
+					if err != nil {
+						s.Warn("output caster failed to send JSON to client", "err", err)
+						return
+					}
+				}
+			}
+		}(conn, stopper)
+
 		go func(conn *websocket.Conn, stop chan bool) {
 			defer internal.LogPanic(s.Logger, "jobqueue websocket scheduler issue updating", true)
 
@@ -471,7 +585,7 @@ func (s *Server) reqToJobs(req jstatusReq, allowedItemStates []queue.ItemState)
 			if allowed[stats.State] {
 				job := item.Data.(*Job)
 				job.Lock()
-				job.State = s.itemStateToJobState(stats.State, job.Lost)
+				job.State = s.itemStateToJobState(stats.State, job.Lost, job.Suspended)
 				if job.Exitcode == req.Exitcode && job.FailReason == req.FailReason {
 					jobs = append(jobs, job)
 				}
@@ -487,7 +601,7 @@ func (s *Server) reqToJobs(req jstatusReq, allowedItemStates []queue.ItemState)
 		if allowed[stats.State] {
 			job := item.Data.(*Job)
 			job.Lock()
-			job.State = s.itemStateToJobState(stats.State, job.Lost)
+			job.State = s.itemStateToJobState(stats.State, job.Lost, job.Suspended)
 			job.Unlock()
 			jobs = append(jobs, job)
 		}
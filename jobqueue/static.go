@@ -3646,195 +3646,227 @@ zFvEs1qgU7gv5V+7LPIgbXuMZSKXfPe7B7vtPibYbT9eDnZH588wzE3Do7FqYupv093X2ThGyLoYIUUB
 	"/js/wr-0.0.1.js": {
 		name:    "wr-0.0.1.js",
 		local:   "static/js/wr-0.0.1.js",
-		size:    4979,
-		modtime: 1534412156,
+		size:    7327,
+		modtime: 1792171969,
 		compressed: `
-H4sIAAAAAAAC/5RYbXPjthH+rl+xVS4WeZIpynZsxz5dJnZyyc30Lp27XGc6tjsDkSsRIxBgAdCymnN/
-e2cBvunNbfRBpoFnF7sPHuyCGo9hwR9RApPAtGZrUHOYC8WsAZsxCyxNoSzAKvjPJI5HoNGWWhpgkCit
-0RRKplwuvHFvPCZ7Lq2BVYYaAVmS0ZDNsHbLNIJWpUwxJbcMVpkSCLLMZ6hHYMok80vbDMmhVZYJWHEh
-YIaATyyxYg2TOI7g/RxyLuEtxCNCUxZr4LIoLSw0MouaPEmIYVZaWHGbkUPWLK/RlMKCQGM8kLxxA1wa
-iywFLhONzPhAcy5HwGQKNlMGQTC9QO0CrC0psw1WxBo0pmWCKQQ24wZytoaZRrYE7jjR6IysUpAz6fib
-oVArchdGvUemoUCdoLSfXMgapjAvZWK5koHnc+Sw8EcPAIAMkjIvBUwhvm6GZsyg4BI3R9vstoatgSnc
-PfihudIQuHEHAw5vqp2MBMqFza6BD4d1APTxAQynFeyOV56auU8V/VP4wGwWud0I3EzYIqtQYLppc9wk
-00L5HIJKB3B01C5bD5CbN12aGsOGgeHUuzgmcCcKj6Ioci7b4edeZ9JERWmyYNOwQ3k3fg947vXquNsQ
-3kLcjY/yV49ux+8eNmlxJ+I3Pxe3U/t2yoV3YJ/aEGq29lBEHwqjynGLGvq00QynUPnaRD13eNtIJMUd
-BR5KxMXwQiKVXN7LFJ9g6uA7cVSYvzM6Hj7SymIPsNCqUJoOmgeT1bhNdtegTqaWdYJcBB0vr1u5bQts
-DkFLxbD19LZjsmdfOiu2MjpuWT20C7Vu2/TbFFvzTetOfNM9kOeurn2PcEtc956ve729XcbVGd9jNIIp
-i0JVlXaGddVjCzQjMAkTSOUyB6Oqiuvaw9qZshpdNxq/1iMTJW7U0M/kZ6OEUgwjyNlTt4BWferlEvjS
-wSJoKV3UKew5EzTfzAYNckxNLYTXFE/nMPha646fB4Z1Eelw7VEN2x9dMw0mJ6dnYWTVT6VmLt+w07+N
-1dS4EyUfUVt6JO4MJkqmxu2H4Jj6DgwMsjJnEjSylM0EEic5szDXKoeUrY3vkELwysEIzJIXBbktpcQE
-jWHaNbiCaWt6PsKo0Moquy6wE2VngyCoeSWqqIW2fcqtWp21uVBKB0Th5flZHIctKtuGfOshMIbT8w1k
-voskBIzhvAszB2DfelgbXklKGwyum1LvAt6q8oSiA0VTQxikMOhuLlllB0wywme7+PwAPid8vos/FJKL
-x+ziXV6U2Lbic7PZ0IMtbuAYDKl7Eje0d8Nz6+VmsEfcaakPKptZ/BOqDlCwgqqM4TJxt8svkj8BFirJ
-wkbzpOW9ct+rWmbx/1asg0pcgYs7fd3hwolVOdEQLlqg/VWV2gQhvIFJDD/AIB5Qc9iavNoe6Siay463
-D1yWFg/6a6evdsc68sek4/Oz5/WQz3b6anfsemOLq9l3pRD/QKYD2tzPbi+DMDLlzFgdHJ+EMIT+uA9D
-Ok1VjEraLKCJSTtbT1byGEIfaNjxO4T+Ff1D7NTPBpNGYbdeOvDhBrgkKfxyA0rD7zckf1ZQR9ecWaee
-YOZaopKQWVtcjcfGsmRJl4+5UKsoUfmYjU/ii9OT+PvJ+Pziu/jyNNxVUT57//PtXhE5yvm/sdKSOz5n
-l99dnHfu65sFyT8LtQjILoRxOzCJT85C2CQ+8O4rVKFWDjUCHgJtwTv+hGlw4s4tHVCg7b0b3AxGMFi6
-7w/u+xf3/fvN4MF1uorKhBXcMkELuBdArg29bVl6MVPz5rQ6Ji1bovT95AUuJ/HJeXx50VDpJdKhsl3y
-Ha32V79Yp+XXzFb5E6tRkjH9ow1iyvhLUaC+Zcbrxk0bwRMMJuF179mltULI2CNdPGbcv/1mTKZ0taAm
-yJYIM6WssZrRi7MSlhcGVkov3SsoLKVKlqq0+9I2h/I+v7w4jyenTeJLFVWL/+rXNlG1FEyrDLnk9qpN
-HAXmKO3IX4x+TKglK71dxYVK3PV4qaLScmGiUq40K36bGdSPVAqDDfMgDEcb90RV0GLUCv54vm5v+407
-fLIo06CCjeBwHlGF6fSKg15c1GFnvVd1umHtLmjd7fpLVf5RpfgTN4UyTEQsTf0z3jIhZixZtvTtKOmF
-NfspGqvVut9J4rm+v3niqrCuOs4KwRLn6Ar6VhX9lmGr+WKB+gr6GQmkX/XK6rhRtwIG/ypRr+maxXK0
-qEf/W13fx5PJ2VmlLXdjXqD9W+3gZv2R5d0eF0iWY7dClZoks+IyVauI9oJQUaZx7lOV3p7+RBpdesH4
-7v7u/uFhvBhB//7+1VG/02Q0LtxbHHXKT7j4+akI+nc/HD1QqXa+htAPpsHdP4++eXgdfj36+s3XV2G/
-o0T/ww6p0LmK8AmToNT17wt0j/lLhakvDyBLIXan704eGkR9mat7FiYqxS+f3t+qvFASpQ1aozbP+6HL
-Efph6ArjfwMAAP//4MqlMHMTAAA=
+H4sIAAAAAAAC/6VYeW/bOBb/P5+C63RiqXFkOXdztGgy006BSafoMdhFmgVoibY0lkStRMXxtpnPvu89
+UhLlI93FGkHikI/v/PEdHA7ZNL4XGePwUxR8weSETRLJVclUxBXjYciqnCnJ/hr5/oAVQlVFVjLOAlkU
+osxlFsbZVB/eGg7xfJzB6XkkCsEEDyJcUpGo2XJYLmSVhSJEthwoZSJYVqVjUQxYWcEJEg1nkKGSiids
+HicJGwPDBx6oZMFAGY+9m7A0zthLBooBNVqxAOl5pdi0EFyJAjllzGdjWJrHKkKGvBEP+leJYokoS02I
+3OISWJRK8BD+BsCm1IrC3gAEwPdIloIlvJiKghSsT6JlHa+AnoUIqwAYOCoCxik4eAwsZywmn8ABPKQk
+sAfdkdtYJHKO7Fxv654XLBdFIDL1kVQu2CWbVFmgYpk52p8DomXfthh88EBQpVUCdP55szQGG5I4E93V
+1rqlZQjSJbu900sTWTCH1okM/lyYSHqJyKYqgqXd3VoB/GgFdi8N2W1sODV7H437L9kNV5FH0XBox20p
+jSpA1Dmz1xjTkoIvHYMDtrPTiq0XkM2F7abmYOMB0JZY7CGxpYWmQi1gt11+3LI2Sy+vysjpHrRcbuuv
+CR63tmq9WxVAWVs/tF/eU8Rv77puoRvxu97z2611kSL1NsSpVaH21hoX4QfVMDYuuQY/rTbgRMOrS/Vo
++a1jSChWELjJENLhCUMMXN6Bkx/gFJKv6GFo/uB4PbSm5sQawryQuSzwomliPDVsjV09UBtTwzoQceJY
+XJ63cFsGGAShdcVuy+mldWRNXCyJLYz2Wq9uikKN29b81sT2ePe0pd/lGpJHG9e6RpCI863H8y1Ma6tV
+hvKMrjGQAssqz6XJtJDmTdbjUwEJrgw4FAhIlykrpcm4VB4WdJTX1HWh0bLueVKJTg79hHw6KRR1gATK
+H+wEaurU0ynwqYuFpFVGWodszZ3A/WbXaSiHWNRcwAnoY10GnWvp+mlCt04ilq81VePt91RMndH+waHr
+KflzVXCy17Xqd6kKLNyBzADOCr+i70oBC2FJ8UhiEeoKDORRBRUKjvOQjyEa4JMUgjApZMpCvih1hUyS
+2DCAqM3iPEe2VZaJAEosL6jA5bxQ5ZbW0IP7AVdqkQtLSytAzKn9iq7CEtrWKZJq7hqYLwsHXXh6fAhO
+bKmiZZKfNAmQHhx3KNNVSqQAwmObrNxA9pMma9WrEGn9/nmT6knhpSyPVHihcGuX9UPWt4OLp6INRyKk
+j1bp0w30KdKnq/SbVCJ9ylV6sgsNW0Z8WnYLurPkG0gtJaIbQO5bCbBWj+SlZX8NuIFkI7KhyfsfUO2I
+hOeYZUpImNRdfsniByZyGURug3nE8lq4r0UtaPBfI5ZIMzFnpHf43PIFgVUSaJDOmwr1K/xbAr8L8Bl7
+xfp+H4vD0ubZ8oqF6DizuN3EWaXERn7t9tnqmgV/EVg8P2m/buLZbp+trp13Qmx231RJ8g/BCweD+4li
+CV/Lagxxdfb2XWDeG/bgt9PoKDMVObgxanfrTQMPWGa4TP6Ff87wH/RO/R2sahB2raHDbq4gcyMU3l4x
+KACfrxD+PMeKXsTAF2mdMZVECHukVH42HJaKBzNsPiA7zL1ApkM+3PdPDvb9F6Ph8cmRf3rgrqIoHb/7
+5XotiMjl8b+FwRJdn8PTo5Njq1/vJiT9PZFTB8+5kL6ahZG/f+iyruMdzd5Q5XJOVAMWAyGE4E38IEJn
+n+4tXlCG4b3tX/UHrD+j3zf0+y39/nzVv6NKZ1wZ8DyGfgkF0AAYFyVOWwoHM6jV9W0lTyo+g6JN9eQJ
+X4Jyx/7pSeNKDRHLla3INyjtNy3MKvm1Z4396FUviHjxWjk+Wvwlh27hGsJKuKHtMokD4YzAcY9k1lyw
+iN9j4zGO9fQLE2CIrQUWQbCDjaVUYBvHwVkmKs5hHpbFjEZQNssk2FWpdWaXm+w+Pj059kcHjeEz6Rnh
+v2rZpWdEgbHawjiL1VlruEhECk3QQDdGrwMsybJYzuKJDKg9Bv6VipPSq7I52PH7uBTFPaZCp3Pccd1B
+p0+UOQrDUvDt8bzt9ht24kEJKA6GbMA22+EZGqtWbORCWruWvGe1uW7NzmnZrfILZfpehuLnGCb3kice
+D0P9XVzzJBlDPFr3rSDpCZm9UAAM5KJnGfFY92/acUatM4tZnvCAGJ2xnpJ5r/UwoH06FQWsRwiQnqmV
+5rphtQJQ/qsSxQLbLJ4KhU8qP0TXC380Ojw02KKOGXLnh5rB1eI9fLHvUAb/2xmqKhAyc4gjsMRYIJUX
+FWKiTc30efzjFYLMc4a3X2+/3t0NpwPW+/r12U7PKjKFmNIUh5Xyo5j+8gCevH21c4epmnhB3nYundt/
+7mzfPXe/73zf/v7M7VlI1A87iEJiBXgRgQNaum039jdDUzcPLIPSs7p9u3/XUNTNXF2zoJKF4svHd9cy
+zWUG8XLaQ62dX3fJRtZz3aVpCHPih0JOYmgwKB3+KcfMsWekkqd5IkqdNz7HqRiwj69vmHNz5Q4YIHRG
+OQT+o4ep6w9fmH5HgQBAfsp4Jk3n49I8ix2RKN32FQ81SHkxq3JSIEN2n/54y+jlIk8kjVQgccBCENYK
+4SU+dAl8KWMVDKBjoeYCLDL6ujR/0+hAOtMroJlyIEXGEBk5z3DOidMq1TMacIPqHAjjENTCQlzDuAXd
+PA4VdvcHvvV4FYl4GuFzzam1iErY85zuY4qYFr+hfWewpx2qv4GR+IU9bh4AjUZPzYCoB3YgHtxFqCfm
+BBRID0Pp6oHPevcgPfWkZz8laVU9UNNMgS0fWFslRDNWKHFx6XVNR7Dz8kLPQfWzmYIvpNJtDL376G45
+39XnqXOAWDr+gNkiESl7rFkgHrhIhosXDKsfLHZEuOve2IxdcFSbRYI7UzBFVMFgiX0S8fPtWOccG2FH
+72gv67CRUFQSz7rs+3c2ska4QCbYUrcg6W8fHJzw8Um/xkp/+ygYnx4FfQOZ/nb44ujgcNKvkaMfhsqg
+bKoyfohZg239/NAkGbrgOb4RY6+FuxSQV3hddKdI/XTfhw61jz1K3+2f19UEP1q1Texx9//jT5ZuYk/p
+oeGOLNc0kTqAyFXH0ArV/RSn5gv8+5AmWXnZM93gfD735geeLKbQUPv+ECh6Ogdc9lCUTgfAvWeSgF52
+TEaA8eCIjOm9NFkcReyirEIEij1c9vweW9DvH3NtmMI6ZKvksrc9mUx62M/KmYD/wjDsDWtJ1FdBHcrC
+v7fXrckpVNAgj2qUL+cQ6rYQPXr7FqmXnpIwgu0t9GBESRYOVjPT6pVL9z6XS0/8m1KcPv2jV1cU7pi7
+FVuXaUj3DoNOTlx9MF3AQePIPRuHusMkZkP9Nve8CeMe20dw0mvCqMtSm6UzRIs6Ggr7Awzawlpcm2fq
+y4oDLt5Z7WxCuJVka9jkMllQndQAyKADqAGwZxA08o5aTKAKJqNoxho+Wm29bUz4E/44MGoZvF6oWCXi
+JQ3WqB0sXgz12sWw1qLGWkdFBX0yIhuP1gAkoYuVy7HvGjTDOL2HIyFo79fgXq/6skYgzNaiEXhpbtGQ
+Hdg5u9ESr/LLbmsFK9Qr/QeAtAMBnxwAAA==
 `,
 	},
 
 	"/status.html": {
 		name:    "status.html",
 		local:   "static/status.html",
-		size:    66335,
-		modtime: 1550137006,
+		size:    76992,
+		modtime: 1792171961,
 		compressed: `
-H4sIAAAAAAAC/+x9a3cbN7Lgd/+KCvfekLRJSkomu7mSqRxbcma8sSdeOzOz9+jozAXZRRJWN8AB0KS5
-uf7vewD0k+wHutWUlZz4gyWRQKFeKBQKQNXzr65/vvrlP9+9gpUK/Msnz/UP8AlbTnvIepdPAACer5B4
-9lfzZ4CKwHxFhEQ17YVqMf6+l/laUeXj5T/ewwdFVCifn9gPnqQtvhqP4eP/CVHsYMEFbIigPJQQKupT
-tRsBYR4wRA89mO1gxrmSSpD15KOE8TgzkpwLulYgxXzaO/koTz7+S8McfzP5ZvKnSUDZ5KPsXT4/sc32
-EXgZgzU4rAVKZIooypkZX6qdT9kyP6ChfKXUeoz/Culm2vu/47+9GF/xYE0UnfnYgzlnCpma9l6/mqK3
-xN5+b0YCnPY2FLdrLlSmw5Z6ajX1cEPnODZ/jIAyqijxx3JOfJyeZYH5lN2BQH/a05iiXCGqHqwELqa9
-k7mUJwnbxt9Ovp38L8OPuZS9Cv4Vdali4U+Mz+94qAwHcYNMwYow75Bv+wPdRR3H307+NDl1G8fKSnEI
-yB3CLFSKM2lEpVaULSVsubiDb8ZbsoMZqi0ig3gc0yyhzgE3y4WzybeTb2qx+8ADBL4AHgrgWwZLZCiI
-Dyv01yhgEbK51qoa3d2K8enkdHK2N5S7vBMAqZCfn6Qz9/mMe7ss6h7dAPWmPUY2PZj7RErz+4wIsD/G
-Hi5I6KseCO6j+ZIuzQTJ6FACKoKg1ZlQhmKvzX67aAiNX2Fby6M1YXsdZoIwr5e1LrpRwVgnHt3soZn/
-KPrzkCHSAO7VUbTXHoXgQvbAI4qMZ5R5096CCyTz1TlkWtSwhfgoFJj/xx5hS60/xEOgrIxH6+yICj+p
-c/g3/YlWonUTvhQTNyOeRLHBMtIy33dNWabzmjD0wfw/3hLBKFuW9CrsadSsuo/+98EQUtkkmfR3HOji
-HN4JPvMxgOkUer3cBK+EEMboeVwp9HKsVZz7iq7P4VcwC+c59F8vtI2TQCV8DKUCAgqDNRdE7PT6wXCu
-6IaqHVApQxzZxgFKSZYIW+r7sORAjGHcAVUS/cWkD597lwFdrhTMEDwk3vOT8NKN+JM77kRrllNfPQyr
-flmhQNgSCQTW0Yih1AuSYYrV1Qm8VpYvjBvyQ4meXlpEyICrFQr4yGdyAq/ZBqXSVg+BKggIC4nv74Au
-YMdD8OkdjmCGejbAiiplx0H4r580cKr+K1qnLLepBMbB50b5Q0lmPnbH84KJXT0n9HpQMyH+SgI8j8zw
-gZXRX5qVStvf5zNRDer1dSmg19cNwLwrB/POHcz9pvAbLpXx28hclaJzTRROFNc/BsMEs3pZW4UBtVvj
-tGf/SJaimWIwUyy2n+vQ98dCT+HcrJj7dH53Dv8mOFeTOWcLKoJrJJ41b73L16ovQaBRZDvv7TAOLHOZ
-+Pec9HEPZHMeMoUCvVIeR23d5V4yAJDfohwjG9Oh+CpsSMlXru5ERieidUkOhhMf2VKt4BLOCtFy4mHk
-Djgx0aMyoFK+jTDoXV7bD+CF7xezsZRtdRSdFlN0b4dI+2TxeMUeWfJtg8XA2bW6j3tlXKz5Cr3QRwGv
-tavi5gJkWH2lp+xgWKoyZf9uFlRIBQL1prt6wv+oWxbP+lt3fJ0sZfWS3XrZTvdOB8S9lctm1vK9A8fe
-EMuwwbCNobyndDUVMZKlGBrACU6gaICyY1f3uLYqMVWO1r7GGezEzmfZc7h5NFGKKKp1Dmenp/9+kfBj
-i74P+r+xDEDx9TggYllo97KgbKNzOAUSKn5RZiVX3x10uIA18bSFOofT3uVrNufB2keF+QjDjHhL7B0q
-D2ULX8tqorgifjp9Tlbf1e9cM9RlIWttz8M1an/qarQFXwqUspcndTzjSvHgvBJOGazxjAjI/jGWStC1
-nvp6e4n57+KlIooNxd/NiMjRadDT+7NIDxKaPfTJ7t1cz/Zn0P93sz9qZCvykNCz/HM3G8WGYh9qajOi
-D558Mev/hcS0RuYhUx2JKoLWubAiuFlxRR/9xgRG2YK3lpZA4nUzqQykjqVkYKYS0vKhbPno5dNeGiHr
-RhYh03O4a2lYqKk8og9+Y/PF7pxay8jnshvTpgF1LCENMhWPnwk6PUIZ3VMOs1B0Y7hmoaCdOwMWaCoL
-+/eDSeG4YZmnT5+aMPgOFVDtFwfI1B51WR0QfAvWz6xx25PzM3/8SY6/K/PXF1wEOR0JZwFV5yDwXyFK
-9R7XfxY8XDt6xpStQzVe1vQ4OF3MdBsTz+Oxt674cqkVOjppiD5NjgSnPbMdt6cP094rplAAYUC150EX
-FAUoDsSXHCSiORqwZ4HAF0B8H+Y8CAjzJBDPQw+2VK1ArYjKQJj0LtM/XHbVzw0x0U5Ua3Ky79KsNsgL
-7ufm5Yb4IWqW1/K6knMzxXruW+X9YGh82mwRt2rQu8wNtvR36xWdcwbJb+O1T3bjORVzP3Mc4bhLrmZm
-5bzTvGxz7Kz/He6YM6ZMcqHIzMdY8V3CiivRaG9eeEZdMKz+bBDfXxj4IzGEX0GgCgUDf0I9uAShf/wA
-Z3AO4zP4PKzZw9eGA6pin43iAG6xgDLLnzH2TjEC19BAg/CAW1Sg68hAp9tOMBEtYi5GFTgGRFAyNqYn
-oGzaO819Qj5Ne2enp5Xuw2EQYQRxEG1NBDI1kSu+fY9rY5+u7RZ+BEQpocH00/EY3/ZzAF08kP2p2y4U
-UeGBtI5CNI9f1juCvzHVKApc1KhH1KVSQXJg2ylJuyBIpZrcI/7xeFWFsgU/tp4chkwqdeS9bl6hHxlw
-bXSjTdilQi9aRlwelUYcW/57QZpq6dsQSZX8Y3CtpN8q0FMl/7YxnsdrE6KT8iNrxUFYqFIt3nBZtWak
-wNooRYvAUoVG3COm9GV14mHkfhCGqpT7SxMGqpB8Cq6N5FuFsipk3zKK9RjkfrTtAyrck3fV3iBp3XJz
-gKrjzYEGmNscoHr8m4NwPkcpjz2V4zN+9+l8FfWo0IE80DZaEEPoTg1iiKkexJ98EUVwi2U/qeNVEpfy
-UBHqy/oYemFUxV5sKw+G5K7fSGmEnrsL1z83D00QplPoR7vvPvz3f+c+jbZa/VHcWe9ccj2NJ55+vxY0
-IGKXb2J9s7SRNX25NtZk742vV/G0VzS9ct1ihXA8V7nH/T6nqFvB/azAmLGqqFlZNJBvUCx8vh1/Ojfx
-wF6TCRUQ3798TsvCgFdb7yWRmbByabNEw+bc5+IclgJ3mXAg1b+awdzoc7O3+7blLQ+Zks1sSjeczHMz
-MHiUXsazaLbnThsOHXOlS65lwh3uNsSXrvPEa0Kwpy5fKIXBWsnnJ55q0tM7lEEMSkvB85y10j8SZa8+
-rXGu0IP3L952QF0M7v2Lt5Ng9vrVlb2Q+pgI/YUG2CGlGtxE8etQmAeaR6M3Y23e2/NZ9K6pvGvuzDTh
-XMy9ZEjQYzZjX8TCMhueoyZ1pf780p2NLVjpapZa6doVF9iFrTBwjq9PbzmjiotrPr9DAV9Nod8/vkZF
-g4IdtVONytGTWe0eizplWP8jof57JJKzI3M8M+ah49to7KwQ3wncmAQSmo5QYAsxNuVeOUVfdUFRJIwF
-F1+CpiIjkKpI72F0uLESv/pE9cpwdJOhx4E597CVtShawqnS4I7H1yJO6RG1rp62UA+/nVJ/UN7PoWrO
-tdjONu50OEE1Aq0mZeHVp0wEq+wVj1zx7QflTfRXA5OXYQR9i0d/2Lv82lcXusnXS3Xh+mCq07lexKav
-umCUpoxxhpqyhyep2UxqPpvuOw9eCfFl58ErIR7FPHglxOOeB/dl1O97HrRCrtWq+w7JXfPoQOmiq8G1
-jA7cb+3VA7faMN/L5BjutdszV7JQg2zLw8esbR8UEQq9jpQtgpZ7HH1EbWvl1DKvM3INrMdM7D+I76vG
-8bdSemNwreNvD0T21bu/dUh1BO2xE/0XLlVHFP8lujvzCCmE1+86JNJmUnqY9dCMd613og2Sgt17PbQ8
-u+5wNbR0/J7WwHe0qwXhnX1O8RiDRl/FYaOvv4ZBEpLsCTSJ4rxe7qS9F9+nzH9q7tQN/3BKHtM6XRRo
-toJqGZM91rrfffS5azLf0A3GpNoMPw9P7B+Owh+Owh+Owh+OwuNwFNIVJbpSbT9sHCts6QW0ix63ihw/
-sjDv41SNNzSgyr6ZPr74M4M9Yh3IYPl7lfp1/E7++DJPhnrEEk9w/B3L29zynlN8GJEnoz1uqSdo/q4E
-3/heJ9s0vmnX9Hp1c/G8Ypv7SaXpnb/m+Xy3D3Bj5y88QLhaEbbsLHqiQUYQH6vH+hJXZEN5KB7AXKVj
-PWJjlSL5e12jflYrFNFNZvkQ17ElD8UczeVpKkzisMesAIY9vxHZO4Bt91BlwbkyD6mRiAX91OIJ4wca
-UJ802+o+K3sMFAFLb9zb8itxXrTW9xvtTv1+Nx1NNjZJAgSM73zCoISO7C1OQ8jQ1BwT6UXehb3Ie7wA
-zr2ugKcxjTjnUDP7cZxyF+8x4Bs0eZt6l/YPt9RuHfPEJlJ5PBx5h8z7ogxJMw49JjVZf1kliU8HHwFH
-fqK+byvEfBFWND+Cil6P/rKiEj7yGZD1GomQpkDRCGahsvW75jz0PVOwLESTWjNTCc0UPwMZzldgyn8x
-VFsu7ihbxrb3AujCJOHUI1AJZK5sPa8FZTgCGhUFE7hBoaJ6YCZZmTSUkVDxgCg6N322K2QGWFxmjEpY
-0E/oTeLXrE4FNo6sCNdIvN7llf0Drp3LPXWsEHGgvPHb5JQBNsdolvaGbps7gx0Nzo+E+u0sTiOcomQB
-DkgpYZZJJXbN0fmCL6rrUkbUDddBEmRiUpxCwD1SkGtiP2mqaXYOvx4MuaGSznw8j+C91e3+bj8bHTT2
-KPH58krKc+gbiGMZ9A+b2RKp5/CrwUD/9MkM/dwYfzFt4DN8Puw/495O92KmmF8/0+sl93a/YLD2icL+
-KAJvv7+Osm4UwLMbiGKIP5rv6mDmQH428ZMDQUXlcdMkxicrFfg9U/+qhISi1LO5dEp6QgyG5gg5mjLF
-BumFQFPeUYbRL1vCzHJQ4vtbfDLlhVZYnqwlV4goSf8cJX7GbOboXmlWvzhLcwSm96TOEGP92ziTdXpF
-vMxep2R83eAqu9UxOx29xKJemucklFiK/CL3jtCi/8OTdtM+dzzrQGKLceq/3NeuaSPtenBVASIwW/zx
-h4YkF7k0pXy4015oufyslzRQtmSr9rxmCMTmuI2rqmpC54EnQSq+BvyE81BRtrwAslAoQI+gHbQtoQpC
-pqgf+3dSqyIPMEpzNixNMdJOxMKs+vXEmXbEB75IJRhNtQ3uBTuipK2aHm5cy8ByRVIfmdJuKqF+C0IO
-q4k3MbF5m16T7D/x03r1c3buWO2tKycpCKh6YejK3U9QIsThCOIceVbGkzlZU0V8+v/Q1AN8g0qhsInE
-gPh+v+eQY/7IiC+ILxtiflaLdyOrG0twOv2yImzGifuzwGknEZczMNRE1fwi17F3eUXYHCv25oW+azyL
-D91XqTweqhMUojsXViqvqf/qL0cQebLKa+LKxmO5+LFx1zlnCpkynX8O1TpUul+Jb3nIMp8GVC3tDQ6D
-cwcs85fNOdaETX1zrwbsRYu+k7uPbFPu6/vLvxMhGzDNi1Ildscy79gsS64o7Lrjm9eCb+nlkc5Yh+uH
-4h3FTtiG64Z8m6Vn2F1xbYarI3MtPWfugGczXDXkmfUpu2KXgXZkhplzWSg8Te6Ag4aChjxEtumMgzFy
-x+PfK7ahgjPNMPg7EZTM/E7mK7JNJd+cdxNFo5RtJIpqExk3r2xHUbzzjbpUlbpu4GOtxP4n0TE6NWjq
-X4vosRu1r+d8vbuAb07P/uf4m9Oz7+HPyPTG9D1KJGK+sheIM+cGeyhZ+Omn+1pbwPqPZEPsp3to3fEJ
-X2v/WU48XKD429ojCiVMzTboIk/kyQlsKG4D7qFvjrA9Ktc+2cUnImH+eD4uFGXC/qH8O8XtW911MCya
-HkSARH+hR15ReZgbQ385UfwOGUxhieodESRAheLl7q8kwEHPfNcblvQk2oZQtozKrU0N5TOJYqNnxwsh
-yG5Q1tf2QSG4aNZxRjzzblA0HDAu2d+sVxzc2e9V2iFKpRznu4Yp9PvVTaMDnNp2P78o+X5LfP8XGqDV
-M+HWSvOB4RZqyCcKrT88hW+/O714UsalgG/wJfE+GMnANNXTAfWKVLNAnBGUtBaa/byst/4XlUmzDSev
-r/UmmXrFOWA+F9D4uZKeqCx8jppALivJibXskJj5Cr3XUoboQlDSePJWLjVVgVzen6y41iZMS1BIkm+f
-72n76XCCnxQyb/ArJDpxvq8jn4ejMrBx9u6OAduU310DjTILdgzWpBDvGGaUq7xzcdkKbUdTgyPAjotC
-HUEZjgA1KldzBHU4Bg+47/3TVErsn8Nplc78c86DdahQtzu0ShfVVummb8e4tWttBMpLTWiZ4aQLGOxB
-ymNz67SG5ACkJN+W2N3im8Pa5TL9YApFOKHXvzVx4oMvYwtZ+LW1c8VfRdaq8Etjcwq/iSzHbdHSHzPV
-EnIJp1X80xQHoa/o2qdm6T87PYUTy4TybGwnJ7BFkHPimytG//G9uWi04dQDArNwCZTBjHMllSDrpLZJ
-FbiZ3kVtV3S+ii8YydBXGo5aIZjLLOOAS6UbVsFZ+HR+h8IcEIUK+ALwE5UK2RxHgBtzH4mHy5XGn+EG
-K4FZDnLtE52dnlby0PDCgymsUcyRqQ/6bzG4GWSY+7RCp4YjqGma0bC6xom+1TZMta+uaayLde1SzRze
-juA/vh9eVPJN8JB5Wca9Nx+IgWXoCL6pAFDETm1AbwcR2JvT2ybdM+tbCuKsAYhkGUu7f9Oku12t0s7f
-NugcL0pp7z816B2vPWnv78p6l9jOchOsN7Dl9iSy4CUtPjuufeV7m/gN6hRubmu2iW84vzObvl/LVruD
-EtDN9qN0ybjAaIAnBRZHooJwbWzeFmeSz+/wsOycNu5byjy+nfwDZx9MI5hOp6AFt6AMq/dsmb37ZB3K
-1aD3nzwUMBN8K1GAx1GaavcyXK+5UJCMIYtCCZ8BfYlV423jzWoCaNDbSnl+ctKDZ+DzuUmVMllxqRgJ
-EJ5B7zz3jcHiGfROLOb/3MofTGRj2osXRvNnibpGOEw442sTKan1SLK9pFa9//3h579OpNKrCl3sBr/G
-pRDOoTcPhTB3vD8Py6ZLHVpzn8v8NrUWsUMRXnHG0HZX3OhPQBhZooAVkTBDZKaU4Ve9YdWq/vTpU70w
-2ivEa+775t6SEjtz0xfHKLX2U2lv18yTMSeTSYmpqCY9KNijV+6wP0quRWgEsiZC4gAnHlFkWNpDTxbd
-a7Ii8ucteyf4GoXaDfo/Ch6Y4E1/WDViPDFNmIeFwQyFtDdT5vZxY2VPsYSpQfqmH5uM/m1lD7MoRuGn
-yoaaMGGiC71nxPef9eqosMY2CWzl7HV14t1ojieeet5e7nNWLIdtUEks9U3BGDdieXvrhGSjgX91uszb
-p3qPLpYjt9bHicI8WFTmQaI0DxS1eYgozsNEdYq0DNXxh0nq4h2fnLKgVdP5cC8oFYEod02+V//y4JK7
-/t2Xk1EBz/YgMlVA74OHOTnZBxC52I5AHKJfLaJhjk5e0bLTOlBW6AAkQBvEzEp2YCms2vCZ45awKry2
-h3kSWct+ng+qpd9k42mZT3OhtPTzTBQt/TANU+yNaa3q/ueJGSyNuLWOwHUTkWsRoWsC6zCYtx+xawKt
-VXCvTbCvCbC9uKBr8K99MLBwBhyE10rmQ0W78uhf4VypaFUa8yuaR5WYJ7OqolV2jtXGDlvHEhupRDxl
-zBtWC1NvV7XqN4OjiH2EEasTEAWE7WDNKVMN56KiAY7A40B8Hzyc23tgGnpor6o0mkJUSHURxXsE2te/
-VMbvX1borxvBs/ySPECgTCrC5ij1xEyn6qiR3QkVUAWBNhFlQYYydbjDnYn6pb7laM9LHGX8vVHiuY1S
-H2yUelOjrF80yns4t+56uuACBho7ClM4vQAKz+H7C6DPnjVZIw6Wf03rDb29NW9F4gguvW0KM+enJDAz
-8JoV6Pn8pPuWx2fg898vAx39tEJPsDqK3yyq32GUvzrqb6OjMT0O3C+JPR0EqSY+sqVawRjOHJDSlix6
-9ckXwEMBvgE9Sp4fguRCARceChdoQSiVMdo2CGnTP2wxeoa7wiC+iFgTn4yjm1wovUBKroEQX3LQjDML
-IAPKEqvpAmxvp+bG8oODlUaSq9FrbS4WggcjULyyodxSNV8NbMA2DRA7mYE5kZgJ/jnNEo1U8V7IbZbN
-BJK7C2fUkoBhW+QSB/QI6EVhxnaoRT7vMdCKA5MtEYsd7SOgZoOZ7fCyrv0RkIqjn+3QircTnSFWYxnS
-y0fmZHb/KGP/5GYIX3+dPcm92W9wWwzhF54YkjoAN3s9buEyPkG64qGeey7G6OQEorNm4833Fe+DEoRJ
-qihno2Q1UivKltIFHBEYb7LNKmVOBs1iYeYekLl56oqe9tCc8FNuK4M7o8Z7jKpXoj3xuwwynbqHc+yG
-oSEZ7uGln2cfca4m2s2spmIYeytNkHclwDVCeL8Wzqd7uSU8M+/ciG6ziOt/it9nGW9gZNsv54VoNlzQ
-WyHaZGEvQLLR0t4OwUZLfBGKzRb5Vkg2WOwLMGyy3LdCr9GyX4Bgs4W/FYrpUeaTRiGCpUmH5H7HooLK
-NMR5cYTQSAsTEp0hfzGGJJHhL8iPz/dxIEsP4Ey4BH6AMziH04taJ1R7wi681FtZhtvIcdY/BkMYt/F7
-YiiXDXwCM17U0SGY4rxoJ2GIABUNUGZ8VQlzwoAIQTexA+oKzvipF7DFvu+Dj1F+TM4QlhzUSvBwuRpp
-P9YVYEDEnZZq4lojrAVuKA+zGLtCM9kxTSIxTTFl4BOFwtn7+wqabFyazNNKd6/kdmz7mVrrgxfTlo3O
-dEbczQHsW3jWeFfRWPVb4dUOrSfu8/x0eH/b2dZ0OlhMxV3ErvhAcXOYn99DX7REPHMVsvBWqeON0ub3
-QpNpkrwnhq+/BnsBtOjpsmOUQNuwUEbXhE0qKfSAMyC5g37XPT2BNRGKzkM/c4v1AojnGbOpJERYOq1z
-26ikZsKquMam6xJne0UzJpeAeui+KJm7vvHImjVxxmOTQY+Eio9dQVEWHdY635aZ4ZKw6Pq8LUJ74dyX
-8e3Bu/cUjiMgy8JsgdP7X1zKnA8lIn4GgwHjW+PMGKKHcAJnp6enjnh+dmxX+JjenjUwvh02XX33IDVe
-iPb6wxSihx0S1WumtNj8dgyOtYAIQXZvovBPCfk2OtTsaLLoHDYzVqsT2VIB3dDb5qqbqEaDvcWokc51
-6wA/0FTrbj59dgvgJguWnWaazKMtv6/fOb3moKovAalJqkSMcZ0RL0pEMQIugDB7lYyyZR2stKfNWkql
-sbwLyvCJ2wL1Wr4knluMcj/phjNHncOnBQlBYjSv+7fDI8ntrVy2FJxJthH6KCB6TGTkFx2F14FTPLoz
-ZXaFW+yL9EAjzeRTe7ZsYXh6Q058ibXtM8lscmlH6lb3IpubpCyJjDg8e0ZdAwlSw4kB3FDXAxMapzWx
-eqFl5xxgl3TyhkhlDHlk8KI/65QrA8E48YO8Q+/UNxWUEiG6nzEeN4Zk/YkIN2fZJUlm3N8xaUmdZ6Xm
-eB/e5IY1Mop7p5+4wkjEvP8c4EALHAFawRdDi5Vi1NUalswyY3Az2YBaL2SO7yU/Fz4TJnMV12kxmzcR
-F7QjyYWispfOpuH7NC9W4lIsuAhe+WZ7UqaDc84k93Hi8+WgF4HSOyGBa7BP6pIXuTEag+Gw8g1ozfva
-vs3/1h9BjPL5Pvzyl7cnJ/D06VNzTWqHCmiwtuShF189j97IjpJHz6sic1/yVntfCGb3LKO85ODRxQIF
-Mpt0ztyBLc2DYfNfGPNeJ0C54tt4i39tTxmzQow7Vz8AX/GtaWV2xkmfUXrwWfTO+8IFoeg8sVOU4jPK
-lki9N6t5dwjZ88i2yESxgy7RMb6glpkNKtP5Ciib+6GHMj3bbIXtGy67FKU5hGzJuJfmfLBDZKIDx5bo
-XEUHeR0ilJwNNkQphVaEzMi+XK9NvpRs0qr8j6R1w7BHqwyG2X9RUMRU5EzCIoWYXDxpEXMpyt1Yv4Tn
-+Ta4aZgvJbp+bqQ0oV5ZHNdcGIuLhB0knqzivMlawNeglaRqF5MgEQEupwSapcks6lKVLrOYsTWNbXSj
-eaaaQxIywrh44kqHEU19c0PGPqMv7uUZxa9js65RhoSRLS13HilPoZP0uYlfo7hJdZsppFCWGjZXFOEg
-BG0rUVxUd46qHLimbU3rGzj3WPHtB5VbULSfNoKPfFaTPSeLoelUlXgmwWzwkc9udOvbmuZZ5g1M5ZWO
-BGceUdjL9cU8yddmaCa4qE5CsyTCK77NIJWVRZ0U7HC62SQDoYqzeeI6Zex1/GahJAHwPdjqtWTrdSY/
-lTNTvZSpSf8qlnpHZWlSVqEsrfL6HmyNyiy04WtapaIJa+2AMW8TGJXszVPYKX/TAgwlabrzJSCacTcu
-yNCYuylWTXgbDTe40cxNQVTa2T36OuWtqdVQTORBqYhmjE3rNDRmrS0g0YCryVhGZ033yPeoVNoDCjtl
-LbJNMYl7FSSasTUu4tCYqa/YpglLo3EMQ1+xTRUb9+jphImcmdeH+uOolqstrSWjaFgRqMjJ1P3yN0JK
-Sgoc1HRtJonDeq2u/ly+fmpZRNy22o8Zl4SJLXccG9/hzrGlSLx1p+bSevFObW2BzwaNr7jnCjutSurY
-wTwHOmjrHNr4yGe/8Bd7Us1OtVEkzVEkqMqpl1OP6K+B/VE1DfPdoqp50XDO3e5wZ6b8T7hz75TEt3XP
-eIPn3t1ojelrwwTOHWOtsEbK6NM9Os+512DsVMUMgB+TP91B2HKLhm4aUJ8IeAZnDQJq2fqJWX0jvl+m
-XyafjVkYM6a1NOJTAah2818Z2koCA+X6XnMctnfCUqKPNUDioEOZStZ0j5XmvFK9aoD8mDFV1WpWAag8
-n2vdVYovKcOf9DJUYoNaEfukXOclWo0PaQehZfdgagdBySYBSedgZIkDVOrwlJsgUxn7PSqxa+JdHi6Y
-dpXsCw2pn/wydEM/im/1LR7R8ctVVIbZFUid+1rHgoBvUM/mjvigwfXT3xpzQvcy1uULseIa14+JE+lx
-75dgxjtk3mPixruoLvmXUQyf7B6XatirCQ/LjJ+o342puKO+349/NuSAQSI+539Y+q+ReJ3SH8FtyoIr
-2y2h3iSP0Mh1xwanuIdFQ9p7uSS+pUsleEi8Wk4eVMOrKWnnenAXDZFcr+2PwP7y+vo8Uwyv1CcrvKKb
-9Bu25VZUfx0lkPhOaEnw3DY8rK83kPS+vIlhy2V/BG/l8hyi26Yu3Igwii6ouoca8gTJY1Ek+zVUJNeA
-b25rkc/7weZC6CaIrlQcFBe92C9wStZrf/eSmgVLDuQmGMG/Dfr/w5aV6A/zZXPSiq/2rxn3dpdPnpvi
-tZdP/n8AAAD//6Z48fgfAwEA
+H4sIAAAAAAAC/+09bXvbOHLf/SsQtV3JG0l29m7bqx17n8TO3qaXXNx4b699XD9XSoQkxhSpA0Ar6tb/
+vTMA+CbxBaAoW0nXdxtJJDCYNwwGA2Dw8tnlh4uf//PqDZmJuX9+8BI/iO8E07MODTrnBwT+Xs6o46qv
+8uecCoeMZw7jVJx1IjEZ/KGTeS084dPzv34k18IREX95pB4cpCWeDQbk079HlK3IJGTk3mFeGHESCc/3
+xKpPnMAlAaUudcloRUZhKLhgzmL4iZPBINMSHzNvIQhn47PO0Sd+9OnvCHPw3fC74e+Hcy+ACp3zl0eq
+2DoCr2OwEocFo5wGgLAXBrJ9Lla+F0zzDUrKZ0IsBvTvkXd/1vmPwV9eDS7C+QIqjnzaIeMwEADnrPP2
+zRl1p7SzXjtw5vSsc+/R5SJkIlNh6blidubSe29MB/JHn3iBJzzHH/Cx49OzF1lggNwdYdQ/6yCmlM8o
+BWgzRifAizHnRwnbBr8b/m74L5If8LxTwb+iKlUs/FMQju/CSEgO0nsgg8yAd5t8W2/oTleEdn4/PDZr
+R8lKhGTu3FEyioQIAy5FJWbQICfLkN2R7wZLB1SGiiWlAYnbkcUS6gxwU1x4AVz4rha763BOSTghYcRI
+uAzIlAaUOT6ZUX9BGZlEwRi1qkZ3l2xwDKx4sdaUubwTAKmQXx6lPfflKHRXWdRd75547lkncO5BC32H
+c/l95DCiPgYunTiRD62wELQPX3pT2UEyOpSA0hBQnR0PGLBWZr2cbgLxKyyreLRwgrUKIwai7GStCxYq
+aOsIGltDM/9I/9xkCJeAO3UUrZWnjIUMarmOcAYjL4AX0CuoM56dkEyJGrZAN2egrfjvwAUrjPoDHAJD
+UMajRbZFQT+LE/KP+ASVaGHDl2LiRo4LiN/TMtIy79umLFMZREx9Iv+F/s0C6O8ltQprSjWrroN/15KQ
+yiJJp78LiTc5IVcsBLM/J2dnpNPJdfBKCFGMnhsKQd0ca0UY+sJbnJBfiRw4T0j37QRtHCfw/08RBy4S
+QecwfDgwgIJ6BhQMzD2MnFCAR7SvCs8p586UkqXn+2QaEkcaRigjOPUnwy556JzPvelMgLUkLjDo5VF0
+bkb8EVBvQmuWU88eh1U/zygDmh0YGWBMVy1GHAckyRSlq0PyVii+BKEkHzqni0MLiwISCgBBPoUjDsWC
+e8oFWj1QVAEjTxA5vg88nJBVGBHfuwNujyj2BjLzhFDtUPLff0LgnvhvPU4pbkP7QUj8UCp/xB1Arj2e
+F3Ts6j6B40FNh/gz+Con2gxvWBl8KUcqtL8vR6wa1NvLUkBvLy3AXJWDuTIHs10XfhdCH5TDwliUonMJ
+OjMUIX70DhPM6mWtFIaI1QKGXPUjGYpGIiDwX2w/F5HvDxh24VyvGPve+A5GAQb+zhDQnHhsfgn9W5m3
+zvlb0eXgSUhFVv1eNWPAMpOOv2Wnj2vQYBxG4Boz6pbyWJc1l3tJA8T5EuWobUyL4quwISWvTN2JjE7o
+cYn3Doc+DaZiRs7Ji0K0jHio3QEjJroen8MQ+V5j0Dm/VA/IK98vZmMp2+ooOi6maGuHCH2yuL1ijyx5
+azEYGLtW27hX0sUaz6gbAc3kLboqZi5AhtUX2GV7h6UqU/Z3A50HjDajOOmu7vA/YsniXn9rjq+Rpawe
+shsP2+ncaYO493xqZy0/GnDsnaMYBvrfwFBuKV2kIkayFEMJOMEJnEXoJC27uru1VYmpMrT2Nc5gK3Y+
+y57NyaOMUuio1gl5cXz8T6cJP5YURi78Z8Dn4HYvBnOHTQvtXhaUKnQCptWJRHhaZiVn329UOAX75qKF
+gu/g/8DAP1/4FHz6XIQBprLA6E3l8YKJj7IC5RaOn3afo9n39TPXDHVZyKjtebhS7Y9NjTYLpww0o5Mn
+FYwD6Mb8pBJOGawBRn6yPwZcMG+BXR+nlzT/Lh4qdGwofgevcnRK9HB+pvUgodmlvrO6GmNvf066/yTn
+R1a2Ig+Juop/5maj2FCsQ01thn5w8GTW/4nEtKCBSwPRkqg0tNaFpeFmxaUf7aHAthXKjPpuK/JAQC2L
+AkGmUsBfX1iPAUrCxpIBF9xtx6pJSC3LRsJMhYMdBNRw7+XTXBpR0I4sogD7a9vSUFBTeegHX1h/UVPX
+xjLyQ97O2IKAWpYQgkzF42eifnsooy3lMIpYO4YLAHmte2MKaCoL9fvRpLDbuNi3334r1yFWVBAPJyZz
+cFvWqMvqAAuXRDn6NfOmZAHTH3zmg+/LJkyTkM1zOhKN5h5wn9G/R5QLmFz/kYXRwnBq4gWLSAymNTU2
+lncz1QYwVwvj6ZIIp1NUaL3Uo58ma7Iwa8N4iFr+Oeu8wXguAageun7exINfIiSOz0PCKZVrM2oxFhfs
+HZiFwlRw7gQuJ9AoWLilJ2ZQyhEZCMPOefrDJKzxUhKjQwGoycnEF1ktkYdemuuX944fUWR5La8rOTcS
+Qcc8VrEejY6X+xXiSg2gz2Ubm/qrxcwDCkjybbCAidFg7LGxn1kPMgxTVDOzst8hL5us++PfZsgiY8p4
+yASuzcWKbxLXnTGr4EjhJoGCZvFZL95A0vP77BBMN6MiYgHxh54LCDH8+IG8ICdk8II8HNYEUWrjMVXB
+Z6tAjFkwpszyZ4y9UZDGNDZjEZ8xC8u0HZppdd5PZEjRkTvTChwDh3nOQJqeuRecdY5zT5zPZx1Qk0r3
+YTOK0ydxFHPhMDCaQz4Ll6DS0j5dqhhKnzhCMATTTdsLwmU3B9DEA1nvus1iQRUeSOMwkH0Aud4R/MJU
+oyhyVKMeukqlguTANlOSZlGoSjXZIgD1tKrySOqwEbOq1ISfoHSFEqTAmsi/QdSrQvQNAl77axsw+LVr
+TdiMkVWqwkcsXqELGXBNlKFJnK1CGxqG2PZKI3Yt/7WoXLX0VUysSv4xuEbSbxTZq5J/06De/toEvTdl
+x1qxEQesVAvcgVehEymwJkrRIJJYoRFbBBGfViceR+4bccdKub+Wcb8Kyafgmki+UeyyQvYNw5b7IPed
+zRepoGvyrpoMJqUbzgahfruzQQSYmw1Ssf+zwWg8hu+77srxrhrz7nyha1ToQB5oEy2IIbSnBjHEVA/i
+J0+iCGaLFwd1vEoCkS4Vjufz+kWTwjCa2kpaHv3KbXjjXAo9t/sUhI5HuyjuGe/qcEuX/O//5p7qufXa
+c5x9rT3iEZdl3W4/bgcnOblGpNOevl8wD7Be5YsoNy4tpKxkroyy7mvt44Cf1tI9MVct1h3DNbctNt8a
+RWQLNk/OpcWriqiWRYrDe8omfrgcfD6RseKOTd+bO75//tIrCxFfLN3XDs8sOZQWS5RxHPohmBmweatM
+qNjDr7IxM/rMTPO6GXqPW1C5nflph5N5bs4lHqU7ZRWazbnThEO7HBSTPdPkjq5gXOENRpAPywAcYTvJ
++ZbyccW5bAZoFLZV3U0xSlgoRde1kpu/A7FZMwSZccW8kHliZccP4MV56VkhBTBV7YyE30wm8XtwEpKv
+QJ3uJ0UGpOdM1anBkhYzQJNGD7ONawaaC8lCQLb8fiXwSKng1vzepDsG1dkPyt58XtAxnmD4+Op9C9TF
+4ADacD56++ZCHXbYJ0J/9ua0RUoRHB7siJg8/L8zejP98aPaekLdS4/f2bvtTaxv0iTBNhuZ4TLTk6Mm
+nTT88fWXa54vYLLQhq2QcHavT+/DwBMhuwzHdzCSPwOvu7t7jdKNEtVqqxqVoyfjrO2LOuXOdOmtWo/F
+9qTBVjl+kWYB2WduX6kFp4/U4WFAvvlmY5a7e/7j2i157YzvWnJlcyR19pPtPzqer3m+W/5m2twMTli1
+nXe26b3MwIV0RIw2kJwt98opetYGRVoYmJfqCWgqUuNURfZUh3/yOAwpKzQa+mvdrsd2zYZqc2ujkYQx
+NcBOEwXKb9FUAgzoUiaD6KE/jCed34WYle1aMLWC/Jx04X/PyRuZBw1+nciflzKaKmVuGhTJCH73amKt
+J28+e+gl71whsB0yDl3a0jiC8BDc7rpfEaewRTRpxw2siN/M9l0L90Mk7LkWe0DWlTbtOCLQyHbnV17i
+AwzpulXZaXlcVYJmh/iqJ/Of9UlX4dGF+eo3vjjFIt9MxalpYoJWh4QiNj1rg1FIWRAGFCl7fJLsepJ9
+b9q2H7xh7Gn7ASCwF/0A8NjvfrAto77uftAIuUaj7hV17uwjpRWTN+euYaR0u7EXG24UPNzK5EjuNYsf
+VrIQQTbl4T5rG8z4MG9PS8qmoeWSEO1Q2xo5tbgVoS2HFmHtM7F/dXxfWK9FlNIbg2u8FvFIZF9c/aVF
+qjW0xyQ6nxxz4vkUgwL6a13Svp3Y1Y+UhxEby53JiEQr5hVT3eOOfQVak3f9yx97+utXZWl/CrloSSN/
+0jua96zbIVrk7VWLRKqMso/T0WR7lxgpsEiOvHW/Ujy7bNFbUXR8TT3nymtrwL5Sp5r3Maj3LA7rgaHv
+JSsLHbwUg91j1u3spsZOfMol/zTZarn2XJ6AOPzNmdwn/6poHUkJquGSy678ta2m5oWLS22T+c67pzGp
+KgPq4xP7SMPrbw7Ebw7Ebw7EV+pAfCFTP8JDMnHY/+8ZYOHwnXhljyYbHPpIGInF4y83Wq6L4Br8B4mo
+WgSZhL6/3TLI3ilGexOAVKX0AVb10HqNpqEX32zVrpEq7dny2n7ODd95c0+olGS7F3+msT3WgQyWX6vU
+L+M0dLuXedLUHks8wfErlrc8Uzv26OOIPGltv6WeoPlVCd76bFFwb38S72DX4gGstpOK7bkT+9MPS/cx
+1nbmlFzM8ES621r0Yk41xH0NWb+mMwd3rbNHMFdpW3tsrFIkv9Yx6gNezalP0/HHOBKoQw/YpMdkXu59
+VgDJni9E9gZgm531nwA3ZNoq6rCJ97nBcf9rcO59x26q+7zsELgGlp76VNfLxmnHG+8rVzP17XaYy2Tn
+3IHBg8Z77Umv7DB7Zve8Osku71Rn6TmbiTpn80gBjuZhsjilr30qjjRfukq3nr3HzbKLWdwWV3sn6E8h
+ZiDFf82yrjdCJs4KVoPLRzoP76nM89w5Vz/skdpV/pCK0KlM2/mbRuxMI/AQ55ekEGlC59+UYodmYvEl
+6YTM9GyZP04mTvoZb57/FI5yV87jVfNRIDy8wpl4eCE3eCycusM43ZLR9axPqXhWsv5JpsluKmxjnHTu
+NgOkJLcRI/nlixikmqzv7bNFulYbxjrn+svT2yW8Ul1drP4lKERmw90WVqnL49kImTkcTBPFdFfhYkHd
+U7RNidHCDU642tuXnr8neLJyzsncWaFRi8CAkdFKT3IAOF+zcTyaf4Umbhu12YFpQyajZcPPL0GP7Tfk
+rQ+sDmirwzhxwY/vkxFevoWvxmHku6iXbkTlPWAEM5OFDNgI6sjhIY/GMwJa75CAimXI7vAqDD2TBe2f
+yBvDsAWA5oxFBK2uyMQLaD/pGozeUyYQvDbQ8oYxKrM0zh3hjWWd5Qy6FQJbsHDk0zkCnHifG3cGc+01
+um1c684l8K+DGXTwB8FfdvrTkkLE2w72faCz8oB+lGkg9sgDEkzO0QUm3dgXI9FCuuO65lq4sdGR97GR
+eeg6BXmS19OHyGIn5NeNJu897o0whbaC9x7L/aKe9TcKu57jh9MLzJjclRAHfN7dLIbZgKlMpY0Y4Kfv
+jKifa+MnWYY8kIfN+pgqFWsFzhwQ62ZqvYY3P4P59KGXdvsavHp/qTNGF8BT4dhiiD/Kd3UwcyAfChOp
+vORj5i2yNy4e4da+DvGA/SUkFN2Tl7sKADtE71BuqNVdptggvWKUrMIIhhL9ZekEoiItqMInDQjjoFCa
+aDzKXimV3FWpb6mk2WsuO6U30sRXSmownYM6Q0zrM7zIKzJnjpuJHJe0jwUusoFj6T3iEIuuIh074DOW
+Ij/JJU1S6P9w0Kzb5za7GZDYoJ36l+vadWalXY+uKsSBVhmVHgz6Vj9Yklzk0pTy4Q696HL5KS+phzML
+qjwvcOwcdSEffMW8T5LQ8RzIxgkMCJmOIwEe2SlxJrgohC2gg7Z0QGnVxET7dzjvGeMyunI9DktzXjcT
+sZ6pyYNRuRdqRtQW1YmHWnofiXQZssmI26SSSd+mnhhZzvHxCt5ET7VBwf3EuQUyfY8eSi2UDvRccYGD
+/QgEOuNgIhoQAjXkmNFsIMmPXDX3LyfeaKfeMsluLG+qqN5i35YrOJ974pWkK7enVbCIHsJHRmt6h8Ox
+s/CE43v/Q3/0GBfvqAAm6MxnwP1ux+Da3x0jPgGHzBLzF7V4W40tsQShQzypCO04sT0LjOZL8Q3TkhrX
+43MPX0t3FqadTjCmFRGUQg897sWbTjoXbhiJI8pYe446wLT10v1pn2h/Xbg2Dnvclom3HlfFm8XALMrK
+6rgD1ivxoDdZ5uO25qna9StxboFl/tSeYzZs6sq92ERtzu0aTWpocF8+o/Gnv2AkyZxprr7MqD2Wubtm
+WbKtddUe39wGfEs3HLfGOrp4LN4B2m2wjS4s+TZK9z22xTUAuWOupXsTW+AZoGvJM+VTtsUuCW3HDJN7
++UjhDsQWOCgpsOQhAGyNgzFyu+Pfm+DeY2GADCO/4KVy0EwbnIOXlXwznk0UtVI2kShKbyzdvLIZRfH0
+T1eJ89cXTs7NfawZW3+it156Ek38WkSPmqh9Mw4Xq1Py3fGLfx7AP38gf6QBTr9B4anDxjN16CyzOrKG
+koKfPl3X2gLWf3LuHfV0Da27cBgu0H/mQ3BQKfvLAvgEY9KZnAad5ok8OgItpkvQSerLbY/gxYLsVvG6
+T5Tf0jmJAhUrvpbvfoGq77EqTBAKuofDCKf+BFueeXwzjyW+HIrwjgZQZErFlcNAZYERr1d/hi+9jnzX
+OSyp6aANAUQVngACKR9hhhfsHa8Yc1a9srqqDjjTQLJVxZHjyhwyzLLBOeXcmVLLWnEIa71WaQV92WF8
+IyXBOyqqi+plqtpyH16VvF+CPmMOcaVnzKwU8gEzkNeQD0WVP3xGfvf98elBGZcwUPPaca+lZKBwoqc9
+zy1SzQJxaii9uGpPPS+rjX+MiogFRBUcvr3ESbLnFudrfSig8aGSnvdKY3LUzPm0kpxYyzaJGc+o+xbX
+iE0ISgoP3/MpUgXtbk+WF0x8XLoEiopRSK7HPFnT9uPDIZg88FN7v5JEJ07WdeThsF8GNr5fs2XA8oLO
+lmGqe03aBqovoGgZrLwNtGWY+trR1lUANOtqLHamWjuAjdq1A7BSwXYBNwp2ABVVbAdg9cXqbYMNffdv
+IhSOD4CPq1Txb3hNbgSOOJTbNKCn1Qb0pqvauFVugQblpta+zMZ7E9Jbg5TH5tZouMsBSEm+LRkiig/G
+oXco6wERRTiBDbiVIe2Nl7ExL3wtTXLhG2VYi19p81j4Uhq5wjfaVN0W+S8xuxWJ5+S4irPIi3nkC2/h
+e9J/eXF8TI4Ue8rTv4PvvqQwWDu+3A32r3+Qe8LuQ88lDhlFU+IFMBcMBRfMWSRXqFeBG+FUcDnzYMKi
+94JxwArh4Jqc3Hc0mGOKLChYBWeCIX3K5CpXJHBhjH72OHSrMe0Tei+3joXRdIb4B7jfrAqY4iBeGIxs
+qeSh5IUL/FtQNgYVucbfrHfTyzD32wptO+yTmqIZ3asrHGtiXblEL2sLplpaVzTW2bpyqQYf3vZBgw5P
+K/kLUwrcn5sy+KN8wHqK8X3yXQWAIrajCb7tabA3x7c21TMDbwrihQWIeHxNa39nUTsZRtPqv7OprkbL
+tPLvLSrHg2Ja+3uL2vHYl9b+57LaJba7fAjAuX651dIjSEmJB8Oxt3waGKd4OSM3tzUz6ndheCfnx7+W
+jbY8ZAJ9go8ZsBZTd28a4GYG1cBBgV3jVBDAAC3rko443n0pDoqGkKUXuOFy+Fc6upaFYEJ2RlBwuHG3
+enqbCXMMFxGf9Tr/GUaMjFi4hKfEDSmXe+F5tFgAuSRpgxdFXR4I9Tmtam8Zz+sTQL3OkvOTo6MODJ9+
+OJaZRIcz0F+MTsKzzknujcQCnh4pzP+25D/IINBZJx5+5c8SddU4DMMgXMigUq1HlK3FUfX+7frDn4Ft
+OHZ5kxVooj6ff0I644gxeYTu4bCsu9ShNYaem5/R1yK2KcKLMAioqg4DPurP3Akc3BqdHHhAA/Gsc1jl
+O3z77bc4/Ko95YsQRnvcyCbYSm79pgOgGZTc42q71ThpczgclpiKatLnBeGMymDEJzyJfUakQBbgmNAe
+HWLE97C0BnYWrDUEPnxYBlcMtICJVa/7IwvnMs7VPaxqMe6YMiIWRPMRxqnkJp6xyh1SWZNNAVts/qYb
+m4zubWUNOaTqSF1lQSSMyUBM57nj+887dVQoY5vEAHP2uvo+Id3Hk5lC3l6uc5ZND5ugkljqm4I2btj0
+9tYISauGfzXa3d31MPTApn2z0rsJWD1aAOsxAlqPEuB6pIDXYwTAHicgVqTJVOy+GQw0YEOPQE5ZvM+2
+z20FpTyGZ9NbtoNQFpez0PGtAJTH2qx0cysQsd5tiYdcCVsHoOcBhkAMQoQNQoaGnmjR2Ng4mljopSRA
+LQKLJdPEFFZtjNFw3loVg1zDPAk/Zp/nI4/pm0zQMX2YjTdmnuZCjenzTJQxfZiGZ9YQUbZ6/XliXEsj
+ko0jlO1ELBtEMG1gbQY71yOaNtAaBT+bBENtgK3FTU2Do82DpYXdYiOsWNJJKsqVRkcLOlBVmYqY6Gbn
+qiiSiYRWEZd0vIpS2W5YG1ZtHGa10pq4V8kT2womzsWxd9jBAW2TR45ijSOOIE6wIovQC4Rld8UEDH3i
+hnjYgrh0rPYDIvRIbVmy6mW4/f9UB7MYVWfdZY4GeYII1G1hBU/xi+MmLi/gAvfyc+y7aW/uW5km6Png
+v87RipRFUMrU4Y6uZEgzdWr7a+5pP3U0+xmXsZ84f/3UjeunDlk/61r1807SrbnK4raxHiLqAZbHp/Dx
+kvwrfDx/bjOibHgQSPaNd3srjw/FkWrv1hZmztVJYGbg2d2v/HDQfsndM/Dl18tAQ1ev0JmsXq2wW71o
+cTWjenVDRYFjegy4XxJj2wjGxffhDMgLA6TQqOnjzmAWcVXBl6D7yblbgisoJGQuZSbQ5hH4Vmi/VbBV
+5T0BR0edP8eTknpvak0cNo7ihnjfXR/v1wEgjg+fyDg5FgZg0xMDagJsbbJnxvKNBSQrydXoNZqLCQvn
+fSCosiBfemI866nAdBoINzIDYwekmwY5jXoJIlU8nTLrZSMYvu5OjVFLAqNNkUvc1R2gp8KpzTBTDvIO
+kNLx12ZYaZ98F2jFEduGiMUTgR2gpqK8zfBSU48dIBWHhZuhFU93WkOsxlylO8/ksvj6OtL6stkhZoPO
+lL9ZL3BbDOHnMLFudQBu1mrckvN4+e4CzzybWUgYG/RCv5xtdEXYJYI5AfcwdNZPhkh4G0y5CThMUaHj
+BHLolMuycgSTfY84Y3kkG6aH4DYa4SfMhitzRg3WGFWvRGviN2nk7Mw8IqVmMZZkmEfIPow+0bEYou9b
+TcVh7ELZIG9KgGnkc7sSxkurOb8i0+/MiG7iWeAfeG9b+BYWRra5j1GIpqWX0QhRC2+jAEcbf6MRejZ+
+RwF+Vp5HMwStPJAiFO18kEZIWvgiBRjaeCON0LPySgoQtPNLGqGYLkEbt6H33zyz2n9TQWUaIT7dQTip
+gYXTa/9PxpAksP6E/HjYxr8tXfeUISbyA3lBTsjxaa2PjI66CS9x+h/Qpfbr8aN3SAZN3LIYyrmFyyLb
+0xUNAlDGPkUSuplTXBzgGVeag64G4BwzfUcv+Mem4KQbfQo+dNf3CeiZctXDgJIpbp9kuKImk0CbApw7
+7A6lmnj+mAaXYj6ILMam0GQqXZl1ECn2AoJH55mxc/qM2MyrbPpppTdasnO6eU+tnSIU05aNaLVG3M0G
+7Fvy3HrSY636jfBqhtaBeT8/PtzedjY1nQYWU4QmYhchFJTbJfJT/NOGiGe2yRbuODbcbWy/ZzjpJsmx
+fIx0qM3BRRkADIMYaMNwl7ncQi4zslEXMxQ6ua0UpiEHqOUw4Y0jP7PD+ZQ4rivNpsA0iBJLo3EOUwfI
+ZP0xq/6qH5gOcaqW7jG5uycOzQcluQ88bhlZE6dHl+k2MTv6wBSUF+i1buNNSiM6dQJ9tOISyDDd3yPd
+hHC5kT4ihWMISLHwHQy+KfO33S+WWVNLRPyc9HqAsHRmJNGH5Aj3GRwb4vlgWK4wJ4Van4HmD21H3zVI
+1gPRWn3grD70w6l4GwgUm9+MwbEWOLhu9U5Hp0rIV8Eru+XcorXrTFuNVrFLBXTj3dqrbqIaFnOLvpXO
+tesAP1JXa68/PZjFl5MBS3UzJHNnw69KFvknujI68IMqFar0kuEEBi+8ICMZEPFii9D3Q+iQ0wOzkSZt
+/TYZlpF2eGI6t9PonMVnwHT6y4wHcy2fQQuZB28YMx0Tn8VuRxyzMlUljRm4w53/Cl4GIfFDeS2HHg7P
+/yvotKYyKd2q1Z3py9srI0XxRJcT6slcdo5Um5Hj6vw/fZhn4s4CubmzTlfkWfu4pkoW7XE5UuOZTkM1
+e8tfO66Z4NZzHRn3QOPVgII8TDGal4DjjuT2nk8bCk7mOIp8TKOoDiZK+entJnXgwIlVWxRlFGFJuyxd
+n0sTqNXu31AwcJ+nzG5cWz6TQyyX7anOGywao5NMUXrQJ8+fe6bGiSOcGACMyYbrf16cTUrpBcrO2OpA
+5XcOF3Lg1wOk/lmnXBkIctLXy08AjeqmgsIUeuZL5ruNOSozrnEzll2S28v8TCRK6iQrNcNjKzIlt5RR
+XDt9YgojEfP6qZ0NLTAEqARfDC1Win5bA1jSy6TBzSRhazyQGZ69fihMOeCMRXwJmPRtWHz3vJNs2ivL
+miALfkzTESYuKBiX+RtfTmfLdHAcBjz06dAPp72OBoUzZ2iTqOO5yen+GA3w7ivPk9ec1e+qtJvdPolR
+PlmHX36KHxiFh+NxK+KKAsNwrQbJAwOgD4Po8/b9JIHCrMjcl+R9WBeCjLZwfR0EDB+TCcU0AzLXp9xy
+Xpq5R2Xskea9ToB8Fi7jkNClWjTPCjGuXJ1MAmDIUjKSktTpp+v4RTkjTk0Q0svjraIUL7k3RApveG0R
+H7m83hCVj9KxaA8XtZTeFBkd9moTHemWInvUegie1vKCsR+50AGSVfVG2L7DA1vtoSrXzxsy7rVc2m4R
+Gb1W3hCdC70G3SJCybK2JUoptCJk+iohR21OuyS+UOUKJaUtI3aNcthm/3Q8b+zDwJRE9AoxObVGpCR7
+b703kedb78YyDZQ+bSKlNPTcsiUIuRUzvgxzI/VwFedlMpZwQVBJqiZUCRIacDkl61TXJEouqlKVMLmY
+sTWFVWDOPgHXJgkZYZwemNIhRVNfXJKxzujTrZy0+Dx91kvLkNBXV6ieaOUp9NcebFwsmLZjsvPMVTpl
+ycFz1+JsrJ6ou4hOqyvre25ME3enN9wY14BOcS1yAwq6jH0MX9YkBctiKCtV5dNKg3AA+AZL39YUzzKv
+J+/e2l5wKgYr32RDtdo5KIIF2OKmD9AotQGbblyh1JcXLevb2BRQB69uoweFmz5Uji1Uao77NTAIxEty
+2qtgb1VWdSySCDwRYJ3oUsBQcgjfTo3FLFcUNJldM3l3uy0I2sYgIHlgDYCuk5jAsm5vgtKQRyO8ImGU
+SYSu7+yo8iaexRd7JGcIDIL3piRGQZbInFAfDusGrWq9KokEHJ42sZHyeKI6tlbM6/xFWHY2Ul9KZXdj
+A5i7DFI2vUY1h8WGGQhVuponri0bJhl7GZ8GLLltYQu2ug3ZepnJcGnMVDdlalK/iqXuTlma3GFVdofF
+Ygu26jutmvA1vRLMhrWqwZi3CYxK9uYpbJW/6W1XJXei5O/bsuNufPuVNXdTrGx4q5vr3SBzUxCVLs0a
+fa3yVl6MVUzkxr1cdoxNL8WyZq26rcuCq0lbUmdldT3kVSrtBoWtspYG98Ukrl3XZcfW+MYsa6a+Ce5t
+WKrbkQyFqlVsXKOnFSbiwmaoHquLgvWdq1zHwItA6fmc8syz+wZL7m9KLyBuJIlMfcvJlqp5qdEtWwdT
+pdZXikoWhxR3DAvfoe9nVJIlE2Oj4lxNmI3KqjvjLQrjtfeGxdOL7g0ryDOtG2WNo4jQSX4OX61JNdvV
++lqafS2oyq6XUw/9q6c+qrphvpq+olg3Z1wNVKOnpz7mlZJVLawZx1LMq0utkXVVRM64YqwVykhJfdqi
+8hh+mFdPVUwC+DH5aQ5C3W0t6YZpAe5Vfk5eWMSus5dVZ/XN8f0y/ZJ55eTAmDGtpTPRCkC1c87KKHIy
+Hy3X95pF8LV11RJ9rAESx/fKVLKmeqw0J5XqVQPkx4ypqlazCkDlGeHrNlA9pQzTEMSmDWpE7EG5znOq
+ND7yWljFMV+3aCH+bxP7N477lzhApQ5PuQkKJh6bf6SYuN/Cu9wcMNUo2WUIqZt8OTRDP44xKjz0SucF
+mEcncLkpkDr3tY4FuPkQe3NLfEBw3fSbNSewlrQuT8SKS7rYJ06kmzyeghlX0PY+ceNKLzs8jWL4zmq/
+VENtSHoKZqxv83lqXkh8HpcRP4UtsWAGgLrxpyX5EonHVgCfOrnreLaRvoTVzXy1lr/C5gkU4E94118b
+XLgDQN3405J+iUS8uexx6b+O+KKt8YErWN3MV0tGxNg8DS8+Uh7N2+oTCKqbfrPuERIVzY9HHx8uAY1W
+e4WGa8uGC1UtoV5miEPk2mODUVxYocHVaSUnPrvk4Vlux63l5MbV7DX3q5uup+smkkNHwGj15e3lSeZm
+9tI5a+HBpaTeYVNuuR6fe5xT3FqvDwGULC6qgpuXvfe4ty1vYth8ClyBf0+IPoNjwg2NkT62Yx6KzRPE
+d0UR79ZQkRyOurmtRT4fJ5DHZO7nenfntbx/7xePLqEzUX99TeEuHDqLhb967UmHnvegZp/8Y6/7D+ri
+vu5h/lrTl0e4I2Uhzg/Ur1Hors4PXh7NxNw/P/g/c4nZ6cAsAQA=
 `,
 	},
 
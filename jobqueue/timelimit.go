@@ -0,0 +1,255 @@
+// Copyright © 2026 Genome Research Limited
+// Author: Sendu Bala <sb10@sanger.ac.uk>.
+//
+//  This file is part of wr.
+//
+//  wr is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Lesser General Public License as published by
+//  the Free Software Foundation, either version 3 of the License, or
+//  (at your option) any later version.
+//
+//  wr is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Lesser General Public License for more details.
+//
+//  You should have received a copy of the GNU Lesser General Public License
+//  along with wr. If not, see <http://www.gnu.org/licenses/>.
+
+package jobqueue
+
+// This file contains the implementation of hard limits on how long a Job's Cmd
+// may run for.
+
+import (
+	"fmt"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// these global variables are primarily exported for testing purposes; you
+// probably shouldn't change them
+var (
+	// TimeLimitGrace is the grace period used by TimeLimits that don't specify
+	// their own.
+	TimeLimitGrace = 1 * time.Minute
+
+	// TimeLimitSchedulerLeeway is how much longer than a Job's TimeLimit we
+	// ask job schedulers to allow it to run for, so that the limit is always
+	// enforced by us, the same way, regardless of scheduler.
+	TimeLimitSchedulerLeeway = 5 * time.Minute
+)
+
+// TimeLimitDefaultSignal is the signal sent to a Cmd when the end of its
+// TimeLimit is near, for TimeLimits that don't specify their own Signal.
+const TimeLimitDefaultSignal = "SIGTERM"
+
+// timeLimitSignals are the signals that can be used as TimeLimit.Signal.
+var timeLimitSignals = map[string]syscall.Signal{
+	"SIGTERM": syscall.SIGTERM,
+	"SIGINT":  syscall.SIGINT,
+	"SIGHUP":  syscall.SIGHUP,
+	"SIGQUIT": syscall.SIGQUIT,
+	"SIGUSR1": syscall.SIGUSR1,
+	"SIGUSR2": syscall.SIGUSR2,
+	"SIGALRM": syscall.SIGALRM,
+	"SIGXCPU": syscall.SIGXCPU,
+}
+
+// TimeLimit describes a hard limit on how long a Job's Cmd may run for. Unlike
+// Requirements.Time, which is only used to help schedule the Job, a Cmd that
+// runs for longer than Limit is killed.
+//
+// Grace before Limit is reached, the Cmd (and its child processes) are sent
+// Signal, giving it a chance to checkpoint or clean up. If it is still running
+// once Limit is reached, it is sent SIGKILL. Either way, unless it exits 0
+// before Limit is reached, it fails with FailReasonTime, and is retried or
+// buried as normal (so use a RetryPolicy with a "time" reason of 0 if retrying
+// would be pointless).
+type TimeLimit struct {
+	// Limit is the maximum time the Cmd may run for.
+	Limit time.Duration
+
+	// Grace is how long before Limit that Signal is sent. 0 means use
+	// TimeLimitGrace. If longer than Limit, Signal is sent as soon as the Cmd
+	// starts.
+	Grace time.Duration
+
+	// Signal is the name of the signal to send, one of SIGTERM, SIGINT,
+	// SIGHUP, SIGQUIT, SIGUSR1, SIGUSR2, SIGALRM or SIGXCPU. The empty string
+	// means use TimeLimitDefaultSignal.
+	Signal string
+}
+
+// NewTimeLimit creates a TimeLimit, checking that the given values are valid.
+// The signal name is case insensitive and the "SIG" prefix is optional.
+func NewTimeLimit(limit, grace time.Duration, signal string) (*TimeLimit, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("time limit must be greater than 0")
+	}
+	if grace < 0 {
+		return nil, fmt.Errorf("time limit grace can't be negative")
+	}
+
+	if signal != "" {
+		signal = strings.ToUpper(signal)
+		if !strings.HasPrefix(signal, "SIG") {
+			signal = "SIG" + signal
+		}
+		if _, exists := timeLimitSignals[signal]; !exists {
+			return nil, fmt.Errorf("time limit signal '%s' is not one of SIGTERM, SIGINT, SIGHUP, SIGQUIT, SIGUSR1, SIGUSR2, SIGALRM or SIGXCPU", signal)
+		}
+	}
+
+	return &TimeLimit{Limit: limit, Grace: grace, Signal: signal}, nil
+}
+
+// signalAfter returns how long after the Cmd starts that our signal should be
+// sent.
+func (tl *TimeLimit) signalAfter() time.Duration {
+	grace := tl.Grace
+	if grace == 0 {
+		grace = TimeLimitGrace
+	}
+	if grace > tl.Limit {
+		return 0
+	}
+	return tl.Limit - grace
+}
+
+// signal returns the signal that should be sent when the limit is near.
+func (tl *TimeLimit) signal() syscall.Signal {
+	if sig, exists := timeLimitSignals[tl.Signal]; exists {
+		return sig
+	}
+	return syscall.SIGTERM
+}
+
+// String returns a short human readable description of the limit, suitable for
+// display in status output.
+func (tl *TimeLimit) String() string {
+	if tl == nil {
+		return ""
+	}
+	signal := tl.Signal
+	if signal == "" {
+		signal = TimeLimitDefaultSignal
+	}
+	return fmt.Sprintf("%s (%s sent %s before)", tl.Limit, signal, tl.Limit-tl.signalAfter())
+}
+
+// ParseTimeLimit is like NewTimeLimit(), but takes durations with unit
+// suffixes (eg. "2h"), as might be supplied by users. An empty limit results
+// in a nil TimeLimit.
+func ParseTimeLimit(limit, grace, signal string) (*TimeLimit, error) {
+	if limit == "" {
+		return nil, nil
+	}
+	l, err := time.ParseDuration(limit)
+	if err != nil {
+		return nil, fmt.Errorf("time limit (%s) was not specified correctly: %s", limit, err)
+	}
+	var g time.Duration
+	if grace != "" {
+		g, err = time.ParseDuration(grace)
+		if err != nil {
+			return nil, fmt.Errorf("time limit grace (%s) was not specified correctly: %s", grace, err)
+		}
+	}
+	return NewTimeLimit(l, g, signal)
+}
+
+// timeLimitTimer fires when a Cmd with a TimeLimit should be signalled, and then
+// again when it should be killed. Its countdown can be paused while the Cmd is
+// suspended. A nil *timeLimitTimer (for a Job without a TimeLimit) never fires.
+type timeLimitTimer struct {
+	tl        *TimeLimit
+	timer     *time.Timer
+	elapsed   time.Duration // run time prior to since
+	since     time.Time     // zero while paused
+	signalled bool
+}
+
+// newTimeLimitTimer starts a timeLimitTimer for the given TimeLimit, returning
+// nil if that is nil.
+func newTimeLimitTimer(tl *TimeLimit) *timeLimitTimer {
+	if tl == nil {
+		return nil
+	}
+	return &timeLimitTimer{tl: tl, timer: time.NewTimer(tl.signalAfter()), since: time.Now()}
+}
+
+// C returns the channel that is sent to when the timer fires.
+func (t *timeLimitTimer) C() <-chan time.Time {
+	if t == nil {
+		return nil
+	}
+	return t.timer.C
+}
+
+// fired should be called after receiving from C(). It returns true if the Cmd
+// should now be killed, or false if it should be signalled (in which case the
+// timer is set to fire again when it should be killed).
+func (t *timeLimitTimer) fired() bool {
+	if t.signalled {
+		return true
+	}
+	t.signalled = true
+	t.timer.Reset(t.remaining())
+	return false
+}
+
+// used returns how long the Cmd has been running for, not counting time spent
+// paused.
+func (t *timeLimitTimer) used() time.Duration {
+	if t.since.IsZero() {
+		return t.elapsed
+	}
+	return t.elapsed + time.Since(t.since)
+}
+
+// remaining returns how long until the timer should next fire.
+func (t *timeLimitTimer) remaining() time.Duration {
+	target := t.tl.Limit
+	if !t.signalled {
+		target = t.tl.signalAfter()
+	}
+	remaining := target - t.used()
+	if remaining < 0 {
+		remaining = 0
+	}
+	return remaining
+}
+
+// pause stops the countdown.
+func (t *timeLimitTimer) pause() {
+	if t == nil || t.since.IsZero() {
+		return
+	}
+	t.elapsed = t.used()
+	t.since = time.Time{}
+	t.stop()
+}
+
+// resume continues the countdown after a pause().
+func (t *timeLimitTimer) resume() {
+	if t == nil || !t.since.IsZero() {
+		return
+	}
+	t.since = time.Now()
+	t.timer.Reset(t.remaining())
+}
+
+// stop stops the timer, so that it won't fire.
+func (t *timeLimitTimer) stop() {
+	if t == nil {
+		return
+	}
+	if !t.timer.Stop() {
+		select {
+		case <-t.timer.C:
+		default:
+		}
+	}
+}
@@ -0,0 +1,221 @@
+// Copyright © 2026 Genome Research Limited
+// Author: Sendu Bala <sb10@sanger.ac.uk>.
+//
+//  This file is part of wr.
+//
+//  wr is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Lesser General Public License as published by
+//  the Free Software Foundation, either version 3 of the License, or
+//  (at your option) any later version.
+//
+//  wr is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Lesser General Public License for more details.
+//
+//  You should have received a copy of the GNU Lesser General Public License
+//  along with wr. If not, see <http://www.gnu.org/licenses/>.
+
+package jobqueue
+
+// This file contains the code for giving the people that share a manager their
+// own identities, so that they can only alter the jobs they added themselves.
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// adminOnlyMethods are the client request methods that affect the manager as a
+// whole, and so can only be used by admins.
+var adminOnlyMethods = map[string]bool{
+	"backup":   true,
+	"pause":    true,
+	"resume":   true,
+	"drain":    true,
+	"shutdown": true,
+	"uadd":     true,
+	"udel":     true,
+	"ulist":    true,
+	"quotaset": true,
+}
+
+// adminOnlyWebRequests are the status web page requests that affect the
+// manager as a whole, and so can only be made by admins.
+var adminOnlyWebRequests = map[string]bool{
+	"confirmBadServer": true,
+	"dismissMsg":       true,
+	"dismissMsgs":      true,
+}
+
+// runnerMethods are the client request methods used to reserve and run Jobs.
+// Reserving a Job gives control over it regardless of who owns it, so these
+// can also only be used by admins (which includes runners, since they use the
+// manager's own token).
+var runnerMethods = map[string]bool{
+	"reserve":  true,
+	"jstart":   true,
+	"jtouch":   true,
+	"jcopy":    true,
+	"jlog":     true,
+	"jout":     true,
+	"jcached":  true,
+	"jarchive": true,
+	"jrelease": true,
+	"jbury":    true,
+}
+
+// validUserName is what the name of a User must match.
+var validUserName = regexp.MustCompile(`^[A-Za-z0-9_.@-]+$`)
+
+// User describes someone who can use the manager. The person who started the
+// manager, and anyone using the manager's own token (such as runners), is an
+// admin User named after the owner of the manager process. Other Users are
+// created with Client.AddUser(), which issues them their own token.
+type User struct {
+	Name string
+
+	// Admin users can alter any Job, can reserve Jobs to run them, and can
+	// control the manager itself. Other users can only alter the Jobs they
+	// added.
+	Admin bool
+}
+
+// owns tells you if this user is allowed to alter the given Job: admins can
+// alter any Job, others only those they added. Jobs without an Owner (added
+// before the manager knew about users) can only be altered by admins.
+func (u *User) owns(job *Job) bool {
+	if u == nil {
+		return false
+	}
+	if u.Admin {
+		return true
+	}
+	job.RLock()
+	defer job.RUnlock()
+	return job.Owner != "" && job.Owner == u.Name
+}
+
+// ownedJobs returns the subset of the given jobs that this user owns().
+func (u *User) ownedJobs(jobs []*Job) []*Job {
+	if u != nil && u.Admin {
+		return jobs
+	}
+	var owned []*Job
+	for _, job := range jobs {
+		if u.owns(job) {
+			owned = append(owned, job)
+		}
+	}
+	return owned
+}
+
+// tokenHash returns the key we store a User under, so that their token itself
+// is never stored.
+func tokenHash(token []byte) string {
+	sum := sha256.Sum256(token)
+	return hex.EncodeToString(sum[:])
+}
+
+// authenticate returns the User that the given token was issued to, or nil if
+// it is not a valid token.
+func (s *Server) authenticate(token []byte) *User {
+	if len(token) != tokenLength {
+		return nil
+	}
+	if tokenMatches(token, s.token) {
+		return s.owner
+	}
+	s.umutex.RLock()
+	defer s.umutex.RUnlock()
+	return s.users[tokenHash(token)]
+}
+
+// addUser creates a new User with the given name, stores it in the database
+// and returns the token they should use to connect to us.
+func (s *Server) addUser(name string, admin bool) ([]byte, error) {
+	if !validUserName.MatchString(name) {
+		return nil, fmt.Errorf("user names can only contain letters, numbers, dots, dashes, underscores and @")
+	}
+
+	s.umutex.Lock()
+	defer s.umutex.Unlock()
+	if name == s.owner.Name {
+		return nil, fmt.Errorf("user %s already exists", name)
+	}
+	for _, user := range s.users {
+		if user.Name == name {
+			return nil, fmt.Errorf("user %s already exists", name)
+		}
+	}
+
+	token, err := generateToken("")
+	if err != nil {
+		return nil, err
+	}
+	hash := tokenHash(token)
+	user := &User{Name: name, Admin: admin}
+	err = s.db.storeUser(hash, user)
+	if err != nil {
+		return nil, err
+	}
+	s.users[hash] = user
+	return token, nil
+}
+
+// removeUser deletes the User with the given name, so that their token no
+// longer works. Their jobs are unaffected. Returns false if there was no such
+// user.
+func (s *Server) removeUser(name string) (bool, error) {
+	s.umutex.Lock()
+	defer s.umutex.Unlock()
+	for hash, user := range s.users {
+		if user.Name != name {
+			continue
+		}
+		err := s.db.deleteUser(hash)
+		if err != nil {
+			return false, err
+		}
+		delete(s.users, hash)
+		return true, nil
+	}
+	return false, nil
+}
+
+// getUsers returns the owner of the manager followed by all the Users created
+// with addUser(), sorted by name.
+func (s *Server) getUsers() []*User {
+	s.umutex.RLock()
+	defer s.umutex.RUnlock()
+	users := make([]*User, 0, len(s.users))
+	for _, user := range s.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Name < users[j].Name
+	})
+	return append([]*User{s.owner}, users...)
+}
+
+// ownedKeys returns the subset of the given job keys that correspond to jobs in
+// the queue that the given user owns().
+func (s *Server) ownedKeys(user *User, keys []string) []string {
+	if user != nil && user.Admin {
+		return keys
+	}
+	var owned []string
+	for _, key := range keys {
+		item, err := s.q.Get(key)
+		if err != nil || item == nil {
+			continue
+		}
+		if user.owns(item.Data.(*Job)) {
+			owned = append(owned, key)
+		}
+	}
+	return owned
+}
@@ -35,6 +35,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/VertebrateResequencing/wr/internal"
 	"github.com/VertebrateResequencing/wr/jobqueue/scheduler"
@@ -55,6 +56,11 @@ const tokenLength = 43
 
 var pss = []byte("Pss:")
 
+// procIORead and procIOWrite are the keys in /proc/<pid>/io that currentIO()
+// reads
+var procIORead = []byte("read_bytes:")
+var procIOWrite = []byte("write_bytes:")
+
 // cr, lf and ellipses get used by stdFilter()
 var cr = []byte("\r")
 var lf = []byte("\n")
@@ -221,6 +227,110 @@ func currentMemory(pid int) (int, error) {
 	return mem, nil
 }
 
+// currentIO returns the number of bytes that the given process and all its
+// children have caused to be read from and written to storage, according to
+// /proc/<pid>/io. The counts for each process include those of any children it
+// has already waited for.
+func currentIO(pid int) (int64, int64, error) {
+	b, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/io", pid))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var read, written int64
+	r := bufio.NewScanner(bytes.NewReader(b))
+	for r.Scan() {
+		fields := bytes.Fields(r.Bytes())
+		if len(fields) != 2 {
+			continue
+		}
+		var count *int64
+		switch {
+		case bytes.Equal(fields[0], procIORead):
+			count = &read
+		case bytes.Equal(fields[0], procIOWrite):
+			count = &written
+		default:
+			continue
+		}
+		*count, err = strconv.ParseInt(string(fields[1]), 10, 64)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	// recurse for children; we read them after their parent so that a child
+	// exiting in between can only make us under count, not double count
+	p, err := process.NewProcess(int32(pid))
+	if err != nil {
+		return read, written, err
+	}
+	children, err := p.Children()
+	if err != nil && err.Error() != "process does not have children" {
+		return read, written, err
+	}
+	for _, child := range children {
+		childRead, childWritten, errr := currentIO(int(child.Pid))
+		if errr != nil {
+			continue
+		}
+		read += childRead
+		written += childWritten
+	}
+
+	return read, written, nil
+}
+
+// currentCPU returns the CPU time used so far by the given process and all its
+// children, according to /proc/<pid>/stat. The time for each process includes
+// that of any children it has already waited for.
+func currentCPU(pid int) (time.Duration, error) {
+	b, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return 0, err
+	}
+
+	// the command name (which may contain spaces) is in brackets, and after
+	// it come the state and then other fields, of which we want utime, stime,
+	// cutime and cstime
+	end := bytes.LastIndexByte(b, ')')
+	if end == -1 {
+		return 0, fmt.Errorf("could not parse /proc/%d/stat", pid)
+	}
+	fields := bytes.Fields(b[end+1:])
+	if len(fields) < 15 {
+		return 0, fmt.Errorf("could not parse /proc/%d/stat", pid)
+	}
+	var ticks int64
+	for _, field := range fields[11:15] {
+		t, errp := strconv.ParseInt(string(field), 10, 64)
+		if errp != nil {
+			return 0, errp
+		}
+		ticks += t
+	}
+	cpu := time.Duration(float64(ticks) / process.ClockTicks * float64(time.Second))
+
+	// recurse for children
+	p, err := process.NewProcess(int32(pid))
+	if err != nil {
+		return cpu, err
+	}
+	children, err := p.Children()
+	if err != nil && err.Error() != "process does not have children" {
+		return cpu, err
+	}
+	for _, child := range children {
+		childCPU, errr := currentCPU(int(child.Pid))
+		if errr != nil {
+			continue
+		}
+		cpu += childCPU
+	}
+
+	return cpu, nil
+}
+
 // get the current disk usage within a directory, in MBs. Optionally, provide a
 // map of absolute paths to dirs (within path) that should not be checked.
 func currentDisk(path string, ignore ...map[string]bool) (int64, error) {
@@ -506,6 +616,23 @@ func mkHashedDir(baseDir, tohash string) (cwd, tmpDir string, err error) {
 	return cwd, tmpDir, os.Mkdir(tmpDir, os.ModePerm)
 }
 
+// absPaths returns the given paths as absolute paths, treating relative ones as
+// being relative to the given base directory. ~/ prefixes are expanded.
+func absPaths(paths []string, base string) []string {
+	if len(paths) == 0 {
+		return nil
+	}
+	abs := make([]string, len(paths))
+	for i, path := range paths {
+		path = internal.TildaToHome(path)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(base, path)
+		}
+		abs[i] = filepath.Clean(path)
+	}
+	return abs
+}
+
 // rmEmptyDirs deletes leafDir and it's parent directories if they are empty,
 // stopping if it reaches baseDir (leaving that undeleted). It's ok if leafDir
 // doesn't exist.
@@ -533,7 +660,7 @@ func rmEmptyDirs(leafDir, baseDir string) error {
 }
 
 // removeAllExcept deletes the contents of a given directory (absolute path),
-// except for the given folders (relative paths).
+// except for the given folders or files (relative paths).
 func removeAllExcept(path string, exceptions []string) error {
 	keepDirs := make(map[string]bool)
 	checkDirs := make(map[string]bool)
@@ -563,6 +690,10 @@ func removeWithExceptions(path string, keepDirs map[string]bool, checkDirs map[s
 	}
 	for _, entry := range entries {
 		abs := filepath.Join(path, entry.Name())
+		if keepDirs[abs] {
+			continue
+		}
+
 		if !entry.IsDir() {
 			err := os.Remove(abs)
 			if err != nil {
@@ -571,10 +702,6 @@ func removeWithExceptions(path string, keepDirs map[string]bool, checkDirs map[s
 			continue
 		}
 
-		if keepDirs[abs] {
-			continue
-		}
-
 		if checkDirs[abs] {
 			err := removeWithExceptions(abs, keepDirs, checkDirs)
 			if err != nil {
@@ -604,16 +731,30 @@ func compressFile(path string) ([]byte, error) {
 // reqForScheduler takes a job's Requirements and returns a possibly modified
 // version if using less than 924MB memory to have +100MB memory to allow some
 // leeway in case the job scheduler calculates used memory differently, and for
-// other memory usage vagaries.
-func reqForScheduler(req *scheduler.Requirements) *scheduler.Requirements {
-	if req.RAM < 924 {
+// other memory usage vagaries. If the job has a TimeLimit, the Time is also
+// increased if necessary so that the job scheduler won't kill the job before
+// we enforce the TimeLimit ourselves.
+func reqForScheduler(req *scheduler.Requirements, tl *TimeLimit) *scheduler.Requirements {
+	var minTime time.Duration
+	if tl != nil {
+		minTime = tl.Limit + TimeLimitSchedulerLeeway
+	}
+	if req.RAM < 924 || req.Time < minTime {
 		// our req will be like the jobs but with memory + 100 to
 		// allow some leeway in case the job scheduler calculates
 		// used memory differently, and for other memory usage
 		// vagaries
+		ram := req.RAM
+		if ram < 924 {
+			ram += 100
+		}
+		t := req.Time
+		if t < minTime {
+			t = minTime
+		}
 		req = &scheduler.Requirements{
-			RAM:   req.RAM + 100,
-			Time:  req.Time,
+			RAM:   ram,
+			Time:  t,
 			Cores: req.Cores,
 			Disk:  req.Disk,
 			Other: req.Other,
@@ -0,0 +1,452 @@
+// Copyright © 2026 Genome Research Limited
+// Author: Sendu Bala <sb10@sanger.ac.uk>.
+//
+//  This file is part of wr.
+//
+//  wr is free software: you can redistribute it and/or modify
+//  it under the terms of the GNU Lesser General Public License as published by
+//  the Free Software Foundation, either version 3 of the License, or
+//  (at your option) any later version.
+//
+//  wr is distributed in the hope that it will be useful,
+//  but WITHOUT ANY WARRANTY; without even the implied warranty of
+//  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU Lesser General Public License for more details.
+//
+//  You should have received a copy of the GNU Lesser General Public License
+//  along with wr. If not, see <http://www.gnu.org/licenses/>.
+
+package jobqueue
+
+// This file contains the implementation of restricting when Jobs can start
+// running, via BeginAt times and recurring Windows.
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/VertebrateResequencing/wr/internal"
+	"github.com/VertebrateResequencing/wr/queue"
+)
+
+// these global variables are primarily exported for testing purposes; you
+// probably shouldn't change them
+var (
+	// ServerWindowCheckInterval is how often we look for ready Jobs whose
+	// Window has closed, to move them back to the delayed state.
+	ServerWindowCheckInterval = 1 * time.Minute
+)
+
+const minutesPerDay = 24 * 60
+
+// beginAtLayouts are the absolute time formats, other than RFC3339, accepted by
+// ParseBeginAt(). They are interpreted in the local time zone.
+var beginAtLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// windowDays lets users refer to days of the week by their 3 letter names.
+var windowDays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// WindowPeriod is one recurring period of the week, as part of a Window.
+type WindowPeriod struct {
+	// Days are the days of the week the period starts on, indexed by
+	// time.Weekday.
+	Days [7]bool
+
+	// Start is the number of minutes after midnight that the period starts.
+	Start int
+
+	// End is the number of minutes after midnight that the period ends. If
+	// not greater than Start, the period ends on the following day, so that
+	// eg. a period from 19:00 to 07:00 covers nights.
+	End int
+}
+
+// Window describes the recurring periods of the week during which a Job can
+// start running, in the manager's local time zone. Jobs that are otherwise
+// ready to run wait in the delayed state outside of their Window. Jobs that
+// started running inside their Window are not stopped when it closes.
+type Window struct {
+	Periods []*WindowPeriod
+}
+
+// ParseWindow creates a Window from a user supplied description. This is a
+// comma separated list of periods, each of which is some days of the week
+// and/or a range of times, separated by a space. Days are given as a 3 letter
+// day name like "Sat", or a range like "Mon-Fri", defaulting to every day.
+// Times are given as a range of 24hr times like "19:00-07:00", defaulting to
+// the whole day. Ranges that end earlier than they start continue in to the
+// following day.
+//
+// For example, "Mon-Fri 19:00-07:00,Sat-Sun" describes nights and weekends. An
+// empty description results in a nil Window, which allows any time.
+func ParseWindow(spec string) (*Window, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+
+	w := &Window{}
+	for _, desc := range strings.Split(spec, ",") {
+		p, err := parseWindowPeriod(desc)
+		if err != nil {
+			return nil, fmt.Errorf("window period '%s' was not specified correctly: %s", strings.TrimSpace(desc), err)
+		}
+		w.Periods = append(w.Periods, p)
+	}
+	return w, nil
+}
+
+// parseWindowPeriod parses one of the periods of a ParseWindow() description.
+func parseWindowPeriod(desc string) (*WindowPeriod, error) {
+	fields := strings.Fields(desc)
+	if len(fields) == 0 || len(fields) > 2 {
+		return nil, fmt.Errorf("expected days and/or times")
+	}
+
+	p := &WindowPeriod{End: minutesPerDay}
+	var daysSet, timesSet bool
+	for _, field := range fields {
+		var err error
+		if strings.Contains(field, ":") {
+			if timesSet {
+				return nil, fmt.Errorf("times given more than once")
+			}
+			p.Start, p.End, err = parseWindowTimes(field)
+			timesSet = true
+		} else {
+			if daysSet {
+				return nil, fmt.Errorf("days given more than once")
+			}
+			p.Days, err = parseWindowDays(field)
+			daysSet = true
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !daysSet {
+		for i := range p.Days {
+			p.Days[i] = true
+		}
+	}
+	return p, nil
+}
+
+// parseWindowDays parses a day name like "Sat" or a range like "Mon-Fri",
+// which may wrap around the end of the week.
+func parseWindowDays(field string) ([7]bool, error) {
+	var days [7]bool
+	names := strings.SplitN(field, "-", 2)
+	first, known := windowDays[strings.ToLower(names[0])]
+	if !known {
+		return days, fmt.Errorf("'%s' is not a day name like Mon", names[0])
+	}
+	last := first
+	if len(names) == 2 {
+		last, known = windowDays[strings.ToLower(names[1])]
+		if !known {
+			return days, fmt.Errorf("'%s' is not a day name like Fri", names[1])
+		}
+	}
+
+	for d := first; ; d = (d + 1) % 7 {
+		days[d] = true
+		if d == last {
+			break
+		}
+	}
+	return days, nil
+}
+
+// parseWindowTimes parses a time range like "19:00-07:00", returning the
+// start and end as minutes after midnight.
+func parseWindowTimes(field string) (int, int, error) {
+	times := strings.SplitN(field, "-", 2)
+	if len(times) != 2 {
+		return 0, 0, fmt.Errorf("'%s' is not a time range like 19:00-07:00", field)
+	}
+	start, err := parseWindowTime(times[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := parseWindowTime(times[1])
+	if err != nil {
+		return 0, 0, err
+	}
+	if start == minutesPerDay {
+		start = 0
+	}
+	return start, end, nil
+}
+
+// parseWindowTime parses a 24hr time like "07:00" in to minutes after
+// midnight. "24:00" is allowed, to mean the end of the day.
+func parseWindowTime(t string) (int, error) {
+	hm := strings.SplitN(t, ":", 2)
+	if len(hm) != 2 {
+		return 0, fmt.Errorf("'%s' is not a time like 07:00", t)
+	}
+	h, errh := strconv.Atoi(hm[0])
+	m, errm := strconv.Atoi(hm[1])
+	if errh != nil || errm != nil || h < 0 || m < 0 || m > 59 || h > 24 || (h == 24 && m > 0) {
+		return 0, fmt.Errorf("'%s' is not a time like 07:00", t)
+	}
+	return h*60 + m, nil
+}
+
+// bounds returns the start and end of the period if it started on the day of
+// the given time, and true if it does start on that day.
+func (p *WindowPeriod) bounds(day time.Time) (time.Time, time.Time, bool) {
+	if !p.Days[day.Weekday()] {
+		return time.Time{}, time.Time{}, false
+	}
+	y, m, d := day.Date()
+	loc := day.Location()
+	start := time.Date(y, m, d, 0, p.Start, 0, 0, loc)
+	endDay := d
+	if p.End <= p.Start {
+		endDay++
+	}
+	end := time.Date(y, m, endDay, 0, p.End, 0, 0, loc)
+	return start, end, true
+}
+
+// Next returns the given time if it is within the Window, otherwise the time
+// the Window next opens. A nil Window always returns the given time.
+func (w *Window) Next(t time.Time) time.Time {
+	if w == nil || len(w.Periods) == 0 {
+		return t
+	}
+	t = t.Local()
+
+	// a period that started yesterday could still be open today, and any
+	// period will have started within the next week
+	var next time.Time
+	for offset := -1; offset <= 7; offset++ {
+		day := t.AddDate(0, 0, offset)
+		for _, p := range w.Periods {
+			start, end, ok := p.bounds(day)
+			if !ok || !end.After(t) {
+				continue
+			}
+			if !start.After(t) {
+				return t
+			}
+			if next.IsZero() || start.Before(next) {
+				next = start
+			}
+		}
+	}
+	return next
+}
+
+// String returns a description of the Window in the form accepted by
+// ParseWindow().
+func (w *Window) String() string {
+	if w == nil {
+		return ""
+	}
+	descs := make([]string, len(w.Periods))
+	for i, p := range w.Periods {
+		descs[i] = p.String()
+	}
+	return strings.Join(descs, ",")
+}
+
+// String returns a description of the period in the form accepted by
+// ParseWindow(). Periods that start on days that aren't all in one range are
+// described as multiple periods.
+func (p *WindowPeriod) String() string {
+	var names []string
+	name := func(d time.Weekday) string {
+		return d.String()[:3]
+	}
+
+	// describe the days as ranges, starting from the first day following a
+	// day we don't start on, so that ranges can wrap around the week
+	first := -1
+	for d := 0; d < 7; d++ {
+		if !p.Days[d] {
+			first = (d + 1) % 7
+			break
+		}
+	}
+	if first != -1 {
+		for i := 0; i < 7; i++ {
+			d := (first + i) % 7
+			if !p.Days[d] {
+				continue
+			}
+			end := d
+			for i+1 < 7 && p.Days[(first+i+1)%7] {
+				i++
+				end = (first + i) % 7
+			}
+			if end == d {
+				names = append(names, name(time.Weekday(d)))
+			} else {
+				names = append(names, name(time.Weekday(d))+"-"+name(time.Weekday(end)))
+			}
+		}
+	}
+
+	var times string
+	if p.Start != 0 || p.End != minutesPerDay {
+		times = fmt.Sprintf("%02d:%02d-%02d:%02d", p.Start/60, p.Start%60, p.End/60, p.End%60)
+	}
+
+	if len(names) == 0 {
+		if times == "" {
+			return "00:00-24:00"
+		}
+		return times
+	}
+
+	// days that aren't all in one range need a period each
+	for i, days := range names {
+		if times != "" {
+			names[i] = days + " " + times
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// ParseBeginAt parses a user supplied time that a Job should not start running
+// before. This can be an RFC3339 time, a date and time like "2006-01-02 15:04"
+// or just a date, in the local time zone, or a duration from now like "2h30m".
+// An empty string results in the zero time, meaning no restriction. It is also
+// used to parse Deadlines.
+func ParseBeginAt(when string) (time.Time, error) {
+	when = strings.TrimSpace(when)
+	if when == "" {
+		return time.Time{}, nil
+	}
+
+	if d, err := time.ParseDuration(when); err == nil {
+		return time.Now().Add(d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, when); err == nil {
+		return t, nil
+	}
+	for _, layout := range beginAtLayouts {
+		if t, err := time.ParseInLocation(layout, when, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("begin time '%s' is not a time like \"2006-01-02 15:04\" or a duration like \"2h\"", when)
+}
+
+// delayIfNotAllowed moves the given ready job to the delay queue if it isn't
+// allowed to start running at the given time due to its BeginAt or Window,
+// returning true if it did so.
+func (s *Server) delayIfNotAllowed(job *Job, now time.Time) bool {
+	notBefore := job.notBefore(now)
+	if !notBefore.After(now) {
+		return false
+	}
+
+	err := s.q.DelayUntil(job.Key(), notBefore)
+	if err != nil {
+		// it must have just been reserved or removed
+		return false
+	}
+
+	if job.getScheduledRunner() {
+		// we no longer need a runner for it
+		job.setScheduledRunner(false)
+		s.decrementGroupCount(job.getSchedulerGroup())
+	}
+	return true
+}
+
+// rescheduleJob should be called after the BeginAt or Window of a job waiting
+// to run has been changed. If it's ready but no longer allowed to start, it
+// is delayed until it is. If it's delayed, it's delayed until it is allowed to
+// start, which can be sooner than before if it hasn't failed (since then it
+// can only be waiting because of its old BeginAt or Window).
+func (s *Server) rescheduleJob(job *Job) {
+	item, err := s.q.Get(job.Key())
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	switch item.Stats().State {
+	case queue.ItemStateReady:
+		s.delayIfNotAllowed(job, now)
+	case queue.ItemStateDelay:
+		notBefore := job.notBefore(now)
+		job.RLock()
+		failed := job.FailReason != ""
+		job.RUnlock()
+		if failed && !notBefore.After(item.ReadyAt()) {
+			return
+		}
+		err = s.q.DelayUntil(job.Key(), notBefore)
+		if err != nil {
+			s.Warn("failed to reschedule job", "cmd", job.Cmd, "err", err)
+		}
+	}
+}
+
+// startWindowChecking begins periodically looking for ready Jobs whose Window
+// has closed, so they can be delayed until it opens again.
+func (s *Server) startWindowChecking() {
+	s.wg.Add(1)
+	go func() {
+		defer internal.LogPanic(s.Logger, "jobqueue window checking", true)
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(ServerWindowCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.windowCheck()
+			case <-s.stopClientHandling:
+				return
+			}
+		}
+	}()
+}
+
+// windowCheck delays all the ready Jobs that aren't allowed to start running
+// right now.
+func (s *Server) windowCheck() {
+	now := time.Now()
+	for _, item := range s.q.AllItems() {
+		if item.Stats().State != queue.ItemStateReady {
+			continue
+		}
+		s.delayIfNotAllowed(item.Data.(*Job), now)
+	}
+}
+
+// reserveFilter is our queue's reserve filter. Jobs that aren't allowed to
+// start running right now due to their BeginAt or Window can't be reserved.
+// Otherwise, they can be reserved if they wouldn't take their owner or RepGroup
+// over quota.
+func (s *Server) reserveFilter(data interface{}) bool {
+	now := time.Now()
+	if data.(*Job).notBefore(now).After(now) {
+		return false
+	}
+	return s.quotas.claim(data)
+}
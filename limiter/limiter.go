@@ -155,3 +155,16 @@ func (l *Limiter) GetLowestLimit(groups []string) int {
 	}
 	return lowest
 }
+
+// GetCount tells you the current count and limit of the given group. If the
+// group has no limit, the returned limit is -1.
+func (l *Limiter) GetCount(name string) (uint, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	group := l.vivifyGroup(name)
+	if group == nil {
+		return 0, -1
+	}
+	return group.current, int(group.limit)
+}
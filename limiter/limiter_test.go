@@ -109,6 +109,13 @@ func TestLimiter(t *testing.T) {
 
 			So(l.Increment([]string{"l3"}), ShouldBeTrue)
 			l.Decrement([]string{"l3"})
+
+			current, limit := l.GetCount("l1")
+			So(current, ShouldEqual, 2)
+			So(limit, ShouldEqual, 3)
+			current, limit = l.GetCount("l3")
+			So(current, ShouldEqual, 0)
+			So(limit, ShouldEqual, -1)
 		})
 
 		Convey("You can change limits with SetLimit(), and Decrement() forgets about unused groups", func() {
@@ -37,6 +37,7 @@ const (
 	ItemStateRun       ItemState = "run"
 	ItemStateBury      ItemState = "bury"
 	ItemStateDependent ItemState = "dependent"
+	ItemStateHold      ItemState = "hold"
 	ItemStateRemoved   ItemState = "removed"
 )
 
@@ -60,27 +61,85 @@ type Item struct {
 	creation      time.Time
 	dependencies  []string
 	remainingDeps map[string]bool
+	depTypes      map[string]DependencyType
 	mutex         sync.RWMutex
-	queueIndexes  [5]int
+	queueIndexes  [6]int
+	party         string
+	readySince    time.Time
+	aging         *priorityAging
+	boost         uint8
+	effPriority   uint8
+	nextAging     time.Time
+	agingIndex    int
+	heldBack      bool
 }
 
 // ItemStats holds information about the Item's state. Remaining is the time
 // remaining in the current sub-queue. This will be a duration of zero for all
 // but the delay and run states. In the delay state it tells you how long before
 // it can be reserved, and in the run state it tells you how long before it will
-// be released automatically.
+// be released automatically. EffectivePriority is the same as Priority, unless
+// the item is in the ready sub-queue and has aged to a higher priority (see
+// Queue.SetPriorityAging()) or been boosted (see Queue.SetPriorityBoost()).
 type ItemStats struct {
-	State     ItemState
-	Reserves  uint32
-	Timeouts  uint32
-	Releases  uint32
-	Buries    uint32
-	Kicks     uint32
-	Age       time.Duration
-	Remaining time.Duration
-	Priority  uint8
-	Delay     time.Duration
-	TTR       time.Duration
+	State             ItemState
+	Reserves          uint32
+	Timeouts          uint32
+	Releases          uint32
+	Buries            uint32
+	Kicks             uint32
+	Age               time.Duration
+	Remaining         time.Duration
+	Priority          uint8
+	EffectivePriority uint8
+	Delay             time.Duration
+	TTR               time.Duration
+}
+
+// priorityAging describes how the effective priority of items in the ready
+// sub-queue rises the longer they wait there. clock tells the time.
+type priorityAging struct {
+	interval time.Duration
+	max      uint8
+	clock    func() time.Time
+}
+
+// agedPriority returns the effective priority of an item with the given
+// priority that has been waiting since the given time.
+func (pa *priorityAging) agedPriority(priority uint8, since time.Time) uint8 {
+	if pa == nil || priority >= pa.max {
+		return priority
+	}
+	steps := pa.clock().Sub(since) / pa.interval
+	if steps >= time.Duration(pa.max-priority) {
+		return pa.max
+	}
+	return priority + uint8(steps)
+}
+
+// nextRise returns when the aged priority of an item with the given priority
+// that has been waiting since the given time will next go up. The bool is false
+// if it never will.
+func (pa *priorityAging) nextRise(priority uint8, since time.Time) (time.Time, bool) {
+	if pa == nil || priority >= pa.max {
+		return time.Time{}, false
+	}
+	steps := pa.clock().Sub(since) / pa.interval
+	if steps >= time.Duration(pa.max-priority) {
+		return time.Time{}, false
+	}
+	return since.Add((steps + 1) * pa.interval), true
+}
+
+// effectivePriority returns the priority this item should be ordered by in the
+// ready sub-queue: its aged priority, or its boost if that is higher. You must
+// hold the item's lock before calling this.
+func (item *Item) effectivePriority() uint8 {
+	effective := item.aging.agedPriority(item.priority, item.readySince)
+	if item.boost > effective {
+		return item.boost
+	}
+	return effective
 }
 
 func newItem(key string, reserveGroup string, data interface{}, priority uint8, delay time.Duration, ttr time.Duration) *Item {
@@ -95,6 +154,8 @@ func newItem(key string, reserveGroup string, data interface{}, priority uint8,
 		buries:       0,
 		kicks:        0,
 		priority:     priority,
+		effPriority:  priority,
+		agingIndex:   -1,
 		delay:        delay,
 		ttr:          ttr,
 		readyAt:      time.Now().Add(delay),
@@ -115,18 +176,23 @@ func (item *Item) Stats() *ItemStats {
 	} else {
 		remaining = time.Duration(0) * time.Second
 	}
+	effective := item.priority
+	if item.state == ItemStateReady {
+		effective = item.effectivePriority()
+	}
 	return &ItemStats{
-		State:     item.state,
-		Reserves:  item.reserves,
-		Timeouts:  item.timeouts,
-		Releases:  item.releases,
-		Buries:    item.buries,
-		Kicks:     item.kicks,
-		Age:       age,
-		Remaining: remaining,
-		Priority:  item.priority,
-		Delay:     item.delay,
-		TTR:       item.ttr,
+		State:             item.state,
+		Reserves:          item.reserves,
+		Timeouts:          item.timeouts,
+		Releases:          item.releases,
+		Buries:            item.buries,
+		Kicks:             item.kicks,
+		Age:               age,
+		Remaining:         remaining,
+		Priority:          item.priority,
+		EffectivePriority: effective,
+		Delay:             item.delay,
+		TTR:               item.ttr,
 	}
 }
 
@@ -198,18 +264,40 @@ func (item *Item) ChangedKey(old, new string) {
 		delete(item.remainingDeps, old)
 		item.remainingDeps[new] = true
 	}
+
+	if dt, exists := item.depTypes[old]; exists {
+		delete(item.depTypes, old)
+		item.depTypes[new] = dt
+	}
+}
+
+// DependencyType returns the type of our dependency on the item with the given
+// key. If we don't depend on that item, or did not specify a type when the
+// dependency was set, returns DependencyAfterOK.
+func (item *Item) DependencyType(key string) DependencyType {
+	item.mutex.RLock()
+	defer item.mutex.RUnlock()
+	return item.depTypes[key]
 }
 
-// setDependencies sets the keys of the other items we are dependent upon. This
-// only records the dependencies on the item; it does not trigger any dependency
-// related actions or updates.
-func (item *Item) setDependencies(deps []string) {
+// setDependencies sets the keys of the other items we are dependent upon, and
+// optionally the types of those dependencies (keys missing from types are
+// DependencyAfterOK). This only records the dependencies on the item; it does
+// not trigger any dependency related actions or updates.
+func (item *Item) setDependencies(deps []string, types map[string]DependencyType) {
 	item.mutex.Lock()
 	defer item.mutex.Unlock()
 	item.dependencies = deps[:]
 	item.remainingDeps = make(map[string]bool)
+	item.depTypes = nil
 	for _, key := range item.dependencies {
 		item.remainingDeps[key] = true
+		if dt := types[key]; dt != DependencyAfterOK {
+			if item.depTypes == nil {
+				item.depTypes = make(map[string]DependencyType)
+			}
+			item.depTypes[key] = dt
+		}
 	}
 }
 
@@ -235,6 +323,14 @@ func (item *Item) restart() {
 	item.readyAt = time.Now().Add(item.delay)
 }
 
+// restartAt is a thread-safe way to set the item's ready time to the given
+// time, regardless of its delay.
+func (item *Item) restartAt(readyAt time.Time) {
+	item.mutex.Lock()
+	defer item.mutex.Unlock()
+	item.readyAt = readyAt
+}
+
 // touch is a thread-safe way to (re)set the item's release time, to allow it
 // more time on the run sub-queue.
 func (item *Item) touch() {
@@ -294,6 +390,15 @@ func (item *Item) switchDependentReady() {
 	item.state = ItemStateReady
 }
 
+// update after we've switched from the dependent to the bury sub-queue
+func (item *Item) switchDependentBury() {
+	item.mutex.Lock()
+	defer item.mutex.Unlock()
+	item.queueIndexes[4] = -1
+	item.buries++
+	item.state = ItemStateBury
+}
+
 // update after we've switched from the ready to the run sub-queue
 func (item *Item) switchReadyRun() {
 	item.mutex.Lock()
@@ -311,6 +416,14 @@ func (item *Item) switchReadyDependent() {
 	item.state = ItemStateDependent
 }
 
+// update after we've switched from the ready to the delay sub-queue
+func (item *Item) switchReadyDelay() {
+	item.mutex.Lock()
+	defer item.mutex.Unlock()
+	item.queueIndexes[1] = -1
+	item.state = ItemStateDelay
+}
+
 // update after we've switched from the run to the ready sub-queue
 func (item *Item) switchRunReady() {
 	item.mutex.Lock()
@@ -376,6 +489,67 @@ func (item *Item) switchBuryDependent() {
 	item.state = ItemStateDependent
 }
 
+// update after we've switched from the delay to the hold sub-queue; readyAt is
+// kept so that we can return to the delay sub-queue if unheld before then
+func (item *Item) switchDelayHold() {
+	item.mutex.Lock()
+	defer item.mutex.Unlock()
+	item.queueIndexes[0] = -1
+	item.state = ItemStateHold
+}
+
+// update after we've switched from the ready to the hold sub-queue
+func (item *Item) switchReadyHold() {
+	item.mutex.Lock()
+	defer item.mutex.Unlock()
+	item.queueIndexes[1] = -1
+	item.state = ItemStateHold
+}
+
+// update after we've switched from the dependent to the hold sub-queue
+func (item *Item) switchDependentHold() {
+	item.mutex.Lock()
+	defer item.mutex.Unlock()
+	item.queueIndexes[4] = -1
+	item.state = ItemStateHold
+}
+
+// update after we've switched from the hold to the delay sub-queue
+func (item *Item) switchHoldDelay() {
+	item.mutex.Lock()
+	defer item.mutex.Unlock()
+	item.queueIndexes[5] = -1
+	item.state = ItemStateDelay
+}
+
+// update after we've switched from the hold to the ready sub-queue
+func (item *Item) switchHoldReady() {
+	item.mutex.Lock()
+	defer item.mutex.Unlock()
+	item.queueIndexes[5] = -1
+	item.readyAt = time.Time{}
+	item.state = ItemStateReady
+}
+
+// update after we've switched from the hold to the dependent sub-queue
+func (item *Item) switchHoldDependent() {
+	item.mutex.Lock()
+	defer item.mutex.Unlock()
+	item.queueIndexes[5] = -1
+	item.readyAt = time.Time{}
+	item.state = ItemStateDependent
+}
+
+// update after we've switched from the hold to the bury sub-queue
+func (item *Item) switchHoldBury() {
+	item.mutex.Lock()
+	defer item.mutex.Unlock()
+	item.queueIndexes[5] = -1
+	item.readyAt = time.Time{}
+	item.buries++
+	item.state = ItemStateBury
+}
+
 // once removed from its queue, we clear out various properties just in case
 func (item *Item) removalCleanup() {
 	item.mutex.Lock()
@@ -387,5 +561,6 @@ func (item *Item) removalCleanup() {
 	item.readyAt = time.Time{}
 	item.queueIndexes[3] = -1
 	item.queueIndexes[4] = -1
+	item.queueIndexes[5] = -1
 	item.state = ItemStateRemoved
 }
@@ -34,7 +34,9 @@ priority (or for those with equal priority, the oldest - fifo) one which
 switches it from the ready queue to the run queue. Items can also have
 dependencies, in which case they start in the dependency queue and only move to
 the ready queue (bypassing the delay queue) once all its dependencies have been
-Remove()d from the queue. Items can also belong to a reservation group, in which
+Remove()d from the queue. (Dependencies can instead be typed so that they are
+resolved when the item depended upon is buried, or when it is either removed or
+buried; see DependencyType.) Items can also belong to a reservation group, in which
 case you can Reserve() an item in a desired group.
 
 In the run queue the item starts a time-to-release (ttr) countdown; when that
@@ -49,6 +51,10 @@ some action). When you know you have a transient problem preventing you from
 handling the item right now, you can manually Release() the item back to the
 delay queue.
 
+Items that have not yet been reserved can be Hold()ed, moving them to the hold
+queue where they keep their dependencies but can't become ready, until you
+Unhold() them.
+
     import "github.com/VertebrateResequencing/wr/queue"
     q = queue.New("myQueue")
     q.SetReadyAddedCallback(func(queuename string, allitemdata []interface{}) {
@@ -98,9 +104,27 @@ const (
 	SubQueueRun       SubQueue = "run"
 	SubQueueBury      SubQueue = "bury"
 	SubQueueDependent SubQueue = "dependent"
+	SubQueueHold      SubQueue = "hold"
 	SubQueueRemoved   SubQueue = "removed"
 )
 
+// DependencyType describes what must happen to an item that another item
+// depends upon for that dependency to be resolved.
+type DependencyType uint8
+
+// DependencyType* constants represent all the possible types of dependency.
+// DependencyAfterOK dependencies (the default) are resolved when the item
+// depended upon is Remove()d. DependencyAfterNotOK dependencies are resolved
+// when the item depended upon is Bury()ed; if it is instead Remove()d, the
+// dependency can never be resolved and the dependent item is moved to the bury
+// sub-queue. DependencyAfterAny dependencies are resolved when the item
+// depended upon is either Remove()d or Bury()ed.
+const (
+	DependencyAfterOK DependencyType = iota
+	DependencyAfterNotOK
+	DependencyAfterAny
+)
+
 // queue has some typical errors
 var (
 	ErrQueueClosed   = errors.New("queue closed")
@@ -110,6 +134,8 @@ var (
 	ErrNotReady      = errors.New("not ready")
 	ErrNotRunning    = errors.New("not running")
 	ErrNotBuried     = errors.New("not buried")
+	ErrNotHoldable   = errors.New("not delayed, ready or dependent")
+	ErrNotHeld       = errors.New("not held")
 )
 
 // Error records an error and the operation, item and queue that caused it.
@@ -141,6 +167,20 @@ type ChangedCallback func(from, to SubQueue, data []interface{})
 // values will be treated as SubQueueReady).
 type TTRCallback func(data interface{}) SubQueue
 
+// PartyCallback is used as a callback to decide which fair-share party an item
+// belongs to, based on that item's data.
+type PartyCallback func(data interface{}) string
+
+// UsageCallback is used as a callback to find out how much of the available
+// resources a fair-share party has been using recently. Parties with lower
+// usage get their items Reserve()d first.
+type UsageCallback func(party string) float64
+
+// ReserveFilterCallback is used as a callback to decide if an item can be
+// Reserve()d right now, based on that item's data. Items it returns false for
+// are skipped over, staying in the ready sub-queue.
+type ReserveFilterCallback func(data interface{}) bool
+
 // defaultTTRCallback is used if the the user never calls SetTTRCallback() and
 // always moves the items to the ready sub-queue.
 var defaultTTRCallback = func(data interface{}) SubQueue {
@@ -160,6 +200,7 @@ type Queue struct {
 	runQueue               *subQueue
 	buryQueue              *buryQueue
 	depQueue               *depQueue
+	holdQueue              *holdQueue
 	delayNotification      chan bool
 	startedDelayProcessing chan bool
 	delayClose             chan bool
@@ -185,6 +226,7 @@ type Stats struct {
 	Running   int
 	Buried    int
 	Dependant int
+	Held      int
 }
 
 // ItemDef makes it possible to supply a slice of Add() args to AddMany().
@@ -195,8 +237,15 @@ type ItemDef struct {
 	Priority     uint8 // highest priority is 255
 	Delay        time.Duration
 	TTR          time.Duration
-	StartQueue   SubQueue // blank, or one of SubQueueRun or SubQueueBury
+	StartQueue   SubQueue // blank, or one of SubQueueRun, SubQueueBury or SubQueueHold
 	Dependencies []string
+	// DependencyTypes optionally gives the type of some of the Dependencies,
+	// keyed on dependency key; those not specified are DependencyAfterOK.
+	DependencyTypes map[string]DependencyType
+	// NotBefore optionally makes an item that would otherwise start in the
+	// ready sub-queue wait in the delay sub-queue until this time. Unlike
+	// Delay, it doesn't affect how long the item is delayed when Release()d.
+	NotBefore time.Time
 }
 
 // New is a helper to create instance of the Queue struct.
@@ -210,6 +259,7 @@ func New(name string) *Queue {
 		runQueue:               newSubQueue(2),
 		buryQueue:              newBuryQueue(),
 		depQueue:               newDependencyQueue(),
+		holdQueue:              newHoldQueue(),
 		ttrNotification:        make(chan bool, 1),
 		startedTTRProcessing:   make(chan bool),
 		ttrClose:               make(chan bool, 1),
@@ -264,12 +314,12 @@ func (queue *Queue) readyAdded() {
 
 		go func() {
 			queue.mutex.RLock()
+			queue.readyQueue.mutex.RLock()
 			var data []interface{}
-			for _, il := range queue.readyQueue.groupedItems {
-				for _, item := range il {
-					data = append(data, item.Data)
-				}
+			for _, item := range queue.readyQueue.allItems() {
+				data = append(data, item.Data)
 			}
+			queue.readyQueue.mutex.RUnlock()
 			queue.mutex.RUnlock()
 			queue.readyAddedCb(queue.Name, data)
 
@@ -284,6 +334,69 @@ func (queue *Queue) readyAdded() {
 	}
 }
 
+// SetFairShare changes the order in which items are Reserve()d, so that instead
+// of purely being in priority then age order, items are divided in to parties
+// (eg. the different users that added them), and among items of the same
+// priority, those belonging to the party with the lowest usage are Reserve()d
+// first. The party callback tells us which party an item's data belongs to, and
+// the usage callback tells us the current usage of a party. Neither callback
+// should call methods on this queue.
+//
+// Supplying nil callbacks returns to the default ordering.
+func (queue *Queue) SetFairShare(party PartyCallback, usage UsageCallback) {
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+	if party == nil || usage == nil {
+		party, usage = nil, nil
+	}
+	queue.readyQueue.setFairShare(party, usage)
+}
+
+// SetPriorityAging makes the effective priority of items in the ready sub-queue
+// rise by 1 for every interval they spend waiting there, up to max, so that low
+// priority items eventually get Reserve()d even while higher priority items
+// keep being added. Items with a priority higher than max are unaffected. The
+// wait time resets each time an item leaves the ready sub-queue.
+//
+// The effective priority is reported by item.Stats(), and is used in place of
+// the priority you supplied to decide the order items are Reserve()d in.
+//
+// Supplying an interval of 0 turns aging off again.
+func (queue *Queue) SetPriorityAging(interval time.Duration, max uint8) {
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+	var pa *priorityAging
+	if interval > 0 {
+		pa = &priorityAging{interval: interval, max: max}
+	}
+	queue.readyQueue.setPriorityAging(pa)
+}
+
+// SetReserveFilter sets a callback that Reserve() will call with the data of
+// the item it would otherwise return, to see if that item can be reserved right
+// now. If not, the next item in order is considered instead, and so on, with
+// all skipped items remaining in the ready sub-queue in their original order.
+// The callback is called while the queue is locked, so it must not call
+// methods on this queue.
+//
+// Supplying nil means all items can be reserved.
+func (queue *Queue) SetReserveFilter(callback ReserveFilterCallback) {
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+	queue.readyQueue.setReserveFilter(callback)
+}
+
+// HoldBack stops the ready items with the given keys from being Reserve()d,
+// until a later call to HoldBack() doesn't include them (items that leave the
+// ready sub-queue also stop being held back). This is much more efficient than
+// a SetReserveFilter() callback that keeps rejecting the same items, since
+// held back items aren't considered by Reserve() at all.
+func (queue *Queue) HoldBack(keys map[string]bool) {
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+	queue.readyQueue.holdBack(keys)
+}
+
 // SetChangedCallback sets a callback that will be called when items move from
 // one sub-queue to another. The callback receives the name of the moved-from
 // sub-queue ('new' in the case of entering the queue for the first time), the
@@ -334,6 +447,7 @@ func (queue *Queue) Destroy() error {
 	queue.runQueue.empty()
 	queue.buryQueue.empty()
 	queue.depQueue.empty()
+	queue.holdQueue.empty()
 	queue.closed = true
 	return nil
 }
@@ -351,6 +465,7 @@ func (queue *Queue) Stats() *Stats {
 		Running:   queue.runQueue.len(),
 		Buried:    queue.buryQueue.len(),
 		Dependant: queue.depQueue.len(),
+		Held:      queue.holdQueue.len(),
 	}
 }
 
@@ -373,7 +488,8 @@ func (queue *Queue) Stats() *Stats {
 // will start in the delay or ready sub-queue as described above. For the
 // purpose of recovering a queue following a crash, however, you can supply
 // either SubQueueRun or SubQueueBury to start the item in one of those
-// sub-queues. If the item has unmet dependencies, startQueue is ignored.
+// sub-queues. If the item has unmet dependencies, startQueue is ignored, except
+// for SubQueueHold, which can be supplied to start the item held (see Hold()).
 //
 // The final argument to Add() is an optional slice of item ids on which this
 // item depends: this item will first enter the dependency sub-queue and only
@@ -400,8 +516,9 @@ func (queue *Queue) Add(key string, reserveGroup string, data interface{}, prior
 	queue.items[key] = item
 
 	// check dependencies
-	if len(deps) == 1 && len(deps[0]) > 0 {
-		queue.setItemDependencies(item, deps[0])
+	hasDeps := len(deps) == 1 && len(deps[0]) > 0
+	if hasDeps && startQueue != SubQueueHold {
+		queue.setItemDependencies(item, deps[0], nil)
 		queue.mutex.Unlock()
 		queue.changed(SubQueueNew, SubQueueDependent, []*Item{item})
 		return item, nil
@@ -422,6 +539,15 @@ func (queue *Queue) Add(key string, reserveGroup string, data interface{}, prior
 		item.switchRunBury()
 		queue.mutex.Unlock()
 		queue.changed(SubQueueNew, SubQueueBury, []*Item{item})
+	case SubQueueHold:
+		if hasDeps {
+			item.setDependencies(deps[0], nil)
+			queue.setQueueDeps(item)
+		}
+		queue.holdQueue.push(item)
+		item.switchDelayHold()
+		queue.mutex.Unlock()
+		queue.changed(SubQueueNew, SubQueueHold, []*Item{item})
 	default:
 		if delay.Nanoseconds() == 0 {
 			// put it directly on the ready queue
@@ -445,9 +571,9 @@ func (queue *Queue) Add(key string, reserveGroup string, data interface{}, prior
 // item, and places the item in the dependency queue. Note that you can be
 // dependent on items that do not exist in the queue; the item will remain in
 // dependent queue until you add items with the given deps keys and then
-// Remove() them.
-func (queue *Queue) setItemDependencies(item *Item, deps []string) {
-	item.setDependencies(deps)
+// Remove() them (or Bury() them, depending on the dependency types).
+func (queue *Queue) setItemDependencies(item *Item, deps []string, types map[string]DependencyType) {
+	item.setDependencies(deps, types)
 	queue.setQueueDeps(item)
 	item.switchDelayDependent()
 	queue.depQueue.push(item)
@@ -479,7 +605,9 @@ func (queue *Queue) itemHasDeps(item *Item) bool {
 // AddMany is like Add(), except that you supply a slice of *ItemDef, and it
 // returns the number that were actually added and the number of items that were
 // not added because they were duplicates of items already in the queue. If an
-// error occurs, nothing will have been added.
+// error occurs, nothing will have been added. Unlike with Add(), a StartQueue of
+// SubQueueBury is honoured even if the item has dependencies (as is
+// SubQueueHold).
 func (queue *Queue) AddMany(items []*ItemDef) (added, dups int, err error) {
 	queue.mutex.Lock()
 
@@ -495,6 +623,7 @@ func (queue *Queue) AddMany(items []*ItemDef) (added, dups int, err error) {
 	var addedDepItems []*Item
 	var addedRunItems []*Item
 	var addedBuryItems []*Item
+	var addedHoldItems []*Item
 	for _, def := range items {
 		_, existed := queue.items[def.Key]
 		if existed {
@@ -505,10 +634,17 @@ func (queue *Queue) AddMany(items []*ItemDef) (added, dups int, err error) {
 		item := newItem(def.Key, def.ReserveGroup, def.Data, def.Priority, def.Delay, def.TTR)
 		queue.items[def.Key] = item
 
-		if len(def.Dependencies) > 0 {
-			queue.setItemDependencies(item, def.Dependencies)
+		if len(def.Dependencies) > 0 && def.StartQueue != SubQueueBury && def.StartQueue != SubQueueHold {
+			queue.setItemDependencies(item, def.Dependencies, def.DependencyTypes)
 			addedDepItems = append(addedDepItems, item)
 		} else {
+			if len(def.Dependencies) > 0 {
+				// buried and held items keep their dependencies, so that they
+				// become dependent again if kicked or unheld
+				item.setDependencies(def.Dependencies, def.DependencyTypes)
+				queue.setQueueDeps(item)
+			}
+
 			switch def.StartQueue {
 			case SubQueueRun:
 				item.switchDelayReady()
@@ -526,8 +662,15 @@ func (queue *Queue) AddMany(items []*ItemDef) (added, dups int, err error) {
 				item.switchReadyRun()
 				item.switchRunBury()
 				addedBuryItems = append(addedBuryItems, item)
+			case SubQueueHold:
+				queue.holdQueue.push(item)
+				item.switchDelayHold()
+				addedHoldItems = append(addedHoldItems, item)
 			default:
-				if def.Delay.Nanoseconds() == 0 {
+				if def.NotBefore.After(item.readyAt) {
+					item.readyAt = def.NotBefore
+				}
+				if !item.readyAt.After(time.Now()) {
 					// put it directly on the ready queue
 					item.switchDelayReady()
 					queue.readyQueue.push(item)
@@ -535,7 +678,7 @@ func (queue *Queue) AddMany(items []*ItemDef) (added, dups int, err error) {
 				} else {
 					queue.delayQueue.push(item)
 					addedDelayItems = append(addedDelayItems, item)
-					if !deferredDelayTrigger && queue.delayTime.After(time.Now().Add(item.delay)) {
+					if !deferredDelayTrigger && queue.delayTime.After(item.readyAt) {
 						defer queue.delayNotificationTrigger(item)
 						deferredDelayTrigger = true
 					}
@@ -563,6 +706,9 @@ func (queue *Queue) AddMany(items []*ItemDef) (added, dups int, err error) {
 	if len(addedBuryItems) > 0 {
 		queue.changed(SubQueueNew, SubQueueBury, addedBuryItems)
 	}
+	if len(addedHoldItems) > 0 {
+		queue.changed(SubQueueNew, SubQueueHold, addedHoldItems)
+	}
 
 	return added, dups, err
 }
@@ -634,74 +780,7 @@ func (queue *Queue) Update(key string, reserveGroup string, data interface{}, pr
 	item.Data = data
 	item.mutex.Unlock()
 	if len(deps) == 1 {
-		// check if dependencies actually changed
-		oldDeps := make(map[string]bool)
-		for _, dep := range item.UnresolvedDependencies() {
-			oldDeps[dep] = true
-		}
-		newDeps := 0
-		for _, dep := range deps[0] {
-			if !oldDeps[dep] {
-				newDeps++
-			}
-			delete(oldDeps, dep)
-		}
-		var toRemove []string
-		for dep := range oldDeps {
-			toRemove = append(toRemove, dep)
-		}
-
-		if len(toRemove) > 0 || newDeps > 0 {
-			// remove any invalid dependencies from our lookup
-			for _, dep := range toRemove {
-				if _, exists := queue.items[dep]; exists {
-					delete(queue.dependants[dep], key)
-					if len(queue.dependants[dep]) == 0 {
-						delete(queue.dependants, dep)
-					}
-				}
-			}
-
-			// set the new dependencies and update our lookup
-			item.setDependencies(deps[0])
-			queue.setQueueDeps(item)
-
-			// if we now have unresolved dependencies and we're not in dependent
-			// state, switch to dependent queue
-			item.mutex.RLock()
-			iState := item.state
-			item.mutex.RUnlock()
-			if len(deps[0]) > 0 && iState != ItemStateDependent {
-				pushToDep := true
-				switch iState {
-				case ItemStateDelay:
-					queue.delayQueue.remove(item)
-					item.switchDelayDependent()
-					changedFrom = SubQueueDelay
-				case ItemStateReady:
-					queue.readyQueue.remove(item)
-					item.switchReadyDependent()
-					changedFrom = SubQueueReady
-				case ItemStateRun:
-					queue.runQueue.remove(item)
-					item.switchRunDependent()
-					changedFrom = SubQueueRun
-				case ItemStateBury:
-					// leave buried things buried; Kick() will put it on the
-					// dependent queue if they are still unresolved by then
-					pushToDep = false
-				}
-				if pushToDep {
-					queue.depQueue.push(item)
-				}
-			} else if len(deps[0]) == 0 {
-				// switch to ready queue
-				queue.depQueue.remove(item)
-				item.switchDependentReady()
-				queue.readyQueue.push(item)
-				addedReady = true
-			}
-		}
+		changedFrom, addedReady = queue.updateItemDependencies(item, deps[0], nil)
 	}
 
 	item.mutex.Lock()
@@ -734,7 +813,12 @@ func (queue *Queue) Update(key string, reserveGroup string, data interface{}, pr
 			item.mutex.Unlock()
 		}
 	} else {
+		ready := item.state == ItemStateReady
 		item.mutex.Unlock()
+		if ready {
+			// the new data may put the item in a different fair-share party
+			queue.readyQueue.update(item)
+		}
 	}
 
 	if addedReady {
@@ -752,6 +836,151 @@ func (queue *Queue) Update(key string, reserveGroup string, data interface{}, pr
 	return nil
 }
 
+// SetPriorityBoost gives the item with the given key a minimum effective
+// priority while it is in the ready sub-queue, so that it is reserved ahead of
+// items with lower (aged) priorities, without changing its actual priority.
+// Supply a boost of 0 to remove any existing boost. The boost remains if the
+// item moves to other sub-queues and later becomes ready again.
+func (queue *Queue) SetPriorityBoost(key string, boost uint8) error {
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+
+	if queue.closed {
+		return Error{queue.Name, "SetPriorityBoost", key, ErrQueueClosed}
+	}
+
+	item, exists := queue.items[key]
+	if !exists {
+		return Error{queue.Name, "SetPriorityBoost", key, ErrNotFound}
+	}
+
+	item.mutex.Lock()
+	if item.boost == boost {
+		item.mutex.Unlock()
+		return nil
+	}
+	item.boost = boost
+	ready := item.state == ItemStateReady
+	item.mutex.Unlock()
+	if ready {
+		queue.readyQueue.update(item)
+	}
+	return nil
+}
+
+// updateItemDependencies is used by Update() and UpdateDependencies() to set
+// new dependencies on an item, switching it to or from the dependent sub-queue
+// as necessary. You must hold the queue lock before calling this. Returns the
+// sub-queue the item switched from if it was moved to the dependent sub-queue,
+// and true if it was instead moved to the ready sub-queue.
+func (queue *Queue) updateItemDependencies(item *Item, deps []string, types map[string]DependencyType) (changedFrom SubQueue, addedReady bool) {
+	key := item.Key
+	// check if dependencies actually changed
+	oldDeps := make(map[string]bool)
+	for _, dep := range item.UnresolvedDependencies() {
+		oldDeps[dep] = true
+	}
+	newDeps := 0
+	for _, dep := range deps {
+		if !oldDeps[dep] || item.DependencyType(dep) != types[dep] {
+			newDeps++
+		}
+		delete(oldDeps, dep)
+	}
+	var toRemove []string
+	for dep := range oldDeps {
+		toRemove = append(toRemove, dep)
+	}
+
+	if len(toRemove) > 0 || newDeps > 0 {
+		// remove any invalid dependencies from our lookup
+		for _, dep := range toRemove {
+			if _, exists := queue.items[dep]; exists {
+				delete(queue.dependants[dep], key)
+				if len(queue.dependants[dep]) == 0 {
+					delete(queue.dependants, dep)
+				}
+			}
+		}
+
+		// set the new dependencies and update our lookup
+		item.setDependencies(deps, types)
+		queue.setQueueDeps(item)
+
+		// if we now have unresolved dependencies and we're not in dependent
+		// state, switch to dependent queue
+		item.mutex.RLock()
+		iState := item.state
+		item.mutex.RUnlock()
+		if len(deps) > 0 && iState != ItemStateDependent {
+			pushToDep := true
+			switch iState {
+			case ItemStateDelay:
+				queue.delayQueue.remove(item)
+				item.switchDelayDependent()
+				changedFrom = SubQueueDelay
+			case ItemStateReady:
+				queue.readyQueue.remove(item)
+				item.switchReadyDependent()
+				changedFrom = SubQueueReady
+			case ItemStateRun:
+				queue.runQueue.remove(item)
+				item.switchRunDependent()
+				changedFrom = SubQueueRun
+			case ItemStateBury, ItemStateHold:
+				// leave buried and held things where they are; Kick() or
+				// Unhold() will put it on the dependent queue if they are
+				// still unresolved by then
+				pushToDep = false
+			}
+			if pushToDep {
+				queue.depQueue.push(item)
+			}
+		} else if len(deps) == 0 && iState == ItemStateDependent {
+			// switch to ready queue
+			queue.depQueue.remove(item)
+			item.switchDependentReady()
+			queue.readyQueue.push(item)
+			addedReady = true
+		}
+	}
+	return changedFrom, addedReady
+}
+
+// UpdateDependencies is a thread-safe way to change the dependencies of an
+// item, along with their types (dependency keys missing from types are
+// DependencyAfterOK). As with Update(), you should supply the item's
+// UnresolvedDependencies() and not its Dependencies() for any existing
+// dependencies you wish to keep.
+func (queue *Queue) UpdateDependencies(key string, deps []string, types map[string]DependencyType) error {
+	queue.mutex.Lock()
+
+	if queue.closed {
+		queue.mutex.Unlock()
+		return Error{queue.Name, "UpdateDependencies", key, ErrQueueClosed}
+	}
+
+	item, exists := queue.items[key]
+	if !exists {
+		queue.mutex.Unlock()
+		return Error{queue.Name, "UpdateDependencies", key, ErrNotFound}
+	}
+
+	changedFrom, addedReady := queue.updateItemDependencies(item, deps, types)
+	queue.mutex.Unlock()
+
+	if addedReady {
+		queue.readyAdded()
+		queue.changed(SubQueueDependent, SubQueueReady, []*Item{item})
+	}
+
+	if changedFrom != "" {
+		queue.changed(changedFrom, SubQueueDependent, []*Item{item})
+	}
+
+	return nil
+}
+
 // ChangeKey is a thread-safe way to change the key an item can be found with
 // using Get() (and also ensures any dependencies involving the old key will
 // continue to work). If an item already exists in the queue with the new key,
@@ -826,6 +1055,48 @@ func (queue *Queue) SetDelay(key string, delay time.Duration) error {
 	return nil
 }
 
+// DelayUntil is a thread-safe way to make an item in the ready or delay
+// sub-queue wait in the delay sub-queue until the given time. Ready items are
+// left alone if the time has already passed. Unlike SetDelay(), this doesn't
+// change how long the item will be delayed if it is later Release()d.
+func (queue *Queue) DelayUntil(key string, readyAt time.Time) error {
+	queue.mutex.Lock()
+	if queue.closed {
+		queue.mutex.Unlock()
+		return Error{queue.Name, "DelayUntil", key, ErrQueueClosed}
+	}
+
+	item, exists := queue.items[key]
+	if !exists {
+		queue.mutex.Unlock()
+		return Error{queue.Name, "DelayUntil", key, ErrNotFound}
+	}
+
+	switch item.state {
+	case ItemStateReady:
+		if !readyAt.After(time.Now()) {
+			queue.mutex.Unlock()
+			return nil
+		}
+		queue.readyQueue.remove(item)
+		item.restartAt(readyAt)
+		queue.delayQueue.push(item)
+		item.switchReadyDelay()
+		queue.mutex.Unlock()
+		queue.delayNotificationTrigger(item)
+		queue.changed(SubQueueReady, SubQueueDelay, []*Item{item})
+	case ItemStateDelay:
+		item.restartAt(readyAt)
+		queue.delayQueue.update(item)
+		queue.mutex.Unlock()
+		queue.delayNotificationTrigger(item)
+	default:
+		queue.mutex.Unlock()
+		return Error{queue.Name, "DelayUntil", key, ErrNotReady}
+	}
+	return nil
+}
+
 // SetReserveGroup is a thread-safe way to change the ReserveGroup of an item.
 func (queue *Queue) SetReserveGroup(key string, newGroup string) error {
 	queue.mutex.Lock()
@@ -863,7 +1134,12 @@ func (queue *Queue) SetReserveGroup(key string, newGroup string) error {
 // so doing starting its ttr countdown. By specifying the optional reserveGroup
 // argument, you will get the next item that was added with the given
 // ReserveGroup (conversely, if your items were added with ReserveGroups but you
-// don't supply one here, you will not get an item).
+// don't supply one here, you will not get an item). If SetPriorityAging() has
+// been used, the priority considered is the item's effective priority. If
+// SetFairShare() has been used, among items of equal priority you get one
+// belonging to the party with the lowest usage. If SetReserveFilter() has been
+// used, you get the first of these items that the filter allows. Items held
+// back with HoldBack() are never returned.
 //
 // You need to Remove() the item when you're done with it. If you're still doing
 // something and ttr is approaching, Touch() it, otherwise it will be assumed
@@ -982,7 +1258,9 @@ func (queue *Queue) Release(key string) error {
 
 // Bury is a thread-safe way to switch an item in the run sub-queue to the
 // bury sub-queue, for when the item can't be dealt with ever, at least until
-// the user takes some action and changes something.
+// the user takes some action and changes something. Items that depend on this
+// one with a DependencyAfterNotOK or DependencyAfterAny dependency have that
+// dependency resolved.
 func (queue *Queue) Bury(key string) error {
 	queue.mutex.Lock()
 
@@ -1008,8 +1286,35 @@ func (queue *Queue) Bury(key string) error {
 	queue.runQueue.remove(item)
 	queue.buryQueue.push(item)
 	item.switchRunBury()
+
+	// transfer any dependants that were waiting for this to fail to the ready
+	// queue
+	var addedReadyItems []*Item
+	if deps, exists := queue.dependants[key]; exists {
+		for depKey, dep := range deps {
+			if dep.DependencyType(key) == DependencyAfterOK {
+				continue
+			}
+			delete(deps, depKey)
+			done := dep.resolveDependency(key)
+			if done && dep.state == ItemStateDependent {
+				queue.depQueue.remove(dep)
+				dep.switchDependentReady()
+				queue.readyQueue.push(dep)
+				addedReadyItems = append(addedReadyItems, dep)
+			}
+		}
+		if len(deps) == 0 {
+			delete(queue.dependants, key)
+		}
+	}
+
 	queue.mutex.Unlock()
 	queue.changed(SubQueueRun, SubQueueBury, []*Item{item})
+	if len(addedReadyItems) > 0 {
+		queue.changed(SubQueueDependent, SubQueueReady, addedReadyItems)
+		queue.readyAdded()
+	}
 
 	return nil
 }
@@ -1054,7 +1359,103 @@ func (queue *Queue) Kick(key string) error {
 	return nil
 }
 
-// Remove is a thread-safe way to remove an item from the queue.
+// Hold is a thread-safe way to switch an item in the delay, ready or dependent
+// sub-queue to the hold sub-queue, for when the item shouldn't be reserved
+// until the user says otherwise. Held items keep their dependencies, which
+// continue to be resolved as normal, but they never become ready until you
+// Unhold() them.
+func (queue *Queue) Hold(key string) error {
+	queue.mutex.Lock()
+
+	if queue.closed {
+		queue.mutex.Unlock()
+		return Error{queue.Name, "Hold", key, ErrQueueClosed}
+	}
+
+	// check it's actually still in the queue first
+	item, ok := queue.items[key]
+	if !ok {
+		queue.mutex.Unlock()
+		return Error{queue.Name, "Hold", key, ErrNotFound}
+	}
+
+	// switch from its current queue to the hold queue
+	var from SubQueue
+	switch item.state {
+	case ItemStateDelay:
+		queue.delayQueue.remove(item)
+		item.switchDelayHold()
+		from = SubQueueDelay
+	case ItemStateReady:
+		queue.readyQueue.remove(item)
+		item.switchReadyHold()
+		from = SubQueueReady
+	case ItemStateDependent:
+		queue.depQueue.remove(item)
+		item.switchDependentHold()
+		from = SubQueueDependent
+	default:
+		queue.mutex.Unlock()
+		return Error{queue.Name, "Hold", key, ErrNotHoldable}
+	}
+	queue.holdQueue.push(item)
+
+	queue.mutex.Unlock()
+	queue.changed(from, SubQueueHold, []*Item{item})
+	return nil
+}
+
+// Unhold is a thread-safe way to switch an item in the hold sub-queue back to
+// the dependent sub-queue if it still has unresolved dependencies, or to the
+// delay sub-queue if its delay (from before it was held) hasn't yet passed, or
+// otherwise to the ready sub-queue.
+func (queue *Queue) Unhold(key string) error {
+	queue.mutex.Lock()
+
+	if queue.closed {
+		queue.mutex.Unlock()
+		return Error{queue.Name, "Unhold", key, ErrQueueClosed}
+	}
+
+	// check it's actually still in the queue first
+	item, ok := queue.items[key]
+	if !ok {
+		queue.mutex.Unlock()
+		return Error{queue.Name, "Unhold", key, ErrNotFound}
+	}
+
+	// and it must be in the hold queue
+	if ok = item.state == ItemStateHold; !ok {
+		queue.mutex.Unlock()
+		return Error{queue.Name, "Unhold", key, ErrNotHeld}
+	}
+
+	queue.holdQueue.remove(item)
+	switch {
+	case len(item.UnresolvedDependencies()) > 0:
+		queue.depQueue.push(item)
+		item.switchHoldDependent()
+		queue.mutex.Unlock()
+		queue.changed(SubQueueHold, SubQueueDependent, []*Item{item})
+	case !item.isready():
+		queue.delayQueue.push(item)
+		item.switchHoldDelay()
+		queue.mutex.Unlock()
+		queue.delayNotificationTrigger(item)
+		queue.changed(SubQueueHold, SubQueueDelay, []*Item{item})
+	default:
+		queue.readyQueue.push(item)
+		item.switchHoldReady()
+		queue.mutex.Unlock()
+		queue.changed(SubQueueHold, SubQueueReady, []*Item{item})
+		queue.readyAdded()
+	}
+	return nil
+}
+
+// Remove is a thread-safe way to remove an item from the queue. Items that
+// depend on this one have that dependency resolved, except for those with a
+// DependencyAfterNotOK dependency, which are buried.
 func (queue *Queue) Remove(key string) error {
 	queue.mutex.Lock()
 
@@ -1070,11 +1471,29 @@ func (queue *Queue) Remove(key string) error {
 		return Error{queue.Name, "Remove", key, ErrNotFound}
 	}
 
-	// transfer any dependants to the ready queue
+	// transfer any dependants to the ready queue, or to the bury queue if they
+	// needed this item to fail
 	addedReady := false
-	var addedReadyItems []*Item
+	var addedReadyItems, addedBuryItems, heldBuryItems []*Item
 	if deps, exists := queue.dependants[key]; exists {
 		for _, dep := range deps {
+			if dep.DependencyType(key) == DependencyAfterNotOK {
+				dep.resolveDependency(key)
+				switch dep.state {
+				case ItemStateDependent:
+					queue.depQueue.remove(dep)
+					queue.buryQueue.push(dep)
+					dep.switchDependentBury()
+					addedBuryItems = append(addedBuryItems, dep)
+				case ItemStateHold:
+					// it could never run even if unheld
+					queue.holdQueue.remove(dep)
+					queue.buryQueue.push(dep)
+					dep.switchHoldBury()
+					heldBuryItems = append(heldBuryItems, dep)
+				}
+				continue
+			}
 			done := dep.resolveDependency(key)
 			if done && dep.state == ItemStateDependent {
 				queue.depQueue.remove(dep)
@@ -1120,6 +1539,9 @@ func (queue *Queue) Remove(key string) error {
 	case ItemStateDependent:
 		queue.depQueue.remove(item)
 		queue.changed(SubQueueDependent, SubQueueRemoved, []*Item{item})
+	case ItemStateHold:
+		queue.holdQueue.remove(item)
+		queue.changed(SubQueueHold, SubQueueRemoved, []*Item{item})
 	}
 	item.removalCleanup()
 
@@ -1128,6 +1550,12 @@ func (queue *Queue) Remove(key string) error {
 		queue.changed(SubQueueDependent, SubQueueReady, addedReadyItems)
 		queue.readyAdded()
 	}
+	if len(addedBuryItems) > 0 {
+		queue.changed(SubQueueDependent, SubQueueBury, addedBuryItems)
+	}
+	if len(heldBuryItems) > 0 {
+		queue.changed(SubQueueHold, SubQueueBury, heldBuryItems)
+	}
 
 	return nil
 }
@@ -1135,7 +1563,7 @@ func (queue *Queue) Remove(key string) error {
 // HasDependents tells you if the item with the given key has any other items
 // depending upon it. You'd want to check this before Remove()ing this item if
 // you're removing it because it was undesired as opposed to complete, as
-// Remove() always triggers dependent items to become ready.
+// Remove() always triggers dependent items to become ready (or buried).
 func (queue *Queue) HasDependents(key string) (bool, error) {
 	queue.mutex.Lock()
 	defer queue.mutex.Unlock()
@@ -1203,7 +1631,7 @@ func (queue *Queue) startDelayProcessing() {
 
 func (queue *Queue) delayNotificationTrigger(item *Item) {
 	queue.mutex.RLock()
-	if queue.delayTime.After(time.Now().Add(item.delay)) {
+	if queue.delayTime.After(item.ReadyAt()) {
 		queue.mutex.RUnlock()
 		queue.delayNotification <- true
 		<-queue.startedDelayProcessing
@@ -35,6 +35,25 @@ type changedStruct struct {
 	count int
 }
 
+// testClock is a clock that only moves when told to, for testing things that
+// depend on how much time has passed.
+type testClock struct {
+	mutex sync.Mutex
+	t     time.Time
+}
+
+func (c *testClock) now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.t
+}
+
+func (c *testClock) advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.t = c.t.Add(d)
+}
+
 func TestQueue(t *testing.T) {
 	Convey("Adding multiple items with a delay to fresh queues always works", t, func() {
 		done := make(chan bool, 1)
@@ -919,6 +938,65 @@ func TestQueue(t *testing.T) {
 		So(stats.Buried, ShouldEqual, 1)
 	})
 
+	Convey("You can add items that wait in the delay sub-queue until a given time, and delay ready items until a given time", t, func() {
+		queue := New("not before queue")
+		defer queue.Destroy()
+
+		added, _, err := queue.AddMany([]*ItemDef{
+			{Key: "key_now", Data: "data", TTR: 100 * time.Millisecond},
+			{Key: "key_later", Data: "data", TTR: 100 * time.Millisecond, NotBefore: time.Now().Add(100 * time.Millisecond)},
+		})
+		So(err, ShouldBeNil)
+		So(added, ShouldEqual, 2)
+
+		stats := queue.Stats()
+		So(stats.Delayed, ShouldEqual, 1)
+		So(stats.Ready, ShouldEqual, 1)
+
+		err = queue.DelayUntil("key_now", time.Now().Add(-1*time.Second))
+		So(err, ShouldBeNil)
+		stats = queue.Stats()
+		So(stats.Ready, ShouldEqual, 1)
+
+		err = queue.DelayUntil("key_now", time.Now().Add(200*time.Millisecond))
+		So(err, ShouldBeNil)
+		stats = queue.Stats()
+		So(stats.Delayed, ShouldEqual, 2)
+		So(stats.Ready, ShouldEqual, 0)
+
+		<-time.After(150 * time.Millisecond)
+		stats = queue.Stats()
+		So(stats.Delayed, ShouldEqual, 1)
+		So(stats.Ready, ShouldEqual, 1)
+
+		item, err := queue.Reserve()
+		So(err, ShouldBeNil)
+		So(item.Key, ShouldEqual, "key_later")
+
+		err = queue.DelayUntil("key_later", time.Now().Add(1*time.Second))
+		So(err, ShouldNotBeNil)
+		qerr, ok := err.(Error)
+		So(ok, ShouldBeTrue)
+		So(qerr.Err, ShouldEqual, ErrNotReady)
+
+		Convey("Releasing items delayed this way uses their normal delay", func() {
+			err = queue.Release("key_later")
+			So(err, ShouldBeNil)
+			stats = queue.Stats()
+			So(stats.Delayed, ShouldEqual, 1)
+			So(stats.Ready, ShouldEqual, 1)
+		})
+
+		Convey("Delayed items can have their wait shortened", func() {
+			err = queue.DelayUntil("key_now", time.Now())
+			So(err, ShouldBeNil)
+			<-time.After(10 * time.Millisecond)
+			stats = queue.Stats()
+			So(stats.Delayed, ShouldEqual, 0)
+			So(stats.Ready, ShouldEqual, 1)
+		})
+	})
+
 	Convey("Once a thousand items with no delay have been added to the queue", t, func() {
 		queue := New("1000 queue")
 		defer queue.Destroy()
@@ -1051,6 +1129,329 @@ func TestQueue(t *testing.T) {
 		})
 	})
 
+	Convey("Once items belonging to different parties have been added to the queue", t, func() {
+		queue := New("fair queue")
+		defer queue.Destroy()
+		type testdata struct {
+			Party string
+		}
+		for i := 0; i < 6; i++ {
+			_, err := queue.Add(fmt.Sprintf("a_%d", i), "g", &testdata{Party: "a"}, 0, 0*time.Second, 30*time.Second, "")
+			So(err, ShouldBeNil)
+		}
+		for i := 0; i < 3; i++ {
+			_, err := queue.Add(fmt.Sprintf("b_%d", i), "g", &testdata{Party: "b"}, 0, 0*time.Second, 30*time.Second, "")
+			So(err, ShouldBeNil)
+		}
+
+		reserveAll := func() []string {
+			var keys []string
+			for {
+				item, err := queue.Reserve("g")
+				if err != nil {
+					break
+				}
+				keys = append(keys, item.Key)
+			}
+			return keys
+		}
+
+		Convey("By default they are reserved in the order they were added", func() {
+			So(reserveAll(), ShouldResemble, []string{"a_0", "a_1", "a_2", "a_3", "a_4", "a_5", "b_0", "b_1", "b_2"})
+		})
+
+		Convey("With fair-share, parties with lower usage get reserved first", func() {
+			usage := map[string]float64{"a": 1}
+			queue.SetFairShare(func(data interface{}) string {
+				return data.(*testdata).Party
+			}, func(party string) float64 {
+				return usage[party]
+			})
+			So(queue.Stats().Ready, ShouldEqual, 9)
+
+			item, err := queue.Reserve("g")
+			So(err, ShouldBeNil)
+			So(item.Key, ShouldEqual, "b_0")
+
+			Convey("Usage is checked on every reserve", func() {
+				var keys []string
+				for {
+					item, err := queue.Reserve("g")
+					if err != nil {
+						break
+					}
+					keys = append(keys, item.Key)
+					usage[item.Data.(*testdata).Party] += 1.5
+				}
+				So(keys, ShouldResemble, []string{"b_1", "a_0", "b_2", "a_1", "a_2", "a_3", "a_4", "a_5"})
+			})
+
+			Convey("Priority still takes precedence", func() {
+				err := queue.Update("a_3", "g", &testdata{Party: "a"}, 1, 0*time.Second, 30*time.Second)
+				So(err, ShouldBeNil)
+				So(reserveAll(), ShouldResemble, []string{"a_3", "b_1", "b_2", "a_0", "a_1", "a_2", "a_4", "a_5"})
+			})
+
+			Convey("Updating an item's data can change its party", func() {
+				err := queue.Update("a_5", "g", &testdata{Party: "c"}, 0, 0*time.Second, 30*time.Second)
+				So(err, ShouldBeNil)
+				usage["b"] = 2
+				So(reserveAll(), ShouldResemble, []string{"a_5", "a_0", "a_1", "a_2", "a_3", "a_4", "b_1", "b_2"})
+			})
+
+			Convey("You can turn it off again", func() {
+				queue.SetFairShare(nil, nil)
+				So(reserveAll(), ShouldResemble, []string{"a_0", "a_1", "a_2", "a_3", "a_4", "a_5", "b_1", "b_2"})
+			})
+		})
+	})
+
+	Convey("With priority aging, items waiting in the ready queue rise in priority", t, func() {
+		queue := New("aging queue")
+		defer queue.Destroy()
+		clock := &testClock{t: time.Now()}
+		queue.readyQueue.clock = clock.now
+		queue.SetPriorityAging(100*time.Millisecond, 3)
+
+		_, err := queue.Add("low", "", "data", 0, 0*time.Second, 30*time.Second, "")
+		So(err, ShouldBeNil)
+		clock.advance(250 * time.Millisecond)
+
+		item, err := queue.Get("low")
+		So(err, ShouldBeNil)
+		stats := item.Stats()
+		So(stats.Priority, ShouldEqual, 0)
+		So(stats.EffectivePriority, ShouldEqual, 2)
+
+		_, err = queue.Add("medium", "", "data", 1, 0*time.Second, 30*time.Second, "")
+		So(err, ShouldBeNil)
+		_, err = queue.Add("high", "", "data", 4, 0*time.Second, 30*time.Second, "")
+		So(err, ShouldBeNil)
+		So(len(queue.readyQueue.agingItems), ShouldEqual, 2)
+
+		item, err = queue.Reserve()
+		So(err, ShouldBeNil)
+		So(item.Key, ShouldEqual, "high")
+		So(item.Stats().EffectivePriority, ShouldEqual, 4)
+
+		item, err = queue.Reserve()
+		So(err, ShouldBeNil)
+		So(item.Key, ShouldEqual, "low")
+		So(item.Stats().EffectivePriority, ShouldEqual, 0)
+
+		Convey("Up to the given maximum, resetting when released", func() {
+			err = queue.Release("low")
+			So(err, ShouldBeNil)
+			clock.advance(550 * time.Millisecond)
+
+			item, err = queue.Get("low")
+			So(err, ShouldBeNil)
+			So(item.Stats().EffectivePriority, ShouldEqual, 3)
+			item, err = queue.Get("medium")
+			So(err, ShouldBeNil)
+			So(item.Stats().EffectivePriority, ShouldEqual, 3)
+
+			item, err = queue.Reserve()
+			So(err, ShouldBeNil)
+			So(item.Key, ShouldEqual, "low")
+			So(len(queue.readyQueue.agingItems), ShouldEqual, 0)
+		})
+
+		Convey("You can turn it off again", func() {
+			_, err = queue.Add("low2", "", "data", 0, 0*time.Second, 30*time.Second, "")
+			So(err, ShouldBeNil)
+			clock.advance(250 * time.Millisecond)
+			queue.SetPriorityAging(0, 0)
+
+			item, err = queue.Reserve()
+			So(err, ShouldBeNil)
+			So(item.Key, ShouldEqual, "medium")
+			item, err = queue.Reserve()
+			So(err, ShouldBeNil)
+			So(item.Key, ShouldEqual, "low2")
+		})
+	})
+
+	Convey("Boosted items are reserved ahead of higher priority ones", t, func() {
+		queue := New("boost queue")
+		defer queue.Destroy()
+
+		_, err := queue.Add("low", "", "data", 0, 0*time.Second, 30*time.Second, "")
+		So(err, ShouldBeNil)
+		_, err = queue.Add("high", "", "data", 5, 0*time.Second, 30*time.Second, "")
+		So(err, ShouldBeNil)
+		_, err = queue.Add("delayed", "", "data", 0, 1*time.Hour, 30*time.Second, "")
+		So(err, ShouldBeNil)
+
+		err = queue.SetPriorityBoost("low", 10)
+		So(err, ShouldBeNil)
+		err = queue.SetPriorityBoost("delayed", 10)
+		So(err, ShouldBeNil)
+		err = queue.SetPriorityBoost("missing", 10)
+		So(err, ShouldNotBeNil)
+		qerr, ok := err.(Error)
+		So(ok, ShouldBeTrue)
+		So(qerr.Err, ShouldEqual, ErrNotFound)
+
+		item, err := queue.Get("low")
+		So(err, ShouldBeNil)
+		stats := item.Stats()
+		So(stats.Priority, ShouldEqual, 0)
+		So(stats.EffectivePriority, ShouldEqual, 10)
+		item, err = queue.Get("delayed")
+		So(err, ShouldBeNil)
+		So(item.Stats().EffectivePriority, ShouldEqual, 0)
+
+		item, err = queue.Reserve()
+		So(err, ShouldBeNil)
+		So(item.Key, ShouldEqual, "low")
+
+		Convey("The boost persists through a release", func() {
+			err = queue.Release("low")
+			So(err, ShouldBeNil)
+			item, err = queue.Reserve()
+			So(err, ShouldBeNil)
+			So(item.Key, ShouldEqual, "low")
+		})
+
+		Convey("You can remove the boost", func() {
+			err = queue.Release("low")
+			So(err, ShouldBeNil)
+			err = queue.SetPriorityBoost("low", 0)
+			So(err, ShouldBeNil)
+			item, err = queue.Reserve()
+			So(err, ShouldBeNil)
+			So(item.Key, ShouldEqual, "high")
+		})
+	})
+
+	Convey("With a reserve filter, items it rejects are skipped over", t, func() {
+		queue := New("filter queue")
+		defer queue.Destroy()
+
+		allowed := map[string]bool{"a": false, "b": true}
+		var filterMutex sync.Mutex
+		queue.SetReserveFilter(func(data interface{}) bool {
+			filterMutex.Lock()
+			defer filterMutex.Unlock()
+			return allowed[data.(string)]
+		})
+
+		_, err := queue.Add("a1", "", "a", 2, 0*time.Second, 30*time.Second, "")
+		So(err, ShouldBeNil)
+		_, err = queue.Add("a2", "", "a", 1, 0*time.Second, 30*time.Second, "")
+		So(err, ShouldBeNil)
+		_, err = queue.Add("b1", "", "b", 0, 0*time.Second, 30*time.Second, "")
+		So(err, ShouldBeNil)
+
+		item, err := queue.Reserve()
+		So(err, ShouldBeNil)
+		So(item.Key, ShouldEqual, "b1")
+
+		item, err = queue.Reserve()
+		So(err, ShouldNotBeNil)
+		So(item, ShouldBeNil)
+		qerr, ok := err.(Error)
+		So(ok, ShouldBeTrue)
+		So(qerr.Err, ShouldEqual, ErrNothingReady)
+		So(queue.Stats().Ready, ShouldEqual, 2)
+
+		Convey("Skipped items keep their order once allowed", func() {
+			filterMutex.Lock()
+			allowed["a"] = true
+			filterMutex.Unlock()
+
+			item, err = queue.Reserve()
+			So(err, ShouldBeNil)
+			So(item.Key, ShouldEqual, "a1")
+			item, err = queue.Reserve()
+			So(err, ShouldBeNil)
+			So(item.Key, ShouldEqual, "a2")
+		})
+
+		Convey("You can remove the filter", func() {
+			queue.SetReserveFilter(nil)
+
+			item, err = queue.Reserve()
+			So(err, ShouldBeNil)
+			So(item.Key, ShouldEqual, "a1")
+		})
+	})
+
+	Convey("Once some items have been held back", t, func() {
+		queue := New("hold back queue")
+		defer queue.Destroy()
+
+		var filterMutex sync.Mutex
+		var filtered []string
+		queue.SetReserveFilter(func(data interface{}) bool {
+			filterMutex.Lock()
+			defer filterMutex.Unlock()
+			filtered = append(filtered, data.(string))
+			return true
+		})
+		_, err := queue.Add("a1", "", "a1", 2, 0*time.Second, 30*time.Second, "")
+		So(err, ShouldBeNil)
+		_, err = queue.Add("a2", "", "a2", 1, 0*time.Second, 30*time.Second, "")
+		So(err, ShouldBeNil)
+		_, err = queue.Add("b1", "", "b1", 0, 0*time.Second, 30*time.Second, "")
+		So(err, ShouldBeNil)
+
+		queue.HoldBack(map[string]bool{"a1": true, "a2": true})
+		So(queue.Stats().Ready, ShouldEqual, 3)
+
+		Convey("Reserve() doesn't consider them", func() {
+			item, err := queue.Reserve()
+			So(err, ShouldBeNil)
+			So(item.Key, ShouldEqual, "b1")
+
+			item, err = queue.Reserve()
+			So(err, ShouldNotBeNil)
+			So(item, ShouldBeNil)
+			So(queue.Stats().Ready, ShouldEqual, 2)
+
+			filterMutex.Lock()
+			So(filtered, ShouldResemble, []string{"b1"})
+			filterMutex.Unlock()
+		})
+
+		Convey("The ready added callback still gets them", func() {
+			readyAdded := make(chan int, 1)
+			queue.SetReadyAddedCallback(func(queuename string, allitemdata []interface{}) {
+				readyAdded <- len(allitemdata)
+			})
+			queue.TriggerReadyAddedCallback()
+			So(<-readyAdded, ShouldEqual, 3)
+		})
+
+		Convey("They can be reserved in order once no longer held back", func() {
+			queue.HoldBack(map[string]bool{"a2": true})
+			item, err := queue.Reserve()
+			So(err, ShouldBeNil)
+			So(item.Key, ShouldEqual, "a1")
+
+			queue.HoldBack(nil)
+			item, err = queue.Reserve()
+			So(err, ShouldBeNil)
+			So(item.Key, ShouldEqual, "a2")
+			item, err = queue.Reserve()
+			So(err, ShouldBeNil)
+			So(item.Key, ShouldEqual, "b1")
+		})
+
+		Convey("They stop being held back if they leave the ready sub-queue", func() {
+			err := queue.Remove("a1")
+			So(err, ShouldBeNil)
+			So(queue.Stats().Ready, ShouldEqual, 2)
+			_, err = queue.Add("a1", "", "a1", 2, 0*time.Second, 30*time.Second, "")
+			So(err, ShouldBeNil)
+
+			item, err := queue.Reserve()
+			So(err, ShouldBeNil)
+			So(item.Key, ShouldEqual, "a1")
+		})
+	})
+
 	Convey("Once a thousand items with a small delay have been added to the queue", t, func() {
 		queue := New("1000 queue")
 		defer queue.Destroy()
@@ -1208,6 +1609,21 @@ func TestQueue(t *testing.T) {
 		So(stats.Ready, ShouldEqual, 10)
 		So(stats.Running, ShouldEqual, 0)
 		So(stats.Buried, ShouldEqual, 10)
+
+		Convey("Items with dependencies can start buried, becoming dependent if kicked", func() {
+			added, _, err = queue.AddMany([]*ItemDef{{Key: "buried_dep", Data: "data", TTR: 100 * time.Millisecond, StartQueue: SubQueueBury, Dependencies: []string{"key_0_run"}}})
+			So(err, ShouldBeNil)
+			So(added, ShouldEqual, 1)
+
+			item, err := queue.Get("buried_dep")
+			So(err, ShouldBeNil)
+			So(item.Stats().State, ShouldEqual, ItemStateBury)
+			So(item.UnresolvedDependencies(), ShouldResemble, []string{"key_0_run"})
+
+			err = queue.Kick("buried_dep")
+			So(err, ShouldBeNil)
+			So(item.Stats().State, ShouldEqual, ItemStateDependent)
+		})
 	})
 
 	Convey("Once some items with dependencies have been added to the queue", t, func() {
@@ -1433,6 +1849,112 @@ func TestQueue(t *testing.T) {
 		})
 	})
 
+	Convey("Once some items have been added to the queue, you can hold and unhold them", t, func() {
+		queue := New("hold queue")
+		defer queue.Destroy()
+		_, err := queue.Add("key_1", "", "1", 0, 0*time.Second, 30*time.Second, "")
+		So(err, ShouldBeNil)
+		_, err = queue.Add("key_2", "", "2", 0, 0*time.Second, 30*time.Second, "")
+		So(err, ShouldBeNil)
+		dep, err := queue.Add("key_3", "", "3", 0, 0*time.Second, 30*time.Second, "", []string{"key_2"})
+		So(err, ShouldBeNil)
+		delayed, err := queue.Add("key_4", "", "4", 0, 1*time.Hour, 30*time.Second, "")
+		So(err, ShouldBeNil)
+
+		for _, key := range []string{"key_1", "key_3", "key_4"} {
+			err = queue.Hold(key)
+			So(err, ShouldBeNil)
+		}
+		stats := queue.Stats()
+		So(stats.Ready, ShouldEqual, 1)
+		So(stats.Held, ShouldEqual, 3)
+		So(stats.Dependant, ShouldEqual, 0)
+		So(stats.Delayed, ShouldEqual, 0)
+		So(dep.Stats().State, ShouldEqual, ItemStateHold)
+
+		err = queue.Hold("key_1")
+		So(err, ShouldNotBeNil)
+		qerr, ok := err.(Error)
+		So(ok, ShouldBeTrue)
+		So(qerr.Err, ShouldEqual, ErrNotHoldable)
+
+		err = queue.Unhold("key_2")
+		So(err, ShouldNotBeNil)
+		qerr, ok = err.(Error)
+		So(ok, ShouldBeTrue)
+		So(qerr.Err, ShouldEqual, ErrNotHeld)
+
+		Convey("Held items can't be reserved, and keep their place once unheld", func() {
+			item, err := queue.Reserve()
+			So(err, ShouldBeNil)
+			So(item.Key, ShouldEqual, "key_2")
+			err = queue.Release("key_2")
+			So(err, ShouldBeNil)
+
+			err = queue.Unhold("key_1")
+			So(err, ShouldBeNil)
+			item, err = queue.Reserve()
+			So(err, ShouldBeNil)
+			So(item.Key, ShouldEqual, "key_1")
+
+			err = queue.Hold("key_1")
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Held items have their dependencies resolved, but stay held until unheld", func() {
+			_, err := queue.Reserve()
+			So(err, ShouldBeNil)
+			err = queue.Remove("key_2")
+			So(err, ShouldBeNil)
+			So(dep.Stats().State, ShouldEqual, ItemStateHold)
+			So(dep.UnresolvedDependencies(), ShouldBeEmpty)
+
+			err = queue.Unhold("key_3")
+			So(err, ShouldBeNil)
+			So(dep.Stats().State, ShouldEqual, ItemStateReady)
+		})
+
+		Convey("Unheld items return to the dependent or delay sub-queue if appropriate", func() {
+			err := queue.Unhold("key_3")
+			So(err, ShouldBeNil)
+			So(dep.Stats().State, ShouldEqual, ItemStateDependent)
+
+			err = queue.Unhold("key_4")
+			So(err, ShouldBeNil)
+			So(delayed.Stats().State, ShouldEqual, ItemStateDelay)
+		})
+
+		Convey("Held items can be removed", func() {
+			err := queue.Remove("key_1")
+			So(err, ShouldBeNil)
+			So(queue.Stats().Held, ShouldEqual, 2)
+		})
+
+		Convey("Items can start held, keeping their dependencies", func() {
+			added, _, err := queue.AddMany([]*ItemDef{
+				{Key: "key_5", Data: "5", TTR: 30 * time.Second, StartQueue: SubQueueHold},
+				{Key: "key_6", Data: "6", TTR: 30 * time.Second, StartQueue: SubQueueHold, Dependencies: []string{"key_2"}, DependencyTypes: map[string]DependencyType{"key_2": DependencyAfterNotOK}},
+			})
+			So(err, ShouldBeNil)
+			So(added, ShouldEqual, 2)
+			So(queue.Stats().Held, ShouldEqual, 5)
+
+			item, err := queue.Add("key_7", "", "7", 0, 0*time.Second, 30*time.Second, SubQueueHold, []string{"key_2"})
+			So(err, ShouldBeNil)
+			So(item.Stats().State, ShouldEqual, ItemStateHold)
+			So(item.UnresolvedDependencies(), ShouldResemble, []string{"key_2"})
+
+			Convey("Held items that can never run get buried", func() {
+				err = queue.Remove("key_2")
+				So(err, ShouldBeNil)
+				six, err := queue.Get("key_6")
+				So(err, ShouldBeNil)
+				So(six.Stats().State, ShouldEqual, ItemStateBury)
+				So(item.Stats().State, ShouldEqual, ItemStateHold)
+			})
+		})
+	})
+
 	Convey("Once some items with dependencies have been added to the queue en-masse", t, func() {
 		// same setup as in previous test
 		queue := New("dep many queue")
@@ -1449,12 +1971,12 @@ func TestQueue(t *testing.T) {
 			Data: "2",
 			TTR:  30 * time.Second,
 		})
-		itemdefs = append(itemdefs, &ItemDef{"key_3", "", "3", 0, 0 * time.Second, 30 * time.Second, "", []string{}})
-		itemdefs = append(itemdefs, &ItemDef{"key_4", "", "4", 0, 0 * time.Second, 30 * time.Second, "", []string{"key_1"}})
-		itemdefs = append(itemdefs, &ItemDef{"key_5", "", "5", 0, 0 * time.Second, 30 * time.Second, "", []string{"key_2", "key_3"}})
-		itemdefs = append(itemdefs, &ItemDef{"key_6", "", "6", 0, 0 * time.Second, 30 * time.Second, "", []string{"key_3", "key_4"}})
-		itemdefs = append(itemdefs, &ItemDef{"key_7", "", "7", 0, 0 * time.Second, 30 * time.Second, "", []string{"key_5", "key_6"}})
-		itemdefs = append(itemdefs, &ItemDef{"key_8", "", "8", 0, 0 * time.Second, 30 * time.Second, "", []string{"key_5"}})
+		itemdefs = append(itemdefs, &ItemDef{"key_3", "", "3", 0, 0 * time.Second, 30 * time.Second, "", []string{}, nil, time.Time{}})
+		itemdefs = append(itemdefs, &ItemDef{"key_4", "", "4", 0, 0 * time.Second, 30 * time.Second, "", []string{"key_1"}, nil, time.Time{}})
+		itemdefs = append(itemdefs, &ItemDef{"key_5", "", "5", 0, 0 * time.Second, 30 * time.Second, "", []string{"key_2", "key_3"}, nil, time.Time{}})
+		itemdefs = append(itemdefs, &ItemDef{"key_6", "", "6", 0, 0 * time.Second, 30 * time.Second, "", []string{"key_3", "key_4"}, nil, time.Time{}})
+		itemdefs = append(itemdefs, &ItemDef{"key_7", "", "7", 0, 0 * time.Second, 30 * time.Second, "", []string{"key_5", "key_6"}, nil, time.Time{}})
+		itemdefs = append(itemdefs, &ItemDef{"key_8", "", "8", 0, 0 * time.Second, 30 * time.Second, "", []string{"key_5"}, nil, time.Time{}})
 
 		added, dups, err := queue.AddMany(itemdefs)
 		So(err, ShouldBeNil)
@@ -1470,6 +1992,77 @@ func TestQueue(t *testing.T) {
 		})
 	})
 
+	Convey("Once some items with typed dependencies have been added to the queue", t, func() {
+		queue := New("dep types queue")
+		defer queue.Destroy()
+
+		var itemdefs []*ItemDef
+		itemdefs = append(itemdefs, &ItemDef{Key: "key_1", Data: "1", TTR: 30 * time.Second})
+		itemdefs = append(itemdefs, &ItemDef{Key: "key_2", Data: "2", TTR: 30 * time.Second})
+		itemdefs = append(itemdefs, &ItemDef{Key: "ok", Data: "ok", TTR: 30 * time.Second, Dependencies: []string{"key_1"}})
+		itemdefs = append(itemdefs, &ItemDef{Key: "notok", Data: "notok", TTR: 30 * time.Second, Dependencies: []string{"key_1"}, DependencyTypes: map[string]DependencyType{"key_1": DependencyAfterNotOK}})
+		itemdefs = append(itemdefs, &ItemDef{Key: "any", Data: "any", TTR: 30 * time.Second, Dependencies: []string{"key_1", "key_2"}, DependencyTypes: map[string]DependencyType{"key_1": DependencyAfterAny, "key_2": DependencyAfterAny}})
+		added, _, err := queue.AddMany(itemdefs)
+		So(err, ShouldBeNil)
+		So(added, ShouldEqual, 5)
+
+		ok, err := queue.Get("ok")
+		So(err, ShouldBeNil)
+		notok, err := queue.Get("notok")
+		So(err, ShouldBeNil)
+		So(notok.DependencyType("key_1"), ShouldEqual, DependencyAfterNotOK)
+		So(notok.DependencyType("key_2"), ShouldEqual, DependencyAfterOK)
+		anyItem, err := queue.Get("any")
+		So(err, ShouldBeNil)
+		So(queue.Stats().Dependant, ShouldEqual, 3)
+
+		Convey("Burying a parent resolves afternotok and afterany dependencies", func() {
+			_, err = queue.Reserve()
+			So(err, ShouldBeNil)
+			_, err = queue.Reserve()
+			So(err, ShouldBeNil)
+			err = queue.Bury("key_1")
+			So(err, ShouldBeNil)
+
+			So(ok.Stats().State, ShouldEqual, ItemStateDependent)
+			So(notok.Stats().State, ShouldEqual, ItemStateReady)
+			So(anyItem.Stats().State, ShouldEqual, ItemStateDependent)
+
+			err = queue.Remove("key_2")
+			So(err, ShouldBeNil)
+			So(anyItem.Stats().State, ShouldEqual, ItemStateReady)
+
+			Convey("Kicking and then removing the parent resolves afterok dependencies", func() {
+				err = queue.Kick("key_1")
+				So(err, ShouldBeNil)
+				err = queue.Remove("key_1")
+				So(err, ShouldBeNil)
+				So(ok.Stats().State, ShouldEqual, ItemStateReady)
+				So(notok.Stats().State, ShouldEqual, ItemStateReady)
+			})
+		})
+
+		Convey("Removing a parent buries afternotok dependants", func() {
+			err = queue.Remove("key_1")
+			So(err, ShouldBeNil)
+			So(ok.Stats().State, ShouldEqual, ItemStateReady)
+			So(notok.Stats().State, ShouldEqual, ItemStateBury)
+			So(anyItem.Stats().State, ShouldEqual, ItemStateDependent)
+			So(anyItem.UnresolvedDependencies(), ShouldResemble, []string{"key_2"})
+		})
+
+		Convey("You can change the types of dependencies", func() {
+			err = queue.UpdateDependencies("ok", []string{"key_1"}, map[string]DependencyType{"key_1": DependencyAfterAny})
+			So(err, ShouldBeNil)
+			So(ok.DependencyType("key_1"), ShouldEqual, DependencyAfterAny)
+			So(ok.Stats().State, ShouldEqual, ItemStateDependent)
+
+			err = queue.Remove("key_1")
+			So(err, ShouldBeNil)
+			So(ok.Stats().State, ShouldEqual, ItemStateReady)
+		})
+	})
+
 	Convey("When you add items to the queue over time, slow readyAddedCallbacks only get called once at a time", t, func() {
 		queue := New("myqueue")
 		defer queue.Destroy()
@@ -24,34 +24,183 @@ package queue
 import (
 	"container/heap"
 	"sync"
+	"time"
 )
 
+// in the ready sub-queue, items are held in a separate heap for each
+// ReserveGroup and fair-share party (the latter always being "" unless
+// SetFairShare() has been used), with items that have been held back (which
+// pop() ignores) kept in their own set of heaps; reserveGroup, party and
+// heldBack say which heap the heap methods currently work on.
 type subQueue struct {
 	mutex        sync.RWMutex
 	items        []*Item
-	groupedItems map[string][]*Item
+	groupedItems map[string]map[string][]*Item
+	heldItems    map[string]map[string][]*Item
 	sqIndex      int
 	reserveGroup string
+	party        string
+	heldBack     bool
+	partyCb      PartyCallback
+	usageCb      UsageCallback
+	filterCb     ReserveFilterCallback
+	aging        *priorityAging
+	agingItems   agingHeap
+	clock        func() time.Time
 }
 
 // create a new subQueue that can hold *Items in "priority" order. sqIndex is
 // one of 0 (priority is based on the item's delay), 1 (priority is based on the
 // item's priority or creation) or 2 (priority is based on the item's ttr).
 func newSubQueue(sqIndex int) *subQueue {
-	queue := &subQueue{sqIndex: sqIndex}
+	queue := &subQueue{sqIndex: sqIndex, clock: time.Now}
 	if sqIndex == 1 {
-		queue.groupedItems = make(map[string][]*Item)
+		queue.groupedItems = make(map[string]map[string][]*Item)
+		queue.heldItems = make(map[string]map[string][]*Item)
 	}
 	heap.Init(queue)
 	return queue
 }
 
+// setFairShare changes the callbacks used to decide the party each item
+// belongs to and the order those parties get their items popped in, rearranging
+// all existing items accordingly.
+func (q *subQueue) setFairShare(partyCb PartyCallback, usageCb UsageCallback) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	items := q.allItems()
+	q.partyCb = partyCb
+	q.usageCb = usageCb
+	q.groupedItems = make(map[string]map[string][]*Item)
+	q.heldItems = make(map[string]map[string][]*Item)
+	for _, item := range items {
+		q.reserveGroup = item.ReserveGroup
+		q.party = q.partyOf(item)
+		q.heldBack = item.heldBack
+		item.party = q.party
+		heap.Push(q, item)
+	}
+}
+
+// setPriorityAging changes how the effective priority of items rises the longer
+// they wait, immediately reordering existing items accordingly. nil turns off
+// aging.
+func (q *subQueue) setPriorityAging(pa *priorityAging) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if pa != nil {
+		pa.clock = q.clock
+	}
+	q.aging = pa
+	q.agingItems = nil
+	for _, heldBack := range []bool{false, true} {
+		for group, parties := range q.heaps(heldBack) {
+			for party, itemList := range parties {
+				for _, item := range itemList {
+					item.mutex.Lock()
+					item.aging = pa
+					item.effPriority = item.effectivePriority()
+					item.agingIndex = -1
+					item.mutex.Unlock()
+					q.trackAging(item)
+				}
+				q.reserveGroup = group
+				q.party = party
+				q.heldBack = heldBack
+				heap.Init(q)
+			}
+		}
+	}
+}
+
+// setReserveFilter changes the callback pop() uses to decide if an item can be
+// popped.
+func (q *subQueue) setReserveFilter(filterCb ReserveFilterCallback) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.filterCb = filterCb
+}
+
+// age recalculates the effective priority of just the items whose aged
+// priority is now due to have risen, fixing their position in their heap. You
+// must hold the lock before calling this.
+func (q *subQueue) age() {
+	now := q.clock()
+	for len(q.agingItems) > 0 && !q.agingItems[0].nextAging.After(now) {
+		item := q.agingItems[0]
+		item.mutex.Lock()
+		effective := item.effectivePriority()
+		changed := effective != item.effPriority
+		item.effPriority = effective
+		next, rises := item.aging.nextRise(item.priority, item.readySince)
+		item.mutex.Unlock()
+
+		if changed {
+			q.reserveGroup = item.ReserveGroup
+			q.party = item.party
+			q.heldBack = item.heldBack
+			heap.Fix(q, item.queueIndexes[q.sqIndex])
+		}
+
+		if rises {
+			item.nextAging = next
+			heap.Fix(&q.agingItems, 0)
+		} else {
+			heap.Pop(&q.agingItems)
+		}
+	}
+}
+
+// trackAging notes when the given item's aged priority will next rise, so that
+// age() will consider it then. You must hold the lock before calling this.
+func (q *subQueue) trackAging(item *Item) {
+	item.mutex.RLock()
+	next, rises := item.aging.nextRise(item.priority, item.readySince)
+	item.mutex.RUnlock()
+	if !rises {
+		q.untrackAging(item)
+		return
+	}
+	item.nextAging = next
+	if item.agingIndex >= 0 {
+		heap.Fix(&q.agingItems, item.agingIndex)
+		return
+	}
+	heap.Push(&q.agingItems, item)
+}
+
+// untrackAging stops age() considering the given item. You must hold the lock
+// before calling this.
+func (q *subQueue) untrackAging(item *Item) {
+	if item.agingIndex >= 0 {
+		heap.Remove(&q.agingItems, item.agingIndex)
+	}
+}
+
+// partyOf returns the fair-share party of the given item.
+func (q *subQueue) partyOf(item *Item) string {
+	if q.partyCb == nil {
+		return ""
+	}
+	return q.partyCb(item.Data)
+}
+
 // push adds an item to the queue
 func (q *subQueue) push(item *Item) {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
 	if q.sqIndex == 1 {
 		q.reserveGroup = item.ReserveGroup
+		q.party = q.partyOf(item)
+		q.heldBack = false
+		item.party = q.party
+		item.heldBack = false
+		item.mutex.Lock()
+		item.readySince = q.clock()
+		item.aging = q.aging
+		item.effPriority = item.effectivePriority()
+		item.mutex.Unlock()
+		q.trackAging(item)
 	}
 	heap.Push(q, item)
 }
@@ -60,59 +209,146 @@ func (q *subQueue) push(item *Item) {
 func (q *subQueue) pop(reserveGroup ...string) *Item {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
-	var itemList []*Item
 	if q.sqIndex == 1 {
 		var group string
 		if len(reserveGroup) == 1 {
 			group = reserveGroup[0]
 		}
-		var existed bool
-		if itemList, existed = q.groupedItems[group]; !existed {
+		parties, existed := q.groupedItems[group]
+		if !existed {
 			return nil
 		}
-		q.reserveGroup = group
-	} else {
-		itemList = q.items
-	}
-	if len(itemList) == 0 {
+		q.age()
+
+		// items the filter rejects are put back once we're done
+		var rejected []*Item
+		defer func() {
+			for _, item := range rejected {
+				q.reserveGroup = item.ReserveGroup
+				q.party = item.party
+				q.heldBack = false
+				heap.Push(q, item)
+			}
+		}()
+
+		q.heldBack = false
+		for {
+			q.reserveGroup = group
+			if q.party, existed = q.nextParty(parties); !existed {
+				return nil
+			}
+			item := heap.Pop(q).(*Item)
+			if q.filterCb == nil || q.filterCb(item.Data) {
+				q.untrackAging(item)
+				return item
+			}
+			rejected = append(rejected, item)
+		}
+	} else if len(q.items) == 0 {
 		return nil
 	}
 	return heap.Pop(q).(*Item)
 }
 
+// nextParty returns the party that should have its first item popped next, out
+// of the given items keyed on party: the one whose first item has the highest
+// effective priority, or for equal priorities the one with the lowest usage, or
+// for equal usages the one with the oldest first item. The bool is false if
+// there are no items.
+func (q *subQueue) nextParty(parties map[string][]*Item) (string, bool) {
+	var next string
+	var nextItem *Item
+	var nextUsage float64
+	for party, itemList := range parties {
+		if len(itemList) == 0 {
+			continue
+		}
+		item := itemList[0]
+		var usage float64
+		if q.usageCb != nil {
+			usage = q.usageCb(party)
+		}
+		if nextItem != nil {
+			if item.effPriority != nextItem.effPriority {
+				if item.effPriority < nextItem.effPriority {
+					continue
+				}
+			} else if usage != nextUsage {
+				if usage > nextUsage {
+					continue
+				}
+			} else if !item.creation.Before(nextItem.creation) {
+				continue
+			}
+		}
+		next, nextItem, nextUsage = party, item, usage
+	}
+	return next, nextItem != nil
+}
+
 // remove removes a given item from the queue
 func (q *subQueue) remove(item *Item) {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
 	if q.sqIndex == 1 {
 		q.reserveGroup = item.ReserveGroup
+		q.party = item.party
+		q.heldBack = item.heldBack
+		q.untrackAging(item)
+		item.heldBack = false
 	}
 	heap.Remove(q, item.queueIndexes[q.sqIndex])
 }
 
+// holdBack moves the items with the given keys out of the heaps that pop()
+// considers, and moves any previously held back items that don't have one of
+// the given keys back in to them.
+func (q *subQueue) holdBack(keys map[string]bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	for _, heldBack := range []bool{false, true} {
+		var move []*Item
+		for _, parties := range q.heaps(heldBack) {
+			for _, itemList := range parties {
+				for _, item := range itemList {
+					if keys[item.Key] != heldBack {
+						move = append(move, item)
+					}
+				}
+			}
+		}
+
+		for _, item := range move {
+			q.reserveGroup = item.ReserveGroup
+			q.party = item.party
+			q.heldBack = heldBack
+			heap.Remove(q, item.queueIndexes[q.sqIndex])
+			q.heldBack = !heldBack
+			item.heldBack = !heldBack
+			heap.Push(q, item)
+		}
+	}
+}
+
 // len tells you how many items are in the queue
 func (q *subQueue) len(reserveGroup ...string) int {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
-	var itemList []*Item
 	if q.sqIndex == 1 {
-		if len(reserveGroup) == 1 {
-			group := reserveGroup[0]
-			var existed bool
-			if itemList, existed = q.groupedItems[group]; !existed {
-				return 0
+		num := 0
+		for _, heldBack := range []bool{false, true} {
+			for group, parties := range q.heaps(heldBack) {
+				if len(reserveGroup) == 1 && group != reserveGroup[0] {
+					continue
+				}
+				for _, itemList := range parties {
+					num += len(itemList)
+				}
 			}
-		} else {
-			num := 0
-			for _, il := range q.groupedItems {
-				num += len(il)
-			}
-			return num
 		}
-	} else {
-		itemList = q.items
+		return num
 	}
-	return len(itemList)
+	return len(q.items)
 }
 
 // firstItem is useful in testing to get the first item in the queue in a
@@ -125,16 +361,34 @@ func (q *subQueue) firstItem() *Item {
 
 // update ensures that if an item's "priority" characteristic(s) change, that
 // its order in the queue is corrected. Optional oldGroup is the previous
-// ReserveGroup that this item had, supplied if the group changed.
+// ReserveGroup that this item had, supplied if the group changed. In the ready
+// sub-queue, this also notices if the item's fair-share party changed.
 func (q *subQueue) update(item *Item, oldGroup ...string) {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
-	if q.sqIndex == 1 && len(oldGroup) == 1 && oldGroup[0] != item.ReserveGroup {
-		q.reserveGroup = oldGroup[0]
-		heap.Remove(q, item.queueIndexes[q.sqIndex])
-		q.reserveGroup = item.ReserveGroup
-		heap.Push(q, item)
-		return
+	if q.sqIndex == 1 {
+		group := item.ReserveGroup
+		if len(oldGroup) == 1 {
+			group = oldGroup[0]
+		}
+		item.mutex.Lock()
+		item.effPriority = item.effectivePriority()
+		item.mutex.Unlock()
+		q.trackAging(item)
+		party := q.partyOf(item)
+		q.heldBack = item.heldBack
+		if group != item.ReserveGroup || party != item.party {
+			q.reserveGroup = group
+			q.party = item.party
+			heap.Remove(q, item.queueIndexes[q.sqIndex])
+			q.reserveGroup = item.ReserveGroup
+			q.party = party
+			item.party = party
+			heap.Push(q, item)
+			return
+		}
+		q.reserveGroup = group
+		q.party = party
 	}
 	heap.Fix(q, item.queueIndexes[q.sqIndex])
 }
@@ -144,27 +398,78 @@ func (q *subQueue) empty() {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
 	if q.sqIndex == 1 {
-		q.groupedItems = make(map[string][]*Item)
+		for _, item := range q.agingItems {
+			item.agingIndex = -1
+		}
+		q.agingItems = nil
+		q.groupedItems = make(map[string]map[string][]*Item)
+		q.heldItems = make(map[string]map[string][]*Item)
 	} else {
 		q.items = nil
 	}
 }
 
+// allItems returns all the items in the ready sub-queue, including held back
+// ones. You must hold the lock before calling this.
+func (q *subQueue) allItems() []*Item {
+	var items []*Item
+	for _, heldBack := range []bool{false, true} {
+		for _, parties := range q.heaps(heldBack) {
+			for _, itemList := range parties {
+				items = append(items, itemList...)
+			}
+		}
+	}
+	return items
+}
+
+// heaps returns the ready sub-queue's heaps of items that have or haven't been
+// held back, keyed on ReserveGroup and then party.
+func (q *subQueue) heaps(heldBack bool) map[string]map[string][]*Item {
+	if heldBack {
+		return q.heldItems
+	}
+	return q.groupedItems
+}
+
+// itemList returns the items the heap methods currently work on.
+func (q *subQueue) itemList() []*Item {
+	if q.sqIndex == 1 {
+		return q.heaps(q.heldBack)[q.reserveGroup][q.party]
+	}
+	return q.items
+}
+
+// setItemList stores the items the heap methods currently work on.
+func (q *subQueue) setItemList(itemList []*Item) {
+	if q.sqIndex != 1 {
+		q.items = itemList
+		return
+	}
+	heaps := q.heaps(q.heldBack)
+	parties, existed := heaps[q.reserveGroup]
+	if !existed {
+		parties = make(map[string][]*Item)
+		heaps[q.reserveGroup] = parties
+	}
+	if len(itemList) == 0 && (q.party != "" || q.heldBack) {
+		// don't accumulate the empty heaps of parties that are no longer
+		// adding items, or that no longer have items held back
+		delete(parties, q.party)
+		if q.heldBack && len(parties) == 0 {
+			delete(heaps, q.reserveGroup)
+		}
+		return
+	}
+	parties[q.party] = itemList
+}
+
 // the following functions are required for the heap implementation, and though
 // they are exported they are not supposed to be used directly - use the above
 // methods instead
 
 func (q *subQueue) Len() int {
-	var itemList []*Item
-	if q.sqIndex == 1 {
-		var existed bool
-		if itemList, existed = q.groupedItems[q.reserveGroup]; !existed {
-			return 0
-		}
-	} else {
-		itemList = q.items
-	}
-	return len(itemList)
+	return len(q.itemList())
 }
 
 func (q *subQueue) Less(i, j int) bool {
@@ -172,28 +477,18 @@ func (q *subQueue) Less(i, j int) bool {
 	case 0:
 		return q.items[i].readyAt.Before(q.items[j].readyAt)
 	case 1:
-		if itemList, existed := q.groupedItems[q.reserveGroup]; existed {
-			if itemList[i].priority == itemList[j].priority {
-				return itemList[i].creation.Before(itemList[j].creation)
-			}
-			return itemList[i].priority > itemList[j].priority
+		itemList := q.itemList()
+		if itemList[i].effPriority == itemList[j].effPriority {
+			return itemList[i].creation.Before(itemList[j].creation)
 		}
-		return false
+		return itemList[i].effPriority > itemList[j].effPriority
 	}
 	// case 2, outside the switch, because we need to return
 	return q.items[i].releaseAt.Before(q.items[j].releaseAt)
 }
 
 func (q *subQueue) Swap(i, j int) {
-	var itemList []*Item
-	if q.sqIndex == 1 {
-		var existed bool
-		if itemList, existed = q.groupedItems[q.reserveGroup]; !existed {
-			return
-		}
-	} else {
-		itemList = q.items
-	}
+	itemList := q.itemList()
 	itemList[i], itemList[j] = itemList[j], itemList[i]
 	itemList[i].mutex.Lock()
 	defer itemList[i].mutex.Unlock()
@@ -207,46 +502,57 @@ func (q *subQueue) Swap(i, j int) {
 
 func (q *subQueue) Push(x interface{}) {
 	item := x.(*Item)
-	var itemList []*Item
-	if q.sqIndex == 1 {
-		var existed bool
-		if itemList, existed = q.groupedItems[q.reserveGroup]; !existed {
-			q.groupedItems[q.reserveGroup] = itemList
-		}
-	} else {
-		itemList = q.items
-	}
+	itemList := q.itemList()
 	item.mutex.Lock()
 	item.queueIndexes[q.sqIndex] = len(itemList)
 	item.mutex.Unlock()
-	itemList = append(itemList, item)
-	if q.sqIndex == 1 {
-		q.groupedItems[q.reserveGroup] = itemList
-	} else {
-		q.items = itemList
-	}
+	q.setItemList(append(itemList, item))
 }
 
 func (q *subQueue) Pop() interface{} {
-	var itemList []*Item
-	if q.sqIndex == 1 {
-		var existed bool
-		if itemList, existed = q.groupedItems[q.reserveGroup]; !existed {
-			return nil
-		}
-	} else {
-		itemList = q.items
+	itemList := q.itemList()
+	if len(itemList) == 0 {
+		return nil
 	}
 	lasti := len(itemList) - 1
 	item := itemList[lasti]
 	item.mutex.Lock()
 	item.queueIndexes[q.sqIndex] = -1
 	item.mutex.Unlock()
-	itemList = itemList[:lasti]
-	if q.sqIndex == 1 {
-		q.groupedItems[q.reserveGroup] = itemList
-	} else {
-		q.items = itemList
-	}
+	q.setItemList(itemList[:lasti])
+	return item
+}
+
+// agingHeap orders items in the ready sub-queue by when their aged priority
+// will next rise, so that age() only has to look at the items that are due. It
+// is only used while holding the subQueue's lock.
+type agingHeap []*Item
+
+func (h agingHeap) Len() int {
+	return len(h)
+}
+
+func (h agingHeap) Less(i, j int) bool {
+	return h[i].nextAging.Before(h[j].nextAging)
+}
+
+func (h agingHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].agingIndex = i
+	h[j].agingIndex = j
+}
+
+func (h *agingHeap) Push(x interface{}) {
+	item := x.(*Item)
+	item.agingIndex = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *agingHeap) Pop() interface{} {
+	old := *h
+	lasti := len(old) - 1
+	item := old[lasti]
+	item.agingIndex = -1
+	*h = old[:lasti]
 	return item
 }
@@ -147,3 +147,53 @@ var getParameterByName = function(name) {
     if (!results[2]) return '';
     return decodeURIComponent(results[2].replace(/\+/g, " "));
 };
+
+// given the Profile of a job (an array of samples with Time, RAM (MB), Disk
+// (MB) and CPU (cumulative nanoseconds) properties), returns the markup of an
+// SVG line plot of RAM, disk and CPU (as cores in use between samples) over
+// time, each scaled to its own maximum.
+var resourceProfileSVG = function(samples) {
+    var width = 300;
+    var height = 80;
+    var times = [];
+    var series = { RAM: [], Disk: [], CPU: [] };
+    for (var i = 0; i < samples.length; i++) {
+        var t = Date.parse(samples[i].Time) / 1000;
+        times.push(t);
+        series.RAM.push(samples[i].RAM);
+        series.Disk.push(samples[i].Disk);
+        var cores = 0;
+        if (i > 0 && t > times[i - 1]) {
+            cores = Math.max(0, (samples[i].CPU - samples[i - 1].CPU) / 1e9 / (t - times[i - 1]));
+        }
+        series.CPU.push(cores);
+    }
+    var start = times[0];
+    var span = (times[times.length - 1] - start) || 1;
+
+    var colours = { RAM: '#337ab7', Disk: '#5cb85c', CPU: '#d9534f' };
+    var descs = {
+        RAM: function(max) { return 'RAM (peak ' + (max > 0 ? max.mbIEC() : '0 MB') + ')'; },
+        Disk: function(max) { return 'Disk (peak ' + (max > 0 ? max.mbIEC() : '0 MB') + ')'; },
+        CPU: function(max) { return 'CPU (peak ' + max.toFixed(2) * 1 + ' cores)'; }
+    };
+    var svg = '<svg xmlns="http://www.w3.org/2000/svg" width="' + width + '" height="' + (height + 15) + '">';
+    svg += '<rect x="0" y="0" width="' + width + '" height="' + height + '" fill="#fff" stroke="#ddd"/>';
+    var legendX = 0;
+    for (var name in series) {
+        var values = series[name];
+        var max = Math.max.apply(null, values);
+        var points = [];
+        for (var i = 0; i < values.length; i++) {
+            var x = ((times[i] - start) / span) * width;
+            var y = height - (max > 0 ? (values[i] / max) * (height - 2) : 0) - 1;
+            points.push(x.toFixed(1) + ',' + y.toFixed(1));
+        }
+        var desc = descs[name](max);
+        svg += '<polyline fill="none" stroke-width="1.5" stroke="' + colours[name] + '" points="' + points.join(' ') + '"><title>' + desc + '</title></polyline>';
+        svg += '<text x="' + legendX + '" y="' + (height + 12) + '" font-size="10" fill="' + colours[name] + '">' + desc + '</text>';
+        legendX += width / 3;
+    }
+    svg += '</svg>';
+    return svg;
+};